@@ -29,6 +29,8 @@ type EchoApplication struct {
 	OrderIds map[string]bool
 }
 
+func (e EchoApplication) OnSessionCreate(sessionID quickfix.SessionID) {
+}
 func (e EchoApplication) OnCreate(sessionID quickfix.SessionID) {
 	e.log.Printf("OnCreate %v\n", sessionID.String())
 }
@@ -19,11 +19,15 @@ import (
 	"bufio"
 	"bytes"
 	"crypto/tls"
+	"fmt"
 	"io"
 	"net"
 	"runtime/debug"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	proxyproto "github.com/pires/go-proxyproto"
 
@@ -43,10 +47,14 @@ type Acceptor struct {
 	dynamicSessions       bool
 	dynamicQualifier      bool
 	dynamicQualifierCount int
+	acceptedBeginStrings  map[string]bool
+	sessionTemplates      map[SessionID]*SessionSettings
 	dynamicSessionChan    chan *session
+	runningDynamic        sync.Map
 	sessionAddr           sync.Map
 	sessionHostPort       map[SessionID]int
 	listeners             map[string]net.Listener
+	addedListeners        []net.Listener
 	connectionValidator   ConnectionValidator
 	tlsConfig             *tls.Config
 	sessionFactory
@@ -75,6 +83,10 @@ func (a *Acceptor) Start() (err error) {
 			if a.sessionHostPort[sessionID], err = sessionSettings.IntSetting(config.SocketAcceptPort); err != nil {
 				return
 			}
+		} else if len(a.addedListeners) > 0 && !a.settings.GlobalSettings().HasSetting(config.SocketAcceptPort) {
+			// The session connects through an added listener only.
+			delete(a.sessionHostPort, sessionID)
+			continue
 		} else if a.sessionHostPort[sessionID], err = a.settings.GlobalSettings().IntSetting(config.SocketAcceptPort); err != nil {
 			return
 		}
@@ -108,6 +120,9 @@ func (a *Acceptor) Start() (err error) {
 			a.listeners[address] = &proxyproto.Listener{Listener: a.listeners[address]}
 		}
 	}
+	for _, listener := range a.addedListeners {
+		a.listeners[listener.Addr().String()] = listener
+	}
 
 	for _, s := range a.sessions {
 		a.sessionGroup.Add(1)
@@ -116,7 +131,7 @@ func (a *Acceptor) Start() (err error) {
 			a.sessionGroup.Done()
 		}(s)
 	}
-	if a.dynamicSessions {
+	if a.dynamicSessions || len(a.sessionTemplates) > 0 {
 		a.dynamicSessionChan = make(chan *session)
 		a.sessionGroup.Add(1)
 		go func() {
@@ -141,7 +156,7 @@ func (a *Acceptor) Stop() {
 		listener.Close()
 	}
 	a.listenerShutdown.Wait()
-	if a.dynamicSessions {
+	if a.dynamicSessionChan != nil {
 		close(a.dynamicSessionChan)
 	}
 	for _, session := range a.sessions {
@@ -157,6 +172,15 @@ func (a *Acceptor) Stop() {
 	}
 }
 
+// StopWithDrain behaves like Stop, but first waits up to timeout for the messages queued by the sessions to be
+// written, so that they are not lost when logging out. If timeout expires, it stops anyway and returns a
+// DrainTimeoutError reporting the number of messages dropped.
+func (a *Acceptor) StopWithDrain(timeout time.Duration) error {
+	err := drainSessions(a.sessions, timeout)
+	a.Stop()
+	return err
+}
+
 // RemoteAddr gets remote IP address for a given session.
 func (a *Acceptor) RemoteAddr(sessionID SessionID) (net.Addr, bool) {
 	addr, ok := a.sessionAddr.Load(sessionID)
@@ -167,17 +191,52 @@ func (a *Acceptor) RemoteAddr(sessionID SessionID) (net.Addr, bool) {
 	return val, ok
 }
 
+// Sessions returns the IDs of the sessions that are currently logged on, including dynamic sessions, sorted
+// by their string form.
+func (a *Acceptor) Sessions() []SessionID {
+	return a.sessionIDs(func(s *session) bool { return s.loggedOnState.Load() })
+}
+
+// AllSessions returns the IDs of the configured sessions, whatever their state, and of the dynamic sessions
+// currently connected, sorted by their string form.
+func (a *Acceptor) AllSessions() []SessionID {
+	return a.sessionIDs(func(*session) bool { return true })
+}
+
+// sessionIDs returns the IDs of the sessions matching the filter.
+func (a *Acceptor) sessionIDs(filter func(*session) bool) []SessionID {
+	var ids []SessionID
+	for sessionID, s := range a.sessions {
+		if filter(s) {
+			ids = append(ids, sessionID)
+		}
+	}
+	a.runningDynamic.Range(func(_, value interface{}) bool {
+		if s := value.(*session); filter(s) {
+			ids = append(ids, s.sessionID)
+		}
+		return true
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+	return ids
+}
+
 // NewAcceptor creates and initializes a new Acceptor.
 func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Settings, logFactory LogFactory) (a *Acceptor, err error) {
 	a = &Acceptor{
-		app:             app,
-		storeFactory:    storeFactory,
-		settings:        settings,
-		logFactory:      logFactory,
-		sessions:        make(map[SessionID]*session),
-		sessionHostPort: make(map[SessionID]int),
-		listeners:       make(map[string]net.Listener),
+		app:              app,
+		storeFactory:     storeFactory,
+		settings:         settings,
+		logFactory:       logFactory,
+		sessions:         make(map[SessionID]*session),
+		sessionTemplates: make(map[SessionID]*SessionSettings),
+		sessionHostPort:  make(map[SessionID]int),
+		listeners:        make(map[string]net.Listener),
+	}
+	if err = settings.Validate(); err != nil {
+		return
 	}
+
 	if a.settings.GlobalSettings().HasSetting(config.DynamicSessions) {
 		if a.dynamicSessions, err = settings.globalSettings.BoolSetting(config.DynamicSessions); err != nil {
 			return
@@ -190,11 +249,26 @@ func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Se
 		}
 	}
 
+	if a.settings.GlobalSettings().HasSetting(config.AcceptedBeginStrings) {
+		var raw string
+		if raw, err = settings.globalSettings.Setting(config.AcceptedBeginStrings); err != nil {
+			return
+		}
+		if a.acceptedBeginStrings, err = parseAcceptedBeginStrings(raw); err != nil {
+			return
+		}
+	}
+
 	if a.globalLog, err = logFactory.Create(); err != nil {
 		return
 	}
 
 	for sessionID, sessionSettings := range settings.SessionSettings() {
+		if isSessionTemplate(sessionID) {
+			a.sessionTemplates[sessionID] = sessionSettings
+			continue
+		}
+
 		sessID := sessionID
 		sessID.Qualifier = ""
 
@@ -210,6 +284,22 @@ func NewAcceptor(app Application, storeFactory MessageStoreFactory, settings *Se
 	return
 }
 
+// parseAcceptedBeginStrings parses the comma separated BeginStrings of the AcceptedBeginStrings setting.
+func parseAcceptedBeginStrings(raw string) (map[string]bool, error) {
+	accepted := make(map[string]bool)
+	for _, beginString := range strings.Split(raw, ",") {
+		beginString = strings.TrimSpace(beginString)
+		switch beginString {
+		case BeginStringFIX40, BeginStringFIX41, BeginStringFIX42, BeginStringFIX43, BeginStringFIX44, BeginStringFIXT11:
+			accepted[beginString] = true
+		default:
+			return nil, IncorrectFormatForSetting{Setting: config.AcceptedBeginStrings, Value: []byte(raw),
+				Err: fmt.Errorf("%q is not a supported FIX version", beginString)}
+		}
+	}
+	return accepted, nil
+}
+
 func (a *Acceptor) listenForConnections(listener net.Listener) {
 	defer a.listenerShutdown.Done()
 
@@ -265,6 +355,10 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 		a.invalidMessage(msgBytes, err)
 		return
 	}
+	if a.acceptedBeginStrings != nil && !a.acceptedBeginStrings[string(beginString)] {
+		a.globalLog.OnEventf("BeginString %s not accepted for incoming message: %s", beginString, msgBytes)
+		return
+	}
 
 	var senderCompID FIXString
 	if err := msg.Header.GetField(tagSenderCompID, &senderCompID); err != nil {
@@ -315,10 +409,12 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 		TargetCompID: string(senderCompID), TargetSubID: string(senderSubID), TargetLocationID: string(senderLocationID),
 	}
 
-	localConnectionPort := netConn.LocalAddr().(*net.TCPAddr).Port
-	if expectedPort, ok := a.sessionHostPort[sessID]; ok && expectedPort != localConnectionPort {
-		a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
-		return
+	// Connections from added listeners, e.g. multiplexed FIX streams, may not have a TCP address to check.
+	if localAddr, ok := netConn.LocalAddr().(*net.TCPAddr); ok {
+		if expectedPort, ok := a.sessionHostPort[sessID]; ok && expectedPort != localAddr.Port {
+			a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
+			return
+		}
 	}
 
 	// We have a session ID and a network connection. This seems to be a good place for any custom authentication logic.
@@ -335,11 +431,22 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 	}
 	session, ok := a.sessions[sessID]
 	if !ok {
-		if !a.dynamicSessions {
+		var sessionSettings *SessionSettings
+		if templateID, ok := a.matchSessionTemplate(sessID); ok {
+			if localAddr, ok := netConn.LocalAddr().(*net.TCPAddr); ok {
+				if expectedPort, ok := a.sessionHostPort[templateID]; ok && expectedPort != localAddr.Port {
+					a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
+					return
+				}
+			}
+			sessionSettings = a.sessionTemplates[templateID].clone()
+		} else if a.dynamicSessions {
+			sessionSettings = a.settings.globalSettings.clone()
+		} else {
 			a.globalLog.OnEventf("Session %v not found for incoming message: %s", sessID, msgBytes)
 			return
 		}
-		dynamicSession, err := a.sessionFactory.createSession(sessID, a.storeFactory, a.settings.globalSettings.clone(), a.logFactory, a.app)
+		dynamicSession, err := a.sessionFactory.createSession(sessID, a.storeFactory, sessionSettings, a.logFactory, a.app)
 		if err != nil {
 			a.globalLog.OnEventf("Dynamic session %v failed to create: %v", sessID, err)
 			return
@@ -349,6 +456,8 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 		defer session.stop()
 	}
 
+	session.setSocketBuffers(netConn)
+	session.setKeepAlive(netConn)
 	a.sessionAddr.Store(sessID, netConn.RemoteAddr())
 	msgIn := make(chan fixIn)
 	msgOut := make(chan []byte)
@@ -366,6 +475,41 @@ func (a *Acceptor) handleConnection(netConn net.Conn) {
 	writeLoop(netConn, msgOut, a.globalLog)
 }
 
+// sessionCompIDWildcard is the SenderCompID or TargetCompID of a session template.
+const sessionCompIDWildcard = "*"
+
+// isSessionTemplate returns true if the session is configured with a wildcard SenderCompID or TargetCompID.
+func isSessionTemplate(sessionID SessionID) bool {
+	return sessionID.SenderCompID == sessionCompIDWildcard || sessionID.TargetCompID == sessionCompIDWildcard
+}
+
+// matchSessionTemplate returns the session template matching sessID. A template with a single wildcard is
+// preferred over one with two, and templates matching equally well are tried in SessionID order.
+func (a *Acceptor) matchSessionTemplate(sessID SessionID) (SessionID, bool) {
+	var match SessionID
+	matchWildcards := 3
+	for templateID := range a.sessionTemplates {
+		candidate := templateID
+		candidate.Qualifier = sessID.Qualifier
+		wildcards := 0
+		if candidate.SenderCompID == sessionCompIDWildcard {
+			candidate.SenderCompID = sessID.SenderCompID
+			wildcards++
+		}
+		if candidate.TargetCompID == sessionCompIDWildcard {
+			candidate.TargetCompID = sessID.TargetCompID
+			wildcards++
+		}
+		if candidate != sessID {
+			continue
+		}
+		if wildcards < matchWildcards || (wildcards == matchWildcards && templateID.String() < match.String()) {
+			match, matchWildcards = templateID, wildcards
+		}
+	}
+	return match, matchWildcards < 3
+}
+
 func (a *Acceptor) dynamicSessionsLoop() {
 	var id int
 	var sessions = map[int]*session{}
@@ -384,6 +528,7 @@ LOOP:
 			id++
 			sessionID := id
 			sessions[sessionID] = session
+			a.runningDynamic.Store(session.sessionID, session)
 			go func() {
 				session.run()
 				err := UnregisterSession(session.sessionID)
@@ -397,6 +542,7 @@ LOOP:
 			session, ok := sessions[id]
 			if ok {
 				a.sessionAddr.Delete(session.sessionID)
+				a.runningDynamic.Delete(session.sessionID)
 				delete(sessions, id)
 			} else {
 				a.globalLog.OnEventf("Missing dynamic session %v!", id)
@@ -426,6 +572,14 @@ func (a *Acceptor) SetConnectionValidator(validator ConnectionValidator) {
 	a.connectionValidator = validator
 }
 
+// AddListener makes the Acceptor accept connections from listener in addition to those configured by
+// SocketAcceptPort, e.g. FIX streams multiplexed over a single connection. Sessions without a SocketAcceptPort
+// setting only connect through added listeners. It must be called before Start, and the Acceptor closes
+// listener on Stop.
+func (a *Acceptor) AddListener(listener net.Listener) {
+	a.addedListeners = append(a.addedListeners, listener)
+}
+
 // SetTLSConfig allows the creator of the Acceptor to specify a fully customizable tls.Config of their choice,
 // which will be used in the Start() method.
 //
@@ -16,9 +16,12 @@
 package quickfix
 
 import (
+	"bufio"
 	"crypto/tls"
 	"net"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/quickfixgo/quickfix/config"
 
@@ -126,3 +129,152 @@ func TestAcceptor_SetTLSConfig(t *testing.T) {
 	assert.NotNil(t, conn)
 	defer conn.Close()
 }
+
+func TestAcceptor_AcceptedBeginStrings(t *testing.T) {
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.AcceptedBeginStrings, "FIX.4.2, FIX.4.4")
+	acceptor, err := NewAcceptor(nil, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{BeginStringFIX42: true, BeginStringFIX44: true}, acceptor.acceptedBeginStrings)
+
+	connect := func(beginString string) []string {
+		log := new(eventLog)
+		acceptor.globalLog = log
+		server, client := net.Pipe()
+		go func() {
+			_, _ = client.Write([]byte("8=" + beginString + "\x019=36\x0135=A\x0134=1\x0149=TW\x0156=ISLD\x0198=0\x01108=30\x0110=000\x01"))
+		}()
+		acceptor.handleConnection(server)
+		_ = client.Close()
+		return log.events
+	}
+	assert.Contains(t, connect(BeginStringFIX43)[0], "BeginString FIX.4.3 not accepted")
+	assert.Contains(t, connect(BeginStringFIX44)[0], "not found for incoming message")
+
+	settings.GlobalSettings().Set(config.AcceptedBeginStrings, "FIX.4.2,FIX.9.9")
+	_, err = NewAcceptor(nil, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	assert.Error(t, err)
+}
+
+func TestAcceptor_SessionTemplates(t *testing.T) {
+	newSettings := func(senderCompID, targetCompID string) *SessionSettings {
+		s := NewSessionSettings()
+		s.Set(config.BeginString, BeginStringFIX44)
+		s.Set(config.SenderCompID, senderCompID)
+		s.Set(config.TargetCompID, targetCompID)
+		s.Set(config.HeartBtInt, "30")
+		return s
+	}
+	settings := NewSettings()
+	settings.GlobalSettings().Set(config.SocketAcceptPort, "5002")
+	for _, s := range []*SessionSettings{newSettings("ISLD", "TW"), newSettings("ISLD", "*"), newSettings("*", "*")} {
+		_, err := settings.AddSession(s)
+		require.NoError(t, err)
+	}
+
+	app := new(MockApp)
+	app.On("FromAdmin").Return(nil)
+	app.On("ToAdmin")
+	app.On("OnLogon")
+	app.On("OnLogout")
+	acceptor, err := NewAcceptor(app, NewMemoryStoreFactory(), settings, NewNullLogFactory())
+	require.NoError(t, err)
+	assert.Len(t, acceptor.sessions, 1, "templates do not create sessions")
+	assert.Len(t, acceptor.sessionTemplates, 2)
+
+	for _, tc := range []struct {
+		sessID   SessionID
+		template SessionID
+		matched  bool
+	}{
+		{SessionID{BeginString: BeginStringFIX44, SenderCompID: "ISLD", TargetCompID: "CLIENT1"}, SessionID{BeginString: BeginStringFIX44, SenderCompID: "ISLD", TargetCompID: "*"}, true},
+		{SessionID{BeginString: BeginStringFIX44, SenderCompID: "OTHER", TargetCompID: "CLIENT1"}, SessionID{BeginString: BeginStringFIX44, SenderCompID: "*", TargetCompID: "*"}, true},
+		{SessionID{BeginString: BeginStringFIX42, SenderCompID: "ISLD", TargetCompID: "CLIENT1"}, SessionID{}, false},
+		{SessionID{BeginString: BeginStringFIX44, SenderCompID: "ISLD", TargetCompID: "CLIENT1", TargetSubID: "DESK"}, SessionID{}, false},
+	} {
+		template, ok := acceptor.matchSessionTemplate(tc.sessID)
+		assert.Equal(t, tc.matched, ok, tc.sessID.String())
+		assert.Equal(t, tc.template, template, tc.sessID.String())
+	}
+
+	require.NoError(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	conn, err := net.Dial("tcp", "localhost:5002")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	logon := NewMessage()
+	logon.Header.SetField(tagBeginString, FIXString(BeginStringFIX44))
+	logon.Header.SetField(tagMsgType, FIXString(msgTypeLogon))
+	logon.Header.SetField(tagSenderCompID, FIXString("CLIENT1"))
+	logon.Header.SetField(tagTargetCompID, FIXString("ISLD"))
+	logon.Header.SetField(tagMsgSeqNum, FIXInt(1))
+	logon.Header.SetField(tagSendingTime, FIXUTCTimestamp{Time: time.Now()})
+	logon.Body.SetField(tagEncryptMethod, FIXInt(0))
+	logon.Body.SetField(tagHeartBtInt, FIXInt(30))
+	_, err = conn.Write(logon.build())
+	require.NoError(t, err)
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	msgBytes, err := newParser(bufio.NewReader(conn)).ReadMessage()
+	require.NoError(t, err)
+	reply := NewMessage()
+	require.NoError(t, ParseMessage(reply, msgBytes))
+	assert.True(t, reply.IsMsgTypeOf(string(msgTypeLogon)))
+	targetCompID, err := reply.Header.GetString(tagTargetCompID)
+	require.Nil(t, err)
+	assert.Equal(t, "CLIENT1", targetCompID, "session created with the Logon's SessionID")
+}
+
+func TestAcceptor_Sessions(t *testing.T) {
+	serverConn, clientConn := NewPipeTransport()
+
+	acceptorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=ACC
+TargetCompID=INIT
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=ACC
+TargetCompID=IDLE
+`))
+	require.Nil(t, err)
+	acceptorApp := logonApp{logons: make(chan SessionID, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	acceptor.ServeConn(serverConn)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	loggedOn := SessionID{BeginString: "FIX.4.4", SenderCompID: "ACC", TargetCompID: "INIT"}
+	idle := SessionID{BeginString: "FIX.4.4", SenderCompID: "ACC", TargetCompID: "IDLE"}
+	assert.Empty(t, acceptor.Sessions())
+	assert.Equal(t, []SessionID{idle, loggedOn}, acceptor.AllSessions())
+
+	initiatorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=INIT
+TargetCompID=ACC
+HeartBtInt=30
+SocketConnectHost=pipe
+SocketConnectPort=0
+`))
+	require.Nil(t, err)
+	initiator, err := NewInitiator(logonApp{logons: make(chan SessionID, 1)}, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	initiator.SetSessionDialer(ConnDialer(clientConn))
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	select {
+	case <-acceptorApp.logons:
+	case <-time.After(10 * time.Second):
+		require.FailNow(t, "timed out waiting for logon")
+	}
+	assert.Equal(t, []SessionID{loggedOn}, acceptor.Sessions())
+	assert.Equal(t, []SessionID{idle, loggedOn}, acceptor.AllSessions())
+}
@@ -15,9 +15,15 @@
 
 package quickfix
 
+import "context"
+
 // Application interface should be implemented by FIX Applications.
 // This is the primary interface for processing messages from a FIX Session.
 type Application interface {
+	// OnSessionCreate notification of a session object being created, before OnCreate. Applications may
+	// initialize their per-session state here.
+	OnSessionCreate(sessionID SessionID)
+
 	// OnCreate notification of a session begin created.
 	OnCreate(sessionID SessionID)
 
@@ -39,3 +45,57 @@ type Application interface {
 	// FromApp notification of app message being received from target.
 	FromApp(message *Message, sessionID SessionID) MessageRejectError
 }
+
+// ApplicationWithContext may be implemented by applications to process incoming app messages with a
+// context. When it is implemented, the session calls FromAppContext in place of FromApp. The context
+// is canceled once ApplicationCallbackTimeout elapses, at which point the session logs a warning and
+// drops the message rather than wait for FromAppContext to return. The session goes on with the next
+// messages meanwhile, so a FromAppContext that ignores the cancellation may still be running when it is
+// called for the next message, and its result is discarded.
+type ApplicationWithContext interface {
+	Application
+
+	// FromAppContext notification of app message being received from target.
+	FromAppContext(ctx context.Context, message *Message, sessionID SessionID) MessageRejectError
+}
+
+// HeartbeatListener may be implemented by applications to monitor connectivity. OnHeartbeat is called when a
+// session receives the Heartbeat answering one of its TestRequests, with the round-trip latency in milliseconds.
+type HeartbeatListener interface {
+	OnHeartbeat(sessionID SessionID, latencyMs int64)
+}
+
+// MessageRejectionListener may be implemented by applications to monitor the messages a session rejects, e.g. to
+// debug data dictionary validation failures or to count rejections. OnMessageRejected is called with the incoming
+// message and the reason after the session replies to it with a Reject or BusinessMessageReject.
+type MessageRejectionListener interface {
+	OnMessageRejected(sessionID SessionID, msg *Message, reason error)
+}
+
+// ApplicationAdapter implements every method of Application as a no-op. Applications may embed it and
+// only implement the notifications they handle.
+type ApplicationAdapter struct{}
+
+// OnSessionCreate does nothing.
+func (ApplicationAdapter) OnSessionCreate(SessionID) {}
+
+// OnCreate does nothing.
+func (ApplicationAdapter) OnCreate(SessionID) {}
+
+// OnLogon does nothing.
+func (ApplicationAdapter) OnLogon(SessionID) {}
+
+// OnLogout does nothing.
+func (ApplicationAdapter) OnLogout(SessionID) {}
+
+// ToAdmin does nothing.
+func (ApplicationAdapter) ToAdmin(*Message, SessionID) {}
+
+// ToApp returns nil, sending the message.
+func (ApplicationAdapter) ToApp(*Message, SessionID) error { return nil }
+
+// FromAdmin returns nil, accepting the message.
+func (ApplicationAdapter) FromAdmin(*Message, SessionID) MessageRejectError { return nil }
+
+// FromApp returns nil, accepting the message.
+func (ApplicationAdapter) FromApp(*Message, SessionID) MessageRejectError { return nil }
@@ -0,0 +1,102 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+type applicationChain []Application
+
+// ApplicationChain returns an Application that passes every notification to apps in order, so that
+// e.g. a rate limiter, a logger and the business logic can be written as separate applications.
+//
+// FromAdmin and FromApp stop at the first application returning a reject, which is then returned.
+// The other notifications are passed to every application. ToApp returns the first error returned,
+// after all applications have been notified. The chain is a HeartbeatListener and a MessageRejectionListener,
+// notifying the applications that are.
+func ApplicationChain(apps ...Application) Application {
+	return applicationChain(apps)
+}
+
+func (chain applicationChain) OnSessionCreate(sessionID SessionID) {
+	for _, app := range chain {
+		app.OnSessionCreate(sessionID)
+	}
+}
+
+func (chain applicationChain) OnCreate(sessionID SessionID) {
+	for _, app := range chain {
+		app.OnCreate(sessionID)
+	}
+}
+
+func (chain applicationChain) OnLogon(sessionID SessionID) {
+	for _, app := range chain {
+		app.OnLogon(sessionID)
+	}
+}
+
+func (chain applicationChain) OnLogout(sessionID SessionID) {
+	for _, app := range chain {
+		app.OnLogout(sessionID)
+	}
+}
+
+func (chain applicationChain) ToAdmin(message *Message, sessionID SessionID) {
+	for _, app := range chain {
+		app.ToAdmin(message, sessionID)
+	}
+}
+
+func (chain applicationChain) ToApp(message *Message, sessionID SessionID) (err error) {
+	for _, app := range chain {
+		if appErr := app.ToApp(message, sessionID); appErr != nil && err == nil {
+			err = appErr
+		}
+	}
+	return err
+}
+
+func (chain applicationChain) FromAdmin(message *Message, sessionID SessionID) MessageRejectError {
+	for _, app := range chain {
+		if reject := app.FromAdmin(message, sessionID); reject != nil {
+			return reject
+		}
+	}
+	return nil
+}
+
+func (chain applicationChain) FromApp(message *Message, sessionID SessionID) MessageRejectError {
+	for _, app := range chain {
+		if reject := app.FromApp(message, sessionID); reject != nil {
+			return reject
+		}
+	}
+	return nil
+}
+
+func (chain applicationChain) OnHeartbeat(sessionID SessionID, latencyMs int64) {
+	for _, app := range chain {
+		if listener, ok := app.(HeartbeatListener); ok {
+			listener.OnHeartbeat(sessionID, latencyMs)
+		}
+	}
+}
+
+func (chain applicationChain) OnMessageRejected(sessionID SessionID, msg *Message, reason error) {
+	for _, app := range chain {
+		if listener, ok := app.(MessageRejectionListener); ok {
+			listener.OnMessageRejected(sessionID, msg, reason)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// chainApp records the notifications it receives into a log shared with the other apps of a chain.
+type chainApp struct {
+	name      string
+	calls     *[]string
+	toAppErr  error
+	fromAdmin MessageRejectError
+	fromApp   MessageRejectError
+}
+
+func (app chainApp) record(call string) { *app.calls = append(*app.calls, app.name+"."+call) }
+
+func (app chainApp) OnSessionCreate(SessionID)   { app.record("OnSessionCreate") }
+func (app chainApp) OnCreate(SessionID)          { app.record("OnCreate") }
+func (app chainApp) OnLogon(SessionID)           { app.record("OnLogon") }
+func (app chainApp) OnLogout(SessionID)          { app.record("OnLogout") }
+func (app chainApp) ToAdmin(*Message, SessionID) { app.record("ToAdmin") }
+
+func (app chainApp) ToApp(*Message, SessionID) error {
+	app.record("ToApp")
+	return app.toAppErr
+}
+
+func (app chainApp) FromAdmin(*Message, SessionID) MessageRejectError {
+	app.record("FromAdmin")
+	return app.fromAdmin
+}
+
+func (app chainApp) FromApp(*Message, SessionID) MessageRejectError {
+	app.record("FromApp")
+	return app.fromApp
+}
+
+func TestApplicationChainCallsAppsInOrder(t *testing.T) {
+	var calls []string
+	chain := ApplicationChain(chainApp{name: "a", calls: &calls}, chainApp{name: "b", calls: &calls})
+	msg := NewMessage()
+
+	chain.OnSessionCreate(SessionID{})
+	chain.OnCreate(SessionID{})
+	chain.OnLogon(SessionID{})
+	chain.ToAdmin(msg, SessionID{})
+	assert.Nil(t, chain.ToApp(msg, SessionID{}))
+	assert.Nil(t, chain.FromAdmin(msg, SessionID{}))
+	assert.Nil(t, chain.FromApp(msg, SessionID{}))
+	chain.OnLogout(SessionID{})
+
+	assert.Equal(t, []string{
+		"a.OnSessionCreate", "b.OnSessionCreate",
+		"a.OnCreate", "b.OnCreate",
+		"a.OnLogon", "b.OnLogon",
+		"a.ToAdmin", "b.ToAdmin",
+		"a.ToApp", "b.ToApp",
+		"a.FromAdmin", "b.FromAdmin",
+		"a.FromApp", "b.FromApp",
+		"a.OnLogout", "b.OnLogout",
+	}, calls)
+}
+
+func TestApplicationChainStopsOnReject(t *testing.T) {
+	var calls []string
+	reject := UnsupportedMessageType()
+	chain := ApplicationChain(
+		chainApp{name: "a", calls: &calls, fromAdmin: reject, fromApp: reject},
+		chainApp{name: "b", calls: &calls},
+	)
+	msg := NewMessage()
+
+	assert.Equal(t, reject, chain.FromAdmin(msg, SessionID{}))
+	assert.Equal(t, reject, chain.FromApp(msg, SessionID{}))
+	assert.Equal(t, []string{"a.FromAdmin", "a.FromApp"}, calls)
+}
+
+func TestApplicationChainToAppNotifiesAllApps(t *testing.T) {
+	var calls []string
+	other := errors.New("other")
+	chain := ApplicationChain(
+		chainApp{name: "a", calls: &calls},
+		chainApp{name: "b", calls: &calls, toAppErr: ErrDoNotSend},
+		chainApp{name: "c", calls: &calls, toAppErr: other},
+	)
+
+	assert.Equal(t, ErrDoNotSend, chain.ToApp(NewMessage(), SessionID{}))
+	assert.Equal(t, []string{"a.ToApp", "b.ToApp", "c.ToApp"}, calls)
+}
+
+// heartbeatChainApp is a chainApp that is also a HeartbeatListener.
+type heartbeatChainApp struct{ chainApp }
+
+func (app heartbeatChainApp) OnHeartbeat(SessionID, int64) { app.record("OnHeartbeat") }
+
+// rejectionChainApp is a chainApp that is also a MessageRejectionListener.
+type rejectionChainApp struct{ chainApp }
+
+func (app rejectionChainApp) OnMessageRejected(SessionID, *Message, error) {
+	app.record("OnMessageRejected")
+}
+
+func TestApplicationChainNotifiesMessageRejectionListeners(t *testing.T) {
+	var calls []string
+	chain := ApplicationChain(chainApp{name: "a", calls: &calls}, rejectionChainApp{chainApp{name: "b", calls: &calls}})
+
+	listener, ok := chain.(MessageRejectionListener)
+	if assert.True(t, ok) {
+		listener.OnMessageRejected(SessionID{}, NewMessage(), errors.New("rejected"))
+	}
+	assert.Equal(t, []string{"b.OnMessageRejected"}, calls)
+}
+
+func TestApplicationChainNotifiesHeartbeatListeners(t *testing.T) {
+	var calls []string
+	chain := ApplicationChain(heartbeatChainApp{chainApp{name: "a", calls: &calls}}, chainApp{name: "b", calls: &calls},
+		heartbeatChainApp{chainApp{name: "c", calls: &calls}})
+
+	listener, ok := chain.(HeartbeatListener)
+	if assert.True(t, ok) {
+		listener.OnHeartbeat(SessionID{}, 5)
+	}
+	assert.Equal(t, []string{"a.OnHeartbeat", "c.OnHeartbeat"}, calls)
+}
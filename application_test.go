@@ -0,0 +1,36 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplicationAdapter(t *testing.T) {
+	var app Application = ApplicationAdapter{}
+	msg := NewMessage()
+
+	app.OnSessionCreate(SessionID{})
+	app.OnCreate(SessionID{})
+	app.OnLogon(SessionID{})
+	app.ToAdmin(msg, SessionID{})
+	assert.Nil(t, app.ToApp(msg, SessionID{}))
+	assert.Nil(t, app.FromAdmin(msg, SessionID{}))
+	assert.Nil(t, app.FromApp(msg, SessionID{}))
+	app.OnLogout(SessionID{})
+}
@@ -0,0 +1,54 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import "sync"
+
+// Authenticator authenticates the Logon messages received by sessions, e.g. against credentials in the Username(553)
+// and Password(554) fields, in addition to the checks made against the session configuration.
+type Authenticator interface {
+	// Authenticate returns an error to reject the Logon message received by the session. The session replies with
+	// a Logout whose Text is the error's message and disconnects.
+	Authenticate(sessionID SessionID, msg *Message) error
+}
+
+var authenticatorLock sync.RWMutex
+var authenticator Authenticator
+
+// RegisterAuthenticator registers a to authenticate the Logon messages received by all sessions, after
+// Application.FromAdmin has accepted them and before the session is logged on. It replaces any Authenticator
+// registered before, a nil Authenticator removes it.
+func RegisterAuthenticator(a Authenticator) {
+	authenticatorLock.Lock()
+	defer authenticatorLock.Unlock()
+
+	authenticator = a
+}
+
+// authenticate returns a RejectLogon if the registered Authenticator rejects msg.
+func authenticate(sessionID SessionID, msg *Message) error {
+	authenticatorLock.RLock()
+	a := authenticator
+	authenticatorLock.RUnlock()
+
+	if a == nil {
+		return nil
+	}
+	if err := a.Authenticate(sessionID, msg); err != nil {
+		return RejectLogon{Text: err.Error()}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// CheckSum returns the CheckSum(10) value of data, the sum of its bytes modulo 256 as a zero-padded
+// three digit decimal string. data is everything preceding the CheckSum field, including the
+// delimiter of the last field before it.
+func CheckSum(data []byte) string {
+	var sum int
+	for _, b := range data {
+		sum += int(b)
+	}
+	return formatCheckSum(sum % 256)
+}
+
+// ValidateCheckSum verifies that msg, a complete FIX message, ends with a CheckSum(10) field holding
+// the CheckSum of the bytes preceding it.
+func ValidateCheckSum(msg []byte) error {
+	const checkSumFieldLen = len("10=000\x01")
+
+	if len(msg) < checkSumFieldLen || msg[len(msg)-1] != '\001' {
+		return fmt.Errorf("message does not end with a CheckSum field")
+	}
+	start := len(msg) - checkSumFieldLen
+	field := msg[start:]
+	if !bytes.HasPrefix(field, []byte("10=")) || (start > 0 && msg[start-1] != '\001') {
+		return fmt.Errorf("message does not end with a CheckSum field")
+	}
+
+	received := string(field[3 : checkSumFieldLen-1])
+	for _, c := range received {
+		if c < '0' || c > '9' {
+			return fmt.Errorf("invalid CheckSum: %q", received)
+		}
+	}
+	if expected := CheckSum(msg[:start]); received != expected {
+		return fmt.Errorf("CheckSum mismatch: expected %s, got %s", expected, received)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckSum(t *testing.T) {
+	assert.Equal(t, "000", CheckSum(nil))
+	assert.Equal(t, "062", CheckSum([]byte("8=FIX.4.2\x019=65\x0135=A\x0149=SERVER\x0156=CLIENT\x0134=177\x0152=20090107-18:15:16\x0198=0\x01108=30\x01")))
+	assert.Equal(t, "007", CheckSum([]byte{7}))
+}
+
+func TestValidateCheckSum(t *testing.T) {
+	valid := "8=FIX.4.2\x019=65\x0135=A\x0149=SERVER\x0156=CLIENT\x0134=177\x0152=20090107-18:15:16\x0198=0\x01108=30\x0110=062\x01"
+	assert.Nil(t, ValidateCheckSum([]byte(valid)))
+
+	var testCases = []struct {
+		msg         string
+		expectedErr string
+	}{
+		{valid[:len(valid)-7] + "10=063\x01", "CheckSum mismatch: expected 062, got 063"},
+		{valid[:len(valid)-7] + "10=6x2\x01", `invalid CheckSum: "6x2"`},
+		{valid[:len(valid)-1], "message does not end with a CheckSum field"},
+		{valid[:len(valid)-7] + "110=62\x01", "message does not end with a CheckSum field"},
+		{"", "message does not end with a CheckSum field"},
+	}
+	for _, tc := range testCases {
+		assert.EqualError(t, ValidateCheckSum([]byte(tc.msg)), tc.expectedErr, tc.msg)
+	}
+}
+
+func FuzzCheckSum(f *testing.F) {
+	f.Add([]byte("8=FIX.4.2\x019=5\x0135=0\x01"))
+	f.Add([]byte{})
+	f.Fuzz(func(t *testing.T, data []byte) {
+		checkSum := CheckSum(data)
+		if len(checkSum) != 3 {
+			t.Fatalf("CheckSum %q is not three digits", checkSum)
+		}
+
+		// A message completed with its CheckSum always validates.
+		msg := append(append([]byte{}, data...), '\001')
+		msg = append(append(msg, "10="+CheckSum(msg)...), '\001')
+		if err := ValidateCheckSum(msg); err != nil {
+			t.Fatalf("ValidateCheckSum(%q): %v", msg, err)
+		}
+	})
+}
+
+func FuzzValidateCheckSum(f *testing.F) {
+	f.Add([]byte("8=FIX.4.2\x019=5\x0135=0\x0110=161\x01"))
+	f.Add([]byte("10=000\x01"))
+	f.Add([]byte("\x01"))
+	f.Fuzz(func(_ *testing.T, msg []byte) {
+		_ = ValidateCheckSum(msg)
+	})
+}
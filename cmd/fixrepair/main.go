@@ -0,0 +1,91 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Command fixrepair rebuilds the header files of file-based message stores from their body files.
+//
+// The sessions and their FileStorePath are read from the same settings file used by the initiator or
+// acceptor. The FIX process must not be running while the stores are repaired.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/store/file"
+)
+
+var session = flag.String("session", "", "only repair the store of the session with this SessionID, e.g. FIX.4.4:SENDER->TARGET")
+
+// repairer is implemented by the file store.
+type repairer interface {
+	Repair() error
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: %v [flags] <path to settings file>\n", os.Args[0])
+	flag.PrintDefaults()
+	os.Exit(2)
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+	if flag.NArg() != 1 {
+		usage()
+	}
+
+	cfg, err := os.Open(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	settings, err := quickfix.ParseSettings(cfg)
+	_ = cfg.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	factory := file.NewStoreFactory(settings)
+	repaired := 0
+	for sessionID := range settings.SessionSettings() {
+		if *session != "" && sessionID.String() != *session {
+			continue
+		}
+		if err := repair(factory, sessionID); err != nil {
+			log.Fatalf("%v: %v", sessionID, err)
+		}
+		fmt.Printf("%v: header rebuilt\n", sessionID)
+		repaired++
+	}
+	if repaired == 0 {
+		log.Fatal("no matching session found")
+	}
+}
+
+func repair(factory quickfix.MessageStoreFactory, sessionID quickfix.SessionID) error {
+	store, err := factory.Create(sessionID)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	r, ok := store.(repairer)
+	if !ok {
+		return fmt.Errorf("store %T cannot be repaired", store)
+	}
+	return r.Repair()
+}
@@ -0,0 +1,423 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package fixml converts FIX messages to and from FIXML, using a FIX data dictionary to name the elements
+// and attributes.
+//
+// A message is written as an element named after its message type within a FIXML root element, e.g.
+// <FIXML><ExecutionReport .../></FIXML>. Body fields are attributes of the message element, header and
+// trailer fields are attributes of its Hdr and Trlr child elements, and each entry of a repeating group is
+// a child element named after the group's NumInGroup field.
+package fixml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"sort"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+const (
+	rootElement    = "FIXML"
+	headerElement  = "Hdr"
+	trailerElement = "Trlr"
+)
+
+const (
+	tagBeginString = quickfix.Tag(8)
+	tagBodyLength  = quickfix.Tag(9)
+	tagCheckSum    = quickfix.Tag(10)
+	tagMsgType     = quickfix.Tag(35)
+)
+
+// Codec converts messages to and from FIXML.
+type Codec struct {
+	transportDataDictionary *datadictionary.DataDictionary
+	appDataDictionary       *datadictionary.DataDictionary
+	messagesByName          map[string]*datadictionary.MessageDef
+}
+
+// NewCodec returns a Codec for the given data dictionaries. The transport data dictionary defines the header
+// and trailer, and may be nil for FIX versions prior to FIXT, in which case both come from the application
+// data dictionary.
+func NewCodec(transportDataDictionary, appDataDictionary *datadictionary.DataDictionary) *Codec {
+	if transportDataDictionary == nil {
+		transportDataDictionary = appDataDictionary
+	}
+	c := &Codec{
+		transportDataDictionary: transportDataDictionary,
+		appDataDictionary:       appDataDictionary,
+		messagesByName:          make(map[string]*datadictionary.MessageDef),
+	}
+	for _, dd := range []*datadictionary.DataDictionary{transportDataDictionary, appDataDictionary} {
+		for _, def := range dd.Messages {
+			c.messagesByName[def.Name] = def
+		}
+	}
+	return c
+}
+
+// Marshal returns the FIXML document for msg.
+func (c *Codec) Marshal(msg *quickfix.Message) ([]byte, error) {
+	var b bytes.Buffer
+	enc := xml.NewEncoder(&b)
+	if err := c.encode(enc, msg); err != nil {
+		return nil, err
+	}
+	if err := enc.Flush(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// Unmarshal parses a FIXML document holding a single message.
+func (c *Codec) Unmarshal(data []byte) (*quickfix.Message, error) {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return c.decode(dec, start)
+		}
+	}
+}
+
+// Message binds a message to a Codec, so that it can be used with encoding/xml.
+type Message struct {
+	*quickfix.Message
+	Codec *Codec
+}
+
+// MarshalXML implements xml.Marshaler. The message is always written within a FIXML root element, whatever
+// the name of start.
+func (m Message) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	return m.Codec.encode(enc, m.Message)
+}
+
+// UnmarshalXML implements xml.Unmarshaler. The start element is either the FIXML root element or the message
+// element itself.
+func (m *Message) UnmarshalXML(dec *xml.Decoder, start xml.StartElement) error {
+	msg, err := m.Codec.decode(dec, start)
+	if err != nil {
+		return err
+	}
+	m.Message = msg
+	return nil
+}
+
+func (c *Codec) encode(enc *xml.Encoder, msg *quickfix.Message) error {
+	msgType, rej := msg.Header.GetBytes(tagMsgType)
+	if rej != nil {
+		return rej
+	}
+	def, ok := c.messageDef(string(msgType))
+	if !ok {
+		return fmt.Errorf("fixml: unknown MsgType %s", msgType)
+	}
+
+	root := xml.StartElement{Name: xml.Name{Local: rootElement}}
+	err := enc.EncodeToken(root)
+	if err != nil {
+		return err
+	}
+
+	headerFields := partFields(c.transportDataDictionary.Header.Parts)
+	trailerFields := partFields(c.transportDataDictionary.Trailer.Parts)
+	err = c.encodeFieldMap(enc, def.Name, &msg.Body.FieldMap, partFields(def.Parts), nil, func() error {
+		skip := map[quickfix.Tag]bool{tagBodyLength: true, tagMsgType: true}
+		if err := c.encodeFieldMap(enc, headerElement, &msg.Header.FieldMap, headerFields, skip, nil); err != nil {
+			return err
+		}
+		skip = map[quickfix.Tag]bool{tagCheckSum: true}
+		if !hasFieldsExcept(&msg.Trailer.FieldMap, skip) {
+			return nil
+		}
+		return c.encodeFieldMap(enc, trailerElement, &msg.Trailer.FieldMap, trailerFields, skip, nil)
+	})
+	if err != nil {
+		return err
+	}
+	return enc.EncodeToken(root.End())
+}
+
+// encodeFieldMap writes fm as an element with the given name. Plain fields become attributes, in the order
+// they are defined in, followed by any field not defined in fields. The children callback, if set, writes
+// the first child elements, followed by an element for each entry of the repeating groups of fm.
+func (c *Codec) encodeFieldMap(enc *xml.Encoder, name string, fm *quickfix.FieldMap, fields []*datadictionary.FieldDef,
+	skip map[quickfix.Tag]bool, children func() error) error {
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+
+	defined := make(map[quickfix.Tag]bool)
+	collectTags(fields, defined)
+	for _, def := range fields {
+		tag := quickfix.Tag(def.Tag())
+		if def.IsGroup() || skip[tag] || !fm.Has(tag) {
+			continue
+		}
+		value, err := fm.GetBytes(tag)
+		if err != nil {
+			return err
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: def.Name()}, Value: string(value)})
+	}
+
+	tags := fm.Tags()
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	for _, tag := range tags {
+		if defined[tag] || skip[tag] {
+			continue
+		}
+		fieldName, ok := c.fieldName(tag)
+		if !ok {
+			return fmt.Errorf("fixml: tag %d is not defined in the data dictionary", tag)
+		}
+		value, err := fm.GetBytes(tag)
+		if err != nil {
+			return err
+		}
+		start.Attr = append(start.Attr, xml.Attr{Name: xml.Name{Local: fieldName}, Value: string(value)})
+	}
+
+	if err := enc.EncodeToken(start); err != nil {
+		return err
+	}
+	if children != nil {
+		if err := children(); err != nil {
+			return err
+		}
+	}
+	for _, def := range fields {
+		tag := quickfix.Tag(def.Tag())
+		if !def.IsGroup() || !fm.Has(tag) {
+			continue
+		}
+		group := quickfix.NewRepeatingGroup(tag, groupTemplate(def))
+		if err := fm.GetGroup(group); err != nil {
+			return err
+		}
+		for i := 0; i < group.Len(); i++ {
+			if err := c.encodeFieldMap(enc, def.Name(), &group.Get(i).FieldMap, def.Fields, nil, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return enc.EncodeToken(start.End())
+}
+
+func (c *Codec) decode(dec *xml.Decoder, start xml.StartElement) (*quickfix.Message, error) {
+	if start.Name.Local == rootElement {
+		msgStart, err := nextStartElement(dec)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := c.decode(dec, msgStart)
+		if err != nil {
+			return nil, err
+		}
+		// Consume the rest of the root element.
+		if err := dec.Skip(); err != nil {
+			return nil, err
+		}
+		return msg, nil
+	}
+
+	def, ok := c.messagesByName[start.Name.Local]
+	if !ok {
+		return nil, fmt.Errorf("fixml: unknown message %s", start.Name.Local)
+	}
+
+	msg := quickfix.NewMessage()
+	headerFields := partFields(c.transportDataDictionary.Header.Parts)
+	trailerFields := partFields(c.transportDataDictionary.Trailer.Parts)
+	err := c.decodeFieldMap(dec, start, &msg.Body.FieldMap, partFields(def.Parts), func(child xml.StartElement) (bool, error) {
+		switch child.Name.Local {
+		case headerElement:
+			return true, c.decodeFieldMap(dec, child, &msg.Header.FieldMap, headerFields, nil)
+		case trailerElement:
+			return true, c.decodeFieldMap(dec, child, &msg.Trailer.FieldMap, trailerFields, nil)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	msg.Header.SetString(tagMsgType, def.MsgType)
+	if !msg.Header.Has(tagBeginString) {
+		return nil, fmt.Errorf("fixml: message %s has no BeginString", def.Name)
+	}
+	return msg, nil
+}
+
+// decodeFieldMap reads the attributes and child elements of start into fm, consuming the tokens up to the
+// matching end element. The child callback, if set, is offered each child element first, and reports
+// whether it consumed it.
+func (c *Codec) decodeFieldMap(dec *xml.Decoder, start xml.StartElement, fm *quickfix.FieldMap, fields []*datadictionary.FieldDef,
+	child func(xml.StartElement) (bool, error)) error {
+	for _, attr := range start.Attr {
+		if attr.Name.Space != "" || attr.Name.Local == "xmlns" {
+			continue
+		}
+		tag, ok := c.fieldTag(attr.Name.Local)
+		if !ok {
+			return fmt.Errorf("fixml: field %s is not defined in the data dictionary", attr.Name.Local)
+		}
+		fm.SetString(tag, attr.Value)
+	}
+
+	var groups []*quickfix.RepeatingGroup
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if child != nil {
+				consumed, err := child(t)
+				if err != nil {
+					return err
+				}
+				if consumed {
+					continue
+				}
+			}
+			def := findGroup(fields, t.Name.Local)
+			if def == nil {
+				return fmt.Errorf("fixml: unexpected element %s in %s", t.Name.Local, start.Name.Local)
+			}
+			var group *quickfix.RepeatingGroup
+			for _, g := range groups {
+				if g.Tag() == quickfix.Tag(def.Tag()) {
+					group = g
+				}
+			}
+			if group == nil {
+				group = quickfix.NewRepeatingGroup(quickfix.Tag(def.Tag()), groupTemplate(def))
+				groups = append(groups, group)
+			}
+			if err := c.decodeFieldMap(dec, t, &group.Add().FieldMap, def.Fields, nil); err != nil {
+				return err
+			}
+		case xml.EndElement:
+			for _, group := range groups {
+				fm.SetGroup(group)
+			}
+			return nil
+		}
+	}
+}
+
+// messageDef returns the definition of the message with the given MsgType.
+func (c *Codec) messageDef(msgType string) (*datadictionary.MessageDef, bool) {
+	if def, ok := c.appDataDictionary.Messages[msgType]; ok {
+		return def, true
+	}
+	def, ok := c.transportDataDictionary.Messages[msgType]
+	return def, ok
+}
+
+// fieldName returns the name the data dictionaries give to tag.
+func (c *Codec) fieldName(tag quickfix.Tag) (string, bool) {
+	for _, dd := range []*datadictionary.DataDictionary{c.appDataDictionary, c.transportDataDictionary} {
+		if ft, ok := dd.FieldTypeByTag[int(tag)]; ok {
+			return ft.Name(), true
+		}
+	}
+	return "", false
+}
+
+// fieldTag returns the tag of the field the data dictionaries name name.
+func (c *Codec) fieldTag(name string) (quickfix.Tag, bool) {
+	for _, dd := range []*datadictionary.DataDictionary{c.appDataDictionary, c.transportDataDictionary} {
+		if ft, ok := dd.FieldTypeByName[name]; ok {
+			return quickfix.Tag(ft.Tag()), true
+		}
+	}
+	return 0, false
+}
+
+// partFields returns the fields of parts in the order they are defined in, with components expanded.
+func partFields(parts []datadictionary.MessagePart) []*datadictionary.FieldDef {
+	var fields []*datadictionary.FieldDef
+	for _, part := range parts {
+		switch p := part.(type) {
+		case *datadictionary.FieldDef:
+			fields = append(fields, p)
+		case interface {
+			Fields() []*datadictionary.FieldDef
+		}:
+			fields = append(fields, p.Fields()...)
+		}
+	}
+	return fields
+}
+
+// groupTemplate returns the template for reading the repeating group defined by def.
+func groupTemplate(def *datadictionary.FieldDef) quickfix.GroupTemplate {
+	template := make(quickfix.GroupTemplate, 0, len(def.Fields))
+	for _, field := range def.Fields {
+		if field.IsGroup() {
+			template = append(template, quickfix.NewRepeatingGroup(quickfix.Tag(field.Tag()), groupTemplate(field)))
+		} else {
+			template = append(template, quickfix.GroupElement(quickfix.Tag(field.Tag())))
+		}
+	}
+	return template
+}
+
+// collectTags adds the tags of fields, including the members of repeating groups, to tags.
+func collectTags(fields []*datadictionary.FieldDef, tags map[quickfix.Tag]bool) {
+	for _, field := range fields {
+		tags[quickfix.Tag(field.Tag())] = true
+		collectTags(field.Fields, tags)
+	}
+}
+
+// findGroup returns the repeating group among fields whose NumInGroup field is named name.
+func findGroup(fields []*datadictionary.FieldDef, name string) *datadictionary.FieldDef {
+	for _, field := range fields {
+		if field.IsGroup() && field.Name() == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// hasFieldsExcept reports whether fm holds any field not in skip.
+func hasFieldsExcept(fm *quickfix.FieldMap, skip map[quickfix.Tag]bool) bool {
+	for _, tag := range fm.Tags() {
+		if !skip[tag] {
+			return true
+		}
+	}
+	return false
+}
+
+func nextStartElement(dec *xml.Decoder) (xml.StartElement, error) {
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return xml.StartElement{}, err
+		}
+		if start, ok := tok.(xml.StartElement); ok {
+			return start, nil
+		}
+	}
+}
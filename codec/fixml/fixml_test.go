@@ -0,0 +1,166 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package fixml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+func newFIX50SP2Codec(t *testing.T) (*Codec, *datadictionary.DataDictionary, *datadictionary.DataDictionary) {
+	transportDataDictionary, err := datadictionary.Parse("../../spec/FIXT11.xml")
+	require.Nil(t, err)
+	appDataDictionary, err := datadictionary.Parse("../../spec/FIX50SP2.xml")
+	require.Nil(t, err)
+	return NewCodec(transportDataDictionary, appDataDictionary), transportDataDictionary, appDataDictionary
+}
+
+func newExecutionReport() *quickfix.Message {
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), "FIXT.1.1")
+	msg.Header.SetString(quickfix.Tag(35), "8")
+	msg.Header.SetString(quickfix.Tag(49), "SENDER")
+	msg.Header.SetString(quickfix.Tag(56), "TARGET")
+	msg.Header.SetInt(quickfix.Tag(34), 12)
+	msg.Header.SetString(quickfix.Tag(52), "20240102-03:04:05.678")
+	msg.Header.SetString(quickfix.Tag(1128), "9")
+
+	msg.Body.SetString(quickfix.Tag(37), "ORD-1")
+	msg.Body.SetString(quickfix.Tag(17), "EXEC-1")
+	msg.Body.SetString(quickfix.Tag(150), "F")
+	msg.Body.SetString(quickfix.Tag(39), "1")
+	msg.Body.SetString(quickfix.Tag(55), "AAPL")
+	msg.Body.SetString(quickfix.Tag(54), "1")
+	msg.Body.SetString(quickfix.Tag(151), "50")
+	msg.Body.SetString(quickfix.Tag(14), "50")
+	msg.Body.SetString(quickfix.Tag(31), "101.25")
+	msg.Body.SetString(quickfix.Tag(32), "50")
+	msg.Body.SetString(quickfix.Tag(58), "partial <fill> & more")
+
+	subIDs := quickfix.NewRepeatingGroup(quickfix.Tag(802), quickfix.GroupTemplate{
+		quickfix.GroupElement(quickfix.Tag(523)),
+		quickfix.GroupElement(quickfix.Tag(803)),
+	})
+	subIDs.Add().SetString(quickfix.Tag(523), "DESK-7").SetString(quickfix.Tag(803), "4")
+
+	parties := quickfix.NewRepeatingGroup(quickfix.Tag(453), quickfix.GroupTemplate{
+		quickfix.GroupElement(quickfix.Tag(448)),
+		quickfix.GroupElement(quickfix.Tag(447)),
+		quickfix.GroupElement(quickfix.Tag(452)),
+		subIDs,
+	})
+	broker := parties.Add()
+	broker.SetString(quickfix.Tag(448), "BROKER").SetString(quickfix.Tag(447), "D").SetString(quickfix.Tag(452), "1")
+	broker.SetGroup(subIDs)
+	parties.Add().SetString(quickfix.Tag(448), "CLIENT").SetString(quickfix.Tag(447), "D").SetString(quickfix.Tag(452), "3")
+	msg.Body.SetGroup(parties)
+	return msg
+}
+
+func TestCodecMarshal(t *testing.T) {
+	codec, _, _ := newFIX50SP2Codec(t)
+
+	data, err := codec.Marshal(newExecutionReport())
+	require.Nil(t, err)
+
+	doc := string(data)
+	assert.Contains(t, doc, `<FIXML><ExecutionReport `)
+	assert.Contains(t, doc, `OrderID="ORD-1"`)
+	assert.Contains(t, doc, `Text="partial &lt;fill&gt; &amp; more"`)
+	assert.Contains(t, doc, `<Hdr BeginString="FIXT.1.1" SenderCompID="SENDER" TargetCompID="TARGET" MsgSeqNum="12"`)
+	assert.Contains(t, doc, `<NoPartyIDs PartyID="BROKER" PartyIDSource="D" PartyRole="1"><NoPartySubIDs PartySubID="DESK-7" PartySubIDType="4"></NoPartySubIDs></NoPartyIDs>`)
+	assert.Contains(t, doc, `<NoPartyIDs PartyID="CLIENT" PartyIDSource="D" PartyRole="3"></NoPartyIDs>`)
+	assert.NotContains(t, doc, "MsgType")
+	assert.NotContains(t, doc, "BodyLength")
+	assert.NotContains(t, doc, "CheckSum")
+	assert.NotContains(t, doc, "Trlr")
+}
+
+func TestCodecRoundTripExecutionReport(t *testing.T) {
+	codec, _, _ := newFIX50SP2Codec(t)
+	msg := newExecutionReport()
+
+	data, err := codec.Marshal(msg)
+	require.Nil(t, err)
+	decoded, err := codec.Unmarshal(data)
+	require.Nil(t, err)
+
+	assert.Equal(t, msg.String(), decoded.String())
+}
+
+func TestCodecRoundTripParsedExecutionReport(t *testing.T) {
+	codec, transportDataDictionary, appDataDictionary := newFIX50SP2Codec(t)
+
+	parsed := quickfix.NewMessage()
+	raw := bytes.NewBufferString(newExecutionReport().String())
+	require.Nil(t, quickfix.ParseMessageWithDataDictionary(parsed, raw, transportDataDictionary, appDataDictionary))
+
+	data, err := codec.Marshal(parsed)
+	require.Nil(t, err)
+	decoded, err := codec.Unmarshal(data)
+	require.Nil(t, err)
+
+	assert.Equal(t, parsed.String(), decoded.String())
+}
+
+func TestMessageXMLRoundTrip(t *testing.T) {
+	codec, _, _ := newFIX50SP2Codec(t)
+	msg := newExecutionReport()
+
+	data, err := xml.Marshal(Message{Message: msg, Codec: codec})
+	require.Nil(t, err)
+
+	decoded := Message{Codec: codec}
+	require.Nil(t, xml.Unmarshal(data, &decoded))
+	assert.Equal(t, msg.String(), decoded.String())
+}
+
+func TestCodecUnmarshalErrors(t *testing.T) {
+	codec, _, _ := newFIX50SP2Codec(t)
+
+	var tests = []struct {
+		name string
+		doc  string
+	}{
+		{"unknown message", `<FIXML><NoSuchMessage><Hdr BeginString="FIXT.1.1"/></NoSuchMessage></FIXML>`},
+		{"unknown field", `<FIXML><ExecutionReport NoSuchField="1"><Hdr BeginString="FIXT.1.1"/></ExecutionReport></FIXML>`},
+		{"unknown element", `<FIXML><ExecutionReport><Hdr BeginString="FIXT.1.1"/><NoSuchGroup/></ExecutionReport></FIXML>`},
+		{"missing BeginString", `<FIXML><ExecutionReport OrderID="1"></ExecutionReport></FIXML>`},
+		{"truncated", `<FIXML><ExecutionReport OrderID="1">`},
+	}
+
+	for _, test := range tests {
+		_, err := codec.Unmarshal([]byte(test.doc))
+		assert.NotNil(t, err, test.name)
+	}
+}
+
+func TestCodecMarshalUnknownMsgType(t *testing.T) {
+	codec, _, _ := newFIX50SP2Codec(t)
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), "FIXT.1.1")
+	msg.Header.SetString(quickfix.Tag(35), "ZZ")
+
+	_, err := codec.Marshal(msg)
+	assert.NotNil(t, err)
+}
@@ -71,6 +71,26 @@ telling you what setting is missing or improperly formatted.
 	SocketConnectPort2=2932
 	SocketConnectHost2=12.12.12.12
 	DataDictionary=somewhere/FIX42.xml
+
+# Environment Variables
+
+Once enabled with Settings.SetEnvExpansion, values may reference environment variables as ${NAME}, e.g.
+
+	[SESSION]
+	BeginString=FIX.4.4
+	SenderCompID=${FIX_SENDER_COMP_ID}
+	TargetCompID=${FIX_TARGET_COMP_ID}
+
+Reading a setting that references an unset variable returns an error naming the variable.
+
+Alternatively, quickfix.ParseSettingsFromEnv, quickfix.NewAcceptorFromEnv and quickfix.NewInitiatorFromEnv read
+all settings from environment variables, without a settings file. Default settings are named
+QUICKFIX_GLOBAL_<SETTING>, the settings of a session QUICKFIX_SESSION_<SESSION>_<SETTING>, e.g.
+
+	QUICKFIX_GLOBAL_SOCKETACCEPTPORT=9000
+	QUICKFIX_SESSION_FIX42_SENDER_TARGET_BEGINSTRING=FIX.4.2
+	QUICKFIX_SESSION_FIX42_SENDER_TARGET_SENDERCOMPID=SENDER
+	QUICKFIX_SESSION_FIX42_SENDER_TARGET_TARGETCOMPID=TARGET
 */
 package config
 
@@ -93,6 +113,9 @@ const (
 	BeginString string = "BeginString"
 
 	// SenderCompID is your ID as associated with this FIX session.
+	// An acceptor session configured with SenderCompID=* is a template: a Logon that matches no
+	// configured session but matches the template's other IDs creates a session with the
+	// template's settings and the SenderCompID of the Logon.
 	//
 	// Required: Yes, unless configuring an acceptor with DynamicSessions=Y
 	//
@@ -100,6 +123,7 @@ const (
 	//
 	// Valid Values:
 	//  - A case-sensitive alpha-numeric string.
+	//  - * (acceptors only)
 	SenderCompID string = "SenderCompID"
 
 	// SenderSubID is your subID as associated with this FIX session.
@@ -123,6 +147,9 @@ const (
 	SenderLocationID string = "SenderLocationID"
 
 	// TargetCompID is the counterparty's ID as associated with this FIX session.
+	// An acceptor session configured with TargetCompID=* is a template: a Logon that matches no
+	// configured session but matches the template's other IDs creates a session with the
+	// template's settings and the TargetCompID of the Logon.
 	//
 	// Required: Yes, unless configuring an acceptor with DynamicSessions=Y
 	//
@@ -130,6 +157,7 @@ const (
 	//
 	// Valid Values:
 	//  - A case-sensitive alpha-numeric string.
+	//  - * (acceptors only)
 	TargetCompID string = "TargetCompID"
 
 	// TargetSubID is the counterparty's subID as associated with this FIX session.
@@ -248,6 +276,8 @@ const (
 
 	// TimeZone sets the time zone for this session; if specified, StartTime and EndTime will be converted from this zone to UTC.
 	// Times in messages will still be set to UTC as this is required by FIX specifications.
+	// StartTime and EndTime follow the daylight saving time transitions of the zone. A StartTime or EndTime in the hour
+	// repeated when clocks are set back refers to its first occurrence.
 	//
 	// Required: No
 	//
@@ -296,6 +326,90 @@ const (
 	//  - N
 	RefreshOnLogon string = "RefreshOnLogon"
 
+	// PersistSessionState determines if the session state, including the resend in progress and the messages
+	// received ahead of it, should be saved alongside the messages and seqnums. After a restart, the
+	// messages received ahead of the resend are then not requested again. The session itself is not resumed in the
+	// state it was in: it starts disconnected and logs on again as usual. Requires a message store that
+	// implements quickfix.SessionStateStore, such as the file store.
+	//
+	// Required: No
+	//
+	// Default: N
+	//
+	// Valid Values:
+	//  - Y
+	//  - N
+	PersistSessionState string = "PersistSessionState"
+
+	// StoreHealthCheckIntervalSecs is the time in seconds between calls to the message store's HealthCheck. After
+	// StoreHealthCheckMaxFailures consecutive failed checks, the session logs the error and disconnects.
+	//
+	// Example Values:
+	//  - StoreHealthCheckIntervalSecs=30
+	//
+	// Required: No
+	//
+	// Default: 0 (the store is not checked)
+	//
+	// Valid Values:
+	//  - Any non-negative integer
+	StoreHealthCheckIntervalSecs string = "StoreHealthCheckIntervalSecs"
+
+	// StoreHealthCheckMaxFailures is the number of consecutive failed store health checks after which the session
+	// disconnects. Only relevant if StoreHealthCheckIntervalSecs is set.
+	//
+	// Required: No
+	//
+	// Default: 3
+	//
+	// Valid Values:
+	//  - A positive integer
+	StoreHealthCheckMaxFailures string = "StoreHealthCheckMaxFailures"
+
+	// LogonFields adds fields to every Logon message the session sends, e.g. for venues requiring a Password(554)
+	// or a RawData(96) token. The fields are set before Application.ToAdmin is called with the Logon. Values may
+	// reference environment variables as ${NAME}, which are expanded each time a Logon is sent.
+	//
+	// Example Values:
+	//  - LogonFields=553=user;554=${FIX_PASSWORD}
+	//
+	// Required: No
+	//
+	// Valid Values:
+	//  - A semicolon separated list of tag=value pairs
+	LogonFields string = "LogonFields"
+
+	// EncryptedTags lists the body fields whose values are encrypted before sending and decrypted after
+	// receiving, for counterparties using tag-level encryption. Encryption is done by the EncryptionProvider
+	// set with quickfix.SetEncryptionProvider. Until one is set, sending a message holding one of the fields fails
+	// and a received one is rejected, as is a received message with a field that cannot be decrypted. Fields nested
+	// in repeating groups are not encrypted.
+	//
+	// Required: No
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A comma separated list of tags, e.g. 58,354,355
+	EncryptedTags string = "EncryptedTags"
+
+	// EncryptMethod is the EncryptMethod(98) sent on Logon when EncryptedTags is set, naming the encryption agreed
+	// with the counterparty. Without EncryptedTags, Logon always carries EncryptMethod 0.
+	//
+	// Required: No
+	//
+	// Default: 0
+	//
+	// Valid Values:
+	//  - 0 (None/Other)
+	//  - 1 (PKCS)
+	//  - 2 (DES)
+	//  - 3 (PKCS/DES)
+	//  - 4 (PGP/DES)
+	//  - 5 (PGP/DES-MD5)
+	//  - 6 (PEM/DES-MD5)
+	EncryptMethod string = "EncryptMethod"
+
 	// ResetOnLogout determines if sequence numbers should be reset to 1 after a normal logout termination.
 	//
 	// Required: No
@@ -307,6 +421,18 @@ const (
 	//  - N
 	ResetOnLogout string = "ResetOnLogout"
 
+	// ResetOnLogoutText sets the Text(58) of the Logout sent when a session with ResetOnLogout enabled is stopped,
+	// telling the counterparty that sequence numbers start over at 1 on the next logon. An empty value sends
+	// the Logout without a Text.
+	//
+	// Required: No
+	//
+	// Default: NextSeqNum = 1
+	//
+	// Valid Values:
+	//  - Any string
+	ResetOnLogoutText string = "ResetOnLogoutText"
+
 	// ResetOnDisconnect determines if sequence numbers should be reset to 1 after an abnormal termination.
 	//
 	// Required: No
@@ -417,6 +543,21 @@ const (
 	//  - N
 	RejectInvalidMessage string = "RejectInvalidMessage"
 
+	// DefaultUnsupportedMsgRejectReason is the BusinessRejectReason (380) of the BusinessMessageReject sent in reply
+	// to a message the application rejects with UnsupportedMessageType, or any other business reject with reason 3
+	// that is not an ApplicationError, for counterparties expecting another code.
+	//
+	// Example Values:
+	//  - DefaultUnsupportedMsgRejectReason=2
+	//
+	// Required: No
+	//
+	// Default: 3
+	//
+	// Valid Values:
+	//  - A non-negative integer
+	DefaultUnsupportedMsgRejectReason string = "DefaultUnsupportedMsgRejectReason"
+
 	// AllowUnknownMessageFields is set by default to N, meaning that non user-defined fields (field with tag < 5000)
 	// will be rejected if they are not defined in the data dictionary,
 	// or are present in messages they do not belong to.
@@ -476,6 +617,20 @@ const (
 	// Valid Values:
 	//  - Any positive integer
 	MaxLatency string = "MaxLatency"
+
+	// ApplicationCallbackTimeout defines the number of milliseconds an application implementing ApplicationWithContext
+	// is given to process an incoming app message in FromAppContext. Once it elapses, the callback's context is canceled,
+	// a warning is logged and the message is dropped without waiting for the callback to return. A callback ignoring the
+	// cancellation may then overlap with the processing of the next messages.
+	// Applications only implementing Application are not affected.
+	//
+	// Required: No
+	//
+	// Default: 0 (no timeout)
+	//
+	// Valid Values:
+	//  - Any non-negative integer
+	ApplicationCallbackTimeout string = "ApplicationCallbackTimeout"
 )
 
 const (
@@ -570,6 +725,22 @@ const (
 	//  - A valid go time.Duration
 	SocketTimeout string = "SocketTimeout"
 
+	// ConnectTimeout bounds the time an initiator spends establishing a connection, including the negotiation
+	// with the proxy server when ProxyType is set. A failed attempt is retried after ReconnectInterval.
+	// Only used for initiators.
+	//
+	// Example Values:
+	//  - ConnectTimeout=10s # 10 seconds
+	//  - ConnectTimeout=10 # 10 seconds
+	//
+	// Required: No
+	//
+	// Default: 0 (no timeout)
+	//
+	// Valid Values:
+	//  - A valid go time.Duration or a positive integer of seconds
+	ConnectTimeout string = "ConnectTimeout"
+
 	// ProxyType sets the type of proxy server to connect to.
 	// Only used for initiators.
 	//
@@ -579,6 +750,7 @@ const (
 	//
 	// Valid Values:
 	//  - socks
+	//  - SOCKS5
 	ProxyType string = "ProxyType"
 
 	// ProxyHost provides the address of the proxy server to connect to.
@@ -624,6 +796,42 @@ const (
 	// Valid Values:
 	//  - Any string
 	ProxyPassword string = "ProxyPassword"
+
+	// TransportType sets the transport carrying the FIX byte stream to the counterparty.
+	// With websocket, the initiator performs a WebSocket (RFC 6455) handshake over the established connection,
+	// using wss when TLS is configured, and exchanges messages in binary frames.
+	// Only used for initiators.
+	//
+	// Required: No
+	//
+	// Default: tcp
+	//
+	// Valid Values:
+	//  - tcp
+	//  - websocket
+	TransportType string = "TransportType"
+
+	// WebSocketPath sets the request path of the WebSocket handshake.
+	// Only used for initiators with TransportType=websocket.
+	//
+	// Required: No
+	//
+	// Default: /
+	//
+	// Valid Values:
+	//  - A URL path, optionally with a query string
+	WebSocketPath string = "WebSocketPath"
+
+	// WebSocketOrigin sets the Origin header of the WebSocket handshake.
+	// Only used for initiators with TransportType=websocket.
+	//
+	// Required: No
+	//
+	// Default: The http or https URL of the connected host
+	//
+	// Valid Values:
+	//  - A URL
+	WebSocketOrigin string = "WebSocketOrigin"
 )
 
 const (
@@ -708,6 +916,19 @@ const (
 	//  - Y
 	//  - N
 	DynamicQualifier string = "DynamicQualifier"
+
+	// AcceptedBeginStrings lists the BeginStrings an acceptor accepts connections for.
+	// A connection whose Logon has a BeginString not listed is logged and disconnected
+	// before any session is looked up or created.
+	// Used for acceptors only.
+	//
+	// Required: No
+	//
+	// Default: All BeginStrings are accepted
+	//
+	// Valid Values:
+	//  - A comma separated list of FIX.4.0, FIX.4.1, FIX.4.2, FIX.4.3, FIX.4.4 and FIXT.1.1
+	AcceptedBeginStrings string = "AcceptedBeginStrings"
 )
 
 const (
@@ -805,12 +1026,13 @@ const (
 	SocketInsecureSkipVerify string = "SocketInsecureSkipVerify"
 
 	// SocketServerName sets the expected server name on a returned certificate, unless SocketInsecureSkipVerify is true.
-	// This is for the TLS Server Name Indication extension.
+	// This is for the TLS Server Name Indication extension: it is sent as the SNI host name, e.g. to reach the right
+	// service behind a TLS terminator shared by several, when SocketConnectHost is an IP address, for which no SNI is sent.
 	// Only used for initiators.
 	//
 	// Required: No
 	//
-	// Default: N/A
+	// Default: The host of the address connected to
 	//
 	// Valid Values:
 	//  - Any string
@@ -843,6 +1065,49 @@ const (
 	//  - Y
 	//  - N
 	SocketUseSSL string = "SocketUseSSL"
+
+	// SSLClientAuthType sets the policy an acceptor applies to the certificates presented by connecting initiators.
+	// If not set, client certificates are required and verified against SocketCAFile or SocketCABytes whenever the acceptor
+	// has certificates of its own, unless SocketUseSSL is set to Y.
+	// Only used for acceptors.
+	//
+	// Required: No
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - RequireAndVerifyClientCert
+	//  - RequireAnyClientCert
+	//  - NoClientCert
+	SSLClientAuthType string = "SSLClientAuthType"
+
+	// SSLClientCertificate is the filepath for the certificate an initiator presents to the acceptor during the TLS handshake.
+	// It takes precedence over SocketCertificateFile and SocketCertificateBytes for client authentication, and enables TLS
+	// on its own.
+	// Must be used with SSLClientPrivateKey.
+	// Must contain PEM encoded data.
+	// Only used for initiators.
+	//
+	// Required: No
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A filepath to a file with read access.
+	SSLClientCertificate string = "SSLClientCertificate"
+
+	// SSLClientPrivateKey is the filepath for the private key of SSLClientCertificate.
+	// Must be used with SSLClientCertificate.
+	// Must contain PEM encoded data.
+	// Only used for initiators.
+	//
+	// Required: No
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A filepath to a file with read access.
+	SSLClientPrivateKey string = "SSLClientPrivateKey"
 )
 
 const (
@@ -975,6 +1240,9 @@ const (
 
 	// FileStorePath sets the directory path in which to write sequence number and message files.
 	// This will create the directory path if it does not already exist.
+	// The path may reference the fields of the session ID as ${BeginString}, ${SenderCompID}, ${SenderSubID},
+	// ${SenderLocationID}, ${TargetCompID}, ${TargetSubID}, ${TargetLocationID} and ${Qualifier},
+	// e.g. /data/fix/${BeginString}/${SenderCompID}-${TargetCompID}. Unrecognized variables are left as-is.
 	// FileStorePath is only relevant if also using file.NewStoreFactory(..) in code
 	// when creating your MessageStoreFactory for your initiator or acceptor.
 	//
@@ -1000,6 +1268,119 @@ const (
 	//  - N
 	FileStoreSync string = "FileStoreSync"
 
+	// FileStoreSyncIntervalMs makes the FileStore sync its message files to the hard drive from a background goroutine
+	// every FileStoreSyncIntervalMs milliseconds, rather than after every write.
+	// This greatly reduces write latency, at the cost of possibly losing the messages written during the last interval on a crash.
+	// Sequence numbers are still synced according to FileStoreSync.
+	// A failed background sync is returned by the next message save or by closing the store.
+	// FileStoreSyncIntervalMs is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: 0 (sync after every write, if FileStoreSync is enabled)
+	//
+	// Valid Values:
+	//  - Any non-negative integer
+	FileStoreSyncIntervalMs string = "FileStoreSyncIntervalMs"
+
+	// FileStorePermissions sets the permission mask used when the FileStore creates its files.
+	// This is useful when the FIX process and the process consuming the store files run as different users in the same group.
+	// FileStorePermissions is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: 0660
+	//
+	// Valid Values:
+	//  - An octal permission mask no greater than 0777, e.g. 0640
+	FileStorePermissions string = "FileStorePermissions"
+
+	// FileStoreCompress makes the FileStore compress message bodies with DEFLATE before writing them to the body file.
+	// Compression only applies to body files created while it is enabled; existing uncompressed body files are detected
+	// and keep being written uncompressed until the store is reset. Compressed body files remain readable when it is disabled.
+	// FileStoreCompress is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: N
+	//
+	// Valid Values:
+	//  - Y
+	//  - N
+	FileStoreCompress string = "FileStoreCompress"
+
+	// FileStoreCompressLevel sets the DEFLATE compression level used when FileStoreCompress is enabled.
+	// FileStoreCompressLevel is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: -1 (the default DEFLATE level, currently 6)
+	//
+	// Valid Values:
+	//  - An integer from -2 (Huffman encoding only) to 9 (best compression), where 0 disables compression
+	FileStoreCompressLevel string = "FileStoreCompressLevel"
+
+	// FileStoreEncryptionKey makes the FileStore encrypt message bodies with AES-256-GCM before writing them to the body file.
+	// Encryption only applies to body files created while it is set; a store whose existing body file is unencrypted
+	// fails to open until it is reset, rather than keep writing messages unencrypted. Encrypted body files cannot be read without the key.
+	// FileStoreEncryptionKey is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: N/A (messages are stored unencrypted)
+	//
+	// Valid Values:
+	//  - A 32 byte AES-256 key, hex-encoded as 64 characters
+	FileStoreEncryptionKey string = "FileStoreEncryptionKey"
+
+	// FileStoreRotateInterval makes the FileStore archive its body and header files at the start of every day or hour,
+	// continuing with new empty files. Archived files are renamed with a UTC timestamp suffix, e.g. FIX.4.4-SENDER-TARGET.body.20240102-000000.000000000,
+	// and are still read to resend the messages saved before the rotation. IterateAllMessages reads them all. Resetting the store deletes them.
+	// FileStoreRotateInterval is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: N/A (never rotate on a schedule)
+	//
+	// Valid Values:
+	//  - daily
+	//  - hourly
+	FileStoreRotateInterval string = "FileStoreRotateInterval"
+
+	// FileStoreMaxBodyBytes makes the FileStore archive its body and header files, as for FileStoreRotateInterval,
+	// before a message would grow the body file beyond FileStoreMaxBodyBytes bytes.
+	// FileStoreMaxBodyBytes is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: 0 (no size limit)
+	//
+	// Valid Values:
+	//  - Any non-negative integer
+	FileStoreMaxBodyBytes string = "FileStoreMaxBodyBytes"
+
+	// FileStoreMmap makes the FileStore read the body file through a read-only memory mapping when
+	// iterating over stored messages, e.g. for a resend request, in place of a read syscall per message.
+	// The mapping only lives for the duration of the iteration. It has no effect on Windows.
+	// FileStoreMmap is only relevant if also using file.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: N
+	//
+	// Valid Values:
+	//  - Y
+	//  - N
+	FileStoreMmap string = "FileStoreMmap"
+
 	// SQLStoreDriver sets the name of the database driver to use for message storage (see https://go.dev/wiki/SQLDrivers for the list of available drivers).
 	// SQLStoreDriver is only relevant if also using sql.NewStoreFactory(..) in code
 	// when creating your MessageStoreFactory for your initiator or acceptor.
@@ -1093,6 +1474,122 @@ const (
 	// Valid Values:
 	//  - A string corresponding to a MongoDB replica set
 	MongoStoreReplicaSet string = "MongoStoreReplicaSet"
+
+	// RedisURL sets the Redis connection URL to use for message storage.
+	// Stores created with the same URL share their sequence numbers and messages, which allows several
+	// acceptor instances to serve the same sessions.
+	//
+	// See https://pkg.go.dev/github.com/redis/go-redis/v9#ParseURL for more information.
+	//
+	// RedisURL is only relevant if also using redis.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: Only if using Redis as your MessageStore
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A Redis URL, e.g. redis://localhost:6379/0
+	RedisURL string = "RedisURL"
+
+	// RedisPassword sets the password used to authenticate with Redis.
+	// If set, it takes precedence over any password included in RedisURL.
+	//
+	// RedisPassword is only relevant if also using redis.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A string
+	RedisPassword string = "RedisPassword"
+
+	// PostgresDSN sets the PostgreSQL connection string to use for message storage.
+	//
+	// See https://pkg.go.dev/github.com/jackc/pgx/v5#ParseConfig for more information.
+	//
+	// PostgresDSN is only relevant if also using postgres.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: Only if using PostgreSQL as your MessageStore
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A PostgreSQL URL or keyword/value connection string
+	PostgresDSN string = "PostgresDSN"
+
+	// PostgresSchemaName sets the schema holding the fix_sessions and fix_messages tables.
+	// The schema and tables are created if they do not already exist.
+	//
+	// PostgresSchemaName is only relevant if also using postgres.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: public
+	//
+	// Valid Values:
+	//  - A valid PostgreSQL schema name
+	PostgresSchemaName string = "PostgresSchemaName"
+
+	// SQLiteFile sets the path of the SQLite database file to use for message storage.
+	// The file and its tables are created if they do not already exist, and the database
+	// is opened in WAL journal mode.
+	//
+	// SQLiteFile is only relevant if also using sqlite.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: Only if using SQLite as your MessageStore
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A valid path to a file
+	SQLiteFile string = "SQLiteFile"
+
+	// AWSRegion sets the AWS region of the DynamoDB table to use for message storage.
+	// Credentials are resolved through the default AWS SDK credential chain.
+	//
+	// AWSRegion is only relevant if also using dynamodb.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: Only if using DynamoDB as your MessageStore
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - An AWS region name, e.g. us-east-1
+	AWSRegion string = "AWSRegion"
+
+	// DynamoDBTable sets the name of the DynamoDB table to use for message storage.
+	// The table must already exist, with a string partition key named session_id and a number sort key named seq_num.
+	//
+	// DynamoDBTable is only relevant if also using dynamodb.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: Only if using DynamoDB as your MessageStore
+	//
+	// Default: N/A
+	//
+	// Valid Values:
+	//  - A DynamoDB table name
+	DynamoDBTable string = "DynamoDBTable"
+
+	// DynamoDBEndpoint overrides the DynamoDB endpoint URL, e.g. to use LocalStack or DynamoDB Local.
+	//
+	// DynamoDBEndpoint is only relevant if also using dynamodb.NewStoreFactory(..) in code
+	// when creating your MessageStoreFactory for your initiator or acceptor.
+	//
+	// Required: No
+	//
+	// Default: The regional AWS endpoint
+	//
+	// Valid Values:
+	//  - A URL, e.g. http://localhost:4566
+	DynamoDBEndpoint string = "DynamoDBEndpoint"
 )
 
 const (
@@ -1113,6 +1610,149 @@ const (
 	//  - A positive integer
 	ResendRequestChunkSize string = "ResendRequestChunkSize"
 
+	// MaxPendingOutboundMessages limits the number of messages queued by a session while they wait to be written
+	// to the counterparty, e.g. while it is slow to read or the session is not logged on.
+	// Once the limit is reached, sending an application message fails with quickfix.ErrSessionQueueFull
+	// instead of growing the queue.
+	//
+	// Example Values:
+	//  - MaxPendingOutboundMessages=1000
+	//
+	// Required: No
+	//
+	// Default: 0 (no limit)
+	//
+	// Valid Values:
+	//  - A non-negative integer
+	MaxPendingOutboundMessages string = "MaxPendingOutboundMessages"
+
+	// MaxOutboundMsgRatePerSecond limits the rate at which a session accepts application messages to send,
+	// e.g. to stay within the message rate a venue enforces. Once the limit is reached, sending an
+	// application message fails with quickfix.ErrRateLimitExceeded. Admin messages are not limited.
+	//
+	// Example Values:
+	//  - MaxOutboundMsgRatePerSecond=100
+	//
+	// Required: No
+	//
+	// Default: 0 (no limit)
+	//
+	// Valid Values:
+	//  - A non-negative integer
+	MaxOutboundMsgRatePerSecond string = "MaxOutboundMsgRatePerSecond"
+
+	// MaxOutboundBurstMessages is the number of application messages a session limited by
+	// MaxOutboundMsgRatePerSecond accepts at once after a quiet period, to absorb short spikes.
+	//
+	// Example Values:
+	//  - MaxOutboundBurstMessages=10
+	//
+	// Required: No
+	//
+	// Default: 1
+	//
+	// Valid Values:
+	//  - A positive integer
+	MaxOutboundBurstMessages string = "MaxOutboundBurstMessages"
+
+	// CPUAffinity pins the goroutine running the session's main loop, which processes the messages the session sends
+	// and receives, to an OS thread bound to the given CPU core, for latency sensitive sessions. The thread is only
+	// bound to the core on Linux; on other platforms the goroutine is locked to its OS thread only.
+	//
+	// Example Values:
+	//  - CPUAffinity=3
+	//
+	// Required: No
+	//
+	// Default: The session is not pinned
+	//
+	// Valid Values:
+	//  - A non-negative integer, the index of the CPU core
+	CPUAffinity string = "CPUAffinity"
+
+	// TCPSendBufferSize sets the size in bytes of the kernel send buffer of the session's TCP connection, e.g. for
+	// high-throughput sessions. The kernel may limit the size, in which case an event is logged and the session
+	// continues with the smaller buffer.
+	//
+	// Example Values:
+	//  - TCPSendBufferSize=8388608
+	//
+	// Required: No
+	//
+	// Default: The operating system default
+	//
+	// Valid Values:
+	//  - A positive integer
+	TCPSendBufferSize string = "TCPSendBufferSize"
+
+	// TCPReceiveBufferSize sets the size in bytes of the kernel receive buffer of the session's TCP connection. As
+	// with TCPSendBufferSize, an event is logged if the kernel limits the size.
+	//
+	// Example Values:
+	//  - TCPReceiveBufferSize=8388608
+	//
+	// Required: No
+	//
+	// Default: The operating system default
+	//
+	// Valid Values:
+	//  - A positive integer
+	TCPReceiveBufferSize string = "TCPReceiveBufferSize"
+
+	// TCPKeepAlive enables or disables TCP keepalive probes on the session's connection, e.g. to keep firewalls with
+	// aggressive idle timeouts from silently dropping it. When set, the probes are configured by TCPKeepAliveIdleSecs,
+	// TCPKeepAliveIntervalSecs and TCPKeepAliveCount.
+	//
+	// Required: No
+	//
+	// Default: Keepalive probes are sent every 15 seconds, as for any Go network connection
+	//
+	// Valid Values:
+	//  - Y
+	//  - N
+	TCPKeepAlive string = "TCPKeepAlive"
+
+	// TCPKeepAliveIdleSecs is the time in seconds a connection with TCPKeepAlive=Y must be idle before the first
+	// keepalive probe is sent.
+	//
+	// Example Values:
+	//  - TCPKeepAliveIdleSecs=60
+	//
+	// Required: No
+	//
+	// Default: 15
+	//
+	// Valid Values:
+	//  - A positive integer
+	TCPKeepAliveIdleSecs string = "TCPKeepAliveIdleSecs"
+
+	// TCPKeepAliveIntervalSecs is the time in seconds between the keepalive probes of a connection with TCPKeepAlive=Y.
+	//
+	// Example Values:
+	//  - TCPKeepAliveIntervalSecs=10
+	//
+	// Required: No
+	//
+	// Default: 15
+	//
+	// Valid Values:
+	//  - A positive integer
+	TCPKeepAliveIntervalSecs string = "TCPKeepAliveIntervalSecs"
+
+	// TCPKeepAliveCount is the number of unanswered keepalive probes after which a connection with TCPKeepAlive=Y
+	// is dropped.
+	//
+	// Example Values:
+	//  - TCPKeepAliveCount=5
+	//
+	// Required: No
+	//
+	// Default: 9
+	//
+	// Valid Values:
+	//  - A positive integer
+	TCPKeepAliveCount string = "TCPKeepAliveCount"
+
 	// EnableLastMsgSeqNumProcessed tells the FIX engine to add the last message sequence number processed
 	// to outgoing message headers (using optional tag 369).
 	//
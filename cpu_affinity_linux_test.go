@@ -0,0 +1,51 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+//go:build linux
+
+package quickfix
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetCPUAffinity(t *testing.T) {
+	var allowed unix.CPUSet
+	require.Nil(t, unix.SchedGetaffinity(0, &allowed))
+	cpu := 0
+	for !allowed.IsSet(cpu) {
+		cpu++
+	}
+
+	done := make(chan unix.CPUSet)
+	go func() {
+		// The thread is not unlocked, so that it exits bound to the core.
+		runtime.LockOSThread()
+		var set unix.CPUSet
+		if err := setCPUAffinity(cpu); err == nil {
+			_ = unix.SchedGetaffinity(0, &set)
+		}
+		done <- set
+	}()
+
+	set := <-done
+	assert.Equal(t, 1, set.Count())
+	assert.True(t, set.IsSet(cpu))
+}
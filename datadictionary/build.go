@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 )
 
 type builder struct {
@@ -225,7 +226,8 @@ func (b builder) buildFieldTypes() {
 }
 
 func buildFieldType(xmlField *XMLField) *FieldType {
-	field := NewFieldType(xmlField.Name, xmlField.Number, xmlField.Type)
+	// Types are spelled in upper case, except by some dictionaries using newer types, e.g. UTCTimestampWithNanos.
+	field := NewFieldType(xmlField.Name, xmlField.Number, strings.ToUpper(xmlField.Type))
 
 	if len(xmlField.Values) > 0 {
 		field.Enums = make(map[string]Enum)
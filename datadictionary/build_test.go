@@ -114,6 +114,17 @@ func (s *BuildSuite) TestInvalidMinor() {
 	}
 }
 
+func (s *BuildSuite) TestFieldTypes() {
+	s.doc.Fields = []*XMLField{
+		{Name: "SendingTime", Number: 52, Type: "UTCTIMESTAMP"},
+		{Name: "TransactTime", Number: 60, Type: "UTCTimestampWithNanos"},
+	}
+	dict, err := s.builder.build(s.doc)
+	s.Nil(err)
+	s.Equal("UTCTIMESTAMP", dict.FieldTypeByTag[52].Type)
+	s.Equal("UTCTIMESTAMPWITHNANOS", dict.FieldTypeByTag[60].Type)
+}
+
 func TestBuildFieldDef(t *testing.T) {
 	var tests = []struct {
 		element string
@@ -298,6 +298,122 @@ func NewMessageDef(name, msgType string, parts []MessagePart) *MessageDef {
 	return &msg
 }
 
+// MessageDef returns the definition of the message with the given MsgType, e.g. to enumerate the fields
+// a message requires.
+func (d *DataDictionary) MessageDef(msgType string) (*MessageDef, bool) {
+	msg, ok := d.Messages[msgType]
+	return msg, ok
+}
+
+// Merge returns a new DataDictionary holding the fields, components and messages of d and other, e.g. to
+// overlay the custom tags and message types of a venue on a standard FIX version. Entries of other replace
+// those of d with the same tag or name, as do its header and trailer if it defines them. The FIX version
+// is that of d. Neither dictionary is modified, but the definitions are shared with the result.
+func (d *DataDictionary) Merge(other *DataDictionary) *DataDictionary {
+	merged := &DataDictionary{
+		FIXType:         d.FIXType,
+		Major:           d.Major,
+		Minor:           d.Minor,
+		ServicePack:     d.ServicePack,
+		FieldTypeByTag:  make(map[int]*FieldType, len(d.FieldTypeByTag)),
+		FieldTypeByName: make(map[string]*FieldType, len(d.FieldTypeByName)),
+		Messages:        make(map[string]*MessageDef, len(d.Messages)),
+		ComponentTypes:  make(map[string]*ComponentType, len(d.ComponentTypes)),
+		Header:          d.Header,
+		Trailer:         d.Trailer,
+	}
+
+	for _, dict := range []*DataDictionary{d, other} {
+		if dict == nil {
+			continue
+		}
+		for tag, fieldType := range dict.FieldTypeByTag {
+			// A tag given a new name is no longer known by its old one.
+			if replaced, ok := merged.FieldTypeByTag[tag]; ok {
+				delete(merged.FieldTypeByName, replaced.Name())
+			}
+			merged.FieldTypeByTag[tag] = fieldType
+		}
+		for name, fieldType := range dict.FieldTypeByName {
+			merged.FieldTypeByName[name] = fieldType
+		}
+		for msgType, msg := range dict.Messages {
+			merged.Messages[msgType] = msg
+		}
+		for name, comp := range dict.ComponentTypes {
+			merged.ComponentTypes[name] = comp
+		}
+		if dict.Header != nil {
+			merged.Header = dict.Header
+		}
+		if dict.Trailer != nil {
+			merged.Trailer = dict.Trailer
+		}
+	}
+
+	return merged
+}
+
+// RequiredFields returns the tags of the fields and repeating groups the message requires, in declaration
+// order. Fields of a component are only required if the component is required.
+func (m MessageDef) RequiredFields() []int {
+	var tags []int
+	for _, f := range m.fieldDefs() {
+		if _, ok := m.RequiredTags[f.Tag()]; ok {
+			tags = append(tags, f.Tag())
+		}
+	}
+	return tags
+}
+
+// OptionalFields returns the tags of the fields and repeating groups the message does not require, in
+// declaration order.
+func (m MessageDef) OptionalFields() []int {
+	var tags []int
+	for _, f := range m.fieldDefs() {
+		if _, ok := m.RequiredTags[f.Tag()]; !ok {
+			tags = append(tags, f.Tag())
+		}
+	}
+	return tags
+}
+
+// Groups returns the repeating groups of the message by the tag of their NoXXX count field. The fields of
+// each group are given by its FieldDef.
+func (m MessageDef) Groups() map[int]*FieldDef {
+	groups := make(map[int]*FieldDef)
+	for tag, f := range m.Fields {
+		if f.IsGroup() {
+			groups[tag] = f
+		}
+	}
+	return groups
+}
+
+// fieldDefs returns the top level fields of the message, including those of its components, in
+// declaration order.
+func (m MessageDef) fieldDefs() []*FieldDef {
+	var fields []*FieldDef
+	seen := make(map[int]bool)
+	add := func(f *FieldDef) {
+		if !seen[f.Tag()] {
+			seen[f.Tag()] = true
+			fields = append(fields, f)
+		}
+	}
+	for _, part := range m.Parts {
+		switch p := part.(type) {
+		case messagePartWithFields:
+			for _, f := range p.Fields() {
+				add(f)
+			}
+		case *FieldDef:
+			add(p)
+		}
+	}
+	return fields
+}
+
 // Parse loads and build a datadictionary instance from an xml file.
 func Parse(path string) (*DataDictionary, error) {
 	var xmlFile *os.File
@@ -1,6 +1,8 @@
 package datadictionary
 
 import (
+	"slices"
+	"strings"
 	"testing"
 )
 
@@ -189,3 +191,106 @@ func TestMessageTags(t *testing.T) {
 		}
 	}
 }
+
+func TestMessageDef(t *testing.T) {
+	d, _ := dict()
+
+	if _, ok := d.MessageDef("ZZ"); ok {
+		t.Error("Found unknown message")
+	}
+
+	nos, ok := d.MessageDef("D")
+	if !ok {
+		t.Fatal("Did not find message")
+	}
+	if nos.Name != "NewOrderSingle" {
+		t.Errorf("Expected NewOrderSingle got %v", nos.Name)
+	}
+
+	required := nos.RequiredFields()
+	if len(required) != len(nos.RequiredTags) {
+		t.Errorf("Expected %v required fields got %v", len(nos.RequiredTags), required)
+	}
+	if len(required) == 0 || required[0] != 11 {
+		t.Errorf("Expected ClOrdID to be the first required field, got %v", required)
+	}
+
+	optional := nos.OptionalFields()
+	if len(required)+len(optional) != len(nos.Fields) {
+		t.Errorf("Expected %v fields got %v required and %v optional", len(nos.Fields), len(required), len(optional))
+	}
+	for _, tag := range []int{1, 453, 526} {
+		if !slices.Contains(optional, tag) {
+			t.Errorf("Expected %v to be optional", tag)
+		}
+	}
+
+	groups := nos.Groups()
+	if parties, ok := groups[453]; !ok || parties.Name() != "NoPartyIDs" {
+		t.Errorf("Did not find NoPartyIDs group")
+	}
+	if _, ok := groups[11]; ok {
+		t.Errorf("ClOrdID should not be a group")
+	}
+}
+
+func TestMerge(t *testing.T) {
+	d, _ := dict()
+	venue, err := ParseSrc(strings.NewReader(`
+<fix type="FIX" major="4" minor="3">
+  <header/>
+  <trailer/>
+  <messages>
+    <message name="VenueStatus" msgtype="U1" msgcat="app">
+      <field name="VenueState" required="Y"/>
+      <field name="FreeText" required="N"/>
+    </message>
+  </messages>
+  <components/>
+  <fields>
+    <field number="5001" name="VenueState" type="INT"/>
+    <field number="58" name="FreeText" type="STRING"/>
+  </fields>
+</fix>`))
+	if err != nil {
+		t.Fatalf("Unexpected err: %v", err)
+	}
+
+	merged := d.Merge(venue)
+	if merged.Major != 4 || merged.Minor != 3 {
+		t.Errorf("Expected FIX 4.3 got %v.%v", merged.Major, merged.Minor)
+	}
+	if _, ok := merged.Messages["D"]; !ok {
+		t.Error("Did not find standard message")
+	}
+	if msg, ok := merged.MessageDef("U1"); !ok || msg.Name != "VenueStatus" {
+		t.Error("Did not find venue message")
+	}
+	if _, ok := d.Messages["U1"]; ok {
+		t.Error("Merge modified the receiver")
+	}
+	if ft, ok := merged.FieldTypeByTag[5001]; !ok || ft.Name() != "VenueState" {
+		t.Error("Did not find venue field")
+	}
+
+	// The venue's definitions win on conflict.
+	if ft := merged.FieldTypeByTag[58]; ft.Name() != "FreeText" {
+		t.Errorf("Expected FreeText got %v", ft.Name())
+	}
+	if _, ok := merged.FieldTypeByName["Text"]; ok {
+		t.Error("Replaced field is still known by its old name")
+	}
+	if merged.FieldTypeByName["FreeText"] != merged.FieldTypeByTag[58] {
+		t.Error("Did not find renamed field by name")
+	}
+	if merged.Header != venue.Header || merged.Trailer != venue.Trailer {
+		t.Error("Expected the venue header and trailer")
+	}
+	if d.FieldTypeByTag[58].Name() != "Text" {
+		t.Error("Merge modified the receiver")
+	}
+
+	if merged := d.Merge(nil); len(merged.Messages) != len(d.Messages) || merged.Header != d.Header {
+		t.Error("Expected a copy when merging nil")
+	}
+}
@@ -16,8 +16,10 @@
 package quickfix
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"strings"
 	"time"
 
 	"golang.org/x/net/proxy"
@@ -25,7 +27,38 @@ import (
 	"github.com/quickfixgo/quickfix/config"
 )
 
+// connectTimeoutDialer bounds each dial of the wrapped dialer, proxy negotiation included.
+type connectTimeoutDialer struct {
+	proxy.ContextDialer
+	timeout time.Duration
+}
+
+func (d connectTimeoutDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+	return d.ContextDialer.DialContext(ctx, network, address)
+}
+
 func loadDialerConfig(settings *SessionSettings) (dialer proxy.ContextDialer, err error) {
+	if dialer, err = loadProxyDialerConfig(settings); err != nil || !settings.HasSetting(config.ConnectTimeout) {
+		return
+	}
+
+	timeout, err := settings.DurationSetting(config.ConnectTimeout)
+	if err != nil {
+		timeoutInt, err := settings.IntSetting(config.ConnectTimeout)
+		if err != nil {
+			return dialer, err
+		}
+		timeout = time.Duration(timeoutInt) * time.Second
+	}
+	if timeout <= 0 {
+		return dialer, IncorrectFormatForSetting{Setting: config.ConnectTimeout, Value: []byte(timeout.String())}
+	}
+	return connectTimeoutDialer{ContextDialer: dialer, timeout: timeout}, nil
+}
+
+func loadProxyDialerConfig(settings *SessionSettings) (dialer proxy.ContextDialer, err error) {
 	stdDialer := &net.Dialer{}
 	if settings.HasSetting(config.SocketTimeout) {
 		timeout, err := settings.DurationSetting(config.SocketTimeout)
@@ -51,8 +84,8 @@ func loadDialerConfig(settings *SessionSettings) (dialer proxy.ContextDialer, er
 		return
 	}
 
-	switch proxyType {
-	case "socks":
+	switch strings.ToLower(proxyType) {
+	case "socks", "socks5":
 		var proxyHost string
 		var proxyPort int
 		if proxyHost, err = settings.Setting(config.ProxyHost); err != nil {
@@ -16,6 +16,7 @@
 package quickfix
 
 import (
+	"context"
 	"net"
 	"testing"
 	"time"
@@ -90,3 +91,63 @@ func (s *DialerTestSuite) TestLoadDialerSocksProxyInvalidPort() {
 	_, err := loadDialerConfig(s.settings.GlobalSettings())
 	s.Require().NotNil(err)
 }
+
+func (s *DialerTestSuite) TestLoadDialerSocks5Proxy() {
+	s.settings.GlobalSettings().Set(config.ProxyType, "SOCKS5")
+	s.settings.GlobalSettings().Set(config.ProxyHost, "localhost")
+	s.settings.GlobalSettings().Set(config.ProxyPort, "31337")
+	dialer, err := loadDialerConfig(s.settings.GlobalSettings())
+	s.Require().Nil(err)
+	s.Require().NotNil(dialer)
+
+	_, ok := dialer.(*net.Dialer)
+	s.Require().False(ok)
+}
+
+func (s *DialerTestSuite) TestLoadDialerWithConnectTimeout() {
+	s.settings.GlobalSettings().Set(config.ConnectTimeout, "5")
+	dialer, err := loadDialerConfig(s.settings.GlobalSettings())
+	s.Require().Nil(err)
+
+	timeoutDialer, ok := dialer.(connectTimeoutDialer)
+	s.Require().True(ok)
+	s.EqualValues(5*time.Second, timeoutDialer.timeout)
+
+	s.settings.GlobalSettings().Set(config.ConnectTimeout, "0s")
+	_, err = loadDialerConfig(s.settings.GlobalSettings())
+	s.Require().NotNil(err)
+
+	s.settings.GlobalSettings().Set(config.ConnectTimeout, "soon")
+	_, err = loadDialerConfig(s.settings.GlobalSettings())
+	s.Require().NotNil(err)
+}
+
+func (s *DialerTestSuite) TestSocksProxyConnectTimeout() {
+	// The proxy accepts connections but never completes the SOCKS5 negotiation.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	s.Require().Nil(err)
+	defer func() { _ = listener.Close() }()
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			defer func() { _ = conn.Close() }()
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	s.Require().Nil(err)
+	s.settings.GlobalSettings().Set(config.ProxyType, "SOCKS5")
+	s.settings.GlobalSettings().Set(config.ProxyHost, host)
+	s.settings.GlobalSettings().Set(config.ProxyPort, port)
+	s.settings.GlobalSettings().Set(config.ConnectTimeout, "100ms")
+	dialer, err := loadDialerConfig(s.settings.GlobalSettings())
+	s.Require().Nil(err)
+
+	start := time.Now()
+	_, err = dialer.DialContext(context.Background(), "tcp", "fix.example.com:5001")
+	s.Require().NotNil(err)
+	s.Less(time.Since(start), 5*time.Second)
+}
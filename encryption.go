@@ -0,0 +1,141 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// EncryptionProvider encrypts and decrypts the values of the body fields listed by the EncryptedTags setting,
+// for counterparties using tag-level encryption. Encrypted values are sent as field values, so they must not
+// contain the SOH delimiter.
+type EncryptionProvider interface {
+	// Encrypt returns the value of the field with the given tag as it is sent.
+	Encrypt(tag Tag, value []byte) ([]byte, error)
+	// Decrypt returns the value of the field with the given tag as it was before Encrypt.
+	Decrypt(tag Tag, value []byte) ([]byte, error)
+}
+
+type nullEncryptionProvider struct{}
+
+func (nullEncryptionProvider) Encrypt(_ Tag, value []byte) ([]byte, error) { return value, nil }
+func (nullEncryptionProvider) Decrypt(_ Tag, value []byte) ([]byte, error) { return value, nil }
+
+// NewNullEncryptionProvider returns an EncryptionProvider leaving field values as they are.
+func NewNullEncryptionProvider() EncryptionProvider {
+	return nullEncryptionProvider{}
+}
+
+// encryptionProviderRef holds the EncryptionProvider of a session, which is replaced atomically.
+type encryptionProviderRef struct {
+	EncryptionProvider
+}
+
+// errNoEncryptionProvider is returned when a message holding one of the EncryptedTags is sent or received by a
+// session without an EncryptionProvider.
+var errNoEncryptionProvider = errors.New("EncryptedTags is set but no EncryptionProvider is")
+
+// parseEncryptedTags parses an EncryptedTags value of comma separated tags.
+func parseEncryptedTags(raw string) ([]Tag, error) {
+	var tags []Tag
+	for _, tagStr := range strings.Split(raw, ",") {
+		tag, err := strconv.Atoi(strings.TrimSpace(tagStr))
+		if err != nil || tag <= 0 {
+			return nil, IncorrectFormatForSetting{Setting: config.EncryptedTags, Value: []byte(raw), Err: fmt.Errorf("invalid tag: %q", tagStr)}
+		}
+		tags = append(tags, Tag(tag))
+	}
+	return tags, nil
+}
+
+// setEncryptionProvider sets the EncryptionProvider used for the EncryptedTags of the session.
+func (s *session) setEncryptionProvider(provider EncryptionProvider) {
+	s.encryption.Store(&encryptionProviderRef{provider})
+}
+
+// encryptFields returns msg with its EncryptedTags encrypted. msg itself is left as it is, so that the caller's
+// message keeps its clear values: a copy is encrypted if msg holds any of the tags.
+func (s *session) encryptFields(msg *Message) (*Message, error) {
+	if _, ok := s.firstEncryptedTag(msg); !ok {
+		return msg, nil
+	}
+	ref := s.encryption.Load()
+	if ref == nil {
+		return nil, errNoEncryptionProvider
+	}
+
+	encrypted := msg.Clone()
+	for _, tag := range s.encryptedTags {
+		if !encrypted.Body.Has(tag) {
+			continue
+		}
+		value, _ := encrypted.Body.GetBytes(tag)
+		value, err := ref.Encrypt(tag, value)
+		if err == nil && bytes.IndexByte(value, '\x01') >= 0 {
+			err = errors.New("encrypted value contains SOH")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tag %d: %w", tag, err)
+		}
+		encrypted.Body.SetBytes(tag, value)
+	}
+	return encrypted, nil
+}
+
+// decryptFields decrypts the EncryptedTags of an incoming message in place. A value the EncryptionProvider fails
+// to decrypt, or that arrives before an EncryptionProvider is set, is rejected as incorrect, so that the message is
+// handled like any other invalid message.
+func (s *session) decryptFields(msg *Message) MessageRejectError {
+	first, ok := s.firstEncryptedTag(msg)
+	if !ok {
+		return nil
+	}
+	ref := s.encryption.Load()
+	if ref == nil {
+		s.log.OnEventf("Msg Decrypt Error: tag %d: %v", first, errNoEncryptionProvider)
+		return ValueIsIncorrect(first)
+	}
+
+	for _, tag := range s.encryptedTags {
+		if !msg.Body.Has(tag) {
+			continue
+		}
+		value, _ := msg.Body.GetBytes(tag)
+		value, err := ref.Decrypt(tag, value)
+		if err != nil {
+			s.log.OnEventf("Msg Decrypt Error: tag %d: %v", tag, err)
+			return ValueIsIncorrect(tag)
+		}
+		msg.Body.SetBytes(tag, value)
+	}
+	return nil
+}
+
+// firstEncryptedTag returns the first of the EncryptedTags msg holds, if any.
+func (s *session) firstEncryptedTag(msg *Message) (Tag, bool) {
+	for _, tag := range s.encryptedTags {
+		if msg.Body.Has(tag) {
+			return tag, true
+		}
+	}
+	return 0, false
+}
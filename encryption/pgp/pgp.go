@@ -0,0 +1,78 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package pgp provides a quickfix.EncryptionProvider encrypting field values with OpenPGP.
+//
+// Values are encrypted to the counterparty's public keys and decrypted with the session's private keys,
+// and sent base64 encoded:
+//
+//	provider := pgp.NewEncryptionProvider(counterpartyKeys, ownKeys)
+//	err := quickfix.SetEncryptionProvider(sessionID, provider)
+package pgp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp" //nolint:staticcheck // Deprecated, but the format legacy venues use.
+	// Encrypt falls back to RIPEMD160 for keys without hash preferences.
+	_ "golang.org/x/crypto/ripemd160" //nolint:staticcheck // Deprecated, but required by openpgp.
+
+	"github.com/quickfixgo/quickfix"
+)
+
+type encryptionProvider struct {
+	recipients openpgp.EntityList
+	keyring    openpgp.EntityList
+}
+
+// NewEncryptionProvider returns an EncryptionProvider encrypting field values to recipients and decrypting
+// them with the private keys of keyring, which must already be decrypted.
+func NewEncryptionProvider(recipients, keyring openpgp.EntityList) quickfix.EncryptionProvider {
+	return encryptionProvider{recipients: recipients, keyring: keyring}
+}
+
+func (p encryptionProvider) Encrypt(_ quickfix.Tag, value []byte) ([]byte, error) {
+	var encrypted bytes.Buffer
+	w, err := openpgp.Encrypt(&encrypted, p.recipients, nil, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "encrypt")
+	}
+	if _, err := w.Write(value); err != nil {
+		return nil, errors.Wrap(err, "encrypt")
+	}
+	if err := w.Close(); err != nil {
+		return nil, errors.Wrap(err, "encrypt")
+	}
+	return base64.StdEncoding.AppendEncode(nil, encrypted.Bytes()), nil
+}
+
+func (p encryptionProvider) Decrypt(_ quickfix.Tag, value []byte) ([]byte, error) {
+	encrypted, err := base64.StdEncoding.AppendDecode(nil, value)
+	if err != nil {
+		return nil, errors.Wrap(err, "decode")
+	}
+	md, err := openpgp.ReadMessage(bytes.NewReader(encrypted), p.keyring, nil, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+	decrypted, err := io.ReadAll(md.UnverifiedBody)
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+	return decrypted, nil
+}
@@ -0,0 +1,54 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package pgp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"        //nolint:staticcheck // Deprecated, but the format legacy venues use.
+	"golang.org/x/crypto/openpgp/packet" //nolint:staticcheck // Deprecated, but the format legacy venues use.
+)
+
+func newEntity(t *testing.T, name string) *openpgp.Entity {
+	entity, err := openpgp.NewEntity(name, "", name+"@example.com", &packet.Config{RSABits: 1024})
+	require.Nil(t, err)
+	return entity
+}
+
+func TestEncryptionProvider(t *testing.T) {
+	counterparty := newEntity(t, "counterparty")
+	sender := NewEncryptionProvider(openpgp.EntityList{counterparty}, nil)
+	receiver := NewEncryptionProvider(nil, openpgp.EntityList{counterparty})
+
+	encrypted, err := sender.Encrypt(58, []byte("secret"))
+	require.Nil(t, err)
+	assert.NotContains(t, string(encrypted), "secret")
+	assert.Equal(t, -1, bytes.IndexByte(encrypted, '\x01'))
+
+	decrypted, err := receiver.Decrypt(58, encrypted)
+	require.Nil(t, err)
+	assert.Equal(t, "secret", string(decrypted))
+
+	other := NewEncryptionProvider(nil, openpgp.EntityList{newEntity(t, "other")})
+	_, err = other.Decrypt(58, encrypted)
+	assert.NotNil(t, err)
+
+	_, err = receiver.Decrypt(58, []byte("not base64!"))
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,141 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// reverseEncryptionProvider "encrypts" values by reversing them.
+type reverseEncryptionProvider struct{}
+
+func reverseBytes(value []byte) []byte {
+	reversed := make([]byte, len(value))
+	for i, b := range value {
+		reversed[len(value)-1-i] = b
+	}
+	return reversed
+}
+
+func (reverseEncryptionProvider) Encrypt(_ Tag, value []byte) ([]byte, error) {
+	return reverseBytes(value), nil
+}
+
+func (reverseEncryptionProvider) Decrypt(_ Tag, value []byte) ([]byte, error) {
+	return reverseBytes(value), nil
+}
+
+// sohEncryptionProvider returns values that cannot be sent.
+type sohEncryptionProvider struct{ reverseEncryptionProvider }
+
+func (sohEncryptionProvider) Encrypt(_ Tag, value []byte) ([]byte, error) {
+	return append(value, '\x01'), nil
+}
+
+func TestParseEncryptedTags(t *testing.T) {
+	tags, err := parseEncryptedTags("58, 354,355")
+	require.Nil(t, err)
+	assert.Equal(t, []Tag{58, 354, 355}, tags)
+
+	for _, invalid := range []string{"", "58,", "abc", "0", "-1"} {
+		_, err := parseEncryptedTags(invalid)
+		assert.NotNil(t, err, invalid)
+	}
+}
+
+func TestSessionEncryptFields(t *testing.T) {
+	s := &session{encryptedTags: []Tag{tagText, 355}}
+	msg := NewMessage()
+	msg.Body.SetString(tagText, "secret")
+	msg.Body.SetString(tagTestReqID, "clear")
+
+	// Messages without encrypted tags are sent as they are without a provider.
+	clear := NewMessage()
+	clear.Body.SetString(tagTestReqID, "clear")
+	sent, err := s.encryptFields(clear)
+	require.Nil(t, err)
+	assert.Same(t, clear, sent)
+	_, err = s.encryptFields(msg)
+	assert.Equal(t, errNoEncryptionProvider, err)
+
+	s.setEncryptionProvider(reverseEncryptionProvider{})
+	encrypted, err := s.encryptFields(msg)
+	require.Nil(t, err)
+	text, _ := encrypted.Body.GetString(tagText)
+	assert.Equal(t, "terces", text)
+	testReqID, _ := encrypted.Body.GetString(tagTestReqID)
+	assert.Equal(t, "clear", testReqID)
+
+	// The caller's message is left unencrypted.
+	text, _ = msg.Body.GetString(tagText)
+	assert.Equal(t, "secret", text)
+
+	require.Nil(t, s.decryptFields(encrypted))
+	text, _ = encrypted.Body.GetString(tagText)
+	assert.Equal(t, "secret", text)
+
+	s.setEncryptionProvider(sohEncryptionProvider{})
+	_, err = s.encryptFields(msg)
+	assert.NotNil(t, err)
+}
+
+func TestSessionDecryptFieldsWithoutProvider(t *testing.T) {
+	s := &session{encryptedTags: []Tag{tagText}, log: nullLog{}}
+	msg := NewMessage()
+	msg.Body.SetString(tagTestReqID, "clear")
+	require.Nil(t, s.decryptFields(msg))
+
+	// Encrypted values are not passed on as they are.
+	msg.Body.SetString(tagText, "terces")
+	reject := s.decryptFields(msg)
+	require.NotNil(t, reject)
+	assert.Equal(t, rejectReasonValueIsIncorrect, reject.RejectReason())
+	assert.Equal(t, tagText, *reject.RefTagID())
+}
+
+// failingEncryptionProvider cannot decrypt any value.
+type failingEncryptionProvider struct{ reverseEncryptionProvider }
+
+func (failingEncryptionProvider) Decrypt(_ Tag, _ []byte) ([]byte, error) {
+	return nil, errors.New("bad key")
+}
+
+func TestSessionDecryptFieldsFailure(t *testing.T) {
+	s := &session{encryptedTags: []Tag{tagText}, log: nullLog{}}
+	s.setEncryptionProvider(failingEncryptionProvider{})
+	msg := NewMessage()
+	msg.Body.SetString(tagText, "terces")
+
+	reject := s.decryptFields(msg)
+	require.NotNil(t, reject)
+	assert.Equal(t, rejectReasonValueIsIncorrect, reject.RejectReason())
+	assert.Equal(t, tagText, *reject.RefTagID())
+}
+
+func TestNullEncryptionProvider(t *testing.T) {
+	provider := NewNullEncryptionProvider()
+	value, err := provider.Encrypt(tagText, []byte("secret"))
+	require.Nil(t, err)
+	assert.True(t, bytes.Equal([]byte("secret"), value))
+	value, err = provider.Decrypt(tagText, value)
+	require.Nil(t, err)
+	assert.True(t, bytes.Equal([]byte("secret"), value))
+}
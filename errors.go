@@ -23,6 +23,14 @@ import (
 // ErrDoNotSend is a convenience error to indicate a DoNotSend in ToApp.
 var ErrDoNotSend = errors.New("Do Not Send")
 
+// ErrSessionQueueFull is returned when a message is sent to a session already holding MaxPendingOutboundMessages
+// messages waiting to be written.
+var ErrSessionQueueFull = errors.New("Session queue full")
+
+// ErrRateLimitExceeded is returned when a message is sent to a session that has already sent
+// MaxOutboundMsgRatePerSecond messages in the last second, beyond its MaxOutboundBurstMessages allowance.
+var ErrRateLimitExceeded = errors.New("Session rate limit exceeded")
+
 // rejectReason enum values.
 const (
 	rejectReasonInvalidTagNumber                          = 0
@@ -72,6 +80,31 @@ func (RejectLogon) BusinessRejectRefID() string { return "" }
 // IsBusinessReject implements MessageRejectError.
 func (RejectLogon) IsBusinessReject() bool { return false }
 
+// ApplicationError is a business level MessageRejectError carrying the BusinessRejectReason (380) it is rejected
+// with. Sessions reply to messages their application rejects as an unsupported message type, with
+// UnsupportedMessageType or another business reject with reason 3, with an ApplicationError whose Reason is set by
+// the DefaultUnsupportedMsgRejectReason setting. An ApplicationError returned by the application is sent as it is.
+type ApplicationError struct {
+	Text   string
+	Reason int
+	RefTag *Tag
+	RefID  string
+}
+
+func (e ApplicationError) Error() string { return e.Text }
+
+// RefTagID implements MessageRejectError.
+func (e ApplicationError) RefTagID() *Tag { return e.RefTag }
+
+// RejectReason implements MessageRejectError.
+func (e ApplicationError) RejectReason() int { return e.Reason }
+
+// BusinessRejectRefID implements MessageRejectError.
+func (e ApplicationError) BusinessRejectRefID() string { return e.RefID }
+
+// IsBusinessReject implements MessageRejectError.
+func (ApplicationError) IsBusinessReject() bool { return true }
+
 type messageRejectError struct {
 	rejectReason        int
 	text                string
@@ -295,3 +295,31 @@ func TestInvalidTagNumber(t *testing.T) {
 		t.Error("Expected IsBusinessReject to be false\n")
 	}
 }
+
+func TestApplicationError(t *testing.T) {
+	var (
+		expectedErrorString             = "Unknown order"
+		expectedRejectReason            = 1
+		expectedRefTagID            Tag = 11
+		expectedBusinessRejectRefID     = "ID"
+	)
+	var msgRej MessageRejectError = ApplicationError{
+		Text: expectedErrorString, Reason: expectedRejectReason, RefTag: &expectedRefTagID, RefID: expectedBusinessRejectRefID,
+	}
+
+	if msgRej.Error() != expectedErrorString {
+		t.Errorf("expected: %s, got: %s\n", expectedErrorString, msgRej.Error())
+	}
+	if msgRej.RejectReason() != expectedRejectReason {
+		t.Errorf("expected: %d, got: %d\n", expectedRejectReason, msgRej.RejectReason())
+	}
+	if *msgRej.RefTagID() != expectedRefTagID {
+		t.Errorf("expected: %d, got: %d\n", expectedRefTagID, *msgRej.RefTagID())
+	}
+	if msgRej.BusinessRejectRefID() != expectedBusinessRejectRefID {
+		t.Errorf("expected: %s, got: %s\n", expectedBusinessRejectRefID, msgRej.BusinessRejectRefID())
+	}
+	if !msgRej.IsBusinessReject() {
+		t.Error("Expected IsBusinessReject to be true\n")
+	}
+}
@@ -17,6 +17,7 @@ package quickfix
 
 import (
 	"bytes"
+	"slices"
 	"sort"
 	"sync"
 	"time"
@@ -51,6 +52,16 @@ func (t tagSort) Len() int           { return len(t.tags) }
 func (t tagSort) Swap(i, j int)      { t.tags[i], t.tags[j] = t.tags[j], t.tags[i] }
 func (t tagSort) Less(i, j int) bool { return t.compare(t.tags[i], t.tags[j]) }
 
+// isSorted reports whether the tags are already in order, without the allocation of sort.IsSorted.
+func (t tagSort) isSorted() bool {
+	for i := 1; i < len(t.tags); i++ {
+		if t.compare(t.tags[i], t.tags[i-1]) {
+			return false
+		}
+	}
+	return true
+}
+
 // FieldMap is a collection of fix fields that make up a fix message.
 type FieldMap struct {
 	tagLookup map[Tag]field
@@ -84,6 +95,26 @@ func (m FieldMap) Tags() []Tag {
 	return tags
 }
 
+// ForEach calls fn for each field in this FieldMap, in the order they are written to the wire, including
+// the members of repeating groups, until fn returns false. Unlike Tags, it does not allocate. The value
+// passed to fn is only valid for the duration of the call, and fn must not modify the FieldMap.
+func (m FieldMap) ForEach(fn func(tag Tag, value []byte) bool) {
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	// Sorting only allocates if the tags are out of order, i.e. after a field has been added.
+	if !m.tagSort.isSorted() {
+		sort.Sort(m.tagSort)
+	}
+	for _, tag := range m.tags {
+		for _, tv := range m.tagLookup[tag] {
+			if !fn(tv.tag, tv.value) {
+				return
+			}
+		}
+	}
+}
+
 // Get parses out a field in this FieldMap. Returned reject may indicate the field is not present, or the field value is invalid.
 func (m FieldMap) Get(parser Field) MessageRejectError {
 	return m.GetField(parser.Tag(), parser)
@@ -252,7 +283,8 @@ func (m *FieldMap) SetField(tag Tag, field FieldValueWriter) *FieldMap {
 	return m.SetBytes(tag, field.Write())
 }
 
-// SetBytes sets bytes.
+// SetBytes sets the field with Tag tag to value. The field holds on to value rather than a copy of it, so
+// unlike SetString it does not allocate a copy of the value, but value must not be modified afterwards.
 func (m *FieldMap) SetBytes(tag Tag, value []byte) *FieldMap {
 	f := m.getOrCreate(tag)
 	initField(f, tag, value)
@@ -280,7 +312,46 @@ func (m *FieldMap) Remove(tag Tag) {
 	m.rwLock.Lock()
 	defer m.rwLock.Unlock()
 
+	m.removeNoLock(tag)
+}
+
+// DeleteField removes the field with Tag tag, as well as all its instances in the repeating groups of the field map,
+// and returns true if any was removed. Removing the count tag of a nested repeating group does not remove the members
+// of the group.
+func (m *FieldMap) DeleteField(tag Tag) bool {
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	_, deleted := m.tagLookup[tag]
+	m.removeNoLock(tag)
+
+	for t, f := range m.tagLookup {
+		if len(f) < 2 {
+			continue
+		}
+
+		kept := make(field, 0, len(f))
+		for _, tv := range f {
+			if tv.tag != tag {
+				kept = append(kept, tv)
+			}
+		}
+		if len(kept) < len(f) {
+			m.tagLookup[t] = kept
+			deleted = true
+		}
+	}
+
+	return deleted
+}
+
+func (m *FieldMap) removeNoLock(tag Tag) {
+	if _, ok := m.tagLookup[tag]; !ok {
+		return
+	}
+
 	delete(m.tagLookup, tag)
+	m.tags = slices.DeleteFunc(m.tags, func(t Tag) bool { return t == tag })
 }
 
 // Clear purges all fields from field map.
@@ -317,6 +388,70 @@ func (m *FieldMap) CopyInto(to *FieldMap) {
 	to.compare = m.compare
 }
 
+// cloneInto overwrites the given FieldMap with a deep copy of this one, including the members of repeating groups.
+func (m *FieldMap) cloneInto(to *FieldMap) {
+	m.rwLock.RLock()
+	defer m.rwLock.RUnlock()
+
+	to.tagLookup = make(map[Tag]field, len(m.tagLookup))
+	for tag, f := range m.tagLookup {
+		clone := make(field, len(f))
+		for i := range f {
+			clone[i] = f[i].clone()
+		}
+		to.tagLookup[tag] = clone
+	}
+	to.tags = make([]Tag, len(m.tags))
+	copy(to.tags, m.tags)
+	to.compare = m.compare
+}
+
+// CopyTo sets every field of this FieldMap on dst, replacing the fields of dst with the same tags and leaving the
+// others as they are. Repeating groups are copied with their members. Unlike CopyInto, dst keeps its field ordering,
+// so that e.g. a header can be copied into the header of each of several outgoing messages.
+func (m *FieldMap) CopyTo(dst *FieldMap) {
+	if dst.rwLock == nil {
+		dst.init()
+	}
+	if dst.rwLock == m.rwLock {
+		return
+	}
+
+	m.rwLock.RLock()
+	defer m.rwLock.RUnlock()
+	dst.rwLock.Lock()
+	defer dst.rwLock.Unlock()
+
+	for tag, f := range m.tagLookup {
+		clone := make(field, len(f))
+		for i := range f {
+			clone[i] = f[i].clone()
+		}
+		if _, ok := dst.tagLookup[tag]; !ok {
+			dst.tags = append(dst.tags, tag)
+		}
+		dst.tagLookup[tag] = clone
+	}
+}
+
+// strip removes the fields with the given tags, including the members of repeating groups with those tags.
+func (m *FieldMap) strip(tags []Tag) {
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	for tag, f := range m.tagLookup {
+		if slices.Contains(tags, tag) {
+			delete(m.tagLookup, tag)
+			continue
+		}
+		if len(f) > 1 {
+			members := slices.DeleteFunc(f[1:], func(tv TagValue) bool { return slices.Contains(tags, tv.tag) })
+			m.tagLookup[tag] = f[:1+len(members)]
+		}
+	}
+	m.tags = slices.DeleteFunc(m.tags, func(tag Tag) bool { return slices.Contains(tags, tag) })
+}
+
 func (m *FieldMap) add(f field) {
 	t := fieldTag(f)
 	if _, ok := m.tagLookup[t]; !ok {
@@ -377,6 +512,19 @@ func (m FieldMap) write(buffer *bytes.Buffer) {
 	}
 }
 
+// appendFields appends the fields of m to fields, in the order they are written to the wire.
+func (m FieldMap) appendFields(fields []TagValue) []TagValue {
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	for _, tag := range m.sortedTags() {
+		if f, ok := m.tagLookup[tag]; ok {
+			fields = append(fields, f...)
+		}
+	}
+	return fields
+}
+
 func (m FieldMap) total() int {
 	m.rwLock.RLock()
 	defer m.rwLock.RUnlock()
@@ -412,3 +560,126 @@ func (m FieldMap) length() int {
 
 	return length
 }
+
+// fieldLength returns the number of bytes the field of tag takes on the wire, or 0 if it is not set.
+func (m FieldMap) fieldLength(tag Tag) int {
+	m.rwLock.RLock()
+	defer m.rwLock.RUnlock()
+
+	length := 0
+	for _, tv := range m.tagLookup[tag] {
+		length += tv.length()
+	}
+	return length
+}
+
+// DiffOp is the kind of difference between two FieldMaps reported by FieldDiff.
+type DiffOp int
+
+const (
+	// DiffAdd reports a field only present in the other FieldMap.
+	DiffAdd DiffOp = iota
+	// DiffRemove reports a field missing from the other FieldMap.
+	DiffRemove
+	// DiffChange reports a field whose value differs between the FieldMaps.
+	DiffChange
+)
+
+func (op DiffOp) String() string {
+	switch op {
+	case DiffAdd:
+		return "Add"
+	case DiffRemove:
+		return "Remove"
+	case DiffChange:
+		return "Change"
+	}
+	return "Unknown"
+}
+
+// FieldDiff describes a field that differs between two FieldMaps.
+//
+// For a plain field, Old and New hold the field's values. For a repeating group, whose members cannot be
+// told apart by tag alone, they hold the whole group as written to the wire, starting with the NumInGroup
+// field, e.g. "453=2\x01448=A\x01448=B\x01". Old is nil for DiffAdd, New is nil for DiffRemove.
+type FieldDiff struct {
+	Tag Tag
+	Old []byte
+	New []byte
+	Op  DiffOp
+}
+
+// Diff returns the fields that differ from m in other, in ascending tag order.
+func (m FieldMap) Diff(other FieldMap) []FieldDiff {
+	m.rwLock.RLock()
+	defer m.rwLock.RUnlock()
+	if other.rwLock != m.rwLock {
+		other.rwLock.RLock()
+		defer other.rwLock.RUnlock()
+	}
+
+	tags := make([]Tag, 0, len(m.tagLookup)+len(other.tagLookup))
+	for tag := range m.tagLookup {
+		tags = append(tags, tag)
+	}
+	for tag := range other.tagLookup {
+		if _, ok := m.tagLookup[tag]; !ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+
+	var diffs []FieldDiff
+	for _, tag := range tags {
+		oldField, inOld := m.tagLookup[tag]
+		newField, inNew := other.tagLookup[tag]
+		switch {
+		case !inNew:
+			diffs = append(diffs, FieldDiff{Tag: tag, Old: diffValue(oldField), Op: DiffRemove})
+		case !inOld:
+			diffs = append(diffs, FieldDiff{Tag: tag, New: diffValue(newField), Op: DiffAdd})
+		default:
+			if oldValue, newValue := diffValue(oldField), diffValue(newField); !bytes.Equal(oldValue, newValue) {
+				diffs = append(diffs, FieldDiff{Tag: tag, Old: oldValue, New: newValue, Op: DiffChange})
+			}
+		}
+	}
+	return diffs
+}
+
+// diffValue returns the value compared by Diff: the field's value, or the whole group for a repeating group.
+func diffValue(f field) []byte {
+	if len(f) == 1 {
+		return f[0].value
+	}
+	var buffer bytes.Buffer
+	writeField(f, &buffer)
+	return buffer.Bytes()
+}
+
+// equal reports whether m and other hold the same fields with the same values. Repeating groups are
+// compared member by member, including nested groups.
+func (m FieldMap) equal(other FieldMap) bool {
+	m.rwLock.RLock()
+	defer m.rwLock.RUnlock()
+	if other.rwLock != m.rwLock {
+		other.rwLock.RLock()
+		defer other.rwLock.RUnlock()
+	}
+
+	if len(m.tagLookup) != len(other.tagLookup) {
+		return false
+	}
+	for tag, f := range m.tagLookup {
+		otherField, ok := other.tagLookup[tag]
+		if !ok || len(f) != len(otherField) {
+			return false
+		}
+		for i := range f {
+			if f[i].tag != otherField[i].tag || !bytes.Equal(f[i].value, otherField[i].value) {
+				return false
+			}
+		}
+	}
+	return true
+}
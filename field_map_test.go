@@ -17,6 +17,9 @@ package quickfix
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,6 +121,38 @@ func TestFieldMap_TypedSetAndGet(t *testing.T) {
 	assert.True(t, bytes.Equal([]byte("hello"), b))
 }
 
+// multipleValueString is a FIX MultipleValueString value, a custom FieldValue.
+type multipleValueString []string
+
+func (f *multipleValueString) Read(b []byte) error {
+	if len(b) == 0 {
+		return errors.New("empty MultipleValueString")
+	}
+	*f = strings.Split(string(b), " ")
+	return nil
+}
+
+func (f multipleValueString) Write() []byte {
+	return []byte(strings.Join(f, " "))
+}
+
+func TestFieldMap_CustomFieldValue(t *testing.T) {
+	var fMap FieldMap
+	fMap.init()
+
+	fMap.SetField(18, multipleValueString{"G", "h", "W"})
+	s, err := fMap.GetString(18)
+	assert.Nil(t, err)
+	assert.Equal(t, "G h W", s)
+
+	var execInst multipleValueString
+	assert.Nil(t, fMap.GetField(18, &execInst))
+	assert.Equal(t, multipleValueString{"G", "h", "W"}, execInst)
+
+	fMap.SetString(18, "")
+	assert.NotNil(t, fMap.GetField(18, &execInst))
+}
+
 func TestFieldMap_BoolTypedSetAndGet(t *testing.T) {
 	var fMap FieldMap
 	fMap.init()
@@ -191,6 +226,52 @@ func TestFieldMap_CopyInto(t *testing.T) {
 	assert.Equal(t, "a", s)
 }
 
+func TestFieldMap_CopyTo(t *testing.T) {
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447)})
+	parties.Add().SetString(Tag(448), "A").SetString(Tag(447), "D")
+	parties.Add().SetString(Tag(448), "B").SetString(Tag(447), "D")
+
+	var src FieldMap
+	src.init()
+	src.SetString(Tag(1), "account").SetString(Tag(11), "order").SetGroup(parties)
+
+	var dst FieldMap
+	dst.initWithOrdering(headerFieldOrdering)
+	dst.SetString(Tag(35), "D").SetString(Tag(11), "old").SetString(Tag(8), "FIX.4.4")
+	src.CopyTo(&dst)
+
+	// Fields of dst are replaced or kept, and dst keeps its ordering.
+	assert.Equal(t, []Tag{8, 35, 1, 11, 453}, dst.sortedTags())
+	s, err := dst.GetString(Tag(11))
+	assert.Nil(t, err)
+	assert.Equal(t, "order", s)
+	s, err = dst.GetString(Tag(8))
+	assert.Nil(t, err)
+	assert.Equal(t, "FIX.4.4", s)
+
+	// Repeating groups are copied with their members.
+	parties = NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447)})
+	assert.Nil(t, dst.GetGroup(parties))
+	if assert.Equal(t, 2, parties.Len()) {
+		s, err = parties.Get(1).GetString(Tag(448))
+		assert.Nil(t, err)
+		assert.Equal(t, "B", s)
+	}
+
+	// The copy does not share values with the source.
+	src.SetString(Tag(1), "other")
+	s, err = dst.GetString(Tag(1))
+	assert.Nil(t, err)
+	assert.Equal(t, "account", s)
+
+	// Copying to an uninitialized FieldMap, or to itself, is allowed.
+	var empty FieldMap
+	src.CopyTo(&empty)
+	assert.Equal(t, src.sortedTags(), empty.sortedTags())
+	src.CopyTo(&src)
+	assert.Equal(t, []Tag{1, 11, 453}, src.sortedTags())
+}
+
 func TestFieldMap_Remove(t *testing.T) {
 	var fMap FieldMap
 	fMap.init()
@@ -201,4 +282,208 @@ func TestFieldMap_Remove(t *testing.T) {
 	fMap.Remove(1)
 	assert.False(t, fMap.Has(1))
 	assert.True(t, fMap.Has(2))
+
+	// Setting a removed tag again writes it once.
+	fMap.SetField(1, FIXString("again"))
+	var b bytes.Buffer
+	fMap.write(&b)
+	assert.Equal(t, "1=again\x012=world\x01", b.String())
+}
+
+func TestFieldMap_DeleteField(t *testing.T) {
+	var fMap FieldMap
+	fMap.init()
+
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447), GroupElement(452)})
+	parties.Add().SetString(Tag(448), "A").SetString(Tag(447), "D").SetInt(Tag(452), 1)
+	parties.Add().SetString(Tag(448), "B").SetString(Tag(447), "D")
+	fMap.SetField(112, FIXString("TEST"))
+	fMap.SetField(58, FIXString("text"))
+	fMap.SetGroup(parties)
+
+	assert.True(t, fMap.DeleteField(112))
+	assert.False(t, fMap.Has(112))
+	assert.False(t, fMap.DeleteField(112))
+
+	assert.True(t, fMap.DeleteField(447))
+	var b bytes.Buffer
+	fMap.write(&b)
+	assert.Equal(t, "58=text\x01453=2\x01448=A\x01452=1\x01448=B\x01", b.String())
+
+	assert.False(t, fMap.DeleteField(447))
+	assert.True(t, fMap.Has(58))
+}
+
+func TestFieldMap_Diff(t *testing.T) {
+	newParties := func(ids ...string) *RepeatingGroup {
+		g := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447)})
+		for _, id := range ids {
+			g.Add().SetString(Tag(448), id).SetString(Tag(447), "D")
+		}
+		return g
+	}
+
+	var before, after FieldMap
+	before.init()
+	after.init()
+	before.SetString(Tag(1), "account").SetString(Tag(11), "order").SetInt(Tag(38), 100).SetGroup(newParties("A", "B"))
+	after.SetString(Tag(11), "order").SetInt(Tag(38), 200).SetString(Tag(58), "text").SetGroup(newParties("A", "C"))
+
+	assert.Equal(t, []FieldDiff{
+		{Tag: 1, Old: []byte("account"), Op: DiffRemove},
+		{Tag: 38, Old: []byte("100"), New: []byte("200"), Op: DiffChange},
+		{Tag: 58, New: []byte("text"), Op: DiffAdd},
+		{
+			Tag: 453,
+			Old: []byte("453=2\x01448=A\x01447=D\x01448=B\x01447=D\x01"),
+			New: []byte("453=2\x01448=A\x01447=D\x01448=C\x01447=D\x01"),
+			Op:  DiffChange,
+		},
+	}, before.Diff(after))
+
+	assert.Empty(t, before.Diff(before))
+	after.SetGroup(newParties("A", "B"))
+	assert.Equal(t, DiffAdd, after.Diff(before)[0].Op)
+	assert.Equal(t, "Change", DiffChange.String())
+}
+
+func TestFieldMap_ForEach(t *testing.T) {
+	var fMap FieldMap
+	fMap.init()
+	fMap.SetField(38, FIXString("100"))
+	fMap.SetField(11, FIXString("ID"))
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448)})
+	parties.Add().SetString(Tag(448), "A")
+	fMap.SetGroup(parties)
+
+	var visited []string
+	fMap.ForEach(func(tag Tag, value []byte) bool {
+		visited = append(visited, fmt.Sprintf("%d=%s", tag, value))
+		return true
+	})
+	assert.Equal(t, []string{"11=ID", "38=100", "453=1", "448=A"}, visited)
+
+	visited = visited[:0]
+	fMap.ForEach(func(tag Tag, value []byte) bool {
+		visited = append(visited, fmt.Sprintf("%d=%s", tag, value))
+		return tag != 38
+	})
+	assert.Equal(t, []string{"11=ID", "38=100"}, visited)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		fMap.ForEach(func(Tag, []byte) bool { return true })
+	})
+	assert.Zero(t, allocs)
+}
+
+// newExecutionReportBody returns the body of an ExecutionReport with 30 fields.
+func newExecutionReportBody() *FieldMap {
+	var fMap FieldMap
+	fMap.init()
+	for _, tag := range []Tag{1, 6, 11, 14, 15, 17, 20, 21, 22, 30, 31, 32, 37, 38, 39, 40, 41, 44, 48, 54, 55, 58, 59, 60, 63, 64, 75, 150, 151, 207} {
+		fMap.SetField(tag, FIXString("value"))
+	}
+	return &fMap
+}
+
+func BenchmarkFieldMap_ForEach(b *testing.B) {
+	fMap := newExecutionReportBody()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var length int
+		fMap.ForEach(func(_ Tag, value []byte) bool {
+			length += len(value)
+			return true
+		})
+	}
+}
+
+func BenchmarkFieldMap_Tags(b *testing.B) {
+	fMap := newExecutionReportBody()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var length int
+		for _, tag := range fMap.Tags() {
+			value, _ := fMap.GetBytes(tag)
+			length += len(value)
+		}
+	}
+}
+
+// newOrderSingleFields returns the tags and values of the body of a NewOrderSingle with 20 fields.
+func newOrderSingleFields() ([]Tag, [][]byte) {
+	tags := []Tag{1, 11, 15, 18, 21, 38, 40, 44, 47, 54, 55, 58, 59, 60, 100, 110, 111, 126, 167, 207}
+	values := make([][]byte, len(tags))
+	for i := range tags {
+		values[i] = []byte("value")
+	}
+	return tags, values
+}
+
+func BenchmarkFieldMap_SetString(b *testing.B) {
+	tags, values := newOrderSingleFields()
+	strValues := make([]string, len(values))
+	for i, value := range values {
+		strValues[i] = string(value)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var fMap FieldMap
+		fMap.init()
+		for j, tag := range tags {
+			fMap.SetString(tag, strValues[j])
+		}
+	}
+}
+
+func BenchmarkFieldMap_SetBytes(b *testing.B) {
+	tags, values := newOrderSingleFields()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var fMap FieldMap
+		fMap.init()
+		for j, tag := range tags {
+			fMap.SetBytes(tag, values[j])
+		}
+	}
+}
+
+// newHeaderFields returns a header of 10 fields.
+func newHeaderFields() *Header {
+	h := &Header{}
+	h.Init()
+	h.SetString(tagBeginString, BeginStringFIX44).SetString(tagMsgType, "D").SetString(tagSenderCompID, "SENDER").
+		SetString(tagTargetCompID, "TARGET").SetInt(tagMsgSeqNum, 1).SetString(tagSendingTime, "20240101-00:00:00.000").
+		SetString(tagSenderSubID, "DESK").SetString(tagTargetSubID, "VENUE").SetString(tagOnBehalfOfCompID, "CLIENT").
+		SetString(tagDeliverToCompID, "BROKER")
+	return h
+}
+
+func BenchmarkFieldMap_CopyTo(b *testing.B) {
+	header := newHeaderFields()
+
+	b.Run("CopyTo", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			msg := NewMessage()
+			header.CopyTo(&msg.Header.FieldMap)
+		}
+	})
+
+	b.Run("SerializeAndParse", func(b *testing.B) {
+		src := NewMessage()
+		header.CopyTo(&src.Header.FieldMap)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			msg := NewMessage()
+			if err := ParseMessage(msg, bytes.NewBuffer(src.build())); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
 }
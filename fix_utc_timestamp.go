@@ -84,3 +84,23 @@ func (f FIXUTCTimestamp) Write() []byte {
 	}
 	return []byte(f.UTC().Format(utcTimestampMillisFormat))
 }
+
+// FIXUTCTimestampNanos is a FIX UTC Timestamp value always written with nanosecond precision, as used by
+// fields of type UTCTimestampWithNanos (FIX 5.0 SP2 EP254). It reads timestamps of any precision.
+// Implements FieldValue.
+type FIXUTCTimestampNanos struct {
+	time.Time
+}
+
+func (f *FIXUTCTimestampNanos) Read(bytes []byte) error {
+	var ts FIXUTCTimestamp
+	if err := ts.Read(bytes); err != nil {
+		return err
+	}
+	f.Time = ts.Time
+	return nil
+}
+
+func (f FIXUTCTimestampNanos) Write() []byte {
+	return []byte(f.UTC().Format(utcTimestampNanosFormat))
+}
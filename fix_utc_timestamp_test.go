@@ -76,3 +76,36 @@ func TestFIXUTCTimestampRead(t *testing.T) {
 		}
 	}
 }
+
+func TestFIXUTCTimestampNanos(t *testing.T) {
+	var tests = []struct {
+		timeStr      string
+		expectedTime time.Time
+	}{
+		{"20160208-22:07:16", time.Date(2016, time.February, 8, 22, 7, 16, 0, time.UTC)},
+		{"20160208-22:07:16.310", time.Date(2016, time.February, 8, 22, 7, 16, 310000000, time.UTC)},
+		{"20160208-22:07:16.123455", time.Date(2016, time.February, 8, 22, 7, 16, 123455000, time.UTC)},
+		{"20160208-22:07:16.954123123", time.Date(2016, time.February, 8, 22, 7, 16, 954123123, time.UTC)},
+	}
+
+	for _, test := range tests {
+		var f quickfix.FIXUTCTimestampNanos
+		if err := f.Read([]byte(test.timeStr)); err != nil {
+			t.Errorf("Unexpected error: %v", err)
+		}
+
+		if !f.Time.Equal(test.expectedTime) {
+			t.Errorf("For Time expected %v got %v", test.expectedTime, f.Time)
+		}
+
+		expected := test.expectedTime.Format("20060102-15:04:05.000000000")
+		if b := f.Write(); string(b) != expected {
+			t.Errorf("got %s; want %s", b, expected)
+		}
+	}
+
+	var f quickfix.FIXUTCTimestampNanos
+	if err := f.Read([]byte("20160208-22:07")); err == nil {
+		t.Error("Expected error")
+	}
+}
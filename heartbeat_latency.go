@@ -0,0 +1,125 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// latencyWindow is the number of samples kept by a HeartbeatLatencyTracker.
+const latencyWindow = 100
+
+// HeartbeatLatencyTracker records the round-trip latency of the TestRequests sent by a session, measured from the
+// sending of a TestRequest to the receipt of the Heartbeat carrying its TestReqID. It keeps the last 100 samples.
+type HeartbeatLatencyTracker struct {
+	mu      sync.Mutex
+	pending map[string]time.Time
+	samples []time.Duration
+	next    int
+}
+
+func newHeartbeatLatencyTracker() *HeartbeatLatencyTracker {
+	return &HeartbeatLatencyTracker{
+		pending: make(map[string]time.Time),
+		samples: make([]time.Duration, 0, latencyWindow),
+	}
+}
+
+// testRequestSent records the time a TestRequest was sent.
+func (t *HeartbeatLatencyTracker) testRequestSent(testReqID string, sentAt time.Time) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Bound the TestRequests awaiting an answer, forgetting the oldest.
+	if _, ok := t.pending[testReqID]; !ok && len(t.pending) >= latencyWindow {
+		var oldestID string
+		var oldest time.Time
+		for id, at := range t.pending {
+			if oldest.IsZero() || at.Before(oldest) {
+				oldestID, oldest = id, at
+			}
+		}
+		delete(t.pending, oldestID)
+	}
+	t.pending[testReqID] = sentAt
+}
+
+// heartbeatReceived records a sample if the Heartbeat answers a pending TestRequest, and returns it.
+func (t *HeartbeatLatencyTracker) heartbeatReceived(testReqID string, receivedAt time.Time) (time.Duration, bool) {
+	if t == nil {
+		return 0, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sentAt, ok := t.pending[testReqID]
+	if !ok {
+		return 0, false
+	}
+	delete(t.pending, testReqID)
+
+	sample := receivedAt.Sub(sentAt)
+	if len(t.samples) < latencyWindow {
+		t.samples = append(t.samples, sample)
+	} else {
+		t.samples[t.next] = sample
+	}
+	t.next = (t.next + 1) % latencyWindow
+	return sample, true
+}
+
+// Samples returns the recorded latencies, oldest first.
+func (t *HeartbeatLatencyTracker) Samples() []time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := make([]time.Duration, 0, len(t.samples))
+	if len(t.samples) == latencyWindow {
+		samples = append(samples, t.samples[t.next:]...)
+		return append(samples, t.samples[:t.next]...)
+	}
+	return append(samples, t.samples...)
+}
+
+// P50 returns the median of the recorded latencies, or zero if there are none.
+func (t *HeartbeatLatencyTracker) P50() time.Duration { return t.percentile(50) }
+
+// P95 returns the 95th percentile of the recorded latencies, or zero if there are none.
+func (t *HeartbeatLatencyTracker) P95() time.Duration { return t.percentile(95) }
+
+// P99 returns the 99th percentile of the recorded latencies, or zero if there are none.
+func (t *HeartbeatLatencyTracker) P99() time.Duration { return t.percentile(99) }
+
+// percentile returns the nearest-rank percentile p of the recorded latencies.
+func (t *HeartbeatLatencyTracker) percentile(p float64) time.Duration {
+	samples := t.Samples()
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+
+	rank := int(math.Ceil(p / 100 * float64(len(samples))))
+	if rank < 1 {
+		rank = 1
+	}
+	return samples[rank-1]
+}
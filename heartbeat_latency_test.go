@@ -0,0 +1,84 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHeartbeatLatencyTrackerPercentiles(t *testing.T) {
+	tracker := newHeartbeatLatencyTracker()
+	assert.Zero(t, tracker.P50())
+	assert.Empty(t, tracker.Samples())
+
+	sent := time.Now()
+	for i := 1; i <= 100; i++ {
+		id := strconv.Itoa(i)
+		tracker.testRequestSent(id, sent)
+		tracker.heartbeatReceived(id, sent.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	assert.Equal(t, 50*time.Millisecond, tracker.P50())
+	assert.Equal(t, 95*time.Millisecond, tracker.P95())
+	assert.Equal(t, 99*time.Millisecond, tracker.P99())
+}
+
+func TestHeartbeatLatencyTrackerRollingWindow(t *testing.T) {
+	tracker := newHeartbeatLatencyTracker()
+
+	sent := time.Now()
+	for i := 1; i <= 150; i++ {
+		tracker.testRequestSent("TEST", sent)
+		tracker.heartbeatReceived("TEST", sent.Add(time.Duration(i)*time.Millisecond))
+	}
+
+	samples := tracker.Samples()
+	assert.Len(t, samples, 100)
+	assert.Equal(t, 51*time.Millisecond, samples[0])
+	assert.Equal(t, 150*time.Millisecond, samples[99])
+	assert.Equal(t, 100*time.Millisecond, tracker.P50())
+}
+
+func TestHeartbeatLatencyTrackerUnmatchedHeartbeat(t *testing.T) {
+	tracker := newHeartbeatLatencyTracker()
+	sent := time.Now()
+
+	tracker.heartbeatReceived("TEST", sent)
+	tracker.testRequestSent("TEST", sent)
+	tracker.heartbeatReceived("OTHER", sent.Add(time.Millisecond))
+	assert.Empty(t, tracker.Samples())
+
+	// A TestRequest is answered once.
+	tracker.heartbeatReceived("TEST", sent.Add(2*time.Millisecond))
+	tracker.heartbeatReceived("TEST", sent.Add(3*time.Millisecond))
+	assert.Equal(t, []time.Duration{2 * time.Millisecond}, tracker.Samples())
+}
+
+func TestHeartbeatLatencyTrackerPendingBound(t *testing.T) {
+	tracker := newHeartbeatLatencyTracker()
+	sent := time.Now()
+	for i := 0; i <= latencyWindow; i++ {
+		tracker.testRequestSent(strconv.Itoa(i), sent.Add(time.Duration(i)*time.Millisecond))
+	}
+	assert.Len(t, tracker.pending, latencyWindow)
+
+	tracker.heartbeatReceived("0", sent.Add(time.Second))
+	assert.Empty(t, tracker.Samples())
+}
@@ -17,6 +17,7 @@ package quickfix
 
 import (
 	"bytes"
+	"errors"
 	"time"
 
 	"github.com/quickfixgo/quickfix/internal"
@@ -233,7 +234,7 @@ func (state inSession) resendMessages(session *session, beginSeqNo, endSeqNo int
 	seqNum := beginSeqNo
 	nextSeqNum := seqNum
 	msg := NewMessage()
-	err := session.store.IterateMessages(beginSeqNo, endSeqNo, func(msgBytes []byte) error {
+	err := iterateMessages(session.store, beginSeqNo, endSeqNo, func(msgBytes []byte) error {
 		err := ParseMessageWithDataDictionary(msg, bytes.NewBuffer(msgBytes), session.transportDataDictionary, session.appDataDictionary)
 		if err != nil {
 			session.log.OnEventf("Resend Msg Parse Error: %v, %v", err.Error(), bytes.NewBuffer(msgBytes).String())
@@ -280,6 +281,22 @@ func (state inSession) resendMessages(session *session, beginSeqNo, endSeqNo int
 	return nil
 }
 
+// iterateMessages behaves like store.IterateMessages, but looks a single message up with GetMessage.
+func iterateMessages(store MessageStore, beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	if beginSeqNum != endSeqNum {
+		return store.IterateMessages(beginSeqNum, endSeqNum, cb)
+	}
+
+	msg, found, err := GetMessage(store, beginSeqNum)
+	if err != nil || !found {
+		return err
+	}
+	if err := cb(msg); err != nil && !errors.Is(err, ErrStopIteration) {
+		return err
+	}
+	return nil
+}
+
 func (state inSession) processReject(session *session, msg *Message, rej MessageRejectError) sessionState {
 	switch TypedError := rej.(type) {
 	case targetTooHigh:
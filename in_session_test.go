@@ -158,6 +158,65 @@ func (s *InSessionTestSuite) TestStop() {
 	s.Disconnected()
 }
 
+func (s *InSessionTestSuite) TestForceLogout() {
+	s.MockApp.On("ToAdmin")
+	rep := make(chan error, 1)
+	s.session.onAdmin(forceLogoutReq{reason: "rogue counterparty", err: rep})
+	s.Nil(<-rep)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.State(logoutState{})
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagText, "rogue counterparty", s.MockApp.lastToAdmin.Body)
+
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("OnLogout")
+	s.fixMsgIn(s.session, s.Logout())
+	s.MockApp.AssertExpectations(s.T())
+	s.State(latentState{})
+	s.Disconnected()
+	s.NotStopped()
+
+	rep = make(chan error, 1)
+	s.session.onAdmin(forceLogoutReq{reason: "again", err: rep})
+	s.NotNil(<-rep)
+}
+
+func (s *InSessionTestSuite) TestStopResetOnLogout() {
+	s.session.ResetOnLogout = true
+	s.session.ResetOnLogoutText = defaultResetOnLogoutText
+
+	s.MockApp.On("ToAdmin")
+	s.session.Stop(s.session)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.State(logoutState{})
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagText, "NextSeqNum = 1", s.MockApp.lastToAdmin.Body)
+
+	// Sequence numbers are reset once the counterparty acknowledges the Logout.
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("OnLogout")
+	s.fixMsgIn(s.session, s.Logout())
+	s.MockApp.AssertExpectations(s.T())
+	s.State(latentState{})
+	s.NextTargetMsgSeqNum(1)
+	s.NextSenderMsgSeqNum(1)
+}
+
+func (s *InSessionTestSuite) TestStopResetOnLogoutEmptyText() {
+	s.session.ResetOnLogout = true
+
+	s.MockApp.On("ToAdmin")
+	s.session.Stop(s.session)
+
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.False(s.MockApp.lastToAdmin.Body.Has(tagText))
+}
+
 func (s *InSessionTestSuite) TestFIXMsgInTargetTooHighEnableLastMsgSeqNumProcessed() {
 	s.session.EnableLastMsgSeqNumProcessed = true
 	s.MessageFactory.seqNum = 5
@@ -385,6 +444,24 @@ func (s *InSessionTestSuite) TestFIXMsgInTargetTooLow() {
 	s.State(logoutState{})
 }
 
+func (s *InSessionTestSuite) TestFIXMsgInDecryptFailure() {
+	s.session.encryptedTags = []Tag{tagText}
+	s.session.setEncryptionProvider(failingEncryptionProvider{})
+
+	s.MockApp.On("ToAdmin")
+	nos := s.NewOrderSingle()
+	nos.Body.SetString(tagText, "terces")
+	s.fixMsgIn(s.session, nos)
+
+	// The message is rejected and consumed rather than passed to the application or requested again.
+	s.MockApp.AssertExpectations(s.T())
+	s.MockApp.AssertNotCalled(s.T(), "FromApp")
+	s.MessageType(string(msgTypeReject), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagRefTagID, int(tagText), s.MockApp.lastToAdmin.Body)
+	s.State(inSession{})
+	s.NextTargetMsgSeqNum(2)
+}
+
 func (s *InSessionTestSuite) TestFIXMsgInTargetTooLowPossDup() {
 	s.IncrNextTargetMsgSeqNum()
 
@@ -408,3 +485,86 @@ func (s *InSessionTestSuite) TestFIXMsgInTargetTooLowPossDup() {
 	s.State(inSession{})
 	s.NextTargetMsgSeqNum(2)
 }
+
+func (s *InSessionTestSuite) TestReplayMessages() {
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.NeedHeartbeat)
+	s.LastToAdminMessageSent()
+
+	s.MockApp.On("ToApp").Return(nil)
+	s.Require().Nil(s.session.send(s.NewOrderSingle()))
+	s.LastToAppMessageSent()
+	s.NextSenderMsgSeqNum(3)
+	s.NextTargetMsgSeqNum(1)
+
+	rep := make(chan error, 1)
+	s.session.onAdmin(replayReq{beginSeqNum: 1, endSeqNum: 0, err: rep})
+	s.Nil(<-rep)
+
+	s.MockApp.AssertNumberOfCalls(s.T(), "ToAdmin", 2)
+	s.MockApp.AssertNumberOfCalls(s.T(), "ToApp", 2)
+
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeSequenceReset), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagMsgSeqNum, 1, s.MockApp.lastToAdmin.Header)
+	s.FieldEquals(tagNewSeqNo, 2, s.MockApp.lastToAdmin.Body)
+
+	s.LastToAppMessageSent()
+	s.MessageType("D", s.MockApp.lastToApp)
+	s.FieldEquals(tagMsgSeqNum, 2, s.MockApp.lastToApp.Header)
+	s.FieldEquals(tagPossDupFlag, true, s.MockApp.lastToApp.Header)
+
+	s.NextSenderMsgSeqNum(3)
+	s.NextTargetMsgSeqNum(1)
+	s.State(inSession{})
+}
+
+func (s *InSessionTestSuite) TestReplayMessagesInvalidRange() {
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.NeedHeartbeat)
+	s.LastToAdminMessageSent()
+
+	s.NotNil(s.session.replay(0, 1))
+	s.NotNil(s.session.replay(2, 0))
+	s.NoMessageSent()
+
+	s.session.State = latentState{}
+	s.EqualError(s.session.replay(1, 1), "Not logged on")
+}
+
+func (s *InSessionTestSuite) TestFIXMsgInUnsupportedMessageType() {
+	s.session.UnsupportedMsgRejectReason = 2
+	s.MockApp.On("FromApp").Return(UnsupportedMessageType())
+	s.MockApp.On("ToApp").Return(nil)
+	s.session.fixMsgIn(s.session, s.NewOrderSingle())
+
+	s.MockApp.AssertExpectations(s.T())
+	s.LastToAppMessageSent()
+	s.MessageType("j", s.MockApp.lastToApp)
+	s.FieldEquals(tagBusinessRejectReason, 2, s.MockApp.lastToApp.Body)
+	s.FieldEquals(tagText, "Unsupported Message Type", s.MockApp.lastToApp.Body)
+	s.NextTargetMsgSeqNum(2)
+}
+
+func (s *InSessionTestSuite) TestFIXMsgInCustomUnsupportedMessageType() {
+	s.session.UnsupportedMsgRejectReason = 2
+	s.MockApp.On("FromApp").Return(NewBusinessMessageRejectErrorWithRefID("No such message here", rejectReasonUnsupportedMessageType, "ORDER-1", nil)).Once()
+	s.MockApp.On("ToApp").Return(nil)
+	s.session.fixMsgIn(s.session, s.NewOrderSingle())
+
+	s.MockApp.AssertExpectations(s.T())
+	s.MessageType("j", s.MockApp.lastToApp)
+	s.FieldEquals(tagBusinessRejectReason, 2, s.MockApp.lastToApp.Body)
+	s.FieldEquals(tagText, "No such message here", s.MockApp.lastToApp.Body)
+	s.FieldEquals(tagBusinessRejectRefID, "ORDER-1", s.MockApp.lastToApp.Body)
+
+	// An ApplicationError keeps the reason the application chose.
+	s.MockApp.On("FromApp").Return(ApplicationError{Text: "Unsupported Message Type", Reason: rejectReasonUnsupportedMessageType}).Once()
+	nos := s.NewOrderSingle()
+	nos.Header.SetField(tagMsgSeqNum, FIXInt(2))
+	s.session.fixMsgIn(s.session, nos)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.FieldEquals(tagBusinessRejectReason, rejectReasonUnsupportedMessageType, s.MockApp.lastToApp.Body)
+	s.NextTargetMsgSeqNum(3)
+}
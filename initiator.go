@@ -19,7 +19,8 @@ import (
 	"bufio"
 	"context"
 	"crypto/tls"
-	"strings"
+	"errors"
+	"net"
 	"sync"
 	"time"
 
@@ -35,14 +36,36 @@ type Initiator struct {
 	logFactory      LogFactory
 	globalLog       Log
 	stopChan        chan interface{}
+	stopMu          sync.RWMutex
 	wg              sync.WaitGroup
 	sessions        map[SessionID]*session
+	reconnects      map[SessionID]chan struct{}
+	sessionDialer   SessionDialer
 	sessionFactory
 }
 
+// SessionDialer opens the network connections of initiator sessions, e.g. to carry several sessions over
+// a single multiplexed connection.
+type SessionDialer interface {
+	// DialSession connects the session with the given ID to address, a SocketConnectHost:SocketConnectPort pair.
+	DialSession(ctx context.Context, sessionID SessionID, address string) (net.Conn, error)
+}
+
+// sessionContextDialer adapts a SessionDialer to the dialer of a single session.
+type sessionContextDialer struct {
+	SessionDialer
+	sessionID SessionID
+}
+
+func (d sessionContextDialer) DialContext(ctx context.Context, _, address string) (net.Conn, error) {
+	return d.DialSession(ctx, d.sessionID, address)
+}
+
 // Start Initiator.
 func (i *Initiator) Start() (err error) {
+	i.stopMu.Lock()
 	i.stopChan = make(chan interface{})
+	i.stopMu.Unlock()
 
 	for sessionID, settings := range i.sessionSettings {
 		// TODO: move into session factory.
@@ -52,13 +75,20 @@ func (i *Initiator) Start() (err error) {
 		}
 
 		var dialer proxy.ContextDialer
-		if dialer, err = loadDialerConfig(settings); err != nil {
+		if i.sessionDialer != nil {
+			dialer = sessionContextDialer{SessionDialer: i.sessionDialer, sessionID: sessionID}
+		} else if dialer, err = loadDialerConfig(settings); err != nil {
+			return
+		}
+
+		var wsConfig *webSocketConfig
+		if wsConfig, err = loadWebSocketConfig(settings); err != nil {
 			return
 		}
 
 		i.wg.Add(1)
 		go func(sessID SessionID) {
-			i.handleConnection(i.sessions[sessID], tlsConfig, dialer)
+			i.handleConnection(i.sessions[sessID], tlsConfig, dialer, wsConfig)
 			i.wg.Done()
 		}(sessionID)
 	}
@@ -85,8 +115,57 @@ func (i *Initiator) Stop() {
 	}
 }
 
+// StopWithDrain behaves like Stop, but first waits up to timeout for the messages queued by the sessions to be
+// written, so that they are not lost when logging out. If timeout expires, it stops anyway and returns a
+// DrainTimeoutError reporting the number of messages dropped.
+func (i *Initiator) StopWithDrain(timeout time.Duration) error {
+	err := drainSessions(i.sessions, timeout)
+	i.Stop()
+	return err
+}
+
+// SetSessionDialer replaces the dialers configured by the session settings, including proxies and
+// ConnectTimeout, with dialer. TLS and the websocket transport are still applied to the connections it opens.
+// It must be called before Start.
+func (i *Initiator) SetSessionDialer(dialer SessionDialer) {
+	i.sessionDialer = dialer
+}
+
+// Reconnect forces the session to reconnect without waiting for ReconnectInterval. The current connection, if
+// any, is closed, and once the session has disconnected it dials the counterparty again. Reconnect returns as
+// soon as the reconnect is requested.
+func (i *Initiator) Reconnect(sessionID SessionID) error {
+	reconnect, ok := i.reconnects[sessionID]
+	if !ok {
+		return errUnknownSession
+	}
+	// Reconnect may be called from another goroutine while Start is running.
+	i.stopMu.RLock()
+	stopChan := i.stopChan
+	i.stopMu.RUnlock()
+	if stopChan == nil {
+		return errors.New("Initiator not started")
+	}
+	select {
+	case <-stopChan:
+		return errors.New("Initiator stopped")
+	default:
+	}
+
+	select {
+	case reconnect <- struct{}{}:
+	default:
+		// A reconnect is already pending.
+	}
+	return nil
+}
+
 // NewInitiator creates and initializes a new Initiator.
 func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings *Settings, logFactory LogFactory) (*Initiator, error) {
+	if err := appSettings.Validate(); err != nil {
+		return nil, err
+	}
+
 	i := &Initiator{
 		app:             app,
 		storeFactory:    storeFactory,
@@ -94,6 +173,7 @@ func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings
 		sessionSettings: appSettings.SessionSettings(),
 		logFactory:      logFactory,
 		sessions:        make(map[SessionID]*session),
+		reconnects:      make(map[SessionID]chan struct{}),
 		sessionFactory:  sessionFactory{true},
 	}
 
@@ -110,6 +190,7 @@ func NewInitiator(app Application, storeFactory MessageStoreFactory, appSettings
 		}
 
 		i.sessions[sessionID] = session
+		i.reconnects[sessionID] = make(chan struct{}, 1)
 	}
 
 	return i, nil
@@ -133,9 +214,11 @@ func (i *Initiator) waitForInSessionTime(session *session) bool {
 }
 
 // waitForReconnectInterval returns true if a reconnect should be re-attempted, false if handler should stop.
-func (i *Initiator) waitForReconnectInterval(reconnectInterval time.Duration) bool {
+// The wait is cut short by a call to Reconnect.
+func (i *Initiator) waitForReconnectInterval(reconnectInterval time.Duration, reconnect <-chan struct{}) bool {
 	select {
 	case <-time.After(reconnectInterval):
+	case <-reconnect:
 	case <-i.stopChan:
 		return false
 	}
@@ -143,7 +226,7 @@ func (i *Initiator) waitForReconnectInterval(reconnectInterval time.Duration) bo
 	return true
 }
 
-func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, dialer proxy.ContextDialer) {
+func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, dialer proxy.ContextDialer, wsConfig *webSocketConfig) {
 	var wg sync.WaitGroup
 	wg.Add(1)
 	go func() {
@@ -157,6 +240,7 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 	}()
 
 	connectionAttempt := 0
+	reconnectRequest := i.reconnects[session.sessionID]
 
 	for {
 		if !i.waitForInSessionTime(session) {
@@ -187,17 +271,11 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 		if err != nil {
 			session.log.OnEventf("Failed to connect: %v", err)
 			goto reconnect
-		} else if tlsConfig != nil {
-			// Unless InsecureSkipVerify is true, server name config is required for TLS
-			// to verify the received certificate
-			if !tlsConfig.InsecureSkipVerify && len(tlsConfig.ServerName) == 0 {
-				serverName := address
-				if c := strings.LastIndex(serverName, ":"); c > 0 {
-					serverName = serverName[:c]
-				}
-				tlsConfig.ServerName = serverName
-			}
-			tlsConn := tls.Client(netConn, tlsConfig)
+		}
+		session.setSocketBuffers(netConn)
+		session.setKeepAlive(netConn)
+		if tlsConfig != nil {
+			tlsConn := tls.Client(netConn, clientTLSConfig(tlsConfig, address))
 			if err = tlsConn.Handshake(); err != nil {
 				session.log.OnEventf("Failed handshake: %v", err)
 				goto reconnect
@@ -205,6 +283,16 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 			netConn = tlsConn
 		}
 
+		if wsConfig != nil {
+			wsConn, err := wsConfig.upgrade(ctx, netConn, address, tlsConfig != nil)
+			if err != nil {
+				session.log.OnEventf("Failed WebSocket handshake: %v", err)
+				_ = netConn.Close()
+				goto reconnect
+			}
+			netConn = wsConn
+		}
+
 		msgIn = make(chan fixIn)
 		msgOut = make(chan []byte)
 		if err := session.connect(msgIn, msgOut); err != nil {
@@ -228,6 +316,17 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 
 		select {
 		case <-disconnected:
+		case <-reconnectRequest:
+			session.log.OnEvent("Reconnect requested")
+			if err := netConn.Close(); err != nil {
+				session.log.OnEvent(err.Error())
+			}
+			select {
+			case <-disconnected:
+			case <-i.stopChan:
+				return
+			}
+			continue
 		case <-i.stopChan:
 			return
 		}
@@ -237,7 +336,7 @@ func (i *Initiator) handleConnection(session *session, tlsConfig *tls.Config, di
 
 		connectionAttempt++
 		session.log.OnEventf("Reconnecting in %v", session.ReconnectInterval)
-		if !i.waitForReconnectInterval(session.ReconnectInterval) {
+		if !i.waitForReconnectInterval(session.ReconnectInterval, reconnectRequest) {
 			return
 		}
 	}
@@ -0,0 +1,117 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInitiator_Reconnect(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+
+	acceptorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=ACC
+TargetCompID=INIT
+`))
+	require.Nil(t, err)
+	acceptorApp := logonApp{logons: make(chan SessionID, 2)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	acceptor.AddListener(listener)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	initiatorSettings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=INIT
+TargetCompID=ACC
+HeartBtInt=30
+ReconnectInterval=300
+SocketConnectHost=127.0.0.1
+SocketConnectPort=%d
+`, listener.Addr().(*net.TCPAddr).Port)))
+	require.Nil(t, err)
+	initiator, err := NewInitiator(logonApp{logons: make(chan SessionID, 2)}, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "INIT", TargetCompID: "ACC"}
+	assert.NotNil(t, initiator.Reconnect(sessionID), "not started")
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	waitForLogon := func() {
+		select {
+		case <-acceptorApp.logons:
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for logon")
+		}
+	}
+	waitForLogon()
+
+	// The session logs on again well before ReconnectInterval.
+	assert.Equal(t, errUnknownSession, initiator.Reconnect(SessionID{BeginString: "FIX.4.4", SenderCompID: "INIT", TargetCompID: "OTHER"}))
+	require.Nil(t, initiator.Reconnect(sessionID))
+	waitForLogon()
+}
+
+func TestInitiator_ReconnectDuringStart(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+	require.Nil(t, listener.Close())
+
+	initiatorSettings, err := ParseSettings(strings.NewReader(fmt.Sprintf(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=RACE
+TargetCompID=ACC
+HeartBtInt=30
+ReconnectInterval=300
+SocketConnectHost=127.0.0.1
+SocketConnectPort=%d
+`, port)))
+	require.Nil(t, err)
+	initiator, err := NewInitiator(logonApp{logons: make(chan SessionID, 2)}, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "RACE", TargetCompID: "ACC"}
+
+	// Run with -race: Reconnect must not race with Start setting up the initiator.
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for j := 0; j < 100; j++ {
+			_ = initiator.Reconnect(sessionID)
+		}
+	}()
+	require.Nil(t, initiator.Start())
+	<-done
+
+	// Stop only once the session loop is running.
+	require.Eventually(t, func() bool { return initiator.sessions[sessionID].loopDone.Load() != nil }, 10*time.Second, time.Millisecond)
+	assert.Nil(t, initiator.Reconnect(sessionID))
+	initiator.Stop()
+	assert.NotNil(t, initiator.Reconnect(sessionID), "stopped")
+}
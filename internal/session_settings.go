@@ -1,25 +1,43 @@
 package internal
 
-import "time"
+import (
+	"net"
+	"time"
+)
 
 // SessionSettings stores all of the configuration for a given session.
 type SessionSettings struct {
 	ResetOnLogon                 bool
 	RefreshOnLogon               bool
 	ResetOnLogout                bool
+	ResetOnLogoutText            string
 	ResetOnDisconnect            bool
 	HeartBtInt                   time.Duration
 	HeartBtIntOverride           bool
 	SessionTime                  *TimeRange
 	InitiateLogon                bool
 	ResendRequestChunkSize       int
+	MaxPendingOutboundMessages   int
+	MaxOutboundMsgRatePerSecond  int
+	MaxOutboundBurstMessages     int
+	UnsupportedMsgRejectReason   int
+	CPUAffinity                  int
+	EnableCPUAffinity            bool
+	TCPSendBufferSize            int
+	TCPReceiveBufferSize         int
+	TCPKeepAlive                 net.KeepAliveConfig
+	ConfigureTCPKeepAlive        bool
 	EnableLastMsgSeqNumProcessed bool
 	EnableNextExpectedMsgSeqNum  bool
 	SkipCheckLatency             bool
 	MaxLatency                   time.Duration
+	ApplicationCallbackTimeout   time.Duration
 	DisableMessagePersist        bool
 	ResetSeqTime                 TimeOfDay
 	EnableResetSeqTime           bool
+	PersistSessionState          bool
+	StoreHealthCheckInterval     time.Duration
+	StoreHealthCheckMaxFailures  int
 
 	// Required on logon for FIX.T.1 messages.
 	DefaultApplVerID string
@@ -223,6 +223,68 @@ func (s *StoreTestSuite) TestMessageStoreGetMessagesVariousRanges() {
 	}
 }
 
+func (s *StoreTestSuite) TestMessageStoreIterateMessagesStopIteration() {
+	// Given the following saved messages
+	s.Require().Nil(s.MsgStore.SaveMessage(1, []byte("hello")))
+	s.Require().Nil(s.MsgStore.SaveMessage(2, []byte("cruel")))
+	s.Require().Nil(s.MsgStore.SaveMessage(3, []byte("world")))
+
+	// When the callback stops the iteration after the second message
+	var msgs []string
+	err := s.MsgStore.IterateMessages(1, 3, func(msg []byte) error {
+		msgs = append(msgs, string(msg))
+		if len(msgs) == 2 {
+			return quickfix.ErrStopIteration
+		}
+		return nil
+	})
+
+	// Then no error should be returned and no further messages should be iterated
+	s.Require().Nil(err)
+	s.Equal([]string{"hello", "cruel"}, msgs)
+}
+
+func (s *StoreTestSuite) TestMessageStoreSaveMessages() {
+	// When a batch of messages is saved
+	s.Require().Nil(quickfix.SaveMessages(s.MsgStore, map[int][]byte{
+		3: []byte("world"),
+		1: []byte("hello"),
+		2: []byte("cruel"),
+	}))
+
+	// Then the messages should be retrieved in seqnum order
+	s.Equal([][]byte{[]byte("hello"), []byte("cruel"), []byte("world")}, s.fetchMessages(1, 3))
+
+	// And the seqnums should be untouched
+	s.Equal(1, s.MsgStore.NextSenderMsgSeqNum())
+}
+
+func (s *StoreTestSuite) TestMessageStoreGetMessage() {
+	// Given the following saved messages
+	s.Require().Nil(s.MsgStore.SaveMessage(1, []byte("hello")))
+	s.Require().Nil(s.MsgStore.SaveMessage(3, []byte("world")))
+
+	// When a saved message is looked up
+	msg, found, err := quickfix.GetMessage(s.MsgStore, 3)
+
+	// Then it should be returned
+	s.Require().Nil(err)
+	s.True(found)
+	s.Equal([]byte("world"), msg)
+
+	// And missing messages should not be found
+	for _, seqNum := range []int{0, 2, 4} {
+		msg, found, err = quickfix.GetMessage(s.MsgStore, seqNum)
+		s.Require().Nil(err)
+		s.False(found, seqNum)
+		s.Nil(msg)
+	}
+}
+
+func (s *StoreTestSuite) TestMessageStoreHealthCheck() {
+	s.Nil(s.MsgStore.HealthCheck())
+}
+
 func (s *StoreTestSuite) TestMessageStoreCreationTime() {
 	s.False(s.MsgStore.CreationTime().IsZero())
 
@@ -78,9 +78,43 @@ func NewWeekRangeInLocation(startTime, endTime TimeOfDay, startDay, endDay time.
 	return r, nil
 }
 
+// on returns the first instant at which the clocks of loc show tod on the day of t. Boundaries are compared as
+// instants rather than as times of day, so that the hour repeated when clocks are set back for daylight saving
+// time does not reopen a range that ended during its first pass. A tod skipped when clocks are set forward
+// falls after the transition, as with time.Date.
+func on(t time.Time, tod TimeOfDay, loc *time.Location) time.Time {
+	year, month, day := t.Date()
+	at := time.Date(year, month, day, tod.hour, tod.minute, tod.second, 0, loc)
+
+	// time.Date may resolve a repeated wall time to its second occurrence.
+	zoneStart, _ := at.ZoneBounds()
+	if zoneStart.IsZero() {
+		return at
+	}
+	_, offset := at.Zone()
+	_, prevOffset := zoneStart.Add(-time.Nanosecond).Zone()
+	if prevOffset <= offset {
+		return at
+	}
+	earlier := at.Add(-time.Duration(prevOffset-offset) * time.Second)
+	if earlier.Before(zoneStart) {
+		return earlier
+	}
+	return at
+}
+
+// isAtOrAfter returns true if t is at or after tod on its day.
+func (r *TimeRange) isAtOrAfter(t time.Time, tod TimeOfDay) bool {
+	return !t.Before(on(t, tod, r.loc))
+}
+
+// isAtOrBefore returns true if t is at or before tod on its day, to the second.
+func (r *TimeRange) isAtOrBefore(t time.Time, tod TimeOfDay) bool {
+	return t.Before(on(t, tod, r.loc).Add(time.Second))
+}
+
 func (r *TimeRange) isInTimeRange(t time.Time) bool {
 	t = t.In(r.loc)
-	ts := NewTimeOfDay(t.Clock()).d
 
 	if len(r.weekdays) > 0 {
 		found := false
@@ -98,10 +132,10 @@ func (r *TimeRange) isInTimeRange(t time.Time) bool {
 	}
 
 	if r.startTime.d < r.endTime.d {
-		return r.startTime.d <= ts && ts <= r.endTime.d
+		return r.isAtOrAfter(t, r.startTime) && r.isAtOrBefore(t, r.endTime)
 	}
 
-	return !(r.endTime.d < ts && ts < r.startTime.d)
+	return r.isAtOrAfter(t, r.startTime) || r.isAtOrBefore(t, r.endTime)
 }
 
 func (r *TimeRange) isInWeekRange(t time.Time) bool {
@@ -132,14 +166,12 @@ func (r *TimeRange) isInWeekRange(t time.Time) bool {
 		}
 	}
 
-	timeOfDay := NewTimeOfDay(t.Clock())
-
 	if day == *r.startDay {
-		return timeOfDay.d >= r.startTime.d
+		return r.isAtOrAfter(t, r.startTime)
 	}
 
 	if day == *r.endDay {
-		return timeOfDay.d <= r.endTime.d
+		return r.isAtOrBefore(t, r.endTime)
 	}
 
 	return true
@@ -195,8 +227,7 @@ func (r *TimeRange) IsInSameRange(t1, t2 time.Time) bool {
 		}
 	}
 
-	sessionEnd := time.Date(t1.Year(), t1.Month(), t1.Day(), r.endTime.hour, r.endTime.minute, r.endTime.second, 0, r.loc)
-	sessionEnd = sessionEnd.AddDate(0, 0, dayOffset)
+	sessionEnd := on(time.Date(t1.Year(), t1.Month(), t1.Day()+dayOffset, 12, 0, 0, 0, r.loc), r.endTime, r.loc)
 
 	return t2.Before(sessionEnd)
 }
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewTimeOfDay(t *testing.T) {
@@ -1017,3 +1018,95 @@ func TestTimeRangeIsInSameRangeWhenNil(t *testing.T) {
 	var tr *TimeRange
 	assert.True(t, tr.IsInSameRange(time1, time2), "always in same range if time range is nil")
 }
+
+func TestTimeRangeAcrossDST(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.Nil(t, err)
+
+	// Clocks go forward to BST at 01:00 UTC on March 31 2024, and back to GMT at 01:00 UTC on October 27 2024.
+	daily, err := NewTimeRangeInLocation(NewTimeOfDay(8, 0, 0), NewTimeOfDay(17, 0, 0), nil, london)
+	require.Nil(t, err)
+	overnight, err := NewTimeRangeInLocation(NewTimeOfDay(17, 0, 0), NewTimeOfDay(1, 30, 0), nil, london)
+	require.Nil(t, err)
+	weekly, err := NewWeekRangeInLocation(NewTimeOfDay(8, 0, 0), NewTimeOfDay(1, 30, 0), time.Monday, time.Sunday, london)
+	require.Nil(t, err)
+
+	for _, example := range []struct {
+		label    string
+		r        *TimeRange
+		time     time.Time
+		expected bool
+	}{
+		{"daily before start in GMT", daily, time.Date(2024, time.March, 30, 7, 59, 59, 0, time.UTC), false},
+		{"daily at start in GMT", daily, time.Date(2024, time.March, 30, 8, 0, 0, 0, time.UTC), true},
+		{"daily before start in BST", daily, time.Date(2024, time.April, 1, 6, 59, 59, 0, time.UTC), false},
+		{"daily at start in BST", daily, time.Date(2024, time.April, 1, 7, 0, 0, 0, time.UTC), true},
+		{"daily at end in BST", daily, time.Date(2024, time.April, 1, 16, 0, 0, 0, time.UTC), true},
+		{"daily after end in BST", daily, time.Date(2024, time.April, 1, 16, 0, 1, 0, time.UTC), false},
+		{"overnight before end in BST", overnight, time.Date(2024, time.October, 27, 0, 0, 0, 0, time.UTC), true},
+		{"overnight at end in BST", overnight, time.Date(2024, time.October, 27, 0, 30, 0, 0, time.UTC), true},
+		{"overnight after end in BST", overnight, time.Date(2024, time.October, 27, 0, 30, 1, 0, time.UTC), false},
+		{"overnight repeated hour in GMT", overnight, time.Date(2024, time.October, 27, 1, 0, 0, 0, time.UTC), false},
+		{"overnight repeated end in GMT", overnight, time.Date(2024, time.October, 27, 1, 30, 0, 0, time.UTC), false},
+		{"weekly before end in BST", weekly, time.Date(2024, time.October, 27, 0, 15, 0, 0, time.UTC), true},
+		{"weekly repeated hour in GMT", weekly, time.Date(2024, time.October, 27, 1, 15, 0, 0, time.UTC), false},
+	} {
+		assert.Equal(t, example.expected, example.r.IsInRange(example.time), example.label)
+	}
+
+	evening := time.Date(2024, time.October, 26, 19, 0, 0, 0, time.UTC)
+	assert.True(t, overnight.IsInSameRange(evening, time.Date(2024, time.October, 27, 0, 15, 0, 0, time.UTC)))
+	assert.False(t, overnight.IsInSameRange(evening, time.Date(2024, time.October, 27, 1, 15, 0, 0, time.UTC)))
+	assert.False(t, overnight.IsInSameRange(evening, time.Date(2024, time.October, 27, 16, 0, 0, 0, time.UTC)))
+}
+
+func TestTimeRangeStraddlingMidnight(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	require.Nil(t, err)
+	newYork, err := time.LoadLocation("America/New_York")
+	require.Nil(t, err)
+
+	// An EndTime before the StartTime ends the session on the next day.
+	londonSummer, err := NewTimeRangeInLocation(NewTimeOfDay(23, 0, 0), NewTimeOfDay(21, 0, 0), nil, london)
+	require.Nil(t, err)
+	newYorkWinter, err := NewTimeRangeInLocation(NewTimeOfDay(23, 0, 0), NewTimeOfDay(21, 0, 0), nil, newYork)
+	require.Nil(t, err)
+	midnightStart, err := NewUTCTimeRange(NewTimeOfDay(0, 0, 0), NewTimeOfDay(21, 0, 0), nil)
+	require.Nil(t, err)
+	midnightEnd, err := NewUTCTimeRange(NewTimeOfDay(23, 0, 0), NewTimeOfDay(0, 0, 0), nil)
+	require.Nil(t, err)
+
+	for _, example := range []struct {
+		label    string
+		r        *TimeRange
+		time     time.Time
+		expected bool
+	}{
+		{"London 22:59 BST", londonSummer, time.Date(2024, time.July, 1, 21, 59, 59, 0, time.UTC), false},
+		{"London 23:00 BST", londonSummer, time.Date(2024, time.July, 1, 22, 0, 0, 0, time.UTC), true},
+		{"London 00:00 BST next day", londonSummer, time.Date(2024, time.July, 1, 23, 0, 0, 0, time.UTC), true},
+		{"London 21:00 BST next day", londonSummer, time.Date(2024, time.July, 2, 20, 0, 0, 0, time.UTC), true},
+		{"London 21:00:01 BST next day", londonSummer, time.Date(2024, time.July, 2, 20, 0, 1, 0, time.UTC), false},
+		{"New York 22:59 EST", newYorkWinter, time.Date(2024, time.January, 16, 3, 59, 59, 0, time.UTC), false},
+		{"New York 23:00 EST", newYorkWinter, time.Date(2024, time.January, 16, 4, 0, 0, 0, time.UTC), true},
+		{"New York 00:00 EST next day", newYorkWinter, time.Date(2024, time.January, 16, 5, 0, 0, 0, time.UTC), true},
+		{"New York 21:00 EST next day", newYorkWinter, time.Date(2024, time.January, 17, 2, 0, 0, 0, time.UTC), true},
+		{"New York 21:00:01 EST next day", newYorkWinter, time.Date(2024, time.January, 17, 2, 0, 1, 0, time.UTC), false},
+		{"midnight start at midnight", midnightStart, time.Date(2024, time.July, 1, 0, 0, 0, 0, time.UTC), true},
+		{"midnight start before midnight", midnightStart, time.Date(2024, time.July, 1, 23, 59, 59, 0, time.UTC), false},
+		{"midnight end at midnight", midnightEnd, time.Date(2024, time.July, 2, 0, 0, 0, 0, time.UTC), true},
+		{"midnight end after midnight", midnightEnd, time.Date(2024, time.July, 2, 0, 0, 1, 0, time.UTC), false},
+	} {
+		assert.Equal(t, example.expected, example.r.IsInRange(example.time), example.label)
+	}
+
+	start := time.Date(2024, time.July, 1, 22, 30, 0, 0, time.UTC)
+	assert.True(t, londonSummer.IsInSameRange(start, time.Date(2024, time.July, 2, 19, 0, 0, 0, time.UTC)))
+	assert.False(t, londonSummer.IsInSameRange(start, time.Date(2024, time.July, 2, 22, 30, 0, 0, time.UTC)))
+	start = time.Date(2024, time.January, 16, 4, 30, 0, 0, time.UTC)
+	assert.True(t, newYorkWinter.IsInSameRange(start, time.Date(2024, time.January, 17, 1, 0, 0, 0, time.UTC)))
+	assert.False(t, newYorkWinter.IsInSameRange(start, time.Date(2024, time.January, 17, 4, 30, 0, 0, time.UTC)))
+	start = time.Date(2024, time.July, 1, 23, 30, 0, 0, time.UTC)
+	assert.True(t, midnightEnd.IsInSameRange(start, time.Date(2024, time.July, 1, 23, 59, 59, 0, time.UTC)))
+	assert.False(t, midnightEnd.IsInSameRange(start, time.Date(2024, time.July, 2, 23, 30, 0, 0, time.UTC)))
+}
@@ -0,0 +1,135 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package slogadapter provides a quickfix.LogFactory that emits messages and events as log/slog records.
+package slogadapter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// Attribute keys of the records emitted by SlogLogger.
+const (
+	AttrSessionID = "fix.session_id"
+	AttrDirection = "fix.direction"
+	AttrMsgType   = "fix.msg_type"
+	AttrSeqNum    = "fix.seq_num"
+	AttrMessage   = "fix.message"
+)
+
+// Values of the AttrDirection attribute.
+const (
+	DirectionIncoming = "incoming"
+	DirectionOutgoing = "outgoing"
+)
+
+// SlogLogger is a quickfix.Log that emits each message and event as an slog record at the info level. Message records
+// carry the session ID, direction, MsgType and MsgSeqNum of the message as attributes, as well as the message itself.
+// Event records carry the session ID.
+type SlogLogger struct {
+	logger    *slog.Logger
+	sessionID string
+}
+
+// NewSlogLogger returns a SlogLogger for the session with the given ID that emits records to logger.
+func NewSlogLogger(logger *slog.Logger, sessionID quickfix.SessionID) *SlogLogger {
+	return &SlogLogger{logger: logger, sessionID: sessionID.String()}
+}
+
+// OnIncoming emits a record for an incoming message.
+func (l *SlogLogger) OnIncoming(msg []byte) {
+	l.logMessage(DirectionIncoming, msg)
+}
+
+// OnOutgoing emits a record for an outgoing message.
+func (l *SlogLogger) OnOutgoing(msg []byte) {
+	l.logMessage(DirectionOutgoing, msg)
+}
+
+// OnEvent emits a record for an event.
+func (l *SlogLogger) OnEvent(s string) {
+	attrs := make([]slog.Attr, 0, 1)
+	if l.sessionID != "" {
+		attrs = append(attrs, slog.String(AttrSessionID, l.sessionID))
+	}
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, s, attrs...)
+}
+
+// OnEventf emits a record for an event according to a format specifier.
+func (l *SlogLogger) OnEventf(format string, a ...interface{}) {
+	if !l.logger.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func (l *SlogLogger) logMessage(direction string, msg []byte) {
+	if !l.logger.Enabled(context.Background(), slog.LevelInfo) {
+		return
+	}
+	attrs := make([]slog.Attr, 0, 5)
+	if l.sessionID != "" {
+		attrs = append(attrs, slog.String(AttrSessionID, l.sessionID))
+	}
+	attrs = append(attrs, slog.String(AttrDirection, direction))
+	if msgType, ok := fieldValue(msg, "35"); ok {
+		attrs = append(attrs, slog.String(AttrMsgType, string(msgType)))
+	}
+	if seqNum, ok := fieldValue(msg, "34"); ok {
+		if n, err := strconv.Atoi(string(seqNum)); err == nil {
+			attrs = append(attrs, slog.Int(AttrSeqNum, n))
+		}
+	}
+	attrs = append(attrs, slog.String(AttrMessage, string(msg)))
+	l.logger.LogAttrs(context.Background(), slog.LevelInfo, direction+" message", attrs...)
+}
+
+// fieldValue returns the value of the first field with the given tag in a raw FIX message, without parsing it.
+func fieldValue(msg []byte, tag string) ([]byte, bool) {
+	prefix := []byte("\x01" + tag + "=")
+	start := bytes.Index(msg, prefix)
+	if start < 0 {
+		return nil, false
+	}
+	value := msg[start+len(prefix):]
+	if end := bytes.IndexByte(value, '\x01'); end >= 0 {
+		value = value[:end]
+	}
+	return value, true
+}
+
+type slogLogFactory struct {
+	logger *slog.Logger
+}
+
+func (f slogLogFactory) Create() (quickfix.Log, error) {
+	return &SlogLogger{logger: f.logger}, nil
+}
+
+func (f slogLogFactory) CreateSessionLog(sessionID quickfix.SessionID) (quickfix.Log, error) {
+	return NewSlogLogger(f.logger, sessionID), nil
+}
+
+// NewLogFactory creates an instance of LogFactory that emits messages and events to logger. The global log omits the
+// session ID attribute.
+func NewLogFactory(logger *slog.Logger) quickfix.LogFactory {
+	return slogLogFactory{logger: logger}
+}
@@ -0,0 +1,112 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package slogadapter
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+func newTestLogFactory(level slog.Level) (quickfix.LogFactory, *bytes.Buffer) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: level}))
+	return NewLogFactory(logger), &buf
+}
+
+func decodeRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	var records []map[string]interface{}
+	dec := json.NewDecoder(buf)
+	for dec.More() {
+		record := make(map[string]interface{})
+		require.Nil(t, dec.Decode(&record))
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestSlogLoggerMessages(t *testing.T) {
+	factory, buf := newTestLogFactory(slog.LevelInfo)
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	log, err := factory.CreateSessionLog(sessionID)
+	require.Nil(t, err)
+
+	msg := "8=FIX.4.4\x019=49\x0135=D\x0134=7\x0149=SENDER\x0152=20240102-03:04:05\x0156=TARGET\x0110=123\x01"
+	log.OnIncoming([]byte(msg))
+	log.OnOutgoing([]byte(msg))
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 2)
+	for i, direction := range []string{DirectionIncoming, DirectionOutgoing} {
+		assert.Equal(t, "INFO", records[i]["level"])
+		assert.Equal(t, sessionID.String(), records[i][AttrSessionID])
+		assert.Equal(t, direction, records[i][AttrDirection])
+		assert.Equal(t, "D", records[i][AttrMsgType])
+		assert.Equal(t, float64(7), records[i][AttrSeqNum])
+		assert.Equal(t, msg, records[i][AttrMessage])
+	}
+}
+
+func TestSlogLoggerEvents(t *testing.T) {
+	factory, buf := newTestLogFactory(slog.LevelInfo)
+
+	global, err := factory.Create()
+	require.Nil(t, err)
+	global.OnEvent("Starting")
+
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	log, err := factory.CreateSessionLog(sessionID)
+	require.Nil(t, err)
+	log.OnEventf("Sent %d messages", 3)
+
+	records := decodeRecords(t, buf)
+	require.Len(t, records, 2)
+	assert.Equal(t, "Starting", records[0]["msg"])
+	assert.NotContains(t, records[0], AttrSessionID)
+	assert.Equal(t, "Sent 3 messages", records[1]["msg"])
+	assert.Equal(t, sessionID.String(), records[1][AttrSessionID])
+}
+
+func TestSlogLoggerDisabledLevel(t *testing.T) {
+	factory, buf := newTestLogFactory(slog.LevelWarn)
+	log, err := factory.Create()
+	require.Nil(t, err)
+
+	log.OnIncoming([]byte("8=FIX.4.4\x019=5\x0135=0\x0110=000\x01"))
+	log.OnEventf("event %d", 1)
+	assert.Zero(t, buf.Len())
+}
+
+func TestFieldValue(t *testing.T) {
+	msg := []byte("8=FIX.4.4\x019=5\x0135=0\x01134=9\x0134=12")
+
+	value, ok := fieldValue(msg, "35")
+	assert.True(t, ok)
+	assert.Equal(t, "0", string(value))
+
+	value, ok = fieldValue(msg, "34")
+	assert.True(t, ok)
+	assert.Equal(t, "12", string(value))
+
+	_, ok = fieldValue(msg, "49")
+	assert.False(t, ok)
+}
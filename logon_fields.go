@@ -0,0 +1,67 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// logonField is a field added to outgoing Logon messages by the LogonFields setting.
+type logonField struct {
+	tag   Tag
+	value []byte
+}
+
+// parseLogonFields parses a LogonFields value of semicolon separated tag=value pairs.
+func parseLogonFields(raw string) ([]logonField, error) {
+	var fields []logonField
+	for _, pair := range strings.Split(raw, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		tagStr, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, IncorrectFormatForSetting{Setting: config.LogonFields, Value: []byte(pair), Err: errors.New("expected tag=value")}
+		}
+		tag, err := strconv.Atoi(strings.TrimSpace(tagStr))
+		if err != nil || tag <= 0 {
+			return nil, IncorrectFormatForSetting{Setting: config.LogonFields, Value: []byte(tagStr), Err: errors.New("invalid tag")}
+		}
+		fields = append(fields, logonField{tag: Tag(tag), value: []byte(value)})
+	}
+	return fields, nil
+}
+
+// setLogonFields adds the fields of the LogonFields setting to an outgoing Logon. Environment variables are
+// expanded on every Logon, so that a rotated secret is picked up without a restart.
+func (s *session) setLogonFields(logon *Message) error {
+	for _, f := range s.logonFields {
+		value, err := expandEnvVars(config.LogonFields, f.value)
+		if err != nil {
+			return err
+		}
+		if f.tag.IsHeader() {
+			logon.Header.SetBytes(f.tag, value)
+		} else {
+			logon.Body.SetBytes(f.tag, value)
+		}
+	}
+	return nil
+}
@@ -50,13 +50,13 @@ func (s logonState) FixMsgIn(session *session, msg *Message) (nextState sessionS
 				return shutdownWithReason(session, msg, false, tooHighErr.Error())
 			}
 
-			return
+			return session.resumePersistedState(nextState)
 
 		default:
 			return handleStateError(session, err)
 		}
 	}
-	return inSession{}
+	return session.resumePersistedState(inSession{})
 }
 
 func (s logonState) Timeout(session *session, e internal.Event) (nextState sessionState) {
@@ -17,6 +17,7 @@ package quickfix
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 	"time"
 
@@ -290,6 +291,15 @@ func (s *LogonStateTestSuite) TestFixMsgInLogonRefreshOnLogon() {
 	}
 }
 
+func (s *LogonStateTestSuite) TestForceLogout() {
+	rep := make(chan error, 1)
+	s.session.onAdmin(forceLogoutReq{reason: "rogue counterparty", err: rep})
+	s.Nil(<-rep)
+	s.State(latentState{})
+	s.Disconnected()
+	s.NotStopped()
+}
+
 func (s *LogonStateTestSuite) TestStop() {
 	var tests = []bool{true, false}
 
@@ -332,6 +342,66 @@ func (s *LogonStateTestSuite) TestFixMsgInLogonRejectLogon() {
 	s.NextSenderMsgSeqNum(3)
 }
 
+type authenticatorFunc func(sessionID SessionID, msg *Message) error
+
+func (f authenticatorFunc) Authenticate(sessionID SessionID, msg *Message) error {
+	return f(sessionID, msg)
+}
+
+func (s *LogonStateTestSuite) TestFixMsgInLogonAuthenticated() {
+	var authenticated SessionID
+	RegisterAuthenticator(authenticatorFunc(func(sessionID SessionID, msg *Message) error {
+		authenticated = sessionID
+		s.MessageType(string(msgTypeLogon), msg)
+		return nil
+	}))
+	defer RegisterAuthenticator(nil)
+
+	s.IncrNextSenderMsgSeqNum()
+	s.MessageFactory.seqNum = 1
+	s.IncrNextTargetMsgSeqNum()
+
+	logon := s.Logon()
+	logon.Body.SetField(tagHeartBtInt, FIXInt(32))
+
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("OnLogon")
+	s.MockApp.On("ToAdmin")
+	s.fixMsgIn(s.session, logon)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.Equal(s.session.sessionID, authenticated)
+	s.State(inSession{})
+}
+
+func (s *LogonStateTestSuite) TestFixMsgInLogonAuthenticationFailed() {
+	RegisterAuthenticator(authenticatorFunc(func(SessionID, *Message) error {
+		return errors.New("invalid password")
+	}))
+	defer RegisterAuthenticator(nil)
+
+	s.IncrNextSenderMsgSeqNum()
+	s.MessageFactory.seqNum = 1
+	s.IncrNextTargetMsgSeqNum()
+
+	logon := s.Logon()
+	logon.Body.SetField(tagHeartBtInt, FIXInt(32))
+
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.MockApp.On("ToAdmin")
+	s.fixMsgIn(s.session, logon)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.State(latentState{})
+
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogout), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagText, "invalid password", s.MockApp.lastToAdmin.Body)
+
+	s.NextTargetMsgSeqNum(3)
+	s.NextSenderMsgSeqNum(3)
+}
+
 func (s *LogonStateTestSuite) TestFixMsgInLogonSeqNumTooHigh() {
 	s.MessageFactory.SetNextSeqNum(6)
 	logon := s.Logon()
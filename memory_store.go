@@ -16,6 +16,7 @@
 package quickfix
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/pkg/errors"
@@ -25,6 +26,12 @@ type memoryStore struct {
 	senderMsgSeqNum, targetMsgSeqNum int
 	creationTime                     time.Time
 	messageMap                       map[int][]byte
+
+	// Monitoring counters, readable while the session uses the store.
+	savedMessages     atomic.Int64
+	retrievedMessages atomic.Int64
+	bytesWritten      atomic.Int64
+	bytesRead         atomic.Int64
 }
 
 func (store *memoryStore) NextSenderMsgSeqNum() int {
@@ -86,6 +93,7 @@ func (store *memoryStore) SaveMessage(seqNum int, msg []byte) error {
 	}
 
 	store.messageMap[seqNum] = msg
+	store.RecordSaved(len(msg))
 	return nil
 }
 
@@ -100,7 +108,11 @@ func (store *memoryStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg
 func (store *memoryStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
 	for seqNum := beginSeqNum; seqNum <= endSeqNum; seqNum++ {
 		if m, ok := store.messageMap[seqNum]; ok {
+			store.RecordRetrieved(len(m))
 			if err := cb(m); err != nil {
+				if errors.Is(err, ErrStopIteration) {
+					return nil
+				}
 				return err
 			}
 		}
@@ -112,11 +124,61 @@ func (store *memoryStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, err
 	var msgs [][]byte
 	err := store.IterateMessages(beginSeqNum, endSeqNum, func(m []byte) error {
 		msgs = append(msgs, m)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return ErrStopIteration
+		}
 		return nil
 	})
 	return msgs, err
 }
 
+// HealthCheck always returns nil, a memory store has nothing that can fail.
+func (store *memoryStore) HealthCheck() error {
+	return nil
+}
+
+// SavedMessageCount returns the number of messages saved to the store.
+func (store *memoryStore) SavedMessageCount() int64 {
+	return store.savedMessages.Load()
+}
+
+// RetrievedMessageCount returns the number of messages read back from the store.
+func (store *memoryStore) RetrievedMessageCount() int64 {
+	return store.retrievedMessages.Load()
+}
+
+// SavedCount returns the number of messages saved to the store, as SavedMessageCount does.
+func (store *memoryStore) SavedCount() int64 {
+	return store.SavedMessageCount()
+}
+
+// RetrievedCount returns the number of messages read back from the store, as RetrievedMessageCount does.
+func (store *memoryStore) RetrievedCount() int64 {
+	return store.RetrievedMessageCount()
+}
+
+// RecordSaved counts a message of size bytes saved to the store.
+func (store *memoryStore) RecordSaved(size int) {
+	store.savedMessages.Add(1)
+	store.bytesWritten.Add(int64(size))
+}
+
+// RecordRetrieved counts a message of size bytes read back from the store.
+func (store *memoryStore) RecordRetrieved(size int) {
+	store.retrievedMessages.Add(1)
+	store.bytesRead.Add(int64(size))
+}
+
+// BytesWritten returns the number of message bytes saved to the store.
+func (store *memoryStore) BytesWritten() int64 {
+	return store.bytesWritten.Load()
+}
+
+// BytesRead returns the number of message bytes read back from the store.
+func (store *memoryStore) BytesRead() int64 {
+	return store.bytesRead.Load()
+}
+
 type memoryStoreFactory struct{}
 
 func (f memoryStoreFactory) Create(_ SessionID) (MessageStore, error) {
@@ -18,7 +18,9 @@ package quickfix
 import (
 	"bytes"
 	"fmt"
+	"hash/fnv"
 	"math"
+	"strconv"
 	"time"
 
 	"github.com/quickfixgo/quickfix/datadictionary"
@@ -155,6 +157,52 @@ func (m *Message) CopyInto(to *Message) {
 	}
 }
 
+// Clone returns a deep copy of the message, including its repeating groups, which can be mutated without
+// affecting the original, e.g. in Application.ToApp. As with CopyInto, the copy is decoupled from the
+// original's input buffer.
+func (m *Message) Clone() *Message {
+	clone := NewMessage()
+	m.Header.cloneInto(&clone.Header.FieldMap)
+	m.Body.cloneInto(&clone.Body.FieldMap)
+	m.Trailer.cloneInto(&clone.Trailer.FieldMap)
+
+	clone.ReceiveTime = m.ReceiveTime
+	clone.bodyBytes = bytes.Clone(m.bodyBytes)
+	clone.fields = make([]TagValue, len(m.fields))
+	for i := range m.fields {
+		clone.fields[i] = m.fields[i].clone()
+	}
+	return clone
+}
+
+// Strip returns a deep copy of the message without the fields with the given tags, which are removed from the
+// header, body and trailer and from every instance of the repeating groups, e.g. msg.Strip(453, 448, 447) to
+// drop party identification before archiving. Stripping the count tag of a repeating group removes the whole
+// group. The message itself is not modified.
+func (m *Message) Strip(tags ...Tag) *Message {
+	stripped := m.Clone()
+	stripped.Header.strip(tags)
+	stripped.Body.strip(tags)
+	stripped.Trailer.strip(tags)
+
+	// The parsed body and fields would still hold the stripped tags.
+	stripped.bodyBytes = nil
+	stripped.fields = nil
+	return stripped
+}
+
+// Equal reports whether m and other hold the same header, body and trailer fields with the same values,
+// including the members of their repeating groups. Unlike comparing String() output, it does not depend
+// on the order the fields were set or parsed in.
+func (m *Message) Equal(other *Message) bool {
+	if m == nil || other == nil {
+		return m == other
+	}
+	return m.Header.equal(other.Header.FieldMap) &&
+		m.Body.equal(other.Body.FieldMap) &&
+		m.Trailer.equal(other.Trailer.FieldMap)
+}
+
 // ParseMessage constructs a Message from a byte slice wrapping a FIX message.
 func ParseMessage(msg *Message, rawMessage *bytes.Buffer) (err error) {
 	return ParseMessageWithDataDictionary(msg, rawMessage, nil, nil)
@@ -478,6 +526,16 @@ func (m *Message) MsgType() (string, MessageRejectError) {
 	return m.Header.GetString(tagMsgType)
 }
 
+// SeqNum returns MsgSeqNum (tag 34) field's value.
+func (m *Message) SeqNum() (int, MessageRejectError) {
+	return m.Header.GetInt(tagMsgSeqNum)
+}
+
+// SenderCompID returns SenderCompID (tag 49) field's value.
+func (m *Message) SenderCompID() (string, MessageRejectError) {
+	return m.Header.GetString(tagSenderCompID)
+}
+
 func (m *Message) msgTypeNoLock() (string, MessageRejectError) {
 	return m.Header.getStringNoLock(tagMsgType)
 }
@@ -574,6 +632,15 @@ func (m *Message) Bytes() []byte {
 	return m.build()
 }
 
+// HashCode returns the 64-bit FNV-1a hash of the message's wire representation, as returned by Bytes.
+// Messages with the same wire representation have the same hash, which does not change between runs or
+// Go versions, so it may be persisted, e.g. to deduplicate messages.
+func (m *Message) HashCode() uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write(m.Bytes())
+	return h.Sum64()
+}
+
 func (m *Message) String() string {
 	if m.rawMessage != nil {
 		return m.rawMessage.String()
@@ -612,9 +679,29 @@ func (m *Message) buildWithBodyBytes(bodyBytes []byte) []byte {
 	return b.Bytes()
 }
 
+// BodyLength returns the BodyLength(9) of the message as it would be sent: the number of bytes following
+// the BodyLength field, up to and including the delimiter preceding the CheckSum(10) field. Unlike the
+// BodyLength field of a received message, it reflects the message's current fields.
+func (m *Message) BodyLength() int {
+	return m.Header.length() + m.Body.length() + m.Trailer.length()
+}
+
+// SizeBytes returns the number of bytes of the message's wire representation, as returned by Bytes, without
+// building it. Use it e.g. to size a buffer before writing the message.
+func (m *Message) SizeBytes() int {
+	if m.rawMessage != nil {
+		return m.rawMessage.Len()
+	}
+
+	bodyLength := m.BodyLength()
+	size := m.Header.fieldLength(tagBeginString) + bodyLength
+	size += len("9=") + len(strconv.Itoa(bodyLength)) + len("\x01")
+	size += len("10=000\x01")
+	return size
+}
+
 func (m *Message) cook() {
-	bodyLength := m.Header.length() + m.Body.length() + m.Trailer.length()
-	m.Header.SetInt(tagBodyLength, bodyLength)
+	m.Header.SetInt(tagBodyLength, m.BodyLength())
 	checkSum := (m.Header.total() + m.Body.total() + m.Trailer.total()) % 256
 	m.Trailer.SetString(tagCheckSum, formatCheckSum(checkSum))
 }
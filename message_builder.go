@@ -0,0 +1,128 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+// MessageOption sets fields of a message built by BuildMessage, or of a repeating group entry built by BuildGroup.
+type MessageOption func(*messageBuilder)
+
+// messageBuilder is the target of MessageOptions: the message being built, and the field map that field options set.
+type messageBuilder struct {
+	msg    *Message
+	fields *FieldMap
+	// added records the order fields are first set in, which orders the fields of a repeating group entry.
+	added map[Tag]int
+}
+
+func (b *messageBuilder) set(tag Tag) {
+	if _, ok := b.added[tag]; !ok {
+		b.added[tag] = len(b.added)
+	}
+}
+
+func (b *messageBuilder) apply(opts []MessageOption) {
+	for _, opt := range opts {
+		opt(b)
+	}
+}
+
+// BuildMessage returns a new message with the given options applied. Field options set body fields, unless passed to
+// WithHeader or WithTrailer.
+//
+//	msg := BuildMessage(
+//		WithHeader(WithStringField(tagBeginString, "FIX.4.4"), WithStringField(tagMsgType, "D")),
+//		WithStringField(Tag(11), "ID"),
+//		WithGroupField(Tag(78), BuildGroup(WithStringField(Tag(79), "ACCT1"))),
+//	)
+func BuildMessage(opts ...MessageOption) *Message {
+	msg := NewMessage()
+	b := &messageBuilder{msg: msg, fields: &msg.Body.FieldMap, added: make(map[Tag]int)}
+	b.apply(opts)
+	return msg
+}
+
+// BuildGroup returns a repeating group entry with the given field options applied, for use with WithGroupField. The
+// fields of the entry are written in the order they are set in, so the delimiter field of the group is set first.
+func BuildGroup(opts ...MessageOption) Group {
+	var g Group
+	b := &messageBuilder{fields: &g.FieldMap, added: make(map[Tag]int)}
+	g.initWithOrdering(func(i, j Tag) bool { return b.added[i] < b.added[j] })
+	b.apply(opts)
+	return g
+}
+
+// WithHeader applies the field options to the header of the message. It has no effect in BuildGroup.
+func WithHeader(opts ...MessageOption) MessageOption {
+	return func(b *messageBuilder) {
+		if b.msg == nil {
+			return
+		}
+		(&messageBuilder{msg: b.msg, fields: &b.msg.Header.FieldMap, added: b.added}).apply(opts)
+	}
+}
+
+// WithTrailer applies the field options to the trailer of the message. It has no effect in BuildGroup.
+func WithTrailer(opts ...MessageOption) MessageOption {
+	return func(b *messageBuilder) {
+		if b.msg == nil {
+			return
+		}
+		(&messageBuilder{msg: b.msg, fields: &b.msg.Trailer.FieldMap, added: b.added}).apply(opts)
+	}
+}
+
+// WithField sets the field with the given tag to value.
+func WithField(tag Tag, value FieldValueWriter) MessageOption {
+	return func(b *messageBuilder) {
+		b.fields.SetField(tag, value)
+		b.set(tag)
+	}
+}
+
+// WithStringField sets the field with the given tag to a string value.
+func WithStringField(tag Tag, value string) MessageOption {
+	return func(b *messageBuilder) {
+		b.fields.SetString(tag, value)
+		b.set(tag)
+	}
+}
+
+// WithIntField sets the field with the given tag to an int value.
+func WithIntField(tag Tag, value int) MessageOption {
+	return func(b *messageBuilder) {
+		b.fields.SetInt(tag, value)
+		b.set(tag)
+	}
+}
+
+// WithBoolField sets the field with the given tag to a bool value.
+func WithBoolField(tag Tag, value bool) MessageOption {
+	return func(b *messageBuilder) {
+		b.fields.SetBool(tag, value)
+		b.set(tag)
+	}
+}
+
+// WithGroupField sets the repeating group with the given NumInGroup tag to the given entries, built with BuildGroup.
+func WithGroupField(tag Tag, groups ...Group) MessageOption {
+	return func(b *messageBuilder) {
+		group := NewRepeatingGroup(tag, nil)
+		for i := range groups {
+			group.groups = append(group.groups, &groups[i])
+		}
+		b.fields.SetGroup(group)
+		b.set(tag)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildMessage(t *testing.T) {
+	msg := BuildMessage(
+		WithHeader(
+			WithStringField(tagBeginString, "FIX.4.2"),
+			WithStringField(tagMsgType, "D"),
+			WithStringField(tagSenderCompID, "TW"),
+			WithStringField(tagTargetCompID, "ISLD"),
+			WithIntField(tagMsgSeqNum, 2),
+			WithStringField(tagSendingTime, "20060102-15:04:05"),
+		),
+		WithStringField(Tag(11), "ID"),
+		WithStringField(Tag(21), "1"),
+		WithIntField(Tag(38), 100),
+		WithField(Tag(40), FIXString("1")),
+		WithStringField(Tag(54), "1"),
+		WithStringField(Tag(55), "AAPL"),
+		WithStringField(Tag(60), "20060102-15:04:05"),
+		WithGroupField(Tag(78),
+			BuildGroup(WithStringField(Tag(79), "ACCT1"), WithStringField(Tag(80), "60")),
+			BuildGroup(WithStringField(Tag(79), "ACCT2"), WithStringField(Tag(80), "40")),
+		),
+	)
+
+	assert.Equal(t, newOrderSingle42().String(), msg.String())
+	assert.False(t, msg.Body.Has(tagSenderCompID), "header fields are not set on the body")
+}
+
+func TestBuildMessageTrailer(t *testing.T) {
+	msg := BuildMessage(WithTrailer(WithStringField(Tag(93), "3"), WithStringField(Tag(89), "sig")), WithBoolField(Tag(43), true))
+
+	signature, err := msg.Trailer.GetString(Tag(89))
+	require.Nil(t, err)
+	assert.Equal(t, "sig", signature)
+	assert.False(t, msg.Body.Has(Tag(89)))
+	assert.True(t, msg.Body.Has(Tag(43)))
+}
+
+func TestBuildGroupFieldOrder(t *testing.T) {
+	msg := BuildMessage(
+		WithHeader(WithStringField(tagBeginString, "FIX.4.4"), WithStringField(tagMsgType, "8")),
+		WithGroupField(Tag(453),
+			BuildGroup(
+				WithStringField(Tag(448), "BROKER"),
+				WithStringField(Tag(447), "D"),
+				WithIntField(Tag(452), 1),
+				WithGroupField(Tag(802), BuildGroup(WithStringField(Tag(523), "DESK-7"), WithIntField(Tag(803), 4))),
+				// WithHeader is ignored within a group.
+				WithHeader(WithStringField(tagSenderCompID, "TW")),
+			),
+		),
+	)
+
+	assert.Contains(t, msg.String(), "453=1\x01448=BROKER\x01447=D\x01452=1\x01802=1\x01523=DESK-7\x01803=4\x01")
+	assert.False(t, msg.Header.Has(tagSenderCompID))
+
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447), GroupElement(452),
+		NewRepeatingGroup(Tag(802), GroupTemplate{GroupElement(523), GroupElement(803)})})
+	require.Nil(t, msg.Body.GetGroup(parties))
+	require.Equal(t, 1, parties.Len())
+	partyID, err := parties.Get(0).GetString(Tag(448))
+	require.Nil(t, err)
+	assert.Equal(t, "BROKER", partyID)
+}
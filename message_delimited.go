@@ -0,0 +1,63 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+)
+
+// ToDelimitedString returns the wire format of the message with each field terminated by delim instead of SOH,
+// e.g. "8=FIX.4.2|9=49|35=0|...|10=123|" for '|'. Checksum and body length are those of the SOH delimited
+// message.
+func (m *Message) ToDelimitedString(delim byte) string {
+	b := bytes.Clone(m.Bytes())
+	for i, c := range b {
+		if c == '\001' {
+			b[i] = delim
+		}
+	}
+	return string(b)
+}
+
+// ParseDelimitedBytes constructs a Message from the output of ToDelimitedString, or any FIX message whose fields
+// are terminated by delim instead of SOH. An occurrence of delim only terminates a field when it is followed by
+// the next "tag=" or ends the data, so field values may contain delim.
+func ParseDelimitedBytes(data []byte, delim byte) (*Message, error) {
+	raw := bytes.Clone(data)
+	for i, c := range raw {
+		if c == delim && endsField(raw[i+1:]) {
+			raw[i] = '\001'
+		}
+	}
+
+	msg := NewMessage()
+	if err := ParseMessage(msg, bytes.NewBuffer(raw)); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// endsField reports whether rest, the data following a delimiter, starts with a "tag=" or is empty.
+func endsField(rest []byte) bool {
+	if len(rest) == 0 {
+		return true
+	}
+	i := 0
+	for i < len(rest) && rest[i] >= '0' && rest[i] <= '9' {
+		i++
+	}
+	return i > 0 && i < len(rest) && rest[i] == '='
+}
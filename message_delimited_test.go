@@ -0,0 +1,55 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageToDelimitedString(t *testing.T) {
+	msg := NewMessage()
+	msg.Header.SetString(tagBeginString, "FIX.4.2").SetString(tagMsgType, "0").
+		SetString(tagSenderCompID, "TW").SetString(tagTargetCompID, "ISLD").SetInt(tagMsgSeqNum, 2)
+
+	raw := msg.String()
+	assert.Equal(t, strings.ReplaceAll(raw, "\x01", "|"), msg.ToDelimitedString('|'))
+	assert.Equal(t, "8=FIX.4.2;9=24;35=0;34=2;49=TW;56=ISLD;10=212;", msg.ToDelimitedString(';'))
+	assert.Equal(t, raw, msg.String(), "message is unchanged")
+}
+
+func TestParseDelimitedBytes(t *testing.T) {
+	msg := newOrderSingle42()
+	msg.Body.SetString(Tag(58), "a|b=c|d")
+
+	for _, delim := range []byte{'|', ';'} {
+		parsed, err := ParseDelimitedBytes([]byte(msg.ToDelimitedString(delim)), delim)
+		require.Nil(t, err)
+		assert.Equal(t, msg.String(), parsed.String())
+
+		text, err := parsed.Body.GetString(Tag(58))
+		require.Nil(t, err)
+		assert.Equal(t, "a|b=c|d", text)
+	}
+}
+
+func TestParseDelimitedBytesInvalid(t *testing.T) {
+	_, err := ParseDelimitedBytes([]byte("9=5|35=0|10=000|"), '|')
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,213 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+)
+
+// MarshalJSON encodes the FieldMap as a JSON object keyed by tag number, e.g. {"11":"ID","55":"AAPL"},
+// with the fields in the order they are written to the wire. A repeating group is encoded as an array of
+// objects, one per group entry, e.g. {"78":[{"79":"A","80":"10"},{"79":"B","80":"20"}]}.
+//
+// The FieldMap does not record group templates, so the fields of a nested repeating group are encoded
+// within the entry of the enclosing group, in wire order. UnmarshalJSON restores them as they were.
+func (m FieldMap) MarshalJSON() ([]byte, error) {
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	var b bytes.Buffer
+	b.WriteByte('{')
+	for i, tag := range m.sortedTags() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := writeJSONField(&b, m.tagLookup[tag]); err != nil {
+			return nil, err
+		}
+	}
+	b.WriteByte('}')
+	return b.Bytes(), nil
+}
+
+// writeJSONField writes a single field as a JSON object member.
+func writeJSONField(b *bytes.Buffer, f field) error {
+	b.WriteString(`"` + strconv.Itoa(int(fieldTag(f))) + `":`)
+	if len(f) == 1 {
+		return writeJSONString(b, f[0].value)
+	}
+
+	// Group entries start with the delimiter, the first field of the group.
+	members := f[1:]
+	delimiter := members[0].tag
+	b.WriteString("[{")
+	for i, tv := range members {
+		if i > 0 {
+			if tv.tag == delimiter {
+				b.WriteString("},{")
+			} else {
+				b.WriteByte(',')
+			}
+		}
+		b.WriteString(`"` + strconv.Itoa(int(tv.tag)) + `":`)
+		if err := writeJSONString(b, tv.value); err != nil {
+			return err
+		}
+	}
+	b.WriteString("}]")
+	return nil
+}
+
+func writeJSONString(b *bytes.Buffer, value []byte) error {
+	encoded, err := json.Marshal(string(value))
+	if err != nil {
+		return err
+	}
+	b.Write(encoded)
+	return nil
+}
+
+// UnmarshalJSON replaces the content of the FieldMap with the fields of a JSON object as encoded by MarshalJSON.
+// Field values must be JSON strings, and repeating groups arrays of objects.
+func (m *FieldMap) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	fields, err := decodeJSONFields(dec)
+	if err != nil {
+		return err
+	}
+
+	if m.rwLock == nil {
+		m.init()
+	}
+	m.rwLock.Lock()
+	defer m.rwLock.Unlock()
+
+	m.clearNoLock()
+	for _, f := range fields {
+		m.add(f)
+	}
+	return nil
+}
+
+// decodeJSONFields reads a JSON object of fields from dec, preserving the order of its members.
+func decodeJSONFields(dec *json.Decoder) ([]field, error) {
+	if err := expectJSONDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+
+	var fields []field
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		tagNum, err := strconv.Atoi(key.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid tag: %q", key)
+		}
+		tag := Tag(tagNum)
+
+		token, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		switch value := token.(type) {
+		case string:
+			f := make(field, 1)
+			initField(f, tag, []byte(value))
+			fields = append(fields, f)
+		case json.Delim:
+			if value != '[' {
+				return nil, fmt.Errorf("tag %d: expected a string or an array of group entries", tag)
+			}
+			f, err := decodeJSONGroup(dec, tag)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, f)
+		default:
+			return nil, fmt.Errorf("tag %d: expected a string or an array of group entries", tag)
+		}
+	}
+
+	if err := expectJSONDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// decodeJSONGroup reads the entries of a repeating group after its opening bracket.
+func decodeJSONGroup(dec *json.Decoder, tag Tag) (field, error) {
+	f := make(field, 1)
+	var count int
+	for dec.More() {
+		entry, err := decodeJSONFields(dec)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range entry {
+			f = append(f, member...)
+		}
+		count++
+	}
+	if err := expectJSONDelim(dec, ']'); err != nil {
+		return nil, err
+	}
+	initField(f, tag, []byte(strconv.Itoa(count)))
+	return f, nil
+}
+
+func expectJSONDelim(dec *json.Decoder, delim json.Delim) error {
+	token, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("expected %v, got %v", delim, token)
+	}
+	return nil
+}
+
+// jsonMessage is the JSON encoding of a Message.
+type jsonMessage struct {
+	Header  *FieldMap `json:"header"`
+	Body    *FieldMap `json:"body"`
+	Trailer *FieldMap `json:"trailer"`
+}
+
+// MarshalJSON encodes the message as a JSON object holding its header, body and trailer, each encoded
+// as described for FieldMap.MarshalJSON, e.g.
+// {"header":{"8":"FIX.4.2","35":"D"},"body":{"55":"AAPL"},"trailer":{"10":"123"}}.
+func (m *Message) MarshalJSON() ([]byte, error) {
+	return json.Marshal(jsonMessage{Header: &m.Header.FieldMap, Body: &m.Body.FieldMap, Trailer: &m.Trailer.FieldMap})
+}
+
+// UnmarshalJSON replaces the content of the message with a JSON object as encoded by MarshalJSON.
+func (m *Message) UnmarshalJSON(data []byte) error {
+	var msg Message
+	msg.Header.Init()
+	msg.Body.Init()
+	msg.Trailer.Init()
+	if err := json.Unmarshal(data, &jsonMessage{Header: &msg.Header.FieldMap, Body: &msg.Body.FieldMap, Trailer: &msg.Trailer.FieldMap}); err != nil {
+		return err
+	}
+
+	*m = msg
+	return nil
+}
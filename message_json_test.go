@@ -0,0 +1,107 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newOrderSingle42 returns a FIX 4.2 NewOrderSingle with a NoAllocs repeating group.
+func newOrderSingle42() *Message {
+	msg := NewMessage()
+	msg.Header.SetString(tagBeginString, "FIX.4.2").SetString(tagMsgType, "D").
+		SetString(tagSenderCompID, "TW").SetString(tagTargetCompID, "ISLD").SetInt(tagMsgSeqNum, 2).
+		SetString(tagSendingTime, "20060102-15:04:05")
+	allocs := NewRepeatingGroup(Tag(78), GroupTemplate{GroupElement(79), GroupElement(80)})
+	allocs.Add().SetString(Tag(79), "ACCT1").SetString(Tag(80), "60")
+	allocs.Add().SetString(Tag(79), "ACCT2").SetString(Tag(80), "40")
+	msg.Body.SetString(Tag(11), "ID").SetString(Tag(21), "1").SetInt(Tag(38), 100).SetString(Tag(40), "1").
+		SetString(Tag(54), "1").SetString(Tag(55), "AAPL").SetString(Tag(60), "20060102-15:04:05").SetGroup(allocs)
+	return msg
+}
+
+func TestMessageMarshalJSON(t *testing.T) {
+	msg := newOrderSingle42()
+	raw := msg.String()
+
+	data, err := json.Marshal(msg)
+	require.Nil(t, err)
+	assert.Equal(t, `{"header":{"8":"FIX.4.2","9":"137","35":"D","34":"2","49":"TW","52":"20060102-15:04:05","56":"ISLD"},`+
+		`"body":{"11":"ID","21":"1","38":"100","40":"1","54":"1","55":"AAPL","60":"20060102-15:04:05",`+
+		`"78":[{"79":"ACCT1","80":"60"},{"79":"ACCT2","80":"40"}]},`+
+		`"trailer":{"10":"176"}}`, string(data))
+
+	// A parsed message takes the same round trip.
+	parsed := NewMessage()
+	require.Nil(t, ParseMessage(parsed, bytes.NewBufferString(raw)))
+	data, err = json.Marshal(parsed)
+	require.Nil(t, err)
+	assert.Contains(t, string(data), `"55":"AAPL"`)
+}
+
+func TestMessageJSONRoundTrip(t *testing.T) {
+	msg := newOrderSingle42()
+	raw := msg.String()
+
+	data, err := json.Marshal(msg)
+	require.Nil(t, err)
+	decoded := NewMessage()
+	require.Nil(t, json.Unmarshal(data, decoded))
+
+	assert.Equal(t, raw, decoded.String())
+	assert.True(t, decoded.IsMsgTypeOf("D"))
+	symbol, err := decoded.Body.GetString(Tag(55))
+	require.Nil(t, err)
+	assert.Equal(t, "AAPL", symbol)
+
+	allocs := NewRepeatingGroup(Tag(78), GroupTemplate{GroupElement(79), GroupElement(80)})
+	require.Nil(t, decoded.Body.GetGroup(allocs))
+	require.Equal(t, 2, allocs.Len())
+	account, err := allocs.Get(1).GetString(Tag(79))
+	require.Nil(t, err)
+	assert.Equal(t, "ACCT2", account)
+}
+
+func TestMessageUnmarshalJSONNestedGroups(t *testing.T) {
+	data := `{"header":{"35":"D","8":"FIX.4.4"},"body":{"453":[{"448":"A","447":"D","802":[{"523":"x","803":"1"},{"523":"y","803":"2"}]}]},"trailer":{}}`
+	msg := NewMessage()
+	require.Nil(t, json.Unmarshal([]byte(data), msg))
+	assert.Contains(t, msg.String(), "453=1\x01448=A\x01447=D\x01802=2\x01523=x\x01803=1\x01523=y\x01803=2\x01")
+
+	// Nested groups are written flat within the enclosing entry, and restored as they were.
+	encoded, err := json.Marshal(msg)
+	require.Nil(t, err)
+	decoded := NewMessage()
+	require.Nil(t, json.Unmarshal(encoded, decoded))
+	assert.Equal(t, msg.String(), decoded.String())
+}
+
+func TestMessageUnmarshalJSONErrors(t *testing.T) {
+	for _, data := range []string{
+		`{"header":{"abc":"1"}}`,
+		`{"body":{"55":55}}`,
+		`{"body":{"78":{"79":"A"}}}`,
+		`{"body":{"78":["A"]}}`,
+		`{"body":[]}`,
+	} {
+		assert.NotNil(t, json.Unmarshal([]byte(data), NewMessage()), data)
+	}
+}
@@ -15,6 +15,8 @@
 
 package quickfix
 
+import "errors"
+
 type routeKey struct {
 	FIXVersion string
 	MsgType    string
@@ -37,14 +39,79 @@ const (
 // A MessageRoute is a function that can process a fromApp/fromAdmin callback.
 type MessageRoute func(msg *Message, sessionID SessionID) MessageRejectError
 
+// businessRejectReasonOther is the BusinessRejectReason of the errors returned by MessageHandlers.
+const businessRejectReasonOther = 0
+
+// A MessageHandler processes the app messages of a MsgType passed to MessageRouter.FromApp. A returned
+// MessageRejectError rejects the message as it is; any other error rejects it with a BusinessMessageReject.
+type MessageHandler func(msg *Message, sessionID SessionID) error
+
+// UnhandledMessageObserver is notified of the app messages a MessageRouter has no handler for.
+type UnhandledMessageObserver interface {
+	OnUnhandledMessage(sessionID SessionID, msgType string)
+}
+
 // A MessageRouter is a mutex for MessageRoutes.
+//
+// A MessageRouter is also an Application dispatching incoming app messages to the MessageHandlers registered with
+// Handle, by MsgType regardless of the FIX version. Its other notifications are no-ops.
 type MessageRouter struct {
-	routes map[routeKey]MessageRoute
+	ApplicationAdapter
+
+	routes            map[routeKey]MessageRoute
+	handlers          map[string]MessageHandler
+	defaultHandler    MessageHandler
+	unhandledObserver UnhandledMessageObserver
 }
 
 // NewMessageRouter returns an initialized MessageRouter instance.
 func NewMessageRouter() *MessageRouter {
-	return &MessageRouter{routes: make(map[routeKey]MessageRoute)}
+	return &MessageRouter{routes: make(map[routeKey]MessageRoute), handlers: make(map[string]MessageHandler)}
+}
+
+// Handle registers the handler FromApp dispatches the messages of msgType to.
+func (c *MessageRouter) Handle(msgType string, handler MessageHandler) {
+	c.handlers[msgType] = handler
+}
+
+// HandleDefault registers the handler FromApp dispatches the messages without a handler of their own to.
+func (c *MessageRouter) HandleDefault(handler MessageHandler) {
+	c.defaultHandler = handler
+}
+
+// SetUnhandledMessageObserver sets the observer notified of the messages FromApp has no handler for, e.g. to count
+// them per MsgType.
+func (c *MessageRouter) SetUnhandledMessageObserver(observer UnhandledMessageObserver) {
+	c.unhandledObserver = observer
+}
+
+// FromApp dispatches msg to the handler registered for its MsgType, or else to the default handler. Messages without
+// a handler are rejected with UnsupportedMessageType, and reported to the UnhandledMessageObserver, if any.
+func (c *MessageRouter) FromApp(msg *Message, sessionID SessionID) MessageRejectError {
+	msgType, reject := msg.MsgType()
+	if reject != nil {
+		return reject
+	}
+
+	handler, ok := c.handlers[msgType]
+	if !ok {
+		handler = c.defaultHandler
+	}
+	if handler == nil {
+		if c.unhandledObserver != nil {
+			c.unhandledObserver.OnUnhandledMessage(sessionID, msgType)
+		}
+		return UnsupportedMessageType()
+	}
+
+	if err := handler(msg, sessionID); err != nil {
+		var reject MessageRejectError
+		if errors.As(err, &reject) {
+			return reject
+		}
+		return NewBusinessMessageRejectError(err.Error(), businessRejectReasonOther, nil)
+	}
+	return nil
 }
 
 // AddRoute adds a route to the MessageRouter instance keyed to begin string and msgType.
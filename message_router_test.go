@@ -205,3 +205,57 @@ func (suite *MessageRouterTestSuite) TestRouteFIXTAppWithDefaultApplVerID() {
 	suite.verifyMessageRoutedBy(ApplVerIDFIX50SP1, "D")
 	suite.Nil(rej)
 }
+
+// recordingUnhandledObserver records the MsgTypes of unhandled messages.
+type recordingUnhandledObserver struct {
+	msgTypes []string
+}
+
+func (o *recordingUnhandledObserver) OnUnhandledMessage(_ SessionID, msgType string) {
+	o.msgTypes = append(o.msgTypes, msgType)
+}
+
+func (suite *MessageRouterTestSuite) TestHandle() {
+	var _ Application = suite.MessageRouter
+	suite.givenAFIX42NewOrderSingle()
+
+	var handledBy string
+	suite.Handle("D", func(msg *Message, sessionID SessionID) error {
+		handledBy = "D"
+		suite.Equal(suite.sessionID, sessionID)
+		suite.Equal(suite.msg, msg)
+		return nil
+	})
+	suite.HandleDefault(func(*Message, SessionID) error {
+		handledBy = "default"
+		return nil
+	})
+
+	suite.Nil(suite.FromApp(suite.msg, suite.sessionID))
+	suite.Equal("D", handledBy)
+
+	delete(suite.handlers, "D")
+	suite.Nil(suite.FromApp(suite.msg, suite.sessionID))
+	suite.Equal("default", handledBy)
+}
+
+func (suite *MessageRouterTestSuite) TestHandleErrors() {
+	suite.givenAFIX42NewOrderSingle()
+
+	reject := NewMessageRejectError("bad value", rejectReasonValueIsIncorrect, nil)
+	suite.Handle("D", func(*Message, SessionID) error { return reject })
+	suite.Equal(reject, suite.FromApp(suite.msg, suite.sessionID))
+
+	suite.Handle("D", func(*Message, SessionID) error { return fmt.Errorf("no liquidity") })
+	suite.Equal(NewBusinessMessageRejectError("no liquidity", 0, nil), suite.FromApp(suite.msg, suite.sessionID))
+}
+
+func (suite *MessageRouterTestSuite) TestHandleUnhandledMessage() {
+	suite.givenAFIX42NewOrderSingle()
+	observer := new(recordingUnhandledObserver)
+	suite.SetUnhandledMessageObserver(observer)
+	suite.Handle("F", func(*Message, SessionID) error { return nil })
+
+	suite.Equal(UnsupportedMessageType(), suite.FromApp(suite.msg, suite.sessionID))
+	suite.Equal([]string{"D"}, observer.msgTypes)
+}
@@ -18,7 +18,9 @@ package quickfix
 import (
 	"bytes"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -87,6 +89,33 @@ func (s *MessageSuite) TestParseMessage() {
 	s.False(s.msg.IsMsgTypeOf("A"))
 }
 
+func (s *MessageSuite) TestHeaderAccessors() {
+	rawMsg := bytes.NewBufferString("8=FIX.4.2\x019=104\x0135=D\x0134=2\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x0111=100\x0121=1\x0140=1\x0154=1\x0155=TSLA\x0160=00010101-00:00:00.000\x0110=039\x01")
+	s.Nil(ParseMessage(s.msg, rawMsg))
+
+	msgType, err := s.msg.MsgType()
+	s.Nil(err)
+	s.Equal("D", msgType)
+	seqNum, err := s.msg.SeqNum()
+	s.Nil(err)
+	s.Equal(2, seqNum)
+	senderCompID, err := s.msg.SenderCompID()
+	s.Nil(err)
+	s.Equal("TW", senderCompID)
+
+	msg := NewMessage()
+	_, err = msg.MsgType()
+	s.Equal(rejectReasonConditionallyRequiredFieldMissing, err.RejectReason())
+	_, err = msg.SeqNum()
+	s.Equal(rejectReasonConditionallyRequiredFieldMissing, err.RejectReason())
+	_, err = msg.SenderCompID()
+	s.Equal(rejectReasonConditionallyRequiredFieldMissing, err.RejectReason())
+
+	msg.Header.SetString(tagMsgSeqNum, "abc")
+	_, err = msg.SeqNum()
+	s.Equal(rejectReasonIncorrectDataFormatForValue, err.RejectReason())
+}
+
 func (s *MessageSuite) TestParseMessageWithDataDictionary() {
 	dict := new(datadictionary.DataDictionary)
 	dict.Header = &datadictionary.MessageDef{
@@ -467,6 +496,206 @@ func (s *MessageSuite) TestReverseRouteFIX40() {
 	s.False(builder.Header.Has(tagOnBehalfOfLocationID), "onbehalfof location id not supported in fix40")
 }
 
+func (s *MessageSuite) TestClone() {
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447)})
+	parties.Add().SetString(Tag(448), "A").SetString(Tag(447), "D")
+	parties.Add().SetString(Tag(448), "B").SetString(Tag(447), "D")
+	s.msg.Header.SetString(tagBeginString, "FIX.4.4").SetString(tagMsgType, "D")
+	s.msg.Body.SetString(Tag(11), "ID").SetGroup(parties)
+	s.msg.ReceiveTime = time.Now()
+	original := s.msg.String()
+
+	clone := s.msg.Clone()
+	s.Equal(original, clone.String())
+	s.Equal(s.msg.ReceiveTime, clone.ReceiveTime)
+
+	// Mutating the clone, including its repeating groups, leaves the original untouched.
+	clone.Header.SetString(tagMsgType, "F")
+	clone.Body.SetString(Tag(11), "OTHER")
+	clone.Body.tagLookup[Tag(453)][1].init(Tag(448), []byte("X"))
+	s.Equal(original, s.msg.String())
+	s.Contains(clone.String(), "35=F")
+	s.Contains(clone.String(), "448=X")
+
+	// The clone of a parsed message is decoupled from the parsed bytes.
+	s.Nil(ParseMessage(s.msg, bytes.NewBufferString(original)))
+	clone = s.msg.Clone()
+	s.True(reflect.DeepEqual(s.msg.fields, clone.fields))
+	clone.fields[0].bytes[0] = 'X'
+	s.Equal(original, s.msg.String())
+	s.Equal("FIX.4.4", string(s.msg.fields[0].value))
+}
+
+func (s *MessageSuite) TestStrip() {
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447), GroupElement(452)})
+	parties.Add().SetString(Tag(448), "A").SetString(Tag(447), "D").SetInt(Tag(452), 1)
+	parties.Add().SetString(Tag(448), "B").SetString(Tag(447), "D").SetInt(Tag(452), 3)
+	s.msg.Header.SetString(tagBeginString, "FIX.4.4").SetString(tagMsgType, "D").SetString(Tag(50), "TRADER")
+	s.msg.Body.SetString(Tag(11), "ID").SetString(Tag(1), "ACCOUNT").SetGroup(parties)
+	original := s.msg.String()
+
+	// Group members are removed from each instance, and the original is left untouched.
+	stripped := s.msg.Strip(448, 447, 50, 1)
+	s.Equal(original, s.msg.String())
+	s.Contains(stripped.String(), "\x0111=ID\x01453=2\x01452=1\x01452=3\x0110=")
+	s.NotContains(stripped.String(), "50=TRADER")
+	s.False(stripped.Header.Has(Tag(50)))
+	s.False(stripped.Body.Has(Tag(1)))
+	s.ElementsMatch([]Tag{tagBeginString, tagMsgType, tagBodyLength}, stripped.Header.Tags())
+
+	// Stripping the count tag removes the whole group.
+	stripped = s.msg.Strip(453).Strip(11)
+	s.False(stripped.Body.Has(Tag(453)))
+	s.NotContains(stripped.String(), "448=")
+	s.NotContains(stripped.String(), "11=ID")
+
+	// A stripped parsed message is built from its remaining fields.
+	s.Nil(ParseMessage(s.msg, bytes.NewBufferString(original)))
+	stripped = s.msg.Strip(tagMsgType)
+	s.Equal(original, s.msg.String())
+	s.NotContains(stripped.String(), "\x0135=D")
+	s.Nil(stripped.bodyBytes)
+}
+
+func (s *MessageSuite) TestEqual() {
+	newParties := func(ids ...string) *RepeatingGroup {
+		parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447)})
+		for _, id := range ids {
+			parties.Add().SetString(Tag(448), id).SetString(Tag(447), "D")
+		}
+		return parties
+	}
+	s.msg.Header.SetString(tagBeginString, "FIX.4.4").SetString(tagMsgType, "D")
+	s.msg.Body.SetString(Tag(11), "ID").SetGroup(newParties("A", "B"))
+
+	// Fields set in a different order compare equal.
+	other := NewMessage()
+	other.Body.SetGroup(newParties("A", "B")).SetString(Tag(11), "ID")
+	other.Header.SetString(tagMsgType, "D").SetString(tagBeginString, "FIX.4.4")
+	s.True(s.msg.Equal(other))
+	s.True(other.Equal(s.msg))
+	s.True(s.msg.Equal(s.msg.Clone()))
+
+	other.Body.SetGroup(newParties("A", "C"))
+	s.False(s.msg.Equal(other))
+	other.Body.SetGroup(newParties("A"))
+	s.False(s.msg.Equal(other))
+	other.Body.SetGroup(newParties("A", "B"))
+	other.Trailer.SetString(tagCheckSum, "000")
+	s.False(s.msg.Equal(other))
+
+	var nilMsg *Message
+	s.False(s.msg.Equal(nil))
+	s.True(nilMsg.Equal(nil))
+
+	// Messages parsed from the same fields in a different order compare equal.
+	parsed, reordered := NewMessage(), NewMessage()
+	s.Nil(ParseMessage(parsed, bytes.NewBufferString("8=FIX.4.29=2835=D34=211=ID21=338=10010=161")))
+	s.Nil(ParseMessage(reordered, bytes.NewBufferString("8=FIX.4.29=2835=D34=238=10021=311=ID10=161")))
+	s.True(parsed.Equal(reordered))
+	s.NotEqual(parsed.String(), reordered.String())
+}
+
+func (s *MessageSuite) TestHashCode() {
+	// Golden values of the 64-bit FNV-1a hash of the wire representation.
+	var tests = []struct {
+		raw  string
+		hash uint64
+	}{
+		{"8=FIX.4.2\x019=104\x0135=D\x0134=2\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x0111=100\x0121=1\x0140=1\x0154=1\x0155=TSLA\x0160=00010101-00:00:00.000\x0110=039\x01", 0x2cda4cde0f5ede61},
+		{"8=FIX.4.4\x019=10\x0135=0\x0134=1\x0110=165\x01", 0x76840ff8140603c3},
+	}
+	for _, test := range tests {
+		msg := NewMessage()
+		s.Require().Nil(ParseMessage(msg, bytes.NewBufferString(test.raw)))
+		s.Equal(test.hash, msg.HashCode(), test.raw)
+	}
+
+	// A built message hashes like the same message parsed.
+	built := NewMessage()
+	built.Header.SetString(tagBeginString, BeginStringFIX44)
+	built.Header.SetString(tagMsgType, "0")
+	built.Header.SetInt(tagMsgSeqNum, 1)
+	s.Equal(uint64(0x76840ff8140603c3), built.HashCode())
+
+	built.Header.SetInt(tagMsgSeqNum, 2)
+	s.NotEqual(uint64(0x76840ff8140603c3), built.HashCode())
+}
+
+func (s *MessageSuite) TestBodyLength() {
+	// Examples with a known-good BodyLength from the FIX 4.2 specification and venue conformance tests.
+	for _, raw := range []string{
+		"8=FIX.4.29=6535=A49=SERVER56=CLIENT34=17752=20090107-18:15:1698=0108=3010=062",
+		"8=FIX.4.29=17835=849=PHLX56=PERS52=20071123-05:30:00.00011=ATOMNOCCC999090020=3150=E39=E55=MSFT167=CS54=138=1540=244=1558=PHLX EQUITY TESTING59=047=C32=031=0151=1514=06=010=128",
+	} {
+		s.Nil(ParseMessage(s.msg, bytes.NewBufferString(raw)))
+		expected, err := s.msg.Header.GetInt(tagBodyLength)
+		s.Nil(err)
+		s.Equal(expected, s.msg.BodyLength())
+	}
+
+	// Changing a field changes the BodyLength without rebuilding the message.
+	s.msg.Body.SetString(Tag(58), "PHLX EQUITY TESTING!")
+	s.Equal(179, s.msg.BodyLength())
+}
+
+func (s *MessageSuite) TestSizeBytes() {
+	s.msg.Header.SetString(tagBeginString, BeginStringFIX42)
+	s.msg.Header.SetString(tagMsgType, "D")
+	s.msg.Header.SetString(tagSenderCompID, "TW")
+	s.msg.Header.SetString(tagTargetCompID, "ISLD")
+	s.msg.Header.SetInt(tagMsgSeqNum, 2)
+	s.msg.Body.SetString(Tag(11), "100")
+	s.msg.Body.SetString(Tag(55), "TSLA")
+	s.Equal(len(s.msg.Bytes()), s.msg.SizeBytes())
+
+	// BodyLength gains a digit.
+	s.msg.Body.SetString(Tag(58), strings.Repeat("x", 100))
+	s.Equal(len(s.msg.Bytes()), s.msg.SizeBytes())
+
+	// A message without a BeginString.
+	s.msg.Header.Remove(tagBeginString)
+	s.Equal(len(s.msg.Bytes()), s.msg.SizeBytes())
+
+	// A parsed message is as long as its raw bytes.
+	raw := "8=FIX.4.2\x019=104\x0135=D\x0134=2\x0149=TW\x0152=20140515-19:49:56.659\x0156=ISLD\x0111=100\x0121=1\x0140=1\x0154=1\x0155=TSLA\x0160=00010101-00:00:00.000\x0110=039\x01"
+	s.Nil(ParseMessage(s.msg, bytes.NewBufferString(raw)))
+	s.Equal(len(raw), s.msg.SizeBytes())
+}
+
+func BenchmarkMessage_SizeBytes(b *testing.B) {
+	msg := newSizeBytesBenchmarkMessage()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = msg.SizeBytes()
+	}
+}
+
+func BenchmarkMessage_LenBytes(b *testing.B) {
+	msg := newSizeBytesBenchmarkMessage()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = len(msg.Bytes())
+	}
+}
+
+func newSizeBytesBenchmarkMessage() *Message {
+	msg := NewMessage()
+	msg.Header.SetString(tagBeginString, BeginStringFIX42)
+	msg.Header.SetString(tagMsgType, "D")
+	msg.Header.SetString(tagSenderCompID, "TW")
+	msg.Header.SetString(tagTargetCompID, "ISLD")
+	msg.Header.SetInt(tagMsgSeqNum, 2)
+	msg.Body.SetString(Tag(11), "100")
+	msg.Body.SetString(Tag(21), "1")
+	msg.Body.SetString(Tag(40), "1")
+	msg.Body.SetString(Tag(54), "1")
+	msg.Body.SetString(Tag(55), "TSLA")
+	return msg
+}
+
 func (s *MessageSuite) TestCopyIntoMessage() {
 	msgString := "8=FIX.4.29=17135=D34=249=TW50=KK52=20060102-15:04:0556=ISLD57=AP144=BB115=JCD116=CS128=MG129=CB142=JV143=RY145=BH11=ID21=338=10040=w54=155=INTC60=20060102-15:04:0510=123"
 	msgBuf := bytes.NewBufferString(msgString)
@@ -0,0 +1,94 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// PrometheusObserver is a quickfix.QueueDepthObserver that exports the state and message counts of the sessions it is
+// registered with:
+//
+//   - fix_session_state, a gauge holding the quickfix.SessionState of each session
+//   - fix_messages_sent_total, the number of messages sent by each session, per MsgType
+//   - fix_messages_received_total, the number of messages received by each session, per MsgType
+//   - fix_session_queue_depth, a gauge holding the number of messages each session holds while they wait to be written
+//
+// It is also a quickfix.UnhandledMessageObserver, exporting fix_messages_unhandled_total, the number of app messages
+// received by each session that a quickfix.MessageRouter had no handler for, per MsgType.
+//
+// Every metric has a session_id label.
+type PrometheusObserver struct {
+	state     *prom.GaugeVec
+	sent      *prom.CounterVec
+	received  *prom.CounterVec
+	queue     *prom.GaugeVec
+	unhandled *prom.CounterVec
+}
+
+// NewPrometheusObserver returns a PrometheusObserver whose metrics are registered with reg. Calling
+// NewPrometheusObserver again with the same registerer reuses the metrics already registered.
+func NewPrometheusObserver(reg prom.Registerer) *PrometheusObserver {
+	return &PrometheusObserver{
+		state: registerCollector(reg, prom.NewGaugeVec(prom.GaugeOpts{
+			Name: "fix_session_state",
+			Help: "State of the FIX session, as a quickfix.SessionState value.",
+		}, []string{"session_id"})),
+		sent: registerCollector(reg, prom.NewCounterVec(prom.CounterOpts{
+			Name: "fix_messages_sent_total",
+			Help: "Number of messages sent by the FIX session.",
+		}, []string{"session_id", "msg_type"})),
+		received: registerCollector(reg, prom.NewCounterVec(prom.CounterOpts{
+			Name: "fix_messages_received_total",
+			Help: "Number of messages received by the FIX session.",
+		}, []string{"session_id", "msg_type"})),
+		queue: registerCollector(reg, prom.NewGaugeVec(prom.GaugeOpts{
+			Name: "fix_session_queue_depth",
+			Help: "Number of messages queued by the FIX session while they wait to be written.",
+		}, []string{"session_id"})),
+		unhandled: registerCollector(reg, prom.NewCounterVec(prom.CounterOpts{
+			Name: "fix_messages_unhandled_total",
+			Help: "Number of app messages received by the FIX session without a MessageRouter handler.",
+		}, []string{"session_id", "msg_type"})),
+	}
+}
+
+// OnStateChange sets the fix_session_state gauge of the session.
+func (o *PrometheusObserver) OnStateChange(sessionID quickfix.SessionID, _, new quickfix.SessionState) {
+	o.state.WithLabelValues(sessionID.String()).Set(float64(new))
+}
+
+// OnMessageSent increments fix_messages_sent_total.
+func (o *PrometheusObserver) OnMessageSent(sessionID quickfix.SessionID, msgType string) {
+	o.sent.WithLabelValues(sessionID.String(), msgType).Inc()
+}
+
+// OnMessageReceived increments fix_messages_received_total.
+func (o *PrometheusObserver) OnMessageReceived(sessionID quickfix.SessionID, msgType string) {
+	o.received.WithLabelValues(sessionID.String(), msgType).Inc()
+}
+
+// OnQueueDepthChange sets the fix_session_queue_depth gauge of the session.
+func (o *PrometheusObserver) OnQueueDepthChange(sessionID quickfix.SessionID, depth int) {
+	o.queue.WithLabelValues(sessionID.String()).Set(float64(depth))
+}
+
+// OnUnhandledMessage increments fix_messages_unhandled_total.
+func (o *PrometheusObserver) OnUnhandledMessage(sessionID quickfix.SessionID, msgType string) {
+	o.unhandled.WithLabelValues(sessionID.String(), msgType).Inc()
+}
@@ -0,0 +1,59 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+func TestPrometheusObserver(t *testing.T) {
+	reg := prom.NewRegistry()
+	obs := NewPrometheusObserver(reg)
+	var _ quickfix.QueueDepthObserver = obs
+
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	obs.OnStateChange(sessionID, quickfix.SessionStateLatent, quickfix.SessionStateLogon)
+	obs.OnStateChange(sessionID, quickfix.SessionStateLogon, quickfix.SessionStateInSession)
+	obs.OnMessageSent(sessionID, "A")
+	obs.OnMessageSent(sessionID, "D")
+	obs.OnMessageSent(sessionID, "D")
+	obs.OnMessageReceived(sessionID, "8")
+
+	id := sessionID.String()
+	assert.Equal(t, float64(quickfix.SessionStateInSession), testutil.ToFloat64(obs.state.WithLabelValues(id)))
+	assert.Equal(t, 1.0, testutil.ToFloat64(obs.sent.WithLabelValues(id, "A")))
+	assert.Equal(t, 2.0, testutil.ToFloat64(obs.sent.WithLabelValues(id, "D")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(obs.received.WithLabelValues(id, "8")))
+
+	// A second observer on the same registry shares the metrics.
+	NewPrometheusObserver(reg).OnMessageReceived(sessionID, "8")
+	assert.Equal(t, 2.0, testutil.ToFloat64(obs.received.WithLabelValues(id, "8")))
+	assert.Equal(t, 3, testutil.CollectAndCount(reg, "fix_session_state", "fix_messages_sent_total", "fix_messages_received_total")-1)
+
+	obs.OnQueueDepthChange(sessionID, 3)
+	obs.OnQueueDepthChange(sessionID, 1)
+	assert.Equal(t, 1.0, testutil.ToFloat64(obs.queue.WithLabelValues(id)))
+
+	var _ quickfix.UnhandledMessageObserver = obs
+	obs.OnUnhandledMessage(sessionID, "D")
+	assert.Equal(t, 1.0, testutil.ToFloat64(obs.unhandled.WithLabelValues(id, "D")))
+}
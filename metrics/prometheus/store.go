@@ -0,0 +1,152 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	"errors"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+const namespace = "quickfix"
+
+// storeMetrics are the counters maintained by MetricsMiddleware.
+type storeMetrics struct {
+	savedMessages     prom.Counter
+	retrievedMessages prom.Counter
+	bytesWritten      prom.Counter
+	bytesRead         prom.Counter
+	errors            *prom.CounterVec
+}
+
+// MetricsMiddleware returns a quickfix.StoreMiddleware that counts the messages and bytes saved to and
+// retrieved from the store, as well as failed store operations, and registers the counters with reg.
+// The counters are shared by every store wrapped by the middleware. Calling MetricsMiddleware again
+// with the same registerer reuses the counters already registered.
+func MetricsMiddleware(reg prom.Registerer) quickfix.StoreMiddleware {
+	m := &storeMetrics{
+		savedMessages: registerCollector(reg, prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "saved_messages_total",
+			Help:      "Number of messages saved to the message store.",
+		})),
+		retrievedMessages: registerCollector(reg, prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "retrieved_messages_total",
+			Help:      "Number of messages read back from the message store.",
+		})),
+		bytesWritten: registerCollector(reg, prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "written_bytes_total",
+			Help:      "Number of message bytes saved to the message store.",
+		})),
+		bytesRead: registerCollector(reg, prom.NewCounter(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "read_bytes_total",
+			Help:      "Number of message bytes read back from the message store.",
+		})),
+		errors: registerCollector(reg, prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "store",
+			Name:      "errors_total",
+			Help:      "Number of failed message store operations.",
+		}, []string{"operation"})),
+	}
+
+	return func(store quickfix.MessageStore) quickfix.MessageStore {
+		return &metricsStore{MessageStore: store, metrics: m}
+	}
+}
+
+// registerCollector registers c with reg, returning the collector that is already registered
+// under the same name if there is one.
+func registerCollector[C prom.Collector](reg prom.Registerer, c C) C {
+	if err := reg.Register(c); err != nil {
+		var are prom.AlreadyRegisteredError
+		if errors.As(err, &are) {
+			if existing, ok := are.ExistingCollector.(C); ok {
+				return existing
+			}
+		}
+		panic(err)
+	}
+	return c
+}
+
+type metricsStore struct {
+	quickfix.MessageStore
+	metrics *storeMetrics
+}
+
+// Unwrap returns the decorated store.
+func (store *metricsStore) Unwrap() quickfix.MessageStore {
+	return store.MessageStore
+}
+
+func (store *metricsStore) SaveMessage(seqNum int, msg []byte) error {
+	err := store.MessageStore.SaveMessage(seqNum, msg)
+	store.observeSave("save", msg, err)
+	return err
+}
+
+func (store *metricsStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	err := store.MessageStore.SaveMessageAndIncrNextSenderMsgSeqNum(seqNum, msg)
+	store.observeSave("save", msg, err)
+	return err
+}
+
+func (store *metricsStore) observeSave(op string, msg []byte, err error) {
+	if err != nil {
+		store.metrics.errors.WithLabelValues(op).Inc()
+		return
+	}
+	store.metrics.savedMessages.Inc()
+	store.metrics.bytesWritten.Add(float64(len(msg)))
+}
+
+func (store *metricsStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	msgs, err := store.MessageStore.GetMessages(beginSeqNum, endSeqNum)
+	if err != nil {
+		store.metrics.errors.WithLabelValues("retrieve").Inc()
+		return msgs, err
+	}
+	for _, msg := range msgs {
+		store.observeRetrieve(msg)
+	}
+	return msgs, nil
+}
+
+func (store *metricsStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	err := store.MessageStore.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		store.observeRetrieve(msg)
+		return cb(msg)
+	})
+	if err != nil {
+		store.metrics.errors.WithLabelValues("retrieve").Inc()
+	}
+	return err
+}
+
+func (store *metricsStore) observeRetrieve(msg []byte) {
+	store.metrics.retrievedMessages.Inc()
+	store.metrics.bytesRead.Add(float64(len(msg)))
+}
@@ -0,0 +1,74 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	prom "github.com/prometheus/client_golang/prometheus"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// StoreFileCollector is a prom.Collector exporting the disk usage of the message stores of sessions that keep
+// their messages in files, such as the file store:
+//
+//   - fix_store_body_bytes, the size of the file holding the messages of each session
+//   - fix_store_header_bytes, the size of the file indexing the messages of each session
+//
+// The sizes are read when the metrics are gathered. Sessions that are not registered, or whose store does not
+// keep files, are left out. Every metric has a session_id label.
+type StoreFileCollector struct {
+	sessionIDs []quickfix.SessionID
+	body       *prom.Desc
+	header     *prom.Desc
+}
+
+// NewStoreFileCollector returns a StoreFileCollector for the stores of the given sessions, to be registered
+// with a prom.Registerer.
+func NewStoreFileCollector(sessionIDs ...quickfix.SessionID) *StoreFileCollector {
+	return &StoreFileCollector{
+		sessionIDs: sessionIDs,
+		body: prom.NewDesc("fix_store_body_bytes",
+			"Size in bytes of the file holding the messages of the FIX session.", []string{"session_id"}, nil),
+		header: prom.NewDesc("fix_store_header_bytes",
+			"Size in bytes of the file indexing the messages of the FIX session.", []string{"session_id"}, nil),
+	}
+}
+
+// Describe implements prom.Collector.
+func (c *StoreFileCollector) Describe(ch chan<- *prom.Desc) {
+	ch <- c.body
+	ch <- c.header
+}
+
+// Collect implements prom.Collector.
+func (c *StoreFileCollector) Collect(ch chan<- prom.Metric) {
+	for _, sessionID := range c.sessionIDs {
+		store, err := quickfix.GetMessageStore(sessionID)
+		if err != nil {
+			continue
+		}
+		stats, ok := quickfix.StoreFileStats(store)
+		if !ok {
+			continue
+		}
+		if size, err := stats.BodyFileSize(); err == nil {
+			ch <- prom.MustNewConstMetric(c.body, prom.GaugeValue, float64(size), sessionID.String())
+		}
+		if size, err := stats.HeaderFileSize(); err == nil {
+			ch <- prom.MustNewConstMetric(c.header, prom.GaugeValue, float64(size), sessionID.String())
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/store/file"
+)
+
+func TestStoreFileCollector(t *testing.T) {
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=FILES
+TargetCompID=TARGET
+`, t.TempDir())))
+	require.Nil(t, err)
+	acceptor, err := quickfix.NewAcceptor(quickfix.ApplicationAdapter{}, file.NewStoreFactory(settings), settings, quickfix.NewNullLogFactory())
+	require.Nil(t, err)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	acceptor.AddListener(listener)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "FILES", TargetCompID: "TARGET"}
+	store, err := quickfix.GetMessageStore(sessionID)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+
+	reg := prom.NewRegistry()
+	reg.MustRegister(NewStoreFileCollector(sessionID, quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "UNKNOWN"}))
+	assert.Equal(t, 2, testutil.CollectAndCount(reg))
+	assert.Nil(t, testutil.GatherAndCompare(reg, strings.NewReader(`
+# HELP fix_store_body_bytes Size in bytes of the file holding the messages of the FIX session.
+# TYPE fix_store_body_bytes gauge
+fix_store_body_bytes{session_id="FIX.4.4:FILES->TARGET"} 5
+`), "fix_store_body_bytes"))
+}
@@ -0,0 +1,64 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package prometheus
+
+import (
+	"testing"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := prom.NewRegistry()
+	inner, err := quickfix.NewMemoryStoreFactory().Create(quickfix.SessionID{})
+	require.Nil(t, err)
+	store := quickfix.WrapStore(inner, MetricsMiddleware(reg))
+
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(2, []byte("world!")))
+	msgs, err := store.GetMessages(1, 2)
+	require.Nil(t, err)
+	require.Len(t, msgs, 2)
+	require.Nil(t, store.IterateMessages(2, 2, func([]byte) error { return nil }))
+
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+	assert.Equal(t, 2.0, counterValue(t, reg, "quickfix_store_saved_messages_total"))
+	assert.Equal(t, 11.0, counterValue(t, reg, "quickfix_store_written_bytes_total"))
+	assert.Equal(t, 3.0, counterValue(t, reg, "quickfix_store_retrieved_messages_total"))
+	assert.Equal(t, 17.0, counterValue(t, reg, "quickfix_store_read_bytes_total"))
+
+	// A second middleware on the same registry shares the counters.
+	other := quickfix.WrapStore(inner, MetricsMiddleware(reg))
+	require.Nil(t, other.SaveMessage(3, []byte("x")))
+	assert.Equal(t, 3.0, counterValue(t, reg, "quickfix_store_saved_messages_total"))
+}
+
+// counterValue returns the value of the named counter registered with reg.
+func counterValue(t *testing.T, reg *prom.Registry, name string) float64 {
+	families, err := reg.Gather()
+	require.Nil(t, err)
+	for _, family := range families {
+		if family.GetName() == name {
+			return family.GetMetric()[0].GetCounter().GetValue()
+		}
+	}
+	t.Fatalf("metric %s not registered", name)
+	return 0
+}
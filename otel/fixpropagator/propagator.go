@@ -0,0 +1,165 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package fixpropagator carries OpenTelemetry trace context in FIX messages, and traces the messages a session sends
+// and receives.
+package fixpropagator
+
+import (
+	"context"
+	"strconv"
+
+	"go.opentelemetry.io/otel/propagation"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// Default user-defined header tags carrying the W3C trace context.
+const (
+	TagTraceParent quickfix.Tag = 9610
+	TagTraceState  quickfix.Tag = 9611
+)
+
+// DefaultTags maps the W3C trace context keys to the header tags carrying them.
+var DefaultTags = map[string]quickfix.Tag{
+	"traceparent": TagTraceParent,
+	"tracestate":  TagTraceState,
+}
+
+// Propagator is a propagation.TextMapPropagator that stores the fields of another propagator under tag numbers, so
+// that they can be carried as FIX fields by a MessageCarrier. Fields of the wrapped propagator without a tag are
+// dropped.
+type Propagator struct {
+	propagator propagation.TextMapPropagator
+	tags       map[string]quickfix.Tag
+	keys       map[string]string
+}
+
+// NewPropagator returns a Propagator for the W3C trace context, carried in the DefaultTags.
+func NewPropagator() *Propagator {
+	return NewPropagatorWithTags(propagation.TraceContext{}, DefaultTags)
+}
+
+// NewPropagatorWithTags returns a Propagator storing each field of propagator under the tag mapped to its key.
+func NewPropagatorWithTags(propagator propagation.TextMapPropagator, tags map[string]quickfix.Tag) *Propagator {
+	p := &Propagator{propagator: propagator, tags: make(map[string]quickfix.Tag), keys: make(map[string]string)}
+	for key, tag := range tags {
+		p.tags[key] = tag
+		p.keys[strconv.Itoa(int(tag))] = key
+	}
+	return p
+}
+
+// Inject sets the fields of the wrapped propagator for ctx into carrier, keyed by tag number.
+func (p *Propagator) Inject(ctx context.Context, carrier propagation.TextMapCarrier) {
+	p.propagator.Inject(ctx, tagCarrier{carrier: carrier, p: p})
+}
+
+// Extract reads the fields of the wrapped propagator from carrier, keyed by tag number, into a copy of ctx.
+func (p *Propagator) Extract(ctx context.Context, carrier propagation.TextMapCarrier) context.Context {
+	return p.propagator.Extract(ctx, tagCarrier{carrier: carrier, p: p})
+}
+
+// Fields returns the tag numbers the propagator sets.
+func (p *Propagator) Fields() []string {
+	var fields []string
+	for _, key := range p.propagator.Fields() {
+		if tag, ok := p.tags[key]; ok {
+			fields = append(fields, strconv.Itoa(int(tag)))
+		}
+	}
+	return fields
+}
+
+// tagCarrier presents a carrier keyed by tag number to the wrapped propagator, which uses its own keys.
+type tagCarrier struct {
+	carrier propagation.TextMapCarrier
+	p       *Propagator
+}
+
+func (c tagCarrier) Get(key string) string {
+	tag, ok := c.p.tags[key]
+	if !ok {
+		return ""
+	}
+	return c.carrier.Get(strconv.Itoa(int(tag)))
+}
+
+func (c tagCarrier) Set(key, value string) {
+	if tag, ok := c.p.tags[key]; ok {
+		c.carrier.Set(strconv.Itoa(int(tag)), value)
+	}
+}
+
+func (c tagCarrier) Keys() []string {
+	var keys []string
+	for _, field := range c.carrier.Keys() {
+		if key, ok := c.p.keys[field]; ok {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// MessageCarrier is a propagation.TextMapCarrier storing values in the header of a message, keyed by tag number.
+// Values are also read from the body, where a message parsed without a data dictionary declaring the tags in the
+// header holds them. Sessions validating against a data dictionary must either declare the tags in its header or
+// set ValidateUserDefinedFields=N.
+type MessageCarrier struct {
+	Message *quickfix.Message
+}
+
+// Get returns the value of the header or body field whose tag number is key.
+func (c MessageCarrier) Get(key string) string {
+	tag, err := strconv.Atoi(key)
+	if err != nil {
+		return ""
+	}
+	if value, rej := c.Message.Header.GetString(quickfix.Tag(tag)); rej == nil {
+		return value
+	}
+	if value, rej := c.Message.Body.GetString(quickfix.Tag(tag)); rej == nil {
+		return value
+	}
+	return ""
+}
+
+// Set sets the header field whose tag number is key. Keys that are not tag numbers are ignored.
+func (c MessageCarrier) Set(key, value string) {
+	if tag, err := strconv.Atoi(key); err == nil {
+		c.Message.Header.SetString(quickfix.Tag(tag), value)
+	}
+}
+
+// Keys returns the tag numbers of the header fields.
+func (c MessageCarrier) Keys() []string {
+	tags := c.Message.Header.Tags()
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = strconv.Itoa(int(tag))
+	}
+	return keys
+}
+
+// Inject sets the trace context of ctx in the header of msg, using the default Propagator. Call it before
+// quickfix.SendToTarget to make the span of the send a child of the caller's span.
+func Inject(ctx context.Context, msg *quickfix.Message) {
+	NewPropagator().Inject(ctx, MessageCarrier{Message: msg})
+}
+
+// Extract returns a copy of ctx holding the trace context carried in the header of msg, using the default Propagator.
+func Extract(ctx context.Context, msg *quickfix.Message) context.Context {
+	return NewPropagator().Extract(ctx, MessageCarrier{Message: msg})
+}
@@ -0,0 +1,80 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package fixpropagator
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+func TestPropagatorInjectExtract(t *testing.T) {
+	tracer := sdktrace.NewTracerProvider().Tracer("test")
+	ctx, span := tracer.Start(context.Background(), "caller")
+	defer span.End()
+
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), "FIX.4.4").SetString(quickfix.Tag(35), "D")
+	Inject(ctx, msg)
+
+	traceParent, err := msg.Header.GetString(TagTraceParent)
+	require.Nil(t, err)
+	assert.Contains(t, traceParent, span.SpanContext().TraceID().String())
+	assert.Contains(t, traceParent, span.SpanContext().SpanID().String())
+
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), msg))
+	assert.True(t, extracted.IsRemote())
+	assert.Equal(t, span.SpanContext().TraceID(), extracted.TraceID())
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+
+	// A message parsed without a data dictionary holds the trace context in its body.
+	parsed := quickfix.NewMessage()
+	require.Nil(t, quickfix.ParseMessage(parsed, bytes.NewBufferString(msg.String())))
+	extracted = trace.SpanContextFromContext(Extract(context.Background(), parsed))
+	assert.Equal(t, span.SpanContext().SpanID(), extracted.SpanID())
+}
+
+func TestPropagatorFields(t *testing.T) {
+	assert.ElementsMatch(t, []string{"9610", "9611"}, NewPropagator().Fields())
+
+	p := NewPropagatorWithTags(NewPropagator().propagator, map[string]quickfix.Tag{"traceparent": 5001})
+	assert.Equal(t, []string{"5001"}, p.Fields())
+}
+
+func TestPropagatorExtractWithoutContext(t *testing.T) {
+	msg := quickfix.NewMessage()
+	extracted := trace.SpanContextFromContext(Extract(context.Background(), msg))
+	assert.False(t, extracted.IsValid())
+}
+
+func TestMessageCarrier(t *testing.T) {
+	msg := quickfix.NewMessage()
+	carrier := MessageCarrier{Message: msg}
+
+	carrier.Set("9610", "value")
+	carrier.Set("traceparent", "ignored")
+	assert.Equal(t, "value", carrier.Get("9610"))
+	assert.Equal(t, "", carrier.Get("9611"))
+	assert.Equal(t, "", carrier.Get("traceparent"))
+	assert.Equal(t, []string{"9610"}, carrier.Keys())
+}
@@ -0,0 +1,253 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package fixpropagator
+
+import (
+	"context"
+	"sync"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+const (
+	tagClOrdID             = quickfix.Tag(11)
+	tagMsgSeqNum           = quickfix.Tag(34)
+	tagMsgType             = quickfix.Tag(35)
+	tagOrdStatus           = quickfix.Tag(39)
+	tagRefSeqNum           = quickfix.Tag(45)
+	tagText                = quickfix.Tag(58)
+	tagExecType            = quickfix.Tag(150)
+	tagBusinessRejectRefID = quickfix.Tag(379)
+)
+
+const (
+	msgTypeReject                = "3"
+	msgTypeExecutionReport       = "8"
+	msgTypeOrderCancelReject     = "9"
+	msgTypeBusinessMessageReject = "j"
+)
+
+// Span attributes.
+const (
+	attrSessionID = attribute.Key("fix.session_id")
+	attrMsgType   = attribute.Key("fix.msg_type")
+	attrSeqNum    = attribute.Key("fix.seq_num")
+	attrClOrdID   = attribute.Key("fix.cl_ord_id")
+	attrExecType  = attribute.Key("fix.exec_type")
+	attrOrdStatus = attribute.Key("fix.ord_status")
+)
+
+// pendingSpan is the span of a sent message awaiting acknowledgement.
+type pendingSpan struct {
+	span    trace.Span
+	clOrdID string
+	seqNum  int
+}
+
+type pendingKey struct {
+	sessionID quickfix.SessionID
+	clOrdID   string
+	seqNum    int
+}
+
+// tracingApplication traces the app messages a session sends and receives.
+type tracingApplication struct {
+	quickfix.Application
+	tracer     trace.Tracer
+	propagator *Propagator
+
+	mu      sync.Mutex
+	pending map[pendingKey]*pendingSpan
+}
+
+// NewTracingApplication wraps app to trace the app messages of its sessions with tracer.
+//
+// A span is started for each app message sent, as a child of the trace context carried by the message, if any (see
+// Inject), and the context of the span is injected into the message in its place. The span of a message with a ClOrdID
+// ends when the counterparty acknowledges it with an ExecutionReport, or rejects it with an OrderCancelReject,
+// BusinessMessageReject or session level Reject; the span of any other message ends once it is sent. Spans still
+// awaiting acknowledgement when the session logs out end with an error status.
+//
+// A span is also started around FromApp for each app message received, as a child of the trace context it carries.
+// The context passed to FromAppContext, if app implements quickfix.ApplicationWithContext, holds the span.
+func NewTracingApplication(app quickfix.Application, tracer trace.Tracer) quickfix.ApplicationWithContext {
+	return &tracingApplication{
+		Application: app,
+		tracer:      tracer,
+		propagator:  NewPropagator(),
+		pending:     make(map[pendingKey]*pendingSpan),
+	}
+}
+
+func (a *tracingApplication) OnLogout(sessionID quickfix.SessionID) {
+	a.mu.Lock()
+	spans := make(map[*pendingSpan]bool)
+	for key, p := range a.pending {
+		if key.sessionID == sessionID {
+			delete(a.pending, key)
+			spans[p] = true
+		}
+	}
+	a.mu.Unlock()
+
+	for p := range spans {
+		p.span.SetStatus(codes.Error, "session logged out before acknowledgement")
+		p.span.End()
+	}
+	a.Application.OnLogout(sessionID)
+}
+
+func (a *tracingApplication) ToApp(msg *quickfix.Message, sessionID quickfix.SessionID) error {
+	carrier := MessageCarrier{Message: msg}
+	msgType, _ := msg.MsgType()
+	seqNum, _ := msg.Header.GetInt(tagMsgSeqNum)
+	clOrdID, _ := msg.Body.GetString(tagClOrdID)
+
+	ctx := a.propagator.Extract(context.Background(), carrier)
+	ctx, span := a.tracer.Start(ctx, "fix.send "+msgType,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			attrSessionID.String(sessionID.String()),
+			attrMsgType.String(msgType),
+			attrSeqNum.Int(seqNum),
+		),
+	)
+	if clOrdID != "" {
+		span.SetAttributes(attrClOrdID.String(clOrdID))
+	}
+	a.propagator.Inject(ctx, carrier)
+
+	if err := a.Application.ToApp(msg, sessionID); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		span.End()
+		return err
+	}
+
+	if clOrdID == "" {
+		span.End()
+		return nil
+	}
+
+	p := &pendingSpan{span: span, clOrdID: clOrdID, seqNum: seqNum}
+	a.mu.Lock()
+	// A message resent with the same ClOrdID supersedes the earlier span.
+	previous := a.pending[pendingKey{sessionID: sessionID, clOrdID: clOrdID}]
+	if previous != nil {
+		delete(a.pending, pendingKey{sessionID: sessionID, seqNum: previous.seqNum})
+	}
+	a.pending[pendingKey{sessionID: sessionID, clOrdID: clOrdID}] = p
+	a.pending[pendingKey{sessionID: sessionID, seqNum: seqNum}] = p
+	a.mu.Unlock()
+
+	if previous != nil {
+		previous.span.End()
+	}
+	return nil
+}
+
+func (a *tracingApplication) FromAdmin(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	if msg.IsMsgTypeOf(msgTypeReject) {
+		if refSeqNum, err := msg.Body.GetInt(tagRefSeqNum); err == nil {
+			a.ack(pendingKey{sessionID: sessionID, seqNum: refSeqNum}, msg, true)
+		}
+	}
+	return a.Application.FromAdmin(msg, sessionID)
+}
+
+func (a *tracingApplication) FromApp(msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	return a.FromAppContext(context.Background(), msg, sessionID)
+}
+
+func (a *tracingApplication) FromAppContext(ctx context.Context, msg *quickfix.Message, sessionID quickfix.SessionID) quickfix.MessageRejectError {
+	msgType, _ := msg.MsgType()
+	seqNum, _ := msg.Header.GetInt(tagMsgSeqNum)
+
+	ctx = a.propagator.Extract(ctx, MessageCarrier{Message: msg})
+	ctx, span := a.tracer.Start(ctx, "fix.receive "+msgType,
+		trace.WithSpanKind(trace.SpanKindConsumer),
+		trace.WithAttributes(
+			attrSessionID.String(sessionID.String()),
+			attrMsgType.String(msgType),
+			attrSeqNum.Int(seqNum),
+		),
+	)
+	defer span.End()
+
+	a.acknowledge(msgType, msg, sessionID)
+
+	var reject quickfix.MessageRejectError
+	if app, ok := a.Application.(quickfix.ApplicationWithContext); ok {
+		reject = app.FromAppContext(ctx, msg, sessionID)
+	} else {
+		reject = a.Application.FromApp(msg, sessionID)
+	}
+	if reject != nil {
+		span.SetStatus(codes.Error, reject.Error())
+	}
+	return reject
+}
+
+// acknowledge ends the span of the sent message an incoming app message acknowledges, if any.
+func (a *tracingApplication) acknowledge(msgType string, msg *quickfix.Message, sessionID quickfix.SessionID) {
+	switch msgType {
+	case msgTypeExecutionReport, msgTypeOrderCancelReject:
+		if clOrdID, err := msg.Body.GetString(tagClOrdID); err == nil {
+			a.ack(pendingKey{sessionID: sessionID, clOrdID: clOrdID}, msg, msgType == msgTypeOrderCancelReject)
+		}
+	case msgTypeBusinessMessageReject:
+		if refID, err := msg.Body.GetString(tagBusinessRejectRefID); err == nil {
+			a.ack(pendingKey{sessionID: sessionID, clOrdID: refID}, msg, true)
+		} else if refSeqNum, err := msg.Body.GetInt(tagRefSeqNum); err == nil {
+			a.ack(pendingKey{sessionID: sessionID, seqNum: refSeqNum}, msg, true)
+		}
+	}
+}
+
+// ack ends the pending span with the given key, if any.
+func (a *tracingApplication) ack(key pendingKey, msg *quickfix.Message, rejected bool) {
+	a.mu.Lock()
+	p, ok := a.pending[key]
+	if ok {
+		delete(a.pending, pendingKey{sessionID: key.sessionID, clOrdID: p.clOrdID})
+		delete(a.pending, pendingKey{sessionID: key.sessionID, seqNum: p.seqNum})
+	}
+	a.mu.Unlock()
+	if !ok {
+		return
+	}
+
+	if execType, err := msg.Body.GetString(tagExecType); err == nil {
+		p.span.SetAttributes(attrExecType.String(execType))
+	}
+	if ordStatus, err := msg.Body.GetString(tagOrdStatus); err == nil {
+		p.span.SetAttributes(attrOrdStatus.String(ordStatus))
+	}
+	if rejected {
+		description := "rejected"
+		if text, err := msg.Body.GetString(tagText); err == nil {
+			description = text
+		}
+		p.span.SetStatus(codes.Error, description)
+	} else {
+		p.span.SetStatus(codes.Ok, "")
+	}
+	p.span.End()
+}
@@ -0,0 +1,178 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package fixpropagator
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+type stubApp struct {
+	toAppErr   error
+	fromAppCtx context.Context
+	loggedOut  bool
+}
+
+func (a *stubApp) OnSessionCreate(quickfix.SessionID)            {}
+func (a *stubApp) OnCreate(quickfix.SessionID)                   {}
+func (a *stubApp) OnLogon(quickfix.SessionID)                    {}
+func (a *stubApp) OnLogout(quickfix.SessionID)                   { a.loggedOut = true }
+func (a *stubApp) ToAdmin(*quickfix.Message, quickfix.SessionID) {}
+func (a *stubApp) ToApp(*quickfix.Message, quickfix.SessionID) error {
+	return a.toAppErr
+}
+func (a *stubApp) FromAdmin(*quickfix.Message, quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+func (a *stubApp) FromApp(*quickfix.Message, quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+func (a *stubApp) FromAppContext(ctx context.Context, _ *quickfix.Message, _ quickfix.SessionID) quickfix.MessageRejectError {
+	a.fromAppCtx = ctx
+	return nil
+}
+
+var testSessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+func newTracingApp(inner quickfix.Application) (quickfix.ApplicationWithContext, *tracetest.SpanRecorder, trace.Tracer) {
+	recorder := tracetest.NewSpanRecorder()
+	tracer := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)).Tracer("test")
+	return NewTracingApplication(inner, tracer), recorder, tracer
+}
+
+func newAppMessage(msgType string, seqNum int, fields map[quickfix.Tag]string) *quickfix.Message {
+	msg := quickfix.NewMessage()
+	msg.Header.SetString(quickfix.Tag(8), "FIX.4.4").SetString(tagMsgType, msgType).SetInt(tagMsgSeqNum, seqNum)
+	for tag, value := range fields {
+		msg.Body.SetString(tag, value)
+	}
+	return msg
+}
+
+func endedSpan(t *testing.T, recorder *tracetest.SpanRecorder, name string) sdktrace.ReadOnlySpan {
+	for _, span := range recorder.Ended() {
+		if span.Name() == name {
+			return span
+		}
+	}
+	t.Fatalf("span %s not ended", name)
+	return nil
+}
+
+func TestTracingApplicationExecutionReportEndsSpan(t *testing.T) {
+	inner := new(stubApp)
+	app, recorder, tracer := newTracingApp(inner)
+
+	callerCtx, caller := tracer.Start(context.Background(), "caller")
+	order := newAppMessage("D", 2, map[quickfix.Tag]string{tagClOrdID: "ORD-1"})
+	Inject(callerCtx, order)
+	require.Nil(t, app.ToApp(order, testSessionID))
+	caller.End()
+
+	// The send span awaits the acknowledgement, and the order carries its context.
+	assert.Len(t, recorder.Ended(), 1)
+	require.Len(t, recorder.Started(), 2)
+	send := recorder.Started()[1]
+	assert.Equal(t, "fix.send D", send.Name())
+	assert.Equal(t, caller.SpanContext().SpanID(), send.Parent().SpanID())
+	assert.Equal(t, send.SpanContext().SpanID(), trace.SpanContextFromContext(Extract(context.Background(), order)).SpanID())
+
+	report := newAppMessage("8", 7, map[quickfix.Tag]string{tagClOrdID: "ORD-1", tagExecType: "0", tagOrdStatus: "0"})
+	Inject(trace.ContextWithSpanContext(context.Background(), send.SpanContext()), report)
+	require.Nil(t, app.FromApp(report, testSessionID))
+
+	ended := endedSpan(t, recorder, "fix.send D")
+	assert.Equal(t, codes.Ok, ended.Status().Code)
+	assert.Contains(t, ended.Attributes(), attrExecType.String("0"))
+	assert.Contains(t, ended.Attributes(), attrClOrdID.String("ORD-1"))
+
+	receive := endedSpan(t, recorder, "fix.receive 8")
+	assert.Equal(t, trace.SpanKindConsumer, receive.SpanKind())
+	assert.Equal(t, send.SpanContext().SpanID(), receive.Parent().SpanID())
+	assert.Equal(t, receive.SpanContext().SpanID(), trace.SpanContextFromContext(inner.fromAppCtx).SpanID())
+}
+
+func TestTracingApplicationRejects(t *testing.T) {
+	var tests = []struct {
+		name   string
+		reject *quickfix.Message
+		admin  bool
+	}{
+		{"business reject by ref id", newAppMessage("j", 5, map[quickfix.Tag]string{tagBusinessRejectRefID: "ORD-1", tagText: "no"}), false},
+		{"business reject by seq num", newAppMessage("j", 5, map[quickfix.Tag]string{tagRefSeqNum: "2", tagText: "no"}), false},
+		{"order cancel reject", newAppMessage("9", 5, map[quickfix.Tag]string{tagClOrdID: "ORD-1", tagText: "no"}), false},
+		{"session reject", newAppMessage("3", 5, map[quickfix.Tag]string{tagRefSeqNum: "2", tagText: "no"}), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			app, recorder, _ := newTracingApp(new(stubApp))
+			require.Nil(t, app.ToApp(newAppMessage("D", 2, map[quickfix.Tag]string{tagClOrdID: "ORD-1"}), testSessionID))
+
+			if test.admin {
+				require.Nil(t, app.FromAdmin(test.reject, testSessionID))
+			} else {
+				require.Nil(t, app.FromApp(test.reject, testSessionID))
+			}
+
+			ended := endedSpan(t, recorder, "fix.send D")
+			assert.Equal(t, codes.Error, ended.Status().Code)
+			assert.Equal(t, "no", ended.Status().Description)
+		})
+	}
+}
+
+func TestTracingApplicationUnacknowledgedMessage(t *testing.T) {
+	app, recorder, _ := newTracingApp(new(stubApp))
+	require.Nil(t, app.ToApp(newAppMessage("V", 2, nil), testSessionID))
+
+	ended := endedSpan(t, recorder, "fix.send V")
+	assert.Equal(t, trace.SpanKindProducer, ended.SpanKind())
+	assert.Equal(t, codes.Unset, ended.Status().Code)
+}
+
+func TestTracingApplicationToAppError(t *testing.T) {
+	app, recorder, _ := newTracingApp(&stubApp{toAppErr: errors.New("do not send")})
+	assert.NotNil(t, app.ToApp(newAppMessage("D", 2, map[quickfix.Tag]string{tagClOrdID: "ORD-1"}), testSessionID))
+
+	ended := endedSpan(t, recorder, "fix.send D")
+	assert.Equal(t, codes.Error, ended.Status().Code)
+}
+
+func TestTracingApplicationLogoutEndsPendingSpans(t *testing.T) {
+	inner := new(stubApp)
+	app, recorder, _ := newTracingApp(inner)
+	require.Nil(t, app.ToApp(newAppMessage("D", 2, map[quickfix.Tag]string{tagClOrdID: "ORD-1"}), testSessionID))
+	require.Nil(t, app.ToApp(newAppMessage("D", 3, map[quickfix.Tag]string{tagClOrdID: "ORD-2"}), testSessionID))
+
+	app.OnLogout(testSessionID)
+
+	assert.True(t, inner.loggedOut)
+	require.Len(t, recorder.Ended(), 2)
+	for _, span := range recorder.Ended() {
+		assert.Equal(t, codes.Error, span.Status().Code)
+	}
+}
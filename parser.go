@@ -31,6 +31,11 @@ type parser struct {
 	bigBuffer, buffer []byte
 	reader            io.Reader
 	lastRead          time.Time
+
+	// bufSize is the initial size of bigBuffer, defaultBufSize if zero.
+	bufSize int
+	// retain keeps the bytes of the messages returned by nextMessage valid, by never reusing bigBuffer.
+	retain bool
 }
 
 func newParser(reader io.Reader) *parser {
@@ -40,12 +45,21 @@ func newParser(reader io.Reader) *parser {
 func (p *parser) readMore() (int, error) {
 	if len(p.buffer) == cap(p.buffer) {
 		var newBuffer []byte
+		bufSize := p.bufSize
+		if bufSize <= 0 {
+			bufSize = defaultBufSize
+		}
 		switch {
 		// Initialize the parser.
 		case len(p.bigBuffer) == 0:
-			p.bigBuffer = make([]byte, defaultBufSize)
+			p.bigBuffer = make([]byte, bufSize)
 			newBuffer = p.bigBuffer[0:0]
 
+		// Move buffer to a new big buffer, leaving the previous messages intact.
+		case p.retain:
+			p.bigBuffer = make([]byte, max(bufSize, 2*len(p.buffer)))
+			newBuffer = p.bigBuffer[0:len(p.buffer)]
+
 		// Shift buffer back to the start of bigBuffer.
 		case 2*len(p.buffer) <= len(p.bigBuffer):
 			newBuffer = p.bigBuffer[0:len(p.buffer)]
@@ -140,26 +154,38 @@ func (p *parser) jumpLength() (int, error) {
 }
 
 func (p *parser) ReadMessage() (msgBytes *bytes.Buffer, err error) {
-	start, err := p.findStart()
+	msg, err := p.nextMessage()
 	if err != nil {
 		return
 	}
+
+	msgBytes = new(bytes.Buffer)
+	msgBytes.Reset()
+	msgBytes.Write(msg)
+
+	return
+}
+
+// nextMessage returns the bytes of the next message, which are only valid until the next read unless
+// retain is set.
+func (p *parser) nextMessage() ([]byte, error) {
+	start, err := p.findStart()
+	if err != nil {
+		return nil, err
+	}
 	p.buffer = p.buffer[start:]
 
 	index, err := p.jumpLength()
 	if err != nil {
-		return
+		return nil, err
 	}
 
 	index, err = p.findEndAfterOffset(index)
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	msgBytes = new(bytes.Buffer)
-	msgBytes.Reset()
-	msgBytes.Write(p.buffer[:index])
+	msg := p.buffer[:index:index]
 	p.buffer = p.buffer[index:]
-
-	return
+	return msg, nil
 }
@@ -0,0 +1,123 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+)
+
+// NewPipeTransport returns the two ends of a synchronous, in-memory connection, e.g. to connect an
+// Initiator and an Acceptor in the same process without binding to a port. serverConn is passed to
+// Acceptor.ServeConn and clientConn to an Initiator through ConnDialer.
+func NewPipeTransport() (serverConn, clientConn net.Conn) {
+	return net.Pipe()
+}
+
+// ServeConn makes the Acceptor handle conn like a connection accepted from a listener, e.g. the server
+// end of NewPipeTransport. Like AddListener, it must be called before Start.
+func (a *Acceptor) ServeConn(conn net.Conn) {
+	a.AddListener(newConnListener(conn))
+}
+
+// connListener is a net.Listener accepting a single connection.
+type connListener struct {
+	mu       sync.Mutex
+	conn     net.Conn
+	addr     net.Addr
+	done     chan struct{}
+	doneOnce sync.Once
+}
+
+// connListenerCount numbers the addresses of connListeners, as the Acceptor keys its listeners by address
+// and the ends of several pipes share theirs.
+var connListenerCount atomic.Int64
+
+// connListenerAddr is the address of a connListener.
+type connListenerAddr struct {
+	net.Addr
+	id int64
+}
+
+func (a connListenerAddr) String() string {
+	return fmt.Sprintf("%s#%d", a.Addr.String(), a.id)
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	addr := connListenerAddr{Addr: conn.LocalAddr(), id: connListenerCount.Add(1)}
+	return &connListener{conn: conn, addr: addr, done: make(chan struct{})}
+}
+
+// Accept returns the connection on the first call, and blocks until the listener is closed on later ones.
+func (l *connListener) Accept() (net.Conn, error) {
+	l.mu.Lock()
+	conn := l.conn
+	l.conn = nil
+	l.mu.Unlock()
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+// Close unblocks Accept, and closes the connection unless it was accepted.
+func (l *connListener) Close() error {
+	l.doneOnce.Do(func() { close(l.done) })
+	l.mu.Lock()
+	conn := l.conn
+	l.conn = nil
+	l.mu.Unlock()
+	if conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.addr
+}
+
+// errConnDialed is returned by a ConnDialer asked for a connection again.
+var errConnDialed = errors.New("connection already dialed")
+
+// connDialer hands out a single connection.
+type connDialer struct {
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// ConnDialer returns a SessionDialer connecting the first session that dials it through conn, e.g. the
+// client end of NewPipeTransport, regardless of the address dialed. Later dials fail, so a session
+// does not reconnect once conn is closed.
+func ConnDialer(conn net.Conn) SessionDialer {
+	return &connDialer{conn: conn}
+}
+
+func (d *connDialer) DialSession(_ context.Context, _ SessionID, _ string) (net.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.conn == nil {
+		return nil, errConnDialed
+	}
+	conn := d.conn
+	d.conn = nil
+	return conn, nil
+}
@@ -0,0 +1,114 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type logonApp struct {
+	logons chan SessionID
+}
+
+func (a logonApp) OnSessionCreate(SessionID)                        {}
+func (a logonApp) OnCreate(SessionID)                               {}
+func (a logonApp) OnLogon(sessionID SessionID)                      { a.logons <- sessionID }
+func (a logonApp) OnLogout(SessionID)                               {}
+func (a logonApp) ToAdmin(*Message, SessionID)                      {}
+func (a logonApp) ToApp(*Message, SessionID) error                  { return nil }
+func (a logonApp) FromAdmin(*Message, SessionID) MessageRejectError { return nil }
+func (a logonApp) FromApp(*Message, SessionID) MessageRejectError   { return nil }
+
+func TestPipeTransport(t *testing.T) {
+	serverConn, clientConn := NewPipeTransport()
+
+	acceptorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=PIPEACC
+TargetCompID=PIPEINIT
+`))
+	require.Nil(t, err)
+	acceptorApp := logonApp{logons: make(chan SessionID, 1)}
+	acceptor, err := NewAcceptor(acceptorApp, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	acceptor.ServeConn(serverConn)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	initiatorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=PIPEINIT
+TargetCompID=PIPEACC
+HeartBtInt=30
+SocketConnectHost=pipe
+SocketConnectPort=0
+`))
+	require.Nil(t, err)
+	initiatorApp := logonApp{logons: make(chan SessionID, 1)}
+	initiator, err := NewInitiator(initiatorApp, NewMemoryStoreFactory(), initiatorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	initiator.SetSessionDialer(ConnDialer(clientConn))
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	for _, app := range []logonApp{acceptorApp, initiatorApp} {
+		select {
+		case <-app.logons:
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for logons")
+		}
+	}
+}
+
+func TestConnDialer(t *testing.T) {
+	serverConn, clientConn := NewPipeTransport()
+	defer serverConn.Close()
+	dialer := ConnDialer(clientConn)
+
+	conn, err := dialer.DialSession(context.Background(), SessionID{}, "pipe:0")
+	require.Nil(t, err)
+	assert.Equal(t, clientConn, conn)
+	_, err = dialer.DialSession(context.Background(), SessionID{}, "pipe:0")
+	assert.Equal(t, errConnDialed, err)
+}
+
+func TestConnListener(t *testing.T) {
+	serverConn, clientConn := NewPipeTransport()
+	defer clientConn.Close()
+	listener := newConnListener(serverConn)
+	assert.NotEqual(t, listener.Addr().String(), newConnListener(serverConn).Addr().String())
+
+	conn, err := listener.Accept()
+	require.Nil(t, err)
+	assert.Equal(t, serverConn, conn)
+
+	accepted := make(chan error)
+	go func() {
+		_, err := listener.Accept()
+		accepted <- err
+	}()
+	require.Nil(t, listener.Close())
+	assert.ErrorIs(t, <-accepted, net.ErrClosed)
+}
@@ -0,0 +1,84 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+// PrettyPrint returns the fields of msg one per line in wire order, with their names and the names of
+// enumerated values resolved from dd, e.g.
+//
+//	8 (BeginString) = FIX.4.2
+//	35 (MsgType) = D (NewOrderSingle)
+//	54 (Side) = 1 (BUY)
+//	1234 (unknown) = X
+//
+// Tags not defined by dd, or all tags when dd is nil, are named unknown. BodyLength and CheckSum are only
+// printed once the message has been parsed or built.
+func PrettyPrint(msg *Message, dd *datadictionary.DataDictionary) string {
+	var fields []TagValue
+	fields = msg.Header.appendFields(fields)
+	fields = msg.Body.appendFields(fields)
+	fields = msg.Trailer.appendFields(fields)
+
+	var b strings.Builder
+	for _, tv := range fields {
+		b.WriteString(strconv.Itoa(int(tv.tag)))
+		b.WriteString(" (")
+		b.WriteString(prettyFieldName(dd, tv.tag))
+		b.WriteString(") = ")
+		b.Write(tv.value)
+		if name, ok := prettyValueName(dd, tv.tag, string(tv.value)); ok {
+			b.WriteString(" (")
+			b.WriteString(name)
+			b.WriteByte(')')
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+func prettyFieldName(dd *datadictionary.DataDictionary, tag Tag) string {
+	if dd != nil {
+		if fieldType, ok := dd.FieldTypeByTag[int(tag)]; ok {
+			return fieldType.Name()
+		}
+	}
+	return "unknown"
+}
+
+// prettyValueName returns the symbolic name of an enumerated value. MsgType values are named after the
+// message they identify.
+func prettyValueName(dd *datadictionary.DataDictionary, tag Tag, value string) (string, bool) {
+	if dd == nil {
+		return "", false
+	}
+	if tag == tagMsgType {
+		if msgDef, ok := dd.Messages[value]; ok {
+			return msgDef.Name, true
+		}
+	}
+	if fieldType, ok := dd.FieldTypeByTag[int(tag)]; ok {
+		if enum, ok := fieldType.Enums[value]; ok {
+			return enum.Description, true
+		}
+	}
+	return "", false
+}
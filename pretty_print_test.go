@@ -0,0 +1,66 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+func TestPrettyPrint(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	require.Nil(t, err)
+
+	msg := NewMessage()
+	msg.Header.SetString(tagBeginString, BeginStringFIX42)
+	msg.Header.SetString(tagMsgType, "D")
+	msg.Body.SetString(Tag(54), "1")
+	msg.Body.SetString(Tag(55), "AAPL")
+	msg.Body.SetString(Tag(1234), "X")
+
+	assert.Equal(t, "8 (BeginString) = FIX.4.2\n"+
+		"35 (MsgType) = D (NewOrderSingle)\n"+
+		"54 (Side) = 1 (BUY)\n"+
+		"55 (Symbol) = AAPL\n"+
+		"1234 (unknown) = X\n", PrettyPrint(msg, dict))
+
+	assert.Equal(t, "8 (unknown) = FIX.4.2\n"+
+		"35 (unknown) = D\n"+
+		"54 (unknown) = 1\n"+
+		"55 (unknown) = AAPL\n"+
+		"1234 (unknown) = X\n", PrettyPrint(msg, nil))
+}
+
+func TestPrettyPrintParsedMessage(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	require.Nil(t, err)
+
+	msg := NewMessage()
+	require.Nil(t, ParseMessage(msg, bytes.NewBufferString("8=FIX.4.29=2435=034=249=TW56=ISLD10=212")))
+
+	assert.Equal(t, "8 (BeginString) = FIX.4.2\n"+
+		"9 (BodyLength) = 24\n"+
+		"35 (MsgType) = 0 (Heartbeat)\n"+
+		"34 (MsgSeqNum) = 2\n"+
+		"49 (SenderCompID) = TW\n"+
+		"56 (TargetCompID) = ISLD\n"+
+		"10 (CheckSum) = 212\n", PrettyPrint(msg, dict))
+}
@@ -67,7 +67,7 @@ func (s *QuickFIXSuite) MessageEqualsBytes(expectedBytes []byte, msg *Message) {
 	s.Equal(string(actualBytes), string(expectedBytes))
 }
 
-// MockStore wraps a memory store and mocks Refresh for convenience.
+// MockStore wraps a memory store and mocks Refresh and HealthCheck for convenience.
 type MockStore struct {
 	mock.Mock
 	memoryStore
@@ -77,6 +77,10 @@ func (s *MockStore) Refresh() error {
 	return s.Called().Error(0)
 }
 
+func (s *MockStore) HealthCheck() error {
+	return s.Called().Error(0)
+}
+
 type MockApp struct {
 	mock.Mock
 
@@ -85,6 +89,9 @@ type MockApp struct {
 	lastToApp       *Message
 }
 
+func (e *MockApp) OnSessionCreate(_ SessionID) {
+}
+
 func (e *MockApp) OnCreate(_ SessionID) {
 }
 
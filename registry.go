@@ -16,6 +16,7 @@
 package quickfix
 
 import (
+	"context"
 	"errors"
 	"sync"
 )
@@ -55,13 +56,129 @@ func Send(m Messagable) (err error) {
 
 // SendToTarget sends a message based on the sessionID. Convenient for use in FromApp since it provides a session ID for incoming messages.
 func SendToTarget(m Messagable, sessionID SessionID) error {
+	return SendToTargetContext(context.Background(), m, sessionID)
+}
+
+// SendToTargetContext behaves like SendToTarget, but returns ctx.Err() if ctx is done before the message can be queued,
+// e.g. while the session is blocked writing to a slow connection.
+func SendToTargetContext(ctx context.Context, m Messagable, sessionID SessionID) error {
 	msg := m.ToMessage()
 	session, ok := lookupSession(sessionID)
 	if !ok {
 		return errUnknownSession
 	}
 
-	return session.queueForSend(msg)
+	return session.queueForSendContext(ctx, msg)
+}
+
+// Session is a reference to a registered session, returned by LookupSession. Sending through it saves the session
+// lookup of SendToTarget, e.g. for applications sending many messages to the same session.
+type Session struct {
+	session *session
+}
+
+// LookupSession returns a reference to the session matching the session id. The reference stays valid while the
+// session is running, also after UnregisterSession.
+func LookupSession(sessionID SessionID) (*Session, error) {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return nil, errUnknownSession
+	}
+	return &Session{session: session}, nil
+}
+
+// SessionID returns the id of the session.
+func (s *Session) SessionID() SessionID {
+	return s.session.sessionID
+}
+
+// Send sends msg on the session, as SendToTarget does for the session id.
+func (s *Session) Send(msg *Message) error {
+	return s.session.queueForSend(msg)
+}
+
+// RegisterObserver registers obs to be notified of the state changes and message traffic of the session.
+func (s *Session) RegisterObserver(obs SessionObserver) {
+	s.session.registerObserver(obs)
+}
+
+// RegisterObserver registers obs with the session matching the session id, as Session.RegisterObserver does.
+func RegisterObserver(sessionID SessionID, obs SessionObserver) error {
+	session, err := LookupSession(sessionID)
+	if err != nil {
+		return err
+	}
+	session.RegisterObserver(obs)
+	return nil
+}
+
+// SetEncryptionProvider sets the EncryptionProvider encrypting the EncryptedTags of the session matching the session id.
+// It should be set before the session is started.
+func SetEncryptionProvider(sessionID SessionID, provider EncryptionProvider) error {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return errUnknownSession
+	}
+	session.setEncryptionProvider(provider)
+	return nil
+}
+
+// ReplayMessages resends the messages from beginSeqNum to endSeqNum, as if the counterparty had requested them
+// with a ResendRequest: application messages are resent with PossDupFlag set, and admin messages are replaced with a
+// SequenceReset-GapFill. An endSeqNum of 0 replays up to the last message sent. The sequence numbers of the session
+// are left unchanged. The session must be running and logged on.
+func ReplayMessages(sessionID SessionID, beginSeqNum, endSeqNum int) error {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return errUnknownSession
+	}
+	if !session.loggedOnState.Load() {
+		return errors.New("Not logged on")
+	}
+
+	rep := make(chan error, 1)
+	return session.request(replayReq{beginSeqNum: beginSeqNum, endSeqNum: endSeqNum, err: rep}, rep)
+}
+
+// ForceLogout disconnects the session matching the session id, e.g. from a misbehaving counterparty, without
+// stopping it. A logged on session sends a Logout with reason as its Text(58), then waits up to 5 seconds for the
+// counterparty's Logout before closing the connection regardless. A session not yet logged on is disconnected
+// right away. ForceLogout returns once the Logout is sent; an initiator reconnects as usual afterwards. It fails
+// if the session is not running.
+func ForceLogout(sessionID SessionID, reason string) error {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return errUnknownSession
+	}
+
+	rep := make(chan error, 1)
+	return session.request(forceLogoutReq{reason: reason, err: rep}, rep)
+}
+
+// QueueDepth returns the number of messages the session holds while they wait to be written to the counterparty.
+// Unlike a QueueDepthObserver, it can be polled at any time; it reads a counter kept up to date by the session,
+// without locking the send queue or allocating. Messages handed to the connection are no longer counted, as
+// the connection takes them one at a time.
+func (s *Session) QueueDepth() int {
+	return int(s.session.queueDepth.Load())
+}
+
+// QueueDepth returns the queue depth of the session matching the session id, as Session.QueueDepth does.
+func QueueDepth(sessionID SessionID) (int, error) {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return 0, errUnknownSession
+	}
+	return int(session.queueDepth.Load()), nil
+}
+
+// GetLatencyTracker returns the HeartbeatLatencyTracker of the session, or nil if the session is unknown.
+func GetLatencyTracker(sessionID SessionID) *HeartbeatLatencyTracker {
+	session, ok := lookupSession(sessionID)
+	if !ok {
+		return nil
+	}
+	return session.latencyTracker
 }
 
 // ResetSession resets session's sequence numbers.
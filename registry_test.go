@@ -0,0 +1,188 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendToTargetContext(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "CTX_SENDER", TargetCompID: "CTX_TARGET"}
+	s := &session{sessionID: sessionID}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	// The session holds the send lock, e.g. while blocked writing to the connection.
+	s.sendMutex.Lock()
+	defer s.sendMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := SendToTargetContext(ctx, NewMessage(), sessionID)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	err = SendToTargetContext(ctx, NewMessage(), sessionID)
+	assert.Equal(t, context.Canceled, err)
+
+	err = SendToTargetContext(context.Background(), NewMessage(), SessionID{BeginString: "FIX.4.4", SenderCompID: "NONE", TargetCompID: "NONE"})
+	assert.Equal(t, errUnknownSession, err)
+}
+
+func TestLookupSession(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "LOOKUP_SENDER", TargetCompID: "LOOKUP_TARGET"}
+	_, err := LookupSession(sessionID)
+	assert.Equal(t, errUnknownSession, err)
+
+	s := &session{sessionID: sessionID}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	ref, err := LookupSession(sessionID)
+	require.Nil(t, err)
+	assert.Equal(t, sessionID, ref.SessionID())
+	assert.Same(t, s, ref.session)
+}
+
+func TestContextMutex(t *testing.T) {
+	var m contextMutex
+	require.Nil(t, m.LockContext(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, m.LockContext(ctx))
+
+	locked := make(chan struct{})
+	go func() {
+		m.Lock()
+		close(locked)
+	}()
+	m.Unlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("mutex was not released")
+	}
+	m.Unlock()
+}
+
+func TestRegisterObserverUnknownSession(t *testing.T) {
+	err := RegisterObserver(SessionID{BeginString: "FIX.4.4", SenderCompID: "NONE", TargetCompID: "NONE"}, new(recordingObserver))
+	assert.Equal(t, errUnknownSession, err)
+}
+
+func TestSessionRegisterObserver(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "OBSERVER_SENDER", TargetCompID: "OBSERVER_TARGET"}
+	s := &session{sessionID: sessionID}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	ref, err := LookupSession(sessionID)
+	require.Nil(t, err)
+	first, second := new(recordingObserver), new(recordingObserver)
+	ref.RegisterObserver(first)
+	require.Nil(t, RegisterObserver(sessionID, second))
+
+	s.notifyObservers(func(obs SessionObserver) { obs.OnMessageSent(sessionID, "0") })
+	assert.Equal(t, []string{"0"}, first.sent)
+	assert.Equal(t, []string{"0"}, second.sent)
+}
+
+func TestGetLatencyTracker(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "LATENCY_SENDER", TargetCompID: "LATENCY_TARGET"}
+	assert.Nil(t, GetLatencyTracker(sessionID))
+
+	s := &session{sessionID: sessionID, latencyTracker: newHeartbeatLatencyTracker()}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+	assert.Same(t, s.latencyTracker, GetLatencyTracker(sessionID))
+}
+
+func TestQueueDepth(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "QUEUE_SENDER", TargetCompID: "QUEUE_TARGET"}
+	_, err := QueueDepth(sessionID)
+	assert.Equal(t, errUnknownSession, err)
+
+	s := &session{sessionID: sessionID}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	s.toSend = append(s.toSend, []byte("8=FIX.4.4"), []byte("8=FIX.4.4"))
+	s.queueDepthChanged()
+	depth, err := QueueDepth(sessionID)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, depth)
+	assert.Zero(t, testing.AllocsPerRun(10, func() { _, _ = QueueDepth(sessionID) }))
+
+	ref, err := LookupSession(sessionID)
+	require.Nil(t, err)
+	assert.Equal(t, 2, ref.QueueDepth())
+	assert.Zero(t, testing.AllocsPerRun(10, func() { _ = ref.QueueDepth() }))
+}
+
+func TestReplayMessagesUnknownSession(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "REPLAY_SENDER", TargetCompID: "REPLAY_TARGET"}
+	assert.Equal(t, errUnknownSession, ReplayMessages(sessionID, 1, 0))
+}
+
+func TestReplayMessagesSessionNotRunning(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "REPLAY_SENDER", TargetCompID: "REPLAY_TARGET"}
+	s := &session{sessionID: sessionID, admin: make(chan interface{})}
+	s.loggedOnState.Store(true)
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	// Never started.
+	assert.Equal(t, errSessionNotRunning, ReplayMessages(sessionID, 1, 0))
+
+	// Stopped without taking the request.
+	loopDone := make(chan struct{})
+	s.loopDone.Store(&loopDone)
+	close(loopDone)
+	assert.Equal(t, errSessionNotRunning, ReplayMessages(sessionID, 1, 0))
+}
+
+func TestForceLogoutUnknownSession(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "FORCE_SENDER", TargetCompID: "FORCE_TARGET"}
+	assert.Equal(t, errUnknownSession, ForceLogout(sessionID, "rogue counterparty"))
+}
+
+func TestForceLogoutSessionNotRunning(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "FORCE_SENDER", TargetCompID: "FORCE_TARGET"}
+	s := &session{sessionID: sessionID, admin: make(chan interface{})}
+	require.Nil(t, registerSession(s))
+	defer func() { _ = UnregisterSession(sessionID) }()
+
+	// Never started.
+	assert.Equal(t, errSessionNotRunning, ForceLogout(sessionID, "rogue counterparty"))
+
+	// Stopped without taking the request.
+	loopDone := make(chan struct{})
+	s.loopDone.Store(&loopDone)
+	close(loopDone)
+	assert.Equal(t, errSessionNotRunning, ForceLogout(sessionID, "rogue counterparty"))
+}
+
+func TestSetEncryptionProviderUnknownSession(t *testing.T) {
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "ENCRYPT_SENDER", TargetCompID: "ENCRYPT_TARGET"}
+	assert.Equal(t, errUnknownSession, SetEncryptionProvider(sessionID, NewNullEncryptionProvider()))
+}
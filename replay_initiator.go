@@ -0,0 +1,243 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// ReplayInitiator acts as the counterparty of a FIX session by replaying a session log, e.g. to run a
+// historical session against an acceptor in an integration test. Each line of the log holds one message,
+// prefixed with "OUT:" for a message the ReplayInitiator sends, or "IN:" for a message it expects to
+// receive:
+//
+//	# Comments and blank lines are ignored.
+//	OUT:8=FIX.4.2|9=67|35=A|34=1|49=CLIENT|52=20200101-00:00:00.000|56=SERVER|98=0|108=30|10=109|
+//	IN:8=FIX.4.2|9=67|35=A|34=1|49=SERVER|52=20200101-00:00:00.001|56=CLIENT|98=0|108=30|10=110|
+//
+// Messages are SOH delimited, or '|' delimited as produced by Message.ToDelimitedString. Outbound messages
+// are sent as logged, with a current SendingTime. Each inbound message must have the MsgType and MsgSeqNum
+// of the logged one, otherwise the replay fails.
+type ReplayInitiator struct {
+	sessionID     SessionID
+	address       string
+	dialer        proxy.ContextDialer
+	sessionDialer SessionDialer
+	app           Application
+	steps         []replayStep
+}
+
+// replayStep is a message of the session log.
+type replayStep struct {
+	line     int
+	outbound bool
+	msg      *Message
+}
+
+const (
+	replayOutPrefix = "OUT:"
+	replayInPrefix  = "IN:"
+)
+
+// NewReplayInitiator creates a ReplayInitiator replaying logFile. The settings must hold a single session,
+// connected to SocketConnectHost and SocketConnectPort.
+func NewReplayInitiator(logFile string, settings *Settings, app Application) (*ReplayInitiator, error) {
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	sessionSettings := settings.SessionSettings()
+	if len(sessionSettings) != 1 {
+		return nil, fmt.Errorf("replay requires exactly one session, found %d", len(sessionSettings))
+	}
+
+	r := &ReplayInitiator{app: app}
+	for sessionID, s := range sessionSettings {
+		host, err := s.Setting(config.SocketConnectHost)
+		if err != nil {
+			return nil, err
+		}
+		port, err := s.Setting(config.SocketConnectPort)
+		if err != nil {
+			return nil, err
+		}
+		if r.dialer, err = loadDialerConfig(s); err != nil {
+			return nil, err
+		}
+		r.sessionID = sessionID
+		r.address = net.JoinHostPort(host, port)
+	}
+
+	var err error
+	if r.steps, err = readReplayLog(logFile); err != nil {
+		return nil, err
+	}
+
+	app.OnCreate(r.sessionID)
+	return r, nil
+}
+
+// readReplayLog parses the messages of a session log.
+func readReplayLog(logFile string) ([]replayStep, error) {
+	f, err := os.Open(logFile)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var steps []replayStep
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimRight(scanner.Bytes(), "\r")
+		if len(bytes.TrimSpace(text)) == 0 || text[0] == '#' {
+			continue
+		}
+
+		step := replayStep{line: line}
+		switch {
+		case bytes.HasPrefix(text, []byte(replayOutPrefix)):
+			step.outbound = true
+			text = text[len(replayOutPrefix):]
+		case bytes.HasPrefix(text, []byte(replayInPrefix)):
+			text = text[len(replayInPrefix):]
+		default:
+			return nil, fmt.Errorf("%s line %d: expected %s or %s prefix", logFile, line, replayOutPrefix, replayInPrefix)
+		}
+
+		delim := byte('|')
+		if bytes.IndexByte(text, '\001') >= 0 {
+			delim = '\001'
+		}
+		if step.msg, err = ParseDelimitedBytes(text, delim); err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", logFile, line, err)
+		}
+		steps = append(steps, step)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// SetSessionDialer replaces the dialer configured by the session settings, as Initiator.SetSessionDialer.
+func (r *ReplayInitiator) SetSessionDialer(dialer SessionDialer) {
+	r.sessionDialer = dialer
+}
+
+// Run connects to the counterparty and replays the session log, returning once every message has been
+// sent or received. It fails on the first inbound message that does not match the log, or when ctx is done.
+func (r *ReplayInitiator) Run(ctx context.Context) error {
+	dialer := r.dialer
+	if r.sessionDialer != nil {
+		dialer = sessionContextDialer{SessionDialer: r.sessionDialer, sessionID: r.sessionID}
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", r.address)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = conn.Close() }()
+	stop := context.AfterFunc(ctx, func() { _ = conn.Close() })
+	defer stop()
+
+	parser := newParser(conn)
+	for _, step := range r.steps {
+		if step.outbound {
+			err = r.send(conn, step)
+		} else {
+			err = r.receive(parser, step)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send writes the message of an outbound step.
+func (r *ReplayInitiator) send(conn net.Conn, step replayStep) error {
+	msg := step.msg
+	msg.Header.SetField(tagSendingTime, FIXUTCTimestamp{Time: time.Now()})
+
+	msgType, err := msg.MsgType()
+	if err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+	if isAdminMessageType([]byte(msgType)) {
+		r.app.ToAdmin(msg, r.sessionID)
+	} else if err := r.app.ToApp(msg, r.sessionID); err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+
+	if _, err := conn.Write(msg.build()); err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+	return nil
+}
+
+// receive reads the next message from the counterparty and checks it against an inbound step.
+func (r *ReplayInitiator) receive(parser *parser, step replayStep) error {
+	msgBytes, err := parser.ReadMessage()
+	if err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+	msg := NewMessage()
+	if err := ParseMessage(msg, msgBytes); err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+
+	expectedType, err := step.msg.MsgType()
+	if err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+	expectedSeqNum, err := step.msg.Header.GetInt(tagMsgSeqNum)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", step.line, err)
+	}
+	msgType, _ := msg.MsgType()
+	seqNum, _ := msg.Header.GetInt(tagMsgSeqNum)
+	if msgType != expectedType || seqNum != expectedSeqNum {
+		return fmt.Errorf("line %d: expected MsgType %v with MsgSeqNum %d, received MsgType %v with MsgSeqNum %d",
+			step.line, expectedType, expectedSeqNum, msgType, seqNum)
+	}
+
+	if isAdminMessageType([]byte(msgType)) {
+		if rej := r.app.FromAdmin(msg, r.sessionID); rej != nil {
+			return fmt.Errorf("line %d: %w", step.line, rej)
+		}
+		if msgType == string(msgTypeLogon) {
+			r.app.OnLogon(r.sessionID)
+		}
+		return nil
+	}
+	if rej := r.app.FromApp(msg, r.sessionID); rej != nil {
+		return fmt.Errorf("line %d: %w", step.line, rej)
+	}
+	return nil
+}
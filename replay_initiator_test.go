@@ -0,0 +1,122 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func replayLogMessage(msgType string, seqNum int, sender, target string) string {
+	msg := NewMessage()
+	msg.Header.SetField(tagBeginString, FIXString(BeginStringFIX44))
+	msg.Header.SetField(tagMsgType, FIXString(msgType))
+	msg.Header.SetField(tagMsgSeqNum, FIXInt(seqNum))
+	msg.Header.SetField(tagSenderCompID, FIXString(sender))
+	msg.Header.SetField(tagTargetCompID, FIXString(target))
+	msg.Header.SetField(tagSendingTime, FIXUTCTimestamp{Time: time.Now()})
+	if msgType == "A" {
+		msg.Body.SetField(tagEncryptMethod, FIXString("0"))
+		msg.Body.SetField(tagHeartBtInt, FIXInt(30))
+	}
+	return msg.ToDelimitedString('|')
+}
+
+func runReplay(t *testing.T, replayLog string) error {
+	serverConn, clientConn := NewPipeTransport()
+
+	acceptorSettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=REPLAYACC
+TargetCompID=REPLAYINIT
+`))
+	require.Nil(t, err)
+	acceptor, err := NewAcceptor(ApplicationAdapter{}, NewMemoryStoreFactory(), acceptorSettings, NewNullLogFactory())
+	require.Nil(t, err)
+	acceptor.ServeConn(serverConn)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	logFile := filepath.Join(t.TempDir(), "session.log")
+	require.Nil(t, os.WriteFile(logFile, []byte(replayLog), 0o600))
+
+	replaySettings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=REPLAYINIT
+TargetCompID=REPLAYACC
+SocketConnectHost=pipe
+SocketConnectPort=0
+`))
+	require.Nil(t, err)
+	replay, err := NewReplayInitiator(logFile, replaySettings, ApplicationAdapter{})
+	require.Nil(t, err)
+	replay.SetSessionDialer(ConnDialer(clientConn))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	return replay.Run(ctx)
+}
+
+func TestReplayInitiator(t *testing.T) {
+	replayLog := strings.Join([]string{
+		"# logon and logout",
+		"OUT:" + replayLogMessage("A", 1, "REPLAYINIT", "REPLAYACC"),
+		"IN:" + replayLogMessage("A", 1, "REPLAYACC", "REPLAYINIT"),
+		"",
+		"OUT:" + replayLogMessage("5", 2, "REPLAYINIT", "REPLAYACC"),
+		"IN:" + replayLogMessage("5", 2, "REPLAYACC", "REPLAYINIT"),
+	}, "\n")
+
+	assert.Nil(t, runReplay(t, replayLog))
+}
+
+func TestReplayInitiatorSeqNumMismatch(t *testing.T) {
+	replayLog := strings.Join([]string{
+		"OUT:" + replayLogMessage("A", 1, "REPLAYINIT", "REPLAYACC"),
+		"IN:" + replayLogMessage("A", 2, "REPLAYACC", "REPLAYINIT"),
+	}, "\n")
+
+	err := runReplay(t, replayLog)
+	require.NotNil(t, err)
+	assert.Equal(t, "line 2: expected MsgType A with MsgSeqNum 2, received MsgType A with MsgSeqNum 1", err.Error())
+}
+
+func TestNewReplayInitiatorInvalidLog(t *testing.T) {
+	logFile := filepath.Join(t.TempDir(), "session.log")
+	require.Nil(t, os.WriteFile(logFile, []byte("SEND:8=FIX.4.4|\n"), 0o600))
+	settings, err := ParseSettings(strings.NewReader(`
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=REPLAYINIT
+TargetCompID=REPLAYACC
+SocketConnectHost=127.0.0.1
+SocketConnectPort=5001
+`))
+	require.Nil(t, err)
+
+	_, err = NewReplayInitiator(logFile, settings, ApplicationAdapter{})
+	require.NotNil(t, err)
+	assert.Contains(t, err.Error(), "line 1: expected OUT: or IN: prefix")
+}
@@ -17,11 +17,16 @@ package quickfix
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"runtime"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"github.com/quickfixgo/quickfix/datadictionary"
 	"github.com/quickfixgo/quickfix/internal"
 )
@@ -40,7 +45,7 @@ type session struct {
 	toSend [][]byte
 
 	// Mutex for access to toSend.
-	sendMutex sync.Mutex
+	sendMutex contextMutex
 
 	sessionEvent chan internal.Event
 	messageEvent chan bool
@@ -55,11 +60,45 @@ type session struct {
 	targetDefaultApplVerID string
 
 	admin chan interface{}
+	// Closed when the session loop returns, nil while it is not running.
+	loopDone atomic.Pointer[chan struct{}]
 	internal.SessionSettings
 	transportDataDictionary *datadictionary.DataDictionary
 	appDataDictionary       *datadictionary.DataDictionary
 
 	timestampPrecision TimestampPrecision
+
+	// Observers notified of state changes and message traffic.
+	observersMu sync.RWMutex
+	observers   []SessionObserver
+
+	latencyTracker *HeartbeatLatencyTracker
+
+	// Length of toSend, readable without the send lock.
+	queueDepth atomic.Int64
+
+	// Whether the state machine is logged on, readable outside the session loop.
+	loggedOnState atomic.Bool
+
+	// Limits the rate of application messages, nil if MaxOutboundMsgRatePerSecond is not set.
+	outboundLimiter *rate.Limiter
+
+	// Fields added to outgoing Logon messages.
+	logonFields []logonField
+
+	// Body fields encrypted by the EncryptionProvider.
+	encryptedTags []Tag
+	encryptMethod int
+	encryption    atomic.Pointer[encryptionProviderRef]
+
+	// Set when PersistSessionState is enabled.
+	stateStore         SessionStateStore
+	resumeState        *PersistedSessionState
+	lastPersistedState *PersistedSessionState
+
+	// Set when StoreHealthCheckIntervalSecs is enabled.
+	nextStoreHealthCheck time.Time
+	storeHealthFailures  int
 }
 
 func (s *session) logError(err error) {
@@ -98,6 +137,81 @@ func (s *session) stop() {
 	})
 }
 
+// errSessionNotRunning is returned for requests to a session whose loop is not running.
+var errSessionNotRunning = errors.New("Session not running")
+
+// request hands req to the session loop and waits for its reply on rep. It fails rather than block if the loop is
+// not running or stops before taking req.
+func (s *session) request(req interface{}, rep <-chan error) error {
+	done := s.loopDone.Load()
+	if done == nil {
+		return errSessionNotRunning
+	}
+	select {
+	case s.admin <- req:
+		return <-rep
+	case <-*done:
+		return errSessionNotRunning
+	}
+}
+
+type replayReq struct {
+	beginSeqNum, endSeqNum int
+	err                    chan<- error
+}
+
+// replay resends the messages in the range as if the counterparty had requested them with a ResendRequest.
+func (s *session) replay(beginSeqNum, endSeqNum int) error {
+	if !s.IsLoggedOn() {
+		return errors.New("Not logged on")
+	}
+
+	lastSeqNum := s.store.NextSenderMsgSeqNum() - 1
+	if endSeqNum == 0 || endSeqNum > lastSeqNum {
+		endSeqNum = lastSeqNum
+	}
+	if beginSeqNum < 1 || beginSeqNum > endSeqNum {
+		return fmt.Errorf("Invalid replay range %d to %d, last sent MsgSeqNum is %d", beginSeqNum, endSeqNum, lastSeqNum)
+	}
+
+	resendRequest := NewMessage()
+	resendRequest.Header.SetField(tagMsgType, FIXString(msgTypeResendRequest))
+	resendRequest.Header.SetInt(tagMsgSeqNum, s.store.NextTargetMsgSeqNum()-1)
+	resendRequest.Body.SetInt(tagBeginSeqNo, beginSeqNum)
+	resendRequest.Body.SetInt(tagEndSeqNo, endSeqNum)
+
+	s.log.OnEventf("Replaying messages FROM: %d TO: %d", beginSeqNum, endSeqNum)
+	return inSession{}.resendMessages(s, beginSeqNum, endSeqNum, *resendRequest)
+}
+
+// forceLogoutTimeout is the time a session waits for the counterparty's Logout in reply to ForceLogout.
+const forceLogoutTimeout = 5 * time.Second
+
+type forceLogoutReq struct {
+	reason string
+	err    chan<- error
+}
+
+// forceLogout sends a Logout and disconnects once the counterparty replies or forceLogoutTimeout elapses.
+func (s *session) forceLogout(reason string) error {
+	if !s.IsConnected() {
+		return errors.New("Not connected")
+	}
+
+	s.log.OnEventf("Warning: forcing logout of session %v: %s", s.sessionID, reason)
+	if !s.IsLoggedOn() {
+		s.setState(s, latentState{})
+		return nil
+	}
+	if err := s.sendLogout(reason); err != nil {
+		s.setState(s, handleStateError(s, err))
+		return err
+	}
+	time.AfterFunc(forceLogoutTimeout, func() { s.sessionEvent <- internal.LogoutTimeout })
+	s.setState(s, logoutState{})
+	return nil
+}
+
 type waitChan <-chan interface{}
 
 type waitForInSessionReq struct{ rep chan<- waitChan }
@@ -170,7 +284,11 @@ func (s *session) sendLogonInReplyTo(setResetSeqNum bool, inReplyTo *Message) er
 	logon.Header.SetField(tagBeginString, FIXString(s.sessionID.BeginString))
 	logon.Header.SetField(tagTargetCompID, FIXString(s.sessionID.TargetCompID))
 	logon.Header.SetField(tagSenderCompID, FIXString(s.sessionID.SenderCompID))
-	logon.Body.SetField(tagEncryptMethod, FIXString("0"))
+	if len(s.encryptedTags) > 0 {
+		logon.Body.SetField(tagEncryptMethod, FIXInt(s.encryptMethod))
+	} else {
+		logon.Body.SetField(tagEncryptMethod, FIXString("0"))
+	}
 	logon.Body.SetField(tagHeartBtInt, FIXInt(s.HeartBtInt.Seconds()))
 
 	if setResetSeqNum {
@@ -181,6 +299,10 @@ func (s *session) sendLogonInReplyTo(setResetSeqNum bool, inReplyTo *Message) er
 		logon.Body.SetField(tagDefaultApplVerID, FIXString(s.DefaultApplVerID))
 	}
 
+	if err := s.setLogonFields(logon); err != nil {
+		return err
+	}
+
 	// Evaluate tag 789.
 	if s.EnableNextExpectedMsgSeqNum {
 		if inReplyTo != nil {
@@ -271,15 +393,30 @@ func (s *session) resend(msg *Message) bool {
 
 // queueForSend will validate, persist, and queue the message for send.
 func (s *session) queueForSend(msg *Message) error {
-	s.sendMutex.Lock()
+	return s.queueForSendContext(context.Background(), msg)
+}
+
+// queueForSendContext behaves like queueForSend, but gives up waiting for the send lock once ctx is done.
+func (s *session) queueForSendContext(ctx context.Context, msg *Message) error {
+	if err := s.sendMutex.LockContext(ctx); err != nil {
+		return err
+	}
 	defer s.sendMutex.Unlock()
 
+	if s.MaxPendingOutboundMessages > 0 && len(s.toSend) >= s.MaxPendingOutboundMessages {
+		return ErrSessionQueueFull
+	}
+	if s.outboundLimiter != nil && !s.outboundLimiter.Allow() {
+		return ErrRateLimitExceeded
+	}
+
 	msgBytes, err := s.prepMessageForSend(msg, nil)
 	if err != nil {
 		return err
 	}
 
 	s.toSend = append(s.toSend, msgBytes)
+	s.queueDepthChanged()
 
 	s.notifyMessageOut()
 
@@ -357,6 +494,11 @@ func (s *session) prepMessageForSend(msg *Message, inReplyTo *Message) (msgBytes
 
 	if isAdminMessageType(msgType) {
 		s.application.ToAdmin(msg, s.sessionID)
+		if bytes.Equal(msgType, msgTypeTestRequest) {
+			if testReqID, err := msg.Body.GetString(tagTestReqID); err == nil {
+				s.latencyTracker.testRequestSent(testReqID, time.Now())
+			}
+		}
 		if bytes.Equal(msgType, msgTypeLogon) {
 			var resetSeqNumFlag FIXBoolean
 			if msg.Body.Has(tagResetSeqNumFlag) {
@@ -381,8 +523,13 @@ func (s *session) prepMessageForSend(msg *Message, inReplyTo *Message) (msgBytes
 		}
 	}
 
+	encrypted, err := s.encryptFields(msg)
+	if err != nil {
+		return
+	}
+
 	// Message converted to bytes here.
-	msgBytes = msg.build()
+	msgBytes = encrypted.build()
 	err = s.persist(seqNum, msgBytes)
 
 	return
@@ -400,6 +547,7 @@ func (s *session) sendQueued(blockUntilSent bool) {
 	for i, msgBytes := range s.toSend {
 		if !s.sendBytes(msgBytes, blockUntilSent) {
 			s.toSend = s.toSend[i:]
+			s.queueDepthChanged()
 			s.notifyMessageOut()
 			return
 		}
@@ -410,6 +558,15 @@ func (s *session) sendQueued(blockUntilSent bool) {
 
 func (s *session) dropQueued() {
 	s.toSend = s.toSend[:0]
+	s.queueDepthChanged()
+}
+
+// queueDepthChanged publishes the length of toSend. The caller must hold the send lock.
+func (s *session) queueDepthChanged() {
+	depth := len(s.toSend)
+	if s.queueDepth.Swap(int64(depth)) != int64(depth) {
+		s.notifyQueueDepth(depth)
+	}
 }
 
 func (s *session) EnqueueBytesAndSend(msg []byte) {
@@ -429,6 +586,7 @@ func (s *session) sendBytes(msg []byte, blockUntilSent bool) bool {
 	if blockUntilSent {
 		s.messageOut <- msg
 		s.log.OnOutgoing(msg)
+		s.notifyMessageSent(msg)
 		s.stateTimer.Reset(s.HeartBtInt)
 		return true
 	}
@@ -436,6 +594,7 @@ func (s *session) sendBytes(msg []byte, blockUntilSent bool) bool {
 	select {
 	case s.messageOut <- msg:
 		s.log.OnOutgoing(msg)
+		s.notifyMessageSent(msg)
 		s.stateTimer.Reset(s.HeartBtInt)
 		return true
 	default:
@@ -514,6 +673,10 @@ func (s *session) handleLogon(msg *Message) error {
 		return err
 	}
 
+	if err := authenticate(s.sessionID, msg); err != nil {
+		return err
+	}
+
 	var resetSeqNumFlag FIXBoolean
 	if err := msg.Body.GetField(tagResetSeqNumFlag, &resetSeqNumFlag); err == nil {
 		if resetSeqNumFlag {
@@ -641,6 +804,10 @@ func (s *session) verifySelect(msg *Message, checkTooHigh bool, checkTooLow bool
 }
 
 func (s *session) verifyMsgAgainstAppImpl(msg *Message) MessageRejectError {
+	if reject := s.decryptFields(msg); reject != nil {
+		return reject
+	}
+
 	if s.Validator != nil {
 		if reject := s.Validator.Validate(msg); reject != nil {
 			return reject
@@ -650,6 +817,13 @@ func (s *session) verifyMsgAgainstAppImpl(msg *Message) MessageRejectError {
 	return s.fromCallback(msg)
 }
 
+// notifyHeartbeat passes the latency of an answered TestRequest to the application, if it is a HeartbeatListener.
+func (s *session) notifyHeartbeat(latency time.Duration) {
+	if listener, ok := s.application.(HeartbeatListener); ok {
+		listener.OnHeartbeat(s.sessionID, latency.Milliseconds())
+	}
+}
+
 func (s *session) fromCallback(msg *Message) MessageRejectError {
 	msgType, err := msg.Header.GetBytes(tagMsgType)
 	if err != nil {
@@ -660,9 +834,36 @@ func (s *session) fromCallback(msg *Message) MessageRejectError {
 		return s.application.FromAdmin(msg, s.sessionID)
 	}
 
+	if app, ok := s.application.(ApplicationWithContext); ok {
+		return s.fromAppContext(app, msg)
+	}
 	return s.application.FromApp(msg, s.sessionID)
 }
 
+// fromAppContext passes msg to app.FromAppContext, giving up on it once ApplicationCallbackTimeout elapses. The
+// message is then dropped and the session moves on, leaving a callback that ignores the canceled context running
+// alongside the processing of the next messages; its result is discarded.
+func (s *session) fromAppContext(app ApplicationWithContext, msg *Message) MessageRejectError {
+	if s.ApplicationCallbackTimeout <= 0 {
+		return app.FromAppContext(context.Background(), msg, s.sessionID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.ApplicationCallbackTimeout)
+	defer cancel()
+
+	result := make(chan MessageRejectError, 1)
+	go func() { result <- app.FromAppContext(ctx, msg, s.sessionID) }()
+
+	select {
+	case reject := <-result:
+		return reject
+	case <-ctx.Done():
+		seqNum, _ := msg.Header.GetInt(tagMsgSeqNum)
+		s.log.OnEventf("Warning: FromAppContext did not return within %v, dropping message %d", s.ApplicationCallbackTimeout, seqNum)
+		return nil
+	}
+}
+
 func (s *session) checkTargetTooLow(msg *Message) MessageRejectError {
 	if !msg.Header.Has(tagMsgSeqNum) {
 		return RequiredTagMissing(tagMsgSeqNum)
@@ -752,6 +953,12 @@ func (s *session) checkBeginString(msg *Message) MessageRejectError {
 func (s *session) doReject(msg *Message, rej MessageRejectError) error {
 	reply := msg.reverseRoute()
 
+	// Business rejects for an unsupported message type use the configured reason, unless the application
+	// chose one with an ApplicationError.
+	if _, ok := rej.(ApplicationError); !ok && rej.IsBusinessReject() && rej.RejectReason() == rejectReasonUnsupportedMessageType {
+		rej = ApplicationError{Text: rej.Error(), Reason: s.UnsupportedMsgRejectReason, RefTag: rej.RefTagID(), RefID: rej.BusinessRejectRefID()}
+	}
+
 	if s.sessionID.BeginString >= BeginStringFIX42 {
 
 		if rej.IsBusinessReject() {
@@ -795,7 +1002,11 @@ func (s *session) doReject(msg *Message, rej MessageRejectError) error {
 	}
 
 	s.log.OnEventf("Message Rejected: %v", rej.Error())
-	return s.sendInReplyTo(reply, msg)
+	err := s.sendInReplyTo(reply, msg)
+	if listener, ok := s.application.(MessageRejectionListener); ok {
+		listener.OnMessageRejected(s.sessionID, msg, rej)
+	}
+	return err
 }
 
 type fixIn struct {
@@ -854,6 +1065,14 @@ func (s *session) onAdmin(msg interface{}) {
 	case stopReq:
 		s.Stop(s)
 
+	case replayReq:
+		msg.err <- s.replay(msg.beginSeqNum, msg.endSeqNum)
+		close(msg.err)
+
+	case forceLogoutReq:
+		msg.err <- s.forceLogout(msg.reason)
+		close(msg.err)
+
 	case waitForInSessionReq:
 		if !s.IsSessionTime() {
 			msg.rep <- s.stateMachine.notifyOnInSessionTime
@@ -863,8 +1082,19 @@ func (s *session) onAdmin(msg interface{}) {
 }
 
 func (s *session) run() {
+	if s.EnableCPUAffinity {
+		// The thread is left locked when run returns, so that it exits with the goroutine rather than
+		// return to the pool bound to the core.
+		runtime.LockOSThread()
+		if err := setCPUAffinity(s.CPUAffinity); err != nil {
+			s.log.OnEventf("Unable to set CPU affinity to %d: %v", s.CPUAffinity, err)
+		}
+	}
+
 	s.stopOnce = sync.Once{}
 	s.Start(s)
+	loopDone := make(chan struct{})
+	s.loopDone.Store(&loopDone)
 	var stopChan = make(chan struct{})
 	s.stateTimer = internal.NewEventTimer(func() {
 		select {
@@ -892,6 +1122,8 @@ func (s *session) run() {
 	ticker := time.NewTicker(time.Second)
 
 	defer func() {
+		s.loopDone.Store(nil)
+		close(loopDone)
 		close(stopChan)
 		s.stateTimer.Stop()
 		s.peerTimer.Stop()
@@ -920,6 +1152,28 @@ func (s *session) run() {
 		case now := <-ticker.C:
 			s.CheckSessionTime(s, now)
 			s.CheckResetTime(s, now)
+			s.checkStoreHealth(now)
 		}
 	}
 }
+
+// checkStoreHealth calls the store's HealthCheck every StoreHealthCheckIntervalSecs. The session disconnects
+// once StoreHealthCheckMaxFailures checks in a row have failed, and keeps doing so until a check succeeds.
+func (s *session) checkStoreHealth(now time.Time) {
+	if s.StoreHealthCheckInterval <= 0 || now.Before(s.nextStoreHealthCheck) {
+		return
+	}
+	s.nextStoreHealthCheck = now.Add(s.StoreHealthCheckInterval)
+
+	err := s.store.HealthCheck()
+	if err == nil {
+		s.storeHealthFailures = 0
+		return
+	}
+	s.storeHealthFailures++
+	if s.storeHealthFailures >= s.StoreHealthCheckMaxFailures && s.IsConnected() {
+		s.setState(s, handleStateError(s, fmt.Errorf("store health check failed %d times in a row: %w", s.storeHealthFailures, err)))
+		return
+	}
+	s.log.OnEventf("Store health check failed (%d in a row): %v", s.storeHealthFailures, err)
+}
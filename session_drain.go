@@ -0,0 +1,58 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"fmt"
+	"time"
+)
+
+// drainPollInterval is the interval at which StopWithDrain checks the send queues of the sessions.
+const drainPollInterval = 10 * time.Millisecond
+
+// DrainTimeoutError is returned by StopWithDrain when messages were still queued once the timeout expired.
+type DrainTimeoutError struct {
+	// Dropped is the number of queued messages that were not written before stopping.
+	Dropped int
+}
+
+func (e DrainTimeoutError) Error() string {
+	return fmt.Sprintf("timed out draining send queues, dropped %d queued messages", e.Dropped)
+}
+
+// drainSessions waits up to timeout for the send queues of the sessions to empty.
+func drainSessions(sessions map[SessionID]*session, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		queued := 0
+		for _, s := range sessions {
+			queued += int(s.queueDepth.Load())
+		}
+		if queued == 0 {
+			return nil
+		}
+
+		if !time.Now().Before(deadline) {
+			for _, s := range sessions {
+				if depth := s.queueDepth.Load(); depth > 0 {
+					s.log.OnEventf("Timed out draining send queue, dropping %d queued messages", depth)
+				}
+			}
+			return DrainTimeoutError{Dropped: queued}
+		}
+		time.Sleep(min(drainPollInterval, time.Until(deadline)))
+	}
+}
@@ -0,0 +1,46 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDrainSessions(t *testing.T) {
+	empty := &session{log: nullLog{}}
+	queued := &session{log: nullLog{}}
+	queued.queueDepth.Store(2)
+	sessions := map[SessionID]*session{
+		{BeginString: "FIX.4.4", SenderCompID: "A", TargetCompID: "B"}: empty,
+		{BeginString: "FIX.4.4", SenderCompID: "C", TargetCompID: "D"}: queued,
+	}
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		queued.queueDepth.Store(0)
+	}()
+	assert.Nil(t, drainSessions(sessions, 5*time.Second))
+
+	queued.queueDepth.Store(3)
+	start := time.Now()
+	err := drainSessions(sessions, 30*time.Millisecond)
+	assert.Equal(t, DrainTimeoutError{Dropped: 3}, err)
+	assert.EqualError(t, err, "timed out draining send queues, dropped 3 queued messages")
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
 
 	"github.com/quickfixgo/quickfix/config"
 	"github.com/quickfixgo/quickfix/datadictionary"
@@ -57,6 +58,9 @@ var applVerIDLookup = map[string]string{
 	"FIX.5.0SP2":     "9",
 }
 
+// defaultResetOnLogoutText is the Text(58) of the Logout sent on stop when ResetOnLogout is enabled.
+const defaultResetOnLogoutText = "NextSeqNum = 1"
+
 type sessionFactory struct {
 	// True if building sessions that initiate logon.
 	BuildInitiators bool
@@ -75,6 +79,7 @@ func (f sessionFactory) createSession(
 	if err = registerSession(session); err != nil {
 		return
 	}
+	application.OnSessionCreate(session.sessionID)
 	application.OnCreate(session.sessionID)
 	session.log.OnEvent("Created session")
 
@@ -84,7 +89,7 @@ func (f sessionFactory) createSession(
 func (f sessionFactory) newSession(
 	sessionID SessionID, storeFactory MessageStoreFactory, settings *SessionSettings, logFactory LogFactory,
 	application Application) (s *session, err error) {
-	s = &session{sessionID: sessionID}
+	s = &session{sessionID: sessionID, latencyTracker: newHeartbeatLatencyTracker()}
 
 	var validatorSettings = defaultValidatorSettings
 	if settings.HasSetting(config.ValidateFieldsOutOfOrder) {
@@ -172,6 +177,42 @@ func (f sessionFactory) newSession(
 		}
 	}
 
+	if settings.HasSetting(config.LogonFields) {
+		var logonFields string
+		if logonFields, err = settings.Setting(config.LogonFields); err != nil {
+			return
+		}
+		if s.logonFields, err = parseLogonFields(logonFields); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.EncryptedTags) {
+		var encryptedTags string
+		if encryptedTags, err = settings.Setting(config.EncryptedTags); err != nil {
+			return
+		}
+		if s.encryptedTags, err = parseEncryptedTags(encryptedTags); err != nil {
+			return
+		}
+	}
+
+	if settings.HasSetting(config.EncryptMethod) {
+		if s.encryptMethod, err = settings.IntSetting(config.EncryptMethod); err != nil {
+			return
+		}
+		if s.encryptMethod < 0 || s.encryptMethod > 6 {
+			err = IncorrectFormatForSetting{Setting: config.EncryptMethod, Value: []byte(strconv.Itoa(s.encryptMethod))}
+			return
+		}
+	}
+
+	if settings.HasSetting(config.PersistSessionState) {
+		if s.PersistSessionState, err = settings.BoolSetting(config.PersistSessionState); err != nil {
+			return
+		}
+	}
+
 	if settings.HasSetting(config.RefreshOnLogon) {
 		if s.RefreshOnLogon, err = settings.BoolSetting(config.RefreshOnLogon); err != nil {
 			return
@@ -184,6 +225,13 @@ func (f sessionFactory) newSession(
 		}
 	}
 
+	s.ResetOnLogoutText = defaultResetOnLogoutText
+	if settings.HasSetting(config.ResetOnLogoutText) {
+		if s.ResetOnLogoutText, err = settings.Setting(config.ResetOnLogoutText); err != nil {
+			return
+		}
+	}
+
 	if settings.HasSetting(config.ResetOnDisconnect) {
 		if s.ResetOnDisconnect, err = settings.BoolSetting(config.ResetOnDisconnect); err != nil {
 			return
@@ -227,12 +275,169 @@ func (f sessionFactory) newSession(
 		s.MaxLatency = time.Duration(maxLatency) * time.Second
 	}
 
+	if settings.HasSetting(config.ApplicationCallbackTimeout) {
+		var timeoutMs int
+		if timeoutMs, err = settings.IntSetting(config.ApplicationCallbackTimeout); err != nil {
+			return
+		}
+
+		if timeoutMs < 0 {
+			err = errors.New("ApplicationCallbackTimeout must be a non-negative integer")
+			return
+		}
+
+		s.ApplicationCallbackTimeout = time.Duration(timeoutMs) * time.Millisecond
+	}
+
 	if settings.HasSetting(config.ResendRequestChunkSize) {
 		if s.ResendRequestChunkSize, err = settings.IntSetting(config.ResendRequestChunkSize); err != nil {
 			return
 		}
 	}
 
+	if settings.HasSetting(config.MaxPendingOutboundMessages) {
+		if s.MaxPendingOutboundMessages, err = settings.IntSetting(config.MaxPendingOutboundMessages); err != nil {
+			return
+		}
+		if s.MaxPendingOutboundMessages < 0 {
+			err = IncorrectFormatForSetting{Setting: config.MaxPendingOutboundMessages, Value: []byte(strconv.Itoa(s.MaxPendingOutboundMessages))}
+			return
+		}
+	}
+
+	if settings.HasSetting(config.MaxOutboundMsgRatePerSecond) {
+		if s.MaxOutboundMsgRatePerSecond, err = settings.IntSetting(config.MaxOutboundMsgRatePerSecond); err != nil {
+			return
+		}
+		if s.MaxOutboundMsgRatePerSecond < 0 {
+			err = IncorrectFormatForSetting{Setting: config.MaxOutboundMsgRatePerSecond, Value: []byte(strconv.Itoa(s.MaxOutboundMsgRatePerSecond))}
+			return
+		}
+	}
+
+	s.UnsupportedMsgRejectReason = rejectReasonUnsupportedMessageType
+	if settings.HasSetting(config.DefaultUnsupportedMsgRejectReason) {
+		if s.UnsupportedMsgRejectReason, err = settings.IntSetting(config.DefaultUnsupportedMsgRejectReason); err != nil {
+			return
+		}
+		if s.UnsupportedMsgRejectReason < 0 {
+			err = IncorrectFormatForSetting{Setting: config.DefaultUnsupportedMsgRejectReason, Value: []byte(strconv.Itoa(s.UnsupportedMsgRejectReason))}
+			return
+		}
+	}
+
+	if settings.HasSetting(config.CPUAffinity) {
+		if s.CPUAffinity, err = settings.IntSetting(config.CPUAffinity); err != nil {
+			return
+		}
+		if s.CPUAffinity < 0 {
+			err = IncorrectFormatForSetting{Setting: config.CPUAffinity, Value: []byte(strconv.Itoa(s.CPUAffinity))}
+			return
+		}
+		s.EnableCPUAffinity = true
+	}
+
+	if settings.HasSetting(config.TCPSendBufferSize) {
+		if s.TCPSendBufferSize, err = settings.IntSetting(config.TCPSendBufferSize); err != nil {
+			return
+		}
+		if s.TCPSendBufferSize <= 0 {
+			err = IncorrectFormatForSetting{Setting: config.TCPSendBufferSize, Value: []byte(strconv.Itoa(s.TCPSendBufferSize))}
+			return
+		}
+	}
+
+	if settings.HasSetting(config.TCPReceiveBufferSize) {
+		if s.TCPReceiveBufferSize, err = settings.IntSetting(config.TCPReceiveBufferSize); err != nil {
+			return
+		}
+		if s.TCPReceiveBufferSize <= 0 {
+			err = IncorrectFormatForSetting{Setting: config.TCPReceiveBufferSize, Value: []byte(strconv.Itoa(s.TCPReceiveBufferSize))}
+			return
+		}
+	}
+
+	if settings.HasSetting(config.TCPKeepAlive) {
+		if s.TCPKeepAlive.Enable, err = settings.BoolSetting(config.TCPKeepAlive); err != nil {
+			return
+		}
+		s.ConfigureTCPKeepAlive = true
+	}
+
+	if s.TCPKeepAlive.Enable {
+		if settings.HasSetting(config.TCPKeepAliveIdleSecs) {
+			var idleSecs int
+			if idleSecs, err = settings.IntSetting(config.TCPKeepAliveIdleSecs); err != nil {
+				return
+			}
+			if idleSecs <= 0 {
+				err = IncorrectFormatForSetting{Setting: config.TCPKeepAliveIdleSecs, Value: []byte(strconv.Itoa(idleSecs))}
+				return
+			}
+			s.TCPKeepAlive.Idle = time.Duration(idleSecs) * time.Second
+		}
+
+		if settings.HasSetting(config.TCPKeepAliveIntervalSecs) {
+			var intervalSecs int
+			if intervalSecs, err = settings.IntSetting(config.TCPKeepAliveIntervalSecs); err != nil {
+				return
+			}
+			if intervalSecs <= 0 {
+				err = IncorrectFormatForSetting{Setting: config.TCPKeepAliveIntervalSecs, Value: []byte(strconv.Itoa(intervalSecs))}
+				return
+			}
+			s.TCPKeepAlive.Interval = time.Duration(intervalSecs) * time.Second
+		}
+
+		if settings.HasSetting(config.TCPKeepAliveCount) {
+			if s.TCPKeepAlive.Count, err = settings.IntSetting(config.TCPKeepAliveCount); err != nil {
+				return
+			}
+			if s.TCPKeepAlive.Count <= 0 {
+				err = IncorrectFormatForSetting{Setting: config.TCPKeepAliveCount, Value: []byte(strconv.Itoa(s.TCPKeepAlive.Count))}
+				return
+			}
+		}
+	}
+
+	if settings.HasSetting(config.StoreHealthCheckIntervalSecs) {
+		var intervalSecs int
+		if intervalSecs, err = settings.IntSetting(config.StoreHealthCheckIntervalSecs); err != nil {
+			return
+		}
+		if intervalSecs < 0 {
+			err = IncorrectFormatForSetting{Setting: config.StoreHealthCheckIntervalSecs, Value: []byte(strconv.Itoa(intervalSecs))}
+			return
+		}
+		s.StoreHealthCheckInterval = time.Duration(intervalSecs) * time.Second
+	}
+
+	s.StoreHealthCheckMaxFailures = 3
+	if settings.HasSetting(config.StoreHealthCheckMaxFailures) {
+		if s.StoreHealthCheckMaxFailures, err = settings.IntSetting(config.StoreHealthCheckMaxFailures); err != nil {
+			return
+		}
+		if s.StoreHealthCheckMaxFailures <= 0 {
+			err = IncorrectFormatForSetting{Setting: config.StoreHealthCheckMaxFailures, Value: []byte(strconv.Itoa(s.StoreHealthCheckMaxFailures))}
+			return
+		}
+	}
+
+	s.MaxOutboundBurstMessages = 1
+	if settings.HasSetting(config.MaxOutboundBurstMessages) {
+		if s.MaxOutboundBurstMessages, err = settings.IntSetting(config.MaxOutboundBurstMessages); err != nil {
+			return
+		}
+		if s.MaxOutboundBurstMessages <= 0 {
+			err = IncorrectFormatForSetting{Setting: config.MaxOutboundBurstMessages, Value: []byte(strconv.Itoa(s.MaxOutboundBurstMessages))}
+			return
+		}
+	}
+
+	if s.MaxOutboundMsgRatePerSecond > 0 {
+		s.outboundLimiter = rate.NewLimiter(rate.Limit(s.MaxOutboundMsgRatePerSecond), s.MaxOutboundBurstMessages)
+	}
+
 	if settings.HasSetting(config.StartTime) || settings.HasSetting(config.EndTime) {
 		var startTimeStr, endTimeStr string
 		if startTimeStr, err = settings.Setting(config.StartTime); err != nil {
@@ -411,6 +616,12 @@ func (f sessionFactory) newSession(
 		return
 	}
 
+	if s.PersistSessionState {
+		if err = f.buildSessionStateStore(s); err != nil {
+			return
+		}
+	}
+
 	s.sessionEvent = make(chan internal.Event)
 	s.messageEvent = make(chan bool, 1)
 	s.admin = make(chan interface{})
@@ -418,6 +629,23 @@ func (f sessionFactory) newSession(
 	return
 }
 
+func (f sessionFactory) buildSessionStateStore(session *session) error {
+	stateStore, ok := sessionStateStore(session.store)
+	if !ok {
+		return errors.New("PersistSessionState requires a message store that persists the session state")
+	}
+	state, found, err := stateStore.LoadSessionState()
+	if err != nil {
+		return err
+	}
+	if found {
+		session.log.OnEventf("Loaded persisted session state: %s", state.State)
+		session.resumeState = &state
+	}
+	session.stateStore = stateStore
+	return nil
+}
+
 func (f sessionFactory) buildAcceptorSettings(session *session, settings *SessionSettings) error {
 	if err := f.buildHeartBtIntSettings(session, settings, false); err != nil {
 		return err
@@ -16,6 +16,7 @@
 package quickfix
 
 import (
+	"net"
 	"testing"
 	"time"
 
@@ -62,12 +63,16 @@ func (s *SessionFactorySuite) TestDefaults() {
 	s.Equal("", session.DefaultApplVerID)
 	s.False(session.InitiateLogon)
 	s.Equal(0, session.ResendRequestChunkSize)
+	s.Equal(0, session.MaxPendingOutboundMessages)
+	s.Equal(0, session.MaxOutboundMsgRatePerSecond)
+	s.Nil(session.outboundLimiter)
 	s.False(session.EnableLastMsgSeqNumProcessed)
 	s.False(session.SkipCheckLatency)
 	s.Equal(Millis, session.timestampPrecision)
 	s.Equal(120*time.Second, session.MaxLatency)
 	s.False(session.DisableMessagePersist)
 	s.False(session.HeartBtIntOverride)
+	s.False(session.PersistSessionState)
 }
 
 func (s *SessionFactorySuite) TestResetOnLogon() {
@@ -150,6 +155,247 @@ func (s *SessionFactorySuite) TestResendRequestChunkSize() {
 	s.NotNil(err)
 }
 
+func (s *SessionFactorySuite) TestMaxPendingOutboundMessages() {
+	s.SessionSettings.Set(config.MaxPendingOutboundMessages, "1000")
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.NotNil(session)
+	s.Equal(1000, session.MaxPendingOutboundMessages)
+
+	s.SessionSettings.Set(config.MaxPendingOutboundMessages, "-1")
+	_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err)
+
+	s.SessionSettings.Set(config.MaxPendingOutboundMessages, "notanint")
+	_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err)
+}
+
+func (s *SessionFactorySuite) TestMaxOutboundMsgRatePerSecond() {
+	s.SessionSettings.Set(config.MaxOutboundMsgRatePerSecond, "100")
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(100, session.MaxOutboundMsgRatePerSecond)
+	s.Equal(1, session.MaxOutboundBurstMessages)
+	s.Require().NotNil(session.outboundLimiter)
+	s.EqualValues(100, session.outboundLimiter.Limit())
+	s.Equal(1, session.outboundLimiter.Burst())
+
+	s.SessionSettings.Set(config.MaxOutboundBurstMessages, "10")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(10, session.outboundLimiter.Burst())
+
+	for _, test := range []struct{ setting, value string }{
+		{config.MaxOutboundBurstMessages, "0"},
+		{config.MaxOutboundMsgRatePerSecond, "-1"},
+		{config.MaxOutboundMsgRatePerSecond, "notanint"},
+	} {
+		s.SetupTest()
+		s.SessionSettings.Set(test.setting, test.value)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, test)
+	}
+}
+
+func (s *SessionFactorySuite) TestLogonFields() {
+	s.SessionSettings.Set(config.LogonFields, "553=user; 554=${PASSWORD};")
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal([]logonField{{tag: 553, value: []byte("user")}, {tag: 554, value: []byte("${PASSWORD}")}}, session.logonFields)
+
+	for _, invalid := range []string{"553", "abc=user", "0=user"} {
+		s.SessionSettings.Set(config.LogonFields, invalid)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, invalid)
+	}
+}
+
+func (s *SessionFactorySuite) TestDefaultUnsupportedMsgRejectReason() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(3, session.UnsupportedMsgRejectReason)
+
+	s.SessionSettings.Set(config.DefaultUnsupportedMsgRejectReason, "2")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(2, session.UnsupportedMsgRejectReason)
+
+	for _, invalid := range []string{"-1", "abc"} {
+		s.SessionSettings.Set(config.DefaultUnsupportedMsgRejectReason, invalid)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, invalid)
+	}
+}
+
+func (s *SessionFactorySuite) TestCPUAffinity() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.False(session.EnableCPUAffinity)
+
+	s.SessionSettings.Set(config.CPUAffinity, "3")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.True(session.EnableCPUAffinity)
+	s.Equal(3, session.CPUAffinity)
+
+	for _, invalid := range []string{"-1", "abc"} {
+		s.SessionSettings.Set(config.CPUAffinity, invalid)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, invalid)
+	}
+}
+
+func (s *SessionFactorySuite) TestTCPBufferSizes() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Zero(session.TCPSendBufferSize)
+	s.Zero(session.TCPReceiveBufferSize)
+
+	s.SessionSettings.Set(config.TCPSendBufferSize, "8388608")
+	s.SessionSettings.Set(config.TCPReceiveBufferSize, "4194304")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(8388608, session.TCPSendBufferSize)
+	s.Equal(4194304, session.TCPReceiveBufferSize)
+
+	for _, setting := range []string{config.TCPSendBufferSize, config.TCPReceiveBufferSize} {
+		for _, invalid := range []string{"0", "-1", "abc"} {
+			s.SessionSettings.Set(setting, invalid)
+			_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+			s.NotNil(err, setting+"="+invalid)
+			s.SessionSettings.Set(setting, "65536")
+		}
+	}
+}
+
+func (s *SessionFactorySuite) TestStoreHealthCheck() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Zero(session.StoreHealthCheckInterval)
+	s.Equal(3, session.StoreHealthCheckMaxFailures)
+
+	s.SessionSettings.Set(config.StoreHealthCheckIntervalSecs, "30")
+	s.SessionSettings.Set(config.StoreHealthCheckMaxFailures, "5")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(30*time.Second, session.StoreHealthCheckInterval)
+	s.Equal(5, session.StoreHealthCheckMaxFailures)
+
+	for _, invalid := range []struct{ setting, value string }{
+		{config.StoreHealthCheckIntervalSecs, "-1"},
+		{config.StoreHealthCheckIntervalSecs, "abc"},
+		{config.StoreHealthCheckMaxFailures, "0"},
+		{config.StoreHealthCheckMaxFailures, "abc"},
+	} {
+		s.SessionSettings.Set(invalid.setting, invalid.value)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, invalid.setting+"="+invalid.value)
+		s.SessionSettings.Set(invalid.setting, "1")
+	}
+}
+
+func (s *SessionFactorySuite) TestTCPKeepAlive() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.False(session.ConfigureTCPKeepAlive)
+
+	s.SessionSettings.Set(config.TCPKeepAlive, "N")
+	s.SessionSettings.Set(config.TCPKeepAliveIdleSecs, "invalid")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.True(session.ConfigureTCPKeepAlive)
+	s.False(session.TCPKeepAlive.Enable)
+
+	s.SessionSettings.Set(config.TCPKeepAlive, "Y")
+	s.SessionSettings.Set(config.TCPKeepAliveIdleSecs, "60")
+	s.SessionSettings.Set(config.TCPKeepAliveIntervalSecs, "10")
+	s.SessionSettings.Set(config.TCPKeepAliveCount, "5")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(net.KeepAliveConfig{Enable: true, Idle: 60 * time.Second, Interval: 10 * time.Second, Count: 5}, session.TCPKeepAlive)
+
+	for _, setting := range []string{config.TCPKeepAliveIdleSecs, config.TCPKeepAliveIntervalSecs, config.TCPKeepAliveCount} {
+		for _, invalid := range []string{"0", "-1", "abc"} {
+			s.SessionSettings.Set(setting, invalid)
+			_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+			s.NotNil(err, setting+"="+invalid)
+			s.SessionSettings.Set(setting, "5")
+		}
+	}
+}
+
+func (s *SessionFactorySuite) TestEncryptedTags() {
+	s.SessionSettings.Set(config.EncryptedTags, "58,354")
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal([]Tag{58, 354}, session.encryptedTags)
+
+	s.SessionSettings.Set(config.EncryptedTags, "58,text")
+	_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err)
+}
+
+func (s *SessionFactorySuite) TestEncryptMethod() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Zero(session.encryptMethod)
+
+	s.SessionSettings.Set(config.EncryptMethod, "5")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(5, session.encryptMethod)
+
+	for _, invalid := range []string{"-1", "7", "PGP"} {
+		s.SessionSettings.Set(config.EncryptMethod, invalid)
+		_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+		s.NotNil(err, invalid)
+	}
+}
+
+// recordingStateStoreFactory creates recordingStateStores holding the given saved states.
+type recordingStateStoreFactory struct {
+	saved []PersistedSessionState
+}
+
+func (f recordingStateStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	store, err := NewMemoryStoreFactory().Create(sessionID)
+	return &recordingStateStore{MessageStore: store, saved: f.saved}, err
+}
+
+func (s *SessionFactorySuite) TestPersistSessionState() {
+	s.SessionSettings.Set(config.PersistSessionState, "Y")
+	_, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err, "the memory store does not persist the session state")
+
+	session, err := s.newSession(s.SessionID, recordingStateStoreFactory{}, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.True(session.PersistSessionState)
+	s.NotNil(session.stateStore)
+	s.Nil(session.resumeState)
+
+	saved := PersistedSessionState{State: "Resend", NextTargetMsgSeqNum: 3, ResendRangeEnd: 5}
+	session, err = s.newSession(s.SessionID, recordingStateStoreFactory{saved: []PersistedSessionState{saved}}, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(&saved, session.resumeState)
+
+	s.SessionSettings.Set(config.PersistSessionState, "N")
+	session, err = s.newSession(s.SessionID, recordingStateStoreFactory{}, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Nil(session.stateStore)
+}
+
+func (s *SessionFactorySuite) TestResetOnLogoutText() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal("NextSeqNum = 1", session.ResetOnLogoutText)
+
+	s.SessionSettings.Set(config.ResetOnLogoutText, "Reset for next day")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal("Reset for next day", session.ResetOnLogoutText)
+}
+
 func (s *SessionFactorySuite) TestEnableLastMsgSeqNumProcessed() {
 	var tests = []struct {
 		setting  string
@@ -456,6 +702,26 @@ func (s *SessionFactorySuite) TestDefaultApplVerID() {
 	}
 }
 
+func (s *SessionFactorySuite) TestTransportDataDictionary() {
+	s.SessionID = SessionID{BeginString: BeginStringFIXT11, TargetCompID: "TW", SenderCompID: "ISLD"}
+	s.SessionSettings.Set(config.DefaultApplVerID, "FIX.5.0SP2")
+	s.SessionSettings.Set(config.TransportDataDictionary, "spec/FIXT11.xml")
+
+	// The transport and app data dictionaries are configured together.
+	_, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err)
+
+	s.SessionSettings.Set(config.AppDataDictionary, "spec/FIX50SP2.xml")
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.NotNil(session.transportDataDictionary)
+	s.NotNil(session.appDataDictionary)
+	validator, ok := session.Validator.(*fixtValidator)
+	s.Require().True(ok)
+	s.Same(session.transportDataDictionary, validator.transportDataDictionary)
+	s.Same(session.appDataDictionary, validator.appDataDictionary)
+}
+
 func (s *SessionFactorySuite) TestNewSessionBuildInitiators() {
 	s.sessionFactory.BuildInitiators = true
 	s.SessionSettings.Set(config.HeartBtInt, "34")
@@ -489,6 +755,21 @@ func (s *SessionFactorySuite) TestDuplicateSession() {
 	s.Nil(err)
 }
 
+func (s *SessionFactorySuite) TestCreateSessionNotifiesApplication() {
+	var calls []string
+	app := chainApp{name: "app", calls: &calls}
+
+	_, err := s.createSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, app)
+	s.Nil(err)
+	defer func() { _ = UnregisterSession(s.SessionID) }()
+	s.Equal([]string{"app.OnSessionCreate", "app.OnCreate"}, calls)
+
+	// A session failing to register is not created.
+	_, err = s.createSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, app)
+	s.NotNil(err)
+	s.Len(calls, 2)
+}
+
 func (s *SessionFactorySuite) TestNewSessionBuildAcceptors() {
 	s.sessionFactory.BuildInitiators = false
 	s.SessionSettings.Set(config.HeartBtInt, "34")
@@ -728,6 +1009,25 @@ func (s *SessionFactorySuite) TestNewSessionMaxLatency() {
 	s.Equal(session.MaxLatency, 20*time.Second)
 }
 
+func (s *SessionFactorySuite) TestNewSessionApplicationCallbackTimeout() {
+	session, err := s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Zero(session.ApplicationCallbackTimeout)
+
+	s.SessionSettings.Set(config.ApplicationCallbackTimeout, "not a number")
+	_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err, "ApplicationCallbackTimeout must be a number")
+
+	s.SessionSettings.Set(config.ApplicationCallbackTimeout, "-1")
+	_, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.NotNil(err, "ApplicationCallbackTimeout must not be negative")
+
+	s.SessionSettings.Set(config.ApplicationCallbackTimeout, "250")
+	session, err = s.newSession(s.SessionID, s.MessageStoreFactory, s.SessionSettings, s.LogFactory, s.App)
+	s.Nil(err)
+	s.Equal(250*time.Millisecond, session.ApplicationCallbackTimeout)
+}
+
 func (s *SessionFactorySuite) TestPersistMessages() {
 	var tests = []struct {
 		setting  string
@@ -15,13 +15,70 @@
 
 package quickfix
 
-import "bytes"
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
 
 // SessionID is a unique identifier of a Session.
 type SessionID struct {
 	BeginString, TargetCompID, TargetSubID, TargetLocationID, SenderCompID, SenderSubID, SenderLocationID, Qualifier string
 }
 
+// SessionIDOption sets an optional field of the SessionID built by NewSessionID.
+type SessionIDOption func(*SessionID)
+
+// WithSenderSubID sets the SenderSubID of a SessionID.
+func WithSenderSubID(senderSubID string) SessionIDOption {
+	return func(s *SessionID) { s.SenderSubID = senderSubID }
+}
+
+// WithSenderLocationID sets the SenderLocationID of a SessionID.
+func WithSenderLocationID(senderLocationID string) SessionIDOption {
+	return func(s *SessionID) { s.SenderLocationID = senderLocationID }
+}
+
+// WithTargetSubID sets the TargetSubID of a SessionID.
+func WithTargetSubID(targetSubID string) SessionIDOption {
+	return func(s *SessionID) { s.TargetSubID = targetSubID }
+}
+
+// WithTargetLocationID sets the TargetLocationID of a SessionID.
+func WithTargetLocationID(targetLocationID string) SessionIDOption {
+	return func(s *SessionID) { s.TargetLocationID = targetLocationID }
+}
+
+// WithQualifier sets the Qualifier of a SessionID.
+func WithQualifier(qualifier string) SessionIDOption {
+	return func(s *SessionID) { s.Qualifier = qualifier }
+}
+
+// NewSessionID returns a SessionID with the given required fields and optional fields. It returns an error
+// if a required field is empty, or if beginString is not one of the supported FIX versions, e.g.
+// BeginStringFIX44 or BeginStringFIXT11.
+func NewSessionID(beginString, senderCompID, targetCompID string, opts ...SessionIDOption) (SessionID, error) {
+	switch beginString {
+	case BeginStringFIX40, BeginStringFIX41, BeginStringFIX42, BeginStringFIX43, BeginStringFIX44, BeginStringFIXT11:
+	case "":
+		return SessionID{}, errors.New("BeginString is required")
+	default:
+		return SessionID{}, fmt.Errorf("BeginString %q is not a supported FIX version", beginString)
+	}
+	if senderCompID == "" {
+		return SessionID{}, errors.New("SenderCompID is required")
+	}
+	if targetCompID == "" {
+		return SessionID{}, errors.New("TargetCompID is required")
+	}
+
+	sessionID := SessionID{BeginString: beginString, SenderCompID: senderCompID, TargetCompID: targetCompID}
+	for _, opt := range opts {
+		opt(&sessionID)
+	}
+	return sessionID, nil
+}
+
 // IsFIXT returns true if the SessionID has a FIXT BeginString.
 func (s SessionID) IsFIXT() bool {
 	return s.BeginString == BeginStringFIXT11
@@ -41,3 +41,30 @@ func TestSessionID_String(t *testing.T) {
 		assert.Equal(t, tc.expectedString, actual)
 	}
 }
+
+func TestNewSessionID(t *testing.T) {
+	sessionID, err := NewSessionID(BeginStringFIX44, "SND", "TAR")
+	assert.Nil(t, err)
+	assert.Equal(t, SessionID{BeginString: "FIX.4.4", SenderCompID: "SND", TargetCompID: "TAR"}, sessionID)
+
+	sessionID, err = NewSessionID(BeginStringFIXT11, "SND", "TAR",
+		WithSenderSubID("SSUB"), WithSenderLocationID("SLOC"),
+		WithTargetSubID("TSUB"), WithTargetLocationID("TLOC"),
+		WithQualifier("BLAH"))
+	assert.Nil(t, err)
+	assert.Equal(t, "FIXT.1.1:SND/SSUB/SLOC->TAR/TSUB/TLOC:BLAH", sessionID.String())
+
+	var testCases = []struct {
+		beginString, senderCompID, targetCompID string
+		expectedErr                             string
+	}{
+		{"", "SND", "TAR", "BeginString is required"},
+		{"FIX.5.0", "SND", "TAR", `BeginString "FIX.5.0" is not a supported FIX version`},
+		{"FIX.4.2", "", "TAR", "SenderCompID is required"},
+		{"FIX.4.2", "SND", "", "TargetCompID is required"},
+	}
+	for _, tc := range testCases {
+		_, err := NewSessionID(tc.beginString, tc.senderCompID, tc.targetCompID)
+		assert.EqualError(t, err, tc.expectedErr)
+	}
+}
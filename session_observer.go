@@ -0,0 +1,150 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import "bytes"
+
+// SessionState identifies the state of a session, as reported to a SessionObserver.
+type SessionState int
+
+// The states of a session.
+const (
+	SessionStateLatent SessionState = iota
+	SessionStateNotSessionTime
+	SessionStateLogon
+	SessionStateInSession
+	SessionStateResend
+	SessionStatePendingTimeout
+	SessionStateLogout
+)
+
+func (s SessionState) String() string {
+	switch s {
+	case SessionStateLatent:
+		return "Latent"
+	case SessionStateNotSessionTime:
+		return "NotSessionTime"
+	case SessionStateLogon:
+		return "Logon"
+	case SessionStateInSession:
+		return "InSession"
+	case SessionStateResend:
+		return "Resend"
+	case SessionStatePendingTimeout:
+		return "PendingTimeout"
+	case SessionStateLogout:
+		return "Logout"
+	}
+	return "Unknown"
+}
+
+// stateOf returns the SessionState of a state of the session state machine.
+func stateOf(state sessionState) SessionState {
+	switch state.(type) {
+	case notSessionTime:
+		return SessionStateNotSessionTime
+	case logonState:
+		return SessionStateLogon
+	case inSession:
+		return SessionStateInSession
+	case resendState:
+		return SessionStateResend
+	case pendingTimeout:
+		return SessionStatePendingTimeout
+	case logoutState:
+		return SessionStateLogout
+	}
+	return SessionStateLatent
+}
+
+// SessionObserver is notified of the state changes and message traffic of the sessions it is registered with by
+// Session.RegisterObserver or RegisterObserver. The methods are called from the goroutine running the session and must not block.
+type SessionObserver interface {
+	// OnStateChange is called when the session moves from the old to the new state.
+	OnStateChange(sessionID SessionID, old, new SessionState)
+
+	// OnMessageSent is called for each message written to the counterparty, including resent messages.
+	OnMessageSent(sessionID SessionID, msgType string)
+
+	// OnMessageReceived is called for each message received from the counterparty that parses.
+	OnMessageReceived(sessionID SessionID, msgType string)
+}
+
+// QueueDepthObserver is a SessionObserver that is also notified of the number of messages a session holds while
+// they wait to be written to the counterparty.
+type QueueDepthObserver interface {
+	SessionObserver
+
+	// OnQueueDepthChange is called when the number of queued messages changes.
+	OnQueueDepthChange(sessionID SessionID, depth int)
+}
+
+func (s *session) registerObserver(obs SessionObserver) {
+	s.observersMu.Lock()
+	defer s.observersMu.Unlock()
+	s.observers = append(s.observers, obs)
+}
+
+func (s *session) notifyObservers(notify func(SessionObserver)) {
+	s.observersMu.RLock()
+	defer s.observersMu.RUnlock()
+	for _, obs := range s.observers {
+		notify(obs)
+	}
+}
+
+func (s *session) notifyStateChange(old, new sessionState) {
+	if old == nil {
+		return
+	}
+	oldState, newState := stateOf(old), stateOf(new)
+	if oldState == newState {
+		return
+	}
+	s.notifyObservers(func(obs SessionObserver) { obs.OnStateChange(s.sessionID, oldState, newState) })
+}
+
+func (s *session) notifyQueueDepth(depth int) {
+	s.notifyObservers(func(obs SessionObserver) {
+		if obs, ok := obs.(QueueDepthObserver); ok {
+			obs.OnQueueDepthChange(s.sessionID, depth)
+		}
+	})
+}
+
+func (s *session) notifyMessageSent(msg []byte) {
+	s.notifyObservers(func(obs SessionObserver) { obs.OnMessageSent(s.sessionID, msgTypeOf(msg)) })
+}
+
+func (s *session) notifyMessageReceived(msg *Message) {
+	s.notifyObservers(func(obs SessionObserver) {
+		msgType, _ := msg.MsgType()
+		obs.OnMessageReceived(s.sessionID, msgType)
+	})
+}
+
+// msgTypeOf returns the MsgType of a raw message, without parsing it.
+func msgTypeOf(msg []byte) string {
+	i := bytes.Index(msg, []byte("\00135="))
+	if i < 0 {
+		return ""
+	}
+	msgType := msg[i+4:]
+	if end := bytes.IndexByte(msgType, '\001'); end >= 0 {
+		msgType = msgType[:end]
+	}
+	return string(msgType)
+}
@@ -16,7 +16,9 @@
 package quickfix
 
 import (
+	"bytes"
 	"fmt"
+	"os"
 	"strconv"
 	"time"
 )
@@ -24,6 +26,9 @@ import (
 // SessionSettings maps session settings to values with typed accessors.
 type SessionSettings struct {
 	settings map[string][]byte
+
+	// expandEnv enables the expansion of ${NAME} references to environment variables in values.
+	expandEnv bool
 }
 
 // ConditionallyRequiredSetting indicates a missing setting.
@@ -46,6 +51,16 @@ func (e IncorrectFormatForSetting) Error() string {
 	return fmt.Sprintf("%q is invalid for %s", e.Value, e.Setting)
 }
 
+// UnsetEnvironmentVariable indicates a setting referencing an environment variable that is not set.
+type UnsetEnvironmentVariable struct {
+	Setting  string
+	Variable string
+}
+
+func (e UnsetEnvironmentVariable) Error() string {
+	return fmt.Sprintf("environment variable %s referenced by %s is not set", e.Variable, e.Setting)
+}
+
 // Init initializes or resets SessionSettings.
 func (s *SessionSettings) Init() {
 	s.settings = make(map[string][]byte)
@@ -86,13 +101,19 @@ func (s *SessionSettings) HasSetting(setting string) bool {
 }
 
 // RawSetting is a settings accessor that returns the raw byte slice value of
-// the setting. Returns an error if the setting is missing.
+// the setting. Returns an error if the setting is missing, or references an
+// environment variable that is not set while environment variable expansion
+// is enabled (see Settings.SetEnvExpansion).
 func (s *SessionSettings) RawSetting(setting string) ([]byte, error) {
 	val, ok := s.settings[setting]
 	if !ok {
 		return nil, ConditionallyRequiredSetting{Setting: setting}
 	}
 
+	if s.expandEnv {
+		return expandEnvVars(setting, val)
+	}
+
 	return val, nil
 }
 
@@ -160,6 +181,7 @@ func (s *SessionSettings) overlay(overlay *SessionSettings) {
 
 func (s *SessionSettings) clone() *SessionSettings {
 	sClone := NewSessionSettings()
+	sClone.expandEnv = s.expandEnv
 
 	for k, v := range s.settings {
 		sClone.settings[k] = v
@@ -167,3 +189,33 @@ func (s *SessionSettings) clone() *SessionSettings {
 
 	return sClone
 }
+
+// expandEnvVars replaces each ${NAME} in the value of setting with the value of the environment variable NAME.
+func expandEnvVars(setting string, val []byte) ([]byte, error) {
+	if !bytes.Contains(val, []byte("${")) {
+		return val, nil
+	}
+
+	var expanded []byte
+	rest := val
+	for {
+		start := bytes.Index(rest, []byte("${"))
+		if start < 0 {
+			break
+		}
+		end := bytes.IndexByte(rest[start:], '}')
+		if end < 0 {
+			break
+		}
+		name := string(rest[start+2 : start+end])
+		envVal, ok := os.LookupEnv(name)
+		if !ok {
+			return nil, UnsetEnvironmentVariable{Setting: setting, Variable: name}
+		}
+		expanded = append(expanded, rest[:start]...)
+		expanded = append(expanded, envVal...)
+		rest = rest[start+end+1:]
+	}
+
+	return append(expanded, rest...), nil
+}
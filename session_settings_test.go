@@ -232,3 +232,47 @@ func TestSessionSettings_Overlay(t *testing.T) {
 		}
 	}
 }
+
+func TestSessionSettings_EnvExpansion(t *testing.T) {
+	t.Setenv("QF_TEST_HOST", "fix.example.com")
+	t.Setenv("QF_TEST_PORT", "5001")
+
+	s := NewSessionSettings()
+	s.Set("SocketConnectHost", "${QF_TEST_HOST}")
+	s.Set("SocketConnectPort", "${QF_TEST_PORT}")
+	s.Set("Address", "tcp://${QF_TEST_HOST}:${QF_TEST_PORT}/fix")
+	s.Set("Unterminated", "${QF_TEST_HOST")
+	s.Set("Missing", "${QF_TEST_UNSET}")
+
+	// Values are read verbatim unless expansion is enabled.
+	if val, err := s.Setting("SocketConnectHost"); err != nil || val != "${QF_TEST_HOST}" {
+		t.Errorf("Expected %v got %v, %v", "${QF_TEST_HOST}", val, err)
+	}
+
+	s.expandEnv = true
+	var tests = []struct {
+		setting  string
+		expected string
+	}{
+		{"SocketConnectHost", "fix.example.com"},
+		{"SocketConnectPort", "5001"},
+		{"Address", "tcp://fix.example.com:5001/fix"},
+		{"Unterminated", "${QF_TEST_HOST"},
+	}
+	for _, test := range tests {
+		if val, err := s.Setting(test.setting); err != nil || val != test.expected {
+			t.Errorf("%v: expected %v got %v, %v", test.setting, test.expected, val, err)
+		}
+	}
+
+	if val, err := s.IntSetting("SocketConnectPort"); err != nil || val != 5001 {
+		t.Errorf("Expected %v got %v, %v", 5001, val, err)
+	}
+
+	_, err := s.Setting("Missing")
+	if err != (UnsetEnvironmentVariable{Setting: "Missing", Variable: "QF_TEST_UNSET"}) {
+		t.Errorf("Expected UnsetEnvironmentVariable error got %v", err)
+	} else if err.Error() != "environment variable QF_TEST_UNSET referenced by Missing is not set" {
+		t.Errorf("Unexpected error message %v", err)
+	}
+}
@@ -96,6 +96,14 @@ func (sm *stateMachine) Incoming(session *session, m fixIn) {
 		session.log.OnEventf("Msg Parse Error: %v, %q", err.Error(), m.bytes)
 	} else {
 		msg.ReceiveTime = m.receiveTime
+		if msg.IsMsgTypeOf(string(msgTypeHeartbeat)) {
+			if testReqID, err := msg.Body.GetString(tagTestReqID); err == nil {
+				if latency, ok := session.latencyTracker.heartbeatReceived(testReqID, msg.ReceiveTime); ok {
+					session.notifyHeartbeat(latency)
+				}
+			}
+		}
+		session.notifyMessageReceived(msg)
 		sm.fixMsgIn(session, msg)
 	}
 
@@ -176,7 +184,11 @@ func (sm *stateMachine) setState(session *session, nextState sessionState) {
 		}
 	}
 
+	prevState := sm.State
 	sm.State = nextState
+	session.loggedOnState.Store(nextState.IsLoggedOn())
+	session.persistState(nextState)
+	session.notifyStateChange(prevState, nextState)
 }
 
 func (sm *stateMachine) notifyInSessionTime() {
@@ -275,7 +287,11 @@ func (loggedOn) ShutdownNow(s *session) {
 }
 
 func (loggedOn) Stop(s *session) (nextState sessionState) {
-	if err := s.initiateLogout(""); err != nil {
+	var reason string
+	if s.ResetOnLogout {
+		reason = s.ResetOnLogoutText
+	}
+	if err := s.initiateLogout(reason); err != nil {
 		return handleStateError(s, err)
 	}
 
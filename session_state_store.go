@@ -0,0 +1,152 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"sort"
+)
+
+// PersistedSessionState is the part of a session's state machine saved by a SessionStateStore, so a
+// restarted process can resume recovering a gap in the messages received from the counterparty. The session
+// itself is not restored: it starts disconnected and logs on again, and only the messages stashed ahead of an
+// outstanding resend are carried over into the resend started on logon.
+type PersistedSessionState struct {
+	// State is the name of the session state when it was saved, e.g. "In Session" or "Resend". It is recorded
+	// for diagnostics and is not restored on start.
+	State string
+	// NextTargetMsgSeqNum is the seqnum expected from the counterparty when the state was saved.
+	NextTargetMsgSeqNum int
+	// ResendRangeEnd is the last seqnum of the ResendRequest outstanding with the counterparty, 0 if none.
+	ResendRangeEnd int
+	// StashedMessages holds the messages received ahead of the outstanding resend, keyed by seqnum.
+	StashedMessages map[int][]byte
+}
+
+// SessionStateStore is implemented by message stores that can persist the session state alongside the
+// messages and seqnums. It is used when PersistSessionState is enabled.
+type SessionStateStore interface {
+	SaveSessionState(state PersistedSessionState) error
+	// LoadSessionState returns the last saved session state, and whether there is one.
+	LoadSessionState() (PersistedSessionState, bool, error)
+}
+
+// sessionStateStore returns the store's SessionStateStore implementation, unwrapping middleware stores.
+func sessionStateStore(store MessageStore) (SessionStateStore, bool) {
+	for store != nil {
+		if stateStore, ok := store.(SessionStateStore); ok {
+			return stateStore, true
+		}
+		unwrapper, ok := store.(StoreUnwrapper)
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// persistState saves the state the session moved to. Moving through the same state again, e.g. on every
+// message received in session, is not saved.
+func (s *session) persistState(state sessionState) {
+	// The state loaded on start is kept until it has been resumed.
+	if s.stateStore == nil || s.resumeState != nil {
+		return
+	}
+
+	persisted := PersistedSessionState{State: state.String(), NextTargetMsgSeqNum: s.store.NextTargetMsgSeqNum()}
+	var stash map[int]*Message
+	switch state := state.(type) {
+	case resendState:
+		persisted.ResendRangeEnd, stash = state.resendRangeEnd, state.messageStash
+	case pendingTimeout:
+		if resend, ok := state.sessionState.(resendState); ok {
+			persisted.ResendRangeEnd, stash = resend.resendRangeEnd, resend.messageStash
+		}
+	}
+	if len(stash) > 0 {
+		persisted.StashedMessages = make(map[int][]byte, len(stash))
+		for seqNum, msg := range stash {
+			persisted.StashedMessages[seqNum] = msg.Bytes()
+		}
+	}
+
+	if s.lastPersistedState != nil && samePersistedState(*s.lastPersistedState, persisted) {
+		return
+	}
+	if err := s.stateStore.SaveSessionState(persisted); err != nil {
+		s.logError(err)
+		return
+	}
+	s.lastPersistedState = &persisted
+}
+
+// samePersistedState reports whether saving b in place of a can be skipped. NextTargetMsgSeqNum only matters
+// while messages are stashed, as it guards their resumption; comparing it otherwise would save the state on
+// every message received in session.
+func samePersistedState(a, b PersistedSessionState) bool {
+	if a.State != b.State || a.ResendRangeEnd != b.ResendRangeEnd || len(a.StashedMessages) != len(b.StashedMessages) {
+		return false
+	}
+	if len(b.StashedMessages) > 0 && a.NextTargetMsgSeqNum != b.NextTargetMsgSeqNum {
+		return false
+	}
+	for seqNum, msg := range b.StashedMessages {
+		if stashed, ok := a.StashedMessages[seqNum]; !ok || !bytes.Equal(stashed, msg) {
+			return false
+		}
+	}
+	return true
+}
+
+// resumePersistedState merges the messages stashed before a restart into the resend started on logon, so
+// they need not be received again. It only applies to the first logon after the session state was loaded.
+func (s *session) resumePersistedState(next sessionState) sessionState {
+	resumed := s.resumeState
+	s.resumeState = nil
+	if resumed == nil || len(resumed.StashedMessages) == 0 {
+		return next
+	}
+
+	resend, ok := next.(resendState)
+	if !ok || s.store.NextTargetMsgSeqNum() < resumed.NextTargetMsgSeqNum {
+		s.log.OnEventf("Discarding %d messages stashed before restart", len(resumed.StashedMessages))
+		return next
+	}
+	if resend.messageStash == nil {
+		resend.messageStash = make(map[int]*Message)
+	}
+
+	seqNums := make([]int, 0, len(resumed.StashedMessages))
+	for seqNum := range resumed.StashedMessages {
+		seqNums = append(seqNums, seqNum)
+	}
+	sort.Ints(seqNums)
+	for _, seqNum := range seqNums {
+		if seqNum < s.store.NextTargetMsgSeqNum() {
+			continue
+		}
+		msg := NewMessage()
+		err := ParseMessageWithDataDictionary(msg, bytes.NewBuffer(resumed.StashedMessages[seqNum]), s.transportDataDictionary, s.appDataDictionary)
+		if err != nil {
+			s.logError(err)
+			continue
+		}
+		resend.messageStash[seqNum] = msg
+	}
+	s.log.OnEventf("Resumed %d messages stashed before restart", len(resend.messageStash))
+	return resend
+}
@@ -0,0 +1,152 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+)
+
+// recordingStateStore is a MessageStore middleware that keeps the session states saved to it.
+type recordingStateStore struct {
+	MessageStore
+	saved []PersistedSessionState
+}
+
+func (s *recordingStateStore) Unwrap() MessageStore { return s.MessageStore }
+
+func (s *recordingStateStore) SaveSessionState(state PersistedSessionState) error {
+	s.saved = append(s.saved, state)
+	return nil
+}
+
+func (s *recordingStateStore) LoadSessionState() (PersistedSessionState, bool, error) {
+	if len(s.saved) == 0 {
+		return PersistedSessionState{}, false, nil
+	}
+	return s.saved[len(s.saved)-1], true, nil
+}
+
+type SessionStateStoreSuite struct {
+	SessionSuiteRig
+	stateStore *recordingStateStore
+}
+
+func TestSessionStateStoreSuite(t *testing.T) {
+	suite.Run(t, new(SessionStateStoreSuite))
+}
+
+func (s *SessionStateStoreSuite) SetupTest() {
+	s.Init()
+	s.Require().Nil(s.session.store.Reset())
+	s.session.State = latentState{}
+	s.stateStore = &recordingStateStore{MessageStore: s.session.store}
+	s.session.stateStore = s.stateStore
+}
+
+// stashedMessage returns a NewOrderSingle received with the given seqnum.
+func (s *SessionStateStoreSuite) stashedMessage(seqNum int) *Message {
+	msg := s.NewOrderSingle()
+	msg.Header.SetField(tagBeginString, FIXString(s.sessionID.BeginString))
+	msg.Header.SetField(tagMsgSeqNum, FIXInt(seqNum))
+	msg.build()
+	return msg
+}
+
+func (s *SessionStateStoreSuite) TestPersistState() {
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(3))
+	s.session.setState(s.session, inSession{})
+	s.session.setState(s.session, inSession{})
+	s.Require().Len(s.stateStore.saved, 1)
+	s.Equal(PersistedSessionState{State: "In Session", NextTargetMsgSeqNum: 3}, s.stateStore.saved[0])
+
+	stashed := s.stashedMessage(6)
+	resend := resendState{resendRangeEnd: 5, messageStash: map[int]*Message{6: stashed}}
+	s.session.setState(s.session, resend)
+	s.session.setState(s.session, pendingTimeout{resend})
+	s.Require().Len(s.stateStore.saved, 2)
+	s.Equal(PersistedSessionState{
+		State:               "Resend",
+		NextTargetMsgSeqNum: 3,
+		ResendRangeEnd:      5,
+		StashedMessages:     map[int][]byte{6: stashed.Bytes()},
+	}, s.stateStore.saved[1])
+
+	// A stash of the same size holding other messages is saved.
+	delete(resend.messageStash, 6)
+	resend.messageStash[7] = s.stashedMessage(7)
+	s.session.setState(s.session, pendingTimeout{resend})
+	s.Require().Len(s.stateStore.saved, 3)
+	s.Equal(map[int][]byte{7: resend.messageStash[7].Bytes()}, s.stateStore.saved[2].StashedMessages)
+
+	// So is the expected seqnum, while messages are stashed.
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(4))
+	s.session.setState(s.session, pendingTimeout{resend})
+	s.Require().Len(s.stateStore.saved, 4)
+	s.Equal(4, s.stateStore.saved[3].NextTargetMsgSeqNum)
+
+	delete(resend.messageStash, 7)
+	s.session.setState(s.session, pendingTimeout{resend})
+	s.Require().Len(s.stateStore.saved, 5)
+	s.Empty(s.stateStore.saved[4].StashedMessages)
+
+	// Without a stash, a new expected seqnum alone is not saved.
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(5))
+	s.session.setState(s.session, pendingTimeout{resend})
+	s.Len(s.stateStore.saved, 5)
+
+	// The state loaded on start is not overwritten before it has been resumed.
+	s.session.resumeState = &PersistedSessionState{State: "Resend"}
+	s.session.setState(s.session, inSession{})
+	s.Len(s.stateStore.saved, 5)
+}
+
+func (s *SessionStateStoreSuite) TestResumePersistedState() {
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(3))
+	s.session.resumeState = &PersistedSessionState{
+		State:               "Resend",
+		NextTargetMsgSeqNum: 3,
+		ResendRangeEnd:      4,
+		StashedMessages:     map[int][]byte{2: s.stashedMessage(2).Bytes(), 5: s.stashedMessage(5).Bytes()},
+	}
+
+	next := s.session.resumePersistedState(resendState{resendRangeEnd: 4})
+	s.Require().IsType(resendState{}, next)
+	stash := next.(resendState).messageStash
+	s.Require().Len(stash, 1)
+	s.Equal(s.stashedMessage(5).String(), stash[5].String())
+	s.Nil(s.session.resumeState)
+
+	// The state is only resumed once.
+	next = s.session.resumePersistedState(resendState{resendRangeEnd: 4})
+	s.Nil(next.(resendState).messageStash)
+}
+
+func (s *SessionStateStoreSuite) TestResumePersistedStateDiscarded() {
+	stash := map[int][]byte{5: s.stashedMessage(5).Bytes()}
+
+	// Without a resend in progress on logon, the stashed messages will be received again.
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(3))
+	s.session.resumeState = &PersistedSessionState{State: "Resend", NextTargetMsgSeqNum: 3, StashedMessages: stash}
+	s.Equal(inSession{}, s.session.resumePersistedState(inSession{}))
+
+	// The seqnums have been reset since the state was saved.
+	s.Require().Nil(s.session.store.SetNextTargetMsgSeqNum(1))
+	s.session.resumeState = &PersistedSessionState{State: "Resend", NextTargetMsgSeqNum: 3, StashedMessages: stash}
+	next := s.session.resumePersistedState(resendState{resendRangeEnd: 4})
+	s.Nil(next.(resendState).messageStash)
+}
@@ -17,6 +17,8 @@ package quickfix
 
 import (
 	"bytes"
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -24,6 +26,7 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/time/rate"
 )
 
 func newFIXString(val string) *FIXString {
@@ -720,6 +723,62 @@ func (s *SessionSuite) TestOnAdminConnectInitiateLogonFIXT11() {
 	s.FieldEquals(tagDefaultApplVerID, "8", s.MockApp.lastToAdmin.Body)
 }
 
+func (s *SessionSuite) TestOnAdminConnectInitiateLogonLogonFields() {
+	var err error
+	s.session.logonFields, err = parseLogonFields("553=user;554=${QF_TEST_LOGON_PASSWORD};115=BROKER")
+	s.Require().Nil(err)
+	s.session.InitiateLogon = true
+	s.T().Setenv("QF_TEST_LOGON_PASSWORD", "secret")
+
+	adminMsg := connect{
+		messageOut: s.Receiver.sendChannel,
+	}
+	s.session.State = latentState{}
+
+	s.MockApp.On("ToAdmin")
+	s.session.onAdmin(adminMsg)
+
+	s.MockApp.AssertExpectations(s.T())
+	s.State(logonState{})
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogon), s.MockApp.lastToAdmin)
+	s.FieldEquals(Tag(553), "user", s.MockApp.lastToAdmin.Body)
+	s.FieldEquals(Tag(554), "secret", s.MockApp.lastToAdmin.Body)
+	s.FieldEquals(Tag(115), "BROKER", s.MockApp.lastToAdmin.Header)
+}
+
+func (s *SessionSuite) TestOnAdminConnectInitiateLogonEncryptMethod() {
+	s.session.InitiateLogon = true
+	s.session.encryptedTags = []Tag{tagText}
+	s.session.encryptMethod = 5
+	s.session.setEncryptionProvider(reverseEncryptionProvider{})
+	s.session.State = latentState{}
+
+	s.MockApp.On("ToAdmin")
+	s.session.onAdmin(connect{messageOut: s.Receiver.sendChannel})
+
+	s.MockApp.AssertExpectations(s.T())
+	s.LastToAdminMessageSent()
+	s.MessageType(string(msgTypeLogon), s.MockApp.lastToAdmin)
+	s.FieldEquals(tagEncryptMethod, 5, s.MockApp.lastToAdmin.Body)
+}
+
+func (s *SessionSuite) TestOnAdminConnectInitiateLogonLogonFieldsUnsetEnv() {
+	var err error
+	s.session.logonFields, err = parseLogonFields("554=${QF_TEST_UNSET_LOGON_PASSWORD}")
+	s.Require().Nil(err)
+	s.session.InitiateLogon = true
+
+	adminMsg := connect{
+		messageOut: s.Receiver.sendChannel,
+	}
+	s.session.State = latentState{}
+	s.session.onAdmin(adminMsg)
+
+	s.NoMessageSent()
+	s.State(latentState{})
+}
+
 func (s *SessionSuite) TestOnAdminConnectRefreshOnLogon() {
 	var tests = []bool{true, false}
 
@@ -825,6 +884,18 @@ func (suite *SessionSendTestSuite) TestQueueForSendAppMessage() {
 	suite.NextSenderMsgSeqNum(2)
 }
 
+func (suite *SessionSendTestSuite) TestSessionSend() {
+	ref := &Session{session: suite.session}
+
+	suite.MockApp.On("ToApp").Return(nil)
+	require.Nil(suite.T(), ref.Send(suite.NewOrderSingle()))
+
+	suite.MockApp.AssertExpectations(suite.T())
+	suite.MessagePersisted(suite.MockApp.lastToApp)
+	suite.FieldEquals(tagMsgSeqNum, 1, suite.MockApp.lastToApp.Header)
+	suite.NextSenderMsgSeqNum(2)
+}
+
 func (suite *SessionSendTestSuite) TestQueueForSendDoNotSendAppMessage() {
 	suite.MockApp.On("ToApp").Return(ErrDoNotSend)
 	suite.Equal(ErrDoNotSend, suite.queueForSend(suite.NewOrderSingle()))
@@ -853,6 +924,87 @@ func (suite *SessionSendTestSuite) TestQueueForSendAdminMessage() {
 	suite.NextSenderMsgSeqNum(2)
 }
 
+type queueDepthObserver struct {
+	recordingObserver
+	depths []int
+}
+
+func (o *queueDepthObserver) OnQueueDepthChange(_ SessionID, depth int) {
+	o.depths = append(o.depths, depth)
+}
+
+func (suite *SessionSendTestSuite) TestQueueForSendQueueFull() {
+	obs := new(queueDepthObserver)
+	suite.session.registerObserver(obs)
+	suite.session.MaxPendingOutboundMessages = 2
+
+	suite.MockApp.On("ToApp").Return(nil)
+	require.Nil(suite.T(), suite.queueForSend(suite.NewOrderSingle()))
+	require.Nil(suite.T(), suite.queueForSend(suite.NewOrderSingle()))
+	suite.Equal(ErrSessionQueueFull, suite.queueForSend(suite.NewOrderSingle()))
+
+	suite.NoMessagePersisted(3)
+	suite.NoMessageSent()
+	suite.NextSenderMsgSeqNum(3)
+	suite.EqualValues(2, suite.session.queueDepth.Load())
+	suite.Equal([]int{1, 2}, obs.depths)
+
+	suite.MockApp.On("ToAdmin")
+	require.Nil(suite.T(), suite.send(suite.Heartbeat()))
+	suite.EqualValues(0, suite.session.queueDepth.Load())
+	suite.Equal([]int{1, 2, 0}, obs.depths)
+}
+
+func (suite *SessionSendTestSuite) TestQueueForSendRateLimitExceeded() {
+	suite.session.outboundLimiter = rate.NewLimiter(rate.Every(time.Hour), 2)
+
+	suite.MockApp.On("ToApp").Return(nil)
+	require.Nil(suite.T(), suite.queueForSend(suite.NewOrderSingle()))
+	require.Nil(suite.T(), suite.queueForSend(suite.NewOrderSingle()))
+	suite.Equal(ErrRateLimitExceeded, suite.queueForSend(suite.NewOrderSingle()))
+
+	suite.NoMessagePersisted(3)
+	suite.NextSenderMsgSeqNum(3)
+	suite.EqualValues(2, suite.session.queueDepth.Load())
+
+	// Admin messages are not limited.
+	suite.MockApp.On("ToAdmin")
+	require.Nil(suite.T(), suite.send(suite.Heartbeat()))
+	suite.NextSenderMsgSeqNum(4)
+}
+
+func (suite *SessionSendTestSuite) TestSendEncryptedTags() {
+	suite.session.encryptedTags = []Tag{tagText}
+	suite.session.setEncryptionProvider(reverseEncryptionProvider{})
+
+	msg := suite.NewOrderSingle()
+	msg.Body.SetString(tagText, "secret")
+	suite.MockApp.On("ToApp").Return(nil)
+	require.Nil(suite.T(), suite.send(msg))
+	suite.MockApp.AssertExpectations(suite.T())
+
+	// The message is sent and persisted encrypted, while the caller's message keeps its clear value.
+	suite.FieldEquals(tagText, "secret", msg.Body)
+	sent, ok := suite.Receiver.LastMessage()
+	suite.Require().True(ok)
+	suite.Contains(string(sent), "58=terces\x01")
+	persisted, err := suite.session.store.GetMessages(1, 1)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{sent}, persisted)
+}
+
+func (suite *SessionSendTestSuite) TestSendEncryptedTagsWithoutProvider() {
+	suite.session.encryptedTags = []Tag{tagText}
+
+	msg := suite.NewOrderSingle()
+	msg.Body.SetString(tagText, "secret")
+	suite.MockApp.On("ToApp").Return(nil)
+	suite.Equal(errNoEncryptionProvider, suite.send(msg))
+
+	suite.NoMessagePersisted(1)
+	suite.NoMessageSent()
+}
+
 func (suite *SessionSendTestSuite) TestSendAppMessage() {
 	suite.MockApp.On("ToApp").Return(nil)
 	require.Nil(suite.T(), suite.send(suite.NewOrderSingle()))
@@ -1026,3 +1178,246 @@ func (s *SessionSuite) TestSeqNumResetTime() {
 	s.NextSenderMsgSeqNum(2)
 
 }
+
+func (s *SessionSuite) TestCheckStoreHealth() {
+	s.session.State = inSession{}
+	s.session.StoreHealthCheckInterval = time.Second
+	s.session.StoreHealthCheckMaxFailures = 2
+	s.MockStore.On("HealthCheck").Return(errors.New("disk full")).Twice()
+	s.MockStore.On("HealthCheck").Return(nil)
+
+	now := time.Now()
+	s.session.checkStoreHealth(now)
+	s.State(inSession{})
+
+	// Checks are spaced by the interval.
+	s.session.checkStoreHealth(now.Add(500 * time.Millisecond))
+	s.MockStore.AssertNumberOfCalls(s.T(), "HealthCheck", 1)
+
+	s.MockApp.On("OnLogout")
+	s.session.checkStoreHealth(now.Add(time.Second))
+	s.MockApp.AssertExpectations(s.T())
+	s.State(latentState{})
+	s.Disconnected()
+
+	s.session.checkStoreHealth(now.Add(2 * time.Second))
+	s.MockStore.AssertNumberOfCalls(s.T(), "HealthCheck", 3)
+	s.Zero(s.session.storeHealthFailures)
+}
+
+func (s *SessionSuite) TestCheckStoreHealthDisabled() {
+	s.session.State = inSession{}
+	s.session.checkStoreHealth(time.Now())
+	s.MockStore.AssertNotCalled(s.T(), "HealthCheck")
+}
+
+func (s *SessionSuite) TestRequestStoppedSession() {
+	s.session.admin = make(chan interface{})
+	stopped := make(chan struct{})
+	go func() {
+		s.session.run()
+		close(stopped)
+	}()
+	s.Eventually(func() bool { return s.session.loopDone.Load() != nil }, time.Second, time.Millisecond)
+
+	s.session.stop()
+	<-stopped
+	rep := make(chan error, 1)
+	s.Equal(errSessionNotRunning, s.session.request(forceLogoutReq{reason: "rogue counterparty", err: rep}, rep))
+}
+
+// contextApp is a MockApp processing app messages through FromAppContext.
+type contextApp struct {
+	*MockApp
+	delay  time.Duration
+	reject MessageRejectError
+	// release, if set, makes FromAppContext ignore its context and wait for release to be closed.
+	release <-chan struct{}
+}
+
+func (app contextApp) FromAppContext(ctx context.Context, _ *Message, _ SessionID) MessageRejectError {
+	if app.release != nil {
+		<-app.release
+		return app.reject
+	}
+	select {
+	case <-time.After(app.delay):
+		return app.reject
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (s *SessionSuite) TestFromAppContext() {
+	reject := UnsupportedMessageType()
+	s.session.application = contextApp{MockApp: &s.MockApp, reject: reject}
+	s.Equal(reject, s.session.fromCallback(s.NewOrderSingle()))
+
+	// Without a timeout, the callback is waited for.
+	s.session.application = contextApp{MockApp: &s.MockApp, delay: 20 * time.Millisecond, reject: reject}
+	s.Equal(reject, s.session.fromCallback(s.NewOrderSingle()))
+
+	// FromApp is not called for applications implementing ApplicationWithContext.
+	s.MockApp.AssertNotCalled(s.T(), "FromApp")
+}
+
+func (s *SessionSuite) TestFromAppContextTimeout() {
+	s.session.ApplicationCallbackTimeout = 10 * time.Millisecond
+	s.session.application = contextApp{MockApp: &s.MockApp, delay: time.Minute, reject: UnsupportedMessageType()}
+
+	start := time.Now()
+	s.Nil(s.session.fromCallback(s.NewOrderSingle()), "the message should be dropped")
+	s.Less(time.Since(start), time.Second)
+
+	// Admin messages are not affected.
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.Nil(s.session.fromCallback(s.Heartbeat()))
+	s.MockApp.AssertExpectations(s.T())
+}
+
+func (s *SessionSuite) TestFromAppContextTimeoutIgnoringContext() {
+	s.session.State = inSession{}
+	s.session.ApplicationCallbackTimeout = 10 * time.Millisecond
+	release := make(chan struct{})
+	defer close(release)
+	s.session.application = contextApp{MockApp: &s.MockApp, reject: UnsupportedMessageType(), release: release}
+
+	// Callbacks that never return do not hold up the session: each message is dropped once the timeout fires,
+	// without a reject, and the next one is processed.
+	for seqNum := 1; seqNum <= 2; seqNum++ {
+		msg := s.NewOrderSingle()
+		msg.Header.SetInt(tagMsgSeqNum, seqNum)
+		s.fixMsgIn(s.session, msg)
+		s.NextTargetMsgSeqNum(seqNum + 1)
+	}
+	s.NoMessageSent()
+	s.State(inSession{})
+}
+
+type recordingObserver struct {
+	stateChanges []string
+	sent         []string
+	received     []string
+}
+
+func (o *recordingObserver) OnStateChange(_ SessionID, old, new SessionState) {
+	o.stateChanges = append(o.stateChanges, old.String()+"->"+new.String())
+}
+
+func (o *recordingObserver) OnMessageSent(_ SessionID, msgType string) {
+	o.sent = append(o.sent, msgType)
+}
+
+func (o *recordingObserver) OnMessageReceived(_ SessionID, msgType string) {
+	o.received = append(o.received, msgType)
+}
+
+func (s *SessionSuite) TestObserver() {
+	obs := new(recordingObserver)
+	s.session.registerObserver(obs)
+
+	s.session.State = latentState{}
+	s.session.HeartBtInt = time.Duration(45) * time.Second
+	s.session.InitiateLogon = true
+	s.MockApp.On("ToAdmin")
+	s.session.onAdmin(connect{messageOut: s.Receiver.sendChannel})
+	s.State(logonState{})
+	s.Equal([]string{"Latent->Logon"}, obs.stateChanges)
+	s.Equal([]string{string(msgTypeLogon)}, obs.sent)
+
+	s.session.State = inSession{}
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(s.Heartbeat().build())})
+	s.State(inSession{})
+	s.Equal([]string{string(msgTypeHeartbeat)}, obs.received)
+
+	s.MockApp.On("OnLogout")
+	s.session.stateMachine.setState(s.session, latentState{})
+	s.Equal([]string{"Latent->Logon", "InSession->Latent"}, obs.stateChanges)
+}
+
+// heartbeatListenerApp records the latencies passed to OnHeartbeat.
+type heartbeatListenerApp struct {
+	*MockApp
+	latencies []int64
+}
+
+func (a *heartbeatListenerApp) OnHeartbeat(_ SessionID, latencyMs int64) {
+	a.latencies = append(a.latencies, latencyMs)
+}
+
+func (s *SessionSuite) TestHeartbeatListener() {
+	app := &heartbeatListenerApp{MockApp: &s.MockApp}
+	s.session.application = app
+	s.session.latencyTracker = newHeartbeatLatencyTracker()
+	s.session.State = inSession{}
+	s.session.HeartBtInt = time.Duration(45) * time.Second
+
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.PeerTimeout)
+
+	heartbeat := s.Heartbeat()
+	heartbeat.Body.SetField(tagTestReqID, FIXString("TEST"))
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(heartbeat.build()), receiveTime: time.Now().Add(time.Second)})
+	s.State(inSession{})
+	s.Require().Len(app.latencies, 1)
+	s.GreaterOrEqual(app.latencies[0], int64(1000))
+
+	// Heartbeats not answering a TestRequest are not notified.
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(s.Heartbeat().build()), receiveTime: time.Now()})
+	s.Len(app.latencies, 1)
+}
+
+// rejectionListenerApp records the messages passed to OnMessageRejected.
+type rejectionListenerApp struct {
+	*MockApp
+	rejected []*Message
+	reasons  []error
+}
+
+func (a *rejectionListenerApp) OnMessageRejected(_ SessionID, msg *Message, reason error) {
+	a.rejected = append(a.rejected, msg)
+	a.reasons = append(a.reasons, reason)
+}
+
+func (s *SessionSuite) TestMessageRejectionListener() {
+	app := &rejectionListenerApp{MockApp: &s.MockApp}
+	s.session.application = app
+	s.session.State = inSession{}
+
+	s.MockApp.On("FromApp").Return(ConditionallyRequiredFieldMissing(Tag(11)))
+	s.MockApp.On("ToApp").Return(nil)
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(s.NewOrderSingle().build())})
+	s.LastToAppMessageSent()
+	s.MessageType("j", s.MockApp.lastToApp)
+
+	s.Require().Len(app.rejected, 1)
+	s.MessageType("D", app.rejected[0])
+	s.Equal(ConditionallyRequiredFieldMissing(Tag(11)), app.reasons[0])
+}
+
+func (s *SessionSuite) TestHeartbeatLatencyTracker() {
+	s.session.latencyTracker = newHeartbeatLatencyTracker()
+	s.session.State = inSession{}
+	s.session.HeartBtInt = time.Duration(45) * time.Second
+
+	s.MockApp.On("ToAdmin")
+	s.session.Timeout(s.session, internal.PeerTimeout)
+	s.State(pendingTimeout{inSession{}})
+
+	heartbeat := s.Heartbeat()
+	heartbeat.Body.SetField(tagTestReqID, FIXString("TEST"))
+	s.MockApp.On("FromAdmin").Return(nil)
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(heartbeat.build()), receiveTime: time.Now().Add(time.Second)})
+	s.State(inSession{})
+
+	samples := s.session.latencyTracker.Samples()
+	s.Require().Len(samples, 1)
+	s.GreaterOrEqual(samples[0], time.Second)
+	s.Equal(samples[0], s.session.latencyTracker.P50())
+
+	// Heartbeats not answering a TestRequest are not sampled.
+	s.session.Incoming(s.session, fixIn{bytes: bytes.NewBuffer(s.Heartbeat().build()), receiveTime: time.Now()})
+	s.Len(s.session.latencyTracker.Samples(), 1)
+}
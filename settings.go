@@ -17,10 +17,14 @@ package quickfix
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"regexp"
+	"slices"
+	"sort"
+	"strings"
 
 	"github.com/quickfixgo/quickfix/config"
 )
@@ -29,6 +33,7 @@ import (
 type Settings struct {
 	globalSettings  *SessionSettings
 	sessionSettings map[SessionID]*SessionSettings
+	envExpansion    bool
 }
 
 // Init initializes or resets a Settings instance.
@@ -55,41 +60,50 @@ func sessionIDFromSessionSettings(globalSettings *SessionSettings, sessionSettin
 
 	for _, settings := range []*SessionSettings{globalSettings, sessionSettings} {
 		if settings.HasSetting(config.BeginString) {
-			sessionID.BeginString, _ = settings.Setting(config.BeginString)
+			sessionID.BeginString = sessionIDSetting(settings, config.BeginString)
 		}
 
 		if settings.HasSetting(config.TargetCompID) {
-			sessionID.TargetCompID, _ = settings.Setting(config.TargetCompID)
+			sessionID.TargetCompID = sessionIDSetting(settings, config.TargetCompID)
 		}
 
 		if settings.HasSetting(config.TargetSubID) {
-			sessionID.TargetSubID, _ = settings.Setting(config.TargetSubID)
+			sessionID.TargetSubID = sessionIDSetting(settings, config.TargetSubID)
 		}
 
 		if settings.HasSetting(config.TargetLocationID) {
-			sessionID.TargetLocationID, _ = settings.Setting(config.TargetLocationID)
+			sessionID.TargetLocationID = sessionIDSetting(settings, config.TargetLocationID)
 		}
 
 		if settings.HasSetting(config.SenderCompID) {
-			sessionID.SenderCompID, _ = settings.Setting(config.SenderCompID)
+			sessionID.SenderCompID = sessionIDSetting(settings, config.SenderCompID)
 		}
 
 		if settings.HasSetting(config.SenderSubID) {
-			sessionID.SenderSubID, _ = settings.Setting(config.SenderSubID)
+			sessionID.SenderSubID = sessionIDSetting(settings, config.SenderSubID)
 		}
 
 		if settings.HasSetting(config.SenderLocationID) {
-			sessionID.SenderLocationID, _ = settings.Setting(config.SenderLocationID)
+			sessionID.SenderLocationID = sessionIDSetting(settings, config.SenderLocationID)
 		}
 
 		if settings.HasSetting(config.SessionQualifier) {
-			sessionID.Qualifier, _ = settings.Setting(config.SessionQualifier)
+			sessionID.Qualifier = sessionIDSetting(settings, config.SessionQualifier)
 		}
 	}
 
 	return sessionID
 }
 
+// sessionIDSetting returns the value of a setting that is part of the SessionID. A value referencing an unset
+// environment variable is returned unexpanded, so that the variable remains visible in the SessionID.
+func sessionIDSetting(settings *SessionSettings, setting string) string {
+	if val, err := settings.Setting(setting); err == nil {
+		return val
+	}
+	return string(settings.settings[setting])
+}
+
 // ParseSettings creates and initializes a Settings instance with config parsed from a Reader.
 // Returns error if the config is has parse errors.
 func ParseSettings(reader io.Reader) (*Settings, error) {
@@ -167,18 +181,12 @@ func (s *Settings) SessionSettings() map[SessionID]*SessionSettings {
 // AddSession adds Session Settings to Settings instance. Returns an error if session settings with duplicate sessionID has already been added.
 func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, error) {
 	s.lazyInit()
+	sessionSettings.expandEnv = s.envExpansion
 
 	sessionID := sessionIDFromSessionSettings(s.GlobalSettings(), sessionSettings)
 
-	switch sessionID.BeginString {
-	case BeginStringFIX40:
-	case BeginStringFIX41:
-	case BeginStringFIX42:
-	case BeginStringFIX43:
-	case BeginStringFIX44:
-	case BeginStringFIXT11:
-	default:
-		return sessionID, errors.New("BeginString must be FIX.4.0 to FIX.4.4 or FIXT.1.1")
+	if err := validateBeginString(sessionID); err != nil {
+		return sessionID, err
 	}
 
 	if _, dup := s.sessionSettings[sessionID]; dup {
@@ -189,3 +197,240 @@ func (s *Settings) AddSession(sessionSettings *SessionSettings) (SessionID, erro
 
 	return sessionID, nil
 }
+
+// SetEnvExpansion enables or disables the expansion of ${NAME} references to environment variables in the values of
+// the global and session settings, e.g. SenderCompID=${FIX_SENDER_COMP_ID}. Expansion is disabled by default. While it
+// is enabled, reading a setting that references an unset variable returns an UnsetEnvironmentVariable error.
+//
+// The SessionIDs of the sessions are resolved again with the new setting. Should two sessions resolve to the same
+// SessionID, the second keeps its previous SessionID, so that adding it to an Initiator or Acceptor fails.
+func (s *Settings) SetEnvExpansion(enabled bool) {
+	s.lazyInit()
+	s.envExpansion = enabled
+	s.globalSettings.expandEnv = enabled
+
+	resolved := make(map[SessionID]*SessionSettings)
+	var unresolved []SessionID
+	for sessionID, settings := range s.sessionSettings {
+		settings.expandEnv = enabled
+		newID := sessionIDFromSessionSettings(s.globalSettings, settings)
+		if _, dup := resolved[newID]; dup {
+			unresolved = append(unresolved, sessionID)
+			continue
+		}
+		resolved[newID] = settings
+	}
+	for _, sessionID := range unresolved {
+		resolved[sessionID] = s.sessionSettings[sessionID]
+	}
+	s.sessionSettings = resolved
+}
+
+// MergeFrom layers other on top of s. The global settings of other overlay those of s, and the session settings of
+// other overlay those of the session of s with the same SessionID, or are added as a new session. Merging from a
+// venue overlay and then a session level override thus lets the last layer win on conflicting keys.
+//
+// The SessionIDs of all sessions are resolved again against the merged global settings, since an overlay may change
+// settings sessions inherit from them. Returns an error, leaving s unchanged, if two sessions resolve to the same
+// SessionID or a session no longer has a valid BeginString.
+func (s *Settings) MergeFrom(other *Settings) error {
+	s.lazyInit()
+	other.lazyInit()
+
+	globalSettings := s.globalSettings.clone()
+	globalSettings.overlay(other.globalSettings)
+
+	// Sessions are resolved as they will be read, with or without environment variable expansion.
+	otherSessions := make(map[SessionID]*SessionSettings, len(other.sessionSettings))
+	for sessionID, settings := range other.sessionSettings {
+		settings = settings.clone()
+		settings.expandEnv = s.envExpansion
+		otherSessions[sessionID] = settings
+	}
+
+	merged := make(map[SessionID]*SessionSettings)
+	for _, source := range []map[SessionID]*SessionSettings{s.sessionSettings, otherSessions} {
+		resolved := make(map[SessionID]bool)
+		for _, settings := range source {
+			sessionID := sessionIDFromSessionSettings(globalSettings, settings)
+			if resolved[sessionID] {
+				return fmt.Errorf("duplicate session configured for %v", sessionID)
+			}
+			resolved[sessionID] = true
+
+			if existing, ok := merged[sessionID]; ok {
+				existing.overlay(settings)
+				continue
+			}
+			if err := validateBeginString(sessionID); err != nil {
+				return err
+			}
+			merged[sessionID] = settings.clone()
+		}
+	}
+
+	s.globalSettings = globalSettings
+	s.sessionSettings = merged
+	return nil
+}
+
+// WriteToWriter writes s to w in the format read by ParseSettings: the global settings in a [DEFAULT] section,
+// followed by a [SESSION] section for each session, holding its own settings only. Sessions are written in the order
+// of their SessionIDs, and settings in the order of their names. Returns an error if a setting cannot be represented
+// in the format.
+func (s *Settings) WriteToWriter(w io.Writer) error {
+	s.lazyInit()
+
+	sessionIDs := make([]SessionID, 0, len(s.sessionSettings))
+	for sessionID := range s.sessionSettings {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	bw := bufio.NewWriter(w)
+	if err := writeSection(bw, "DEFAULT", s.globalSettings); err != nil {
+		return err
+	}
+	for _, sessionID := range sessionIDs {
+		if _, err := bw.WriteString("\n"); err != nil {
+			return err
+		}
+		if err := writeSection(bw, "SESSION", s.sessionSettings[sessionID]); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func writeSection(w *bufio.Writer, name string, settings *SessionSettings) error {
+	keys := make([]string, 0, len(settings.settings))
+	for key := range settings.settings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if _, err := fmt.Fprintf(w, "[%s]\n", name); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		val := settings.settings[key]
+		if key == "" || strings.ContainsAny(key, "=\r\n") || strings.HasPrefix(key, "#") || strings.HasPrefix(key, "[") {
+			return fmt.Errorf("setting name %q cannot be written", key)
+		}
+		if bytes.ContainsAny(val, "\r\n") {
+			return fmt.Errorf("value of setting %v cannot be written: contains a line break", key)
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, val); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settingsSchema lists the settings required by the sessions of a BeginString.
+type settingsSchema struct {
+	// required lists the settings every session must have.
+	required []string
+	// requiredWith maps a setting to the settings that must be set along with it.
+	requiredWith map[string][]string
+}
+
+var fixSettingsSchema = settingsSchema{
+	required: []string{config.BeginString, config.SenderCompID, config.TargetCompID},
+	requiredWith: map[string][]string{
+		config.StartTime: {config.EndTime},
+		config.EndTime:   {config.StartTime},
+		config.StartDay:  {config.EndDay},
+		config.EndDay:    {config.StartDay},
+	},
+}
+
+var fixtSettingsSchema = settingsSchema{
+	required: []string{config.BeginString, config.SenderCompID, config.TargetCompID, config.DefaultApplVerID},
+	requiredWith: map[string][]string{
+		config.StartTime:               {config.EndTime},
+		config.EndTime:                 {config.StartTime},
+		config.StartDay:                {config.EndDay},
+		config.EndDay:                  {config.StartDay},
+		config.TransportDataDictionary: {config.AppDataDictionary},
+		config.AppDataDictionary:       {config.TransportDataDictionary},
+	},
+}
+
+// settingsSchemas holds the settingsSchema of each BeginString.
+var settingsSchemas = map[string]settingsSchema{
+	BeginStringFIX40:  fixSettingsSchema,
+	BeginStringFIX41:  fixSettingsSchema,
+	BeginStringFIX42:  fixSettingsSchema,
+	BeginStringFIX43:  fixSettingsSchema,
+	BeginStringFIX44:  fixSettingsSchema,
+	BeginStringFIXT11: fixtSettingsSchema,
+}
+
+// Validate checks that every session has the settings required for its BeginString, taking the global settings
+// into account. The returned error lists every missing setting as a ConditionallyRequiredSetting, prefixed with the
+// SessionID of the session missing it, and is nil if none are missing. Validate is called by NewAcceptor and
+// NewInitiator.
+func (s *Settings) Validate() error {
+	s.lazyInit()
+
+	sessionSettings := s.SessionSettings()
+	sessionIDs := make([]SessionID, 0, len(sessionSettings))
+	for sessionID := range sessionSettings {
+		sessionIDs = append(sessionIDs, sessionID)
+	}
+	sort.Slice(sessionIDs, func(i, j int) bool { return sessionIDs[i].String() < sessionIDs[j].String() })
+
+	var errs []error
+	for _, sessionID := range sessionIDs {
+		schema, ok := settingsSchemas[sessionID.BeginString]
+		if !ok {
+			errs = append(errs, fmt.Errorf("%v: %w", sessionID, validateBeginString(sessionID)))
+			continue
+		}
+		for _, missing := range schema.missingSettings(sessionSettings[sessionID]) {
+			errs = append(errs, fmt.Errorf("%v: %w", sessionID, ConditionallyRequiredSetting{Setting: missing}))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// missingSettings returns the settings required by the schema that settings lacks, in a stable order.
+func (schema settingsSchema) missingSettings(settings *SessionSettings) []string {
+	var missing []string
+	for _, setting := range schema.required {
+		if !settings.HasSetting(setting) {
+			missing = append(missing, setting)
+		}
+	}
+
+	set := make([]string, 0, len(schema.requiredWith))
+	for setting := range schema.requiredWith {
+		if settings.HasSetting(setting) {
+			set = append(set, setting)
+		}
+	}
+	sort.Strings(set)
+	for _, setting := range set {
+		for _, other := range schema.requiredWith[setting] {
+			if !settings.HasSetting(other) && !slices.Contains(missing, other) {
+				missing = append(missing, other)
+			}
+		}
+	}
+	return missing
+}
+
+func validateBeginString(sessionID SessionID) error {
+	switch sessionID.BeginString {
+	case BeginStringFIX40:
+	case BeginStringFIX41:
+	case BeginStringFIX42:
+	case BeginStringFIX43:
+	case BeginStringFIX44:
+	case BeginStringFIXT11:
+	default:
+		return errors.New("BeginString must be FIX.4.0 to FIX.4.4 or FIXT.1.1")
+	}
+	return nil
+}
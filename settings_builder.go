@@ -0,0 +1,99 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import "github.com/quickfixgo/quickfix/config"
+
+// SettingsBuilder builds Settings in code, e.g. from configuration held in a database, without writing a
+// configuration file for ParseSettings first.
+//
+//	settings, err := quickfix.NewSettingsBuilder().
+//		SetGlobal(config.SocketConnectHost, "127.0.0.1").
+//		SetGlobal(config.SocketConnectPort, "5001").
+//		AddSession(quickfix.SessionID{BeginString: quickfix.BeginStringFIX44, SenderCompID: "SENDER", TargetCompID: "TARGET"}).
+//		Set(config.HeartBtInt, "30").
+//		Done().
+//		Build()
+type SettingsBuilder struct {
+	global   *SessionSettings
+	sessions []*SessionBuilder
+}
+
+// SessionBuilder sets the settings of a session added to a SettingsBuilder.
+type SessionBuilder struct {
+	builder  *SettingsBuilder
+	settings *SessionSettings
+}
+
+// NewSettingsBuilder returns an empty SettingsBuilder.
+func NewSettingsBuilder() *SettingsBuilder {
+	return &SettingsBuilder{global: NewSessionSettings()}
+}
+
+// SetGlobal sets a global setting, inherited by every session that does not set it itself.
+func (b *SettingsBuilder) SetGlobal(key, value string) *SettingsBuilder {
+	b.global.Set(key, value)
+	return b
+}
+
+// AddSession adds a session with the settings making up sessionID, its BeginString, CompIDs, SubIDs,
+// LocationIDs and SessionQualifier. Empty fields of sessionID are left unset.
+func (b *SettingsBuilder) AddSession(sessionID SessionID) *SessionBuilder {
+	settings := NewSessionSettings()
+	for _, setting := range []struct{ key, value string }{
+		{config.BeginString, sessionID.BeginString},
+		{config.SenderCompID, sessionID.SenderCompID},
+		{config.SenderSubID, sessionID.SenderSubID},
+		{config.SenderLocationID, sessionID.SenderLocationID},
+		{config.TargetCompID, sessionID.TargetCompID},
+		{config.TargetSubID, sessionID.TargetSubID},
+		{config.TargetLocationID, sessionID.TargetLocationID},
+		{config.SessionQualifier, sessionID.Qualifier},
+	} {
+		if setting.value != "" {
+			settings.Set(setting.key, setting.value)
+		}
+	}
+
+	session := &SessionBuilder{builder: b, settings: settings}
+	b.sessions = append(b.sessions, session)
+	return session
+}
+
+// Build returns Settings holding the global settings and the sessions added so far. As with ParseSettings,
+// an error is returned if a session has an unsupported BeginString, or two sessions have the same SessionID.
+// The builder can be reused afterwards, it does not share any state with the returned Settings.
+func (b *SettingsBuilder) Build() (*Settings, error) {
+	s := NewSettings()
+	s.GlobalSettings().overlay(b.global)
+	for _, session := range b.sessions {
+		if _, err := s.AddSession(session.settings.clone()); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Set sets a setting of the session, overriding the global setting of the same name.
+func (b *SessionBuilder) Set(key, value string) *SessionBuilder {
+	b.settings.Set(key, value)
+	return b
+}
+
+// Done returns the SettingsBuilder the session was added to, to continue building the Settings.
+func (b *SessionBuilder) Done() *SettingsBuilder {
+	return b.builder
+}
@@ -0,0 +1,78 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+func TestSettingsBuilder(t *testing.T) {
+	sessionID1 := SessionID{BeginString: BeginStringFIX44, SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	sessionID2 := SessionID{BeginString: BeginStringFIX42, SenderCompID: "SENDER", SenderSubID: "DESK", TargetCompID: "TARGET", Qualifier: "BACKUP"}
+
+	builder := NewSettingsBuilder().
+		SetGlobal(config.SocketConnectHost, "127.0.0.1").
+		SetGlobal(config.HeartBtInt, "30").
+		AddSession(sessionID1).
+		Set(config.SocketConnectPort, "5001").
+		Done().
+		AddSession(sessionID2).
+		Set(config.SocketConnectPort, "5002").
+		Set(config.HeartBtInt, "60").
+		Done()
+	settings, err := builder.Build()
+	require.Nil(t, err)
+
+	val, err := settings.GlobalSettings().Setting(config.SocketConnectHost)
+	require.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", val)
+
+	sessionSettings := settings.SessionSettings()
+	require.Len(t, sessionSettings, 2)
+	for sessionID, expected := range map[SessionID]map[string]string{
+		sessionID1: {config.SocketConnectHost: "127.0.0.1", config.SocketConnectPort: "5001", config.HeartBtInt: "30"},
+		sessionID2: {config.SocketConnectHost: "127.0.0.1", config.SocketConnectPort: "5002", config.HeartBtInt: "60", config.SenderSubID: "DESK", config.SessionQualifier: "BACKUP"},
+	} {
+		require.Contains(t, sessionSettings, sessionID)
+		for key, value := range expected {
+			val, err := sessionSettings[sessionID].Setting(key)
+			require.Nil(t, err)
+			assert.Equal(t, value, val, "%v %v", sessionID, key)
+		}
+	}
+	assert.False(t, sessionSettings[sessionID1].HasSetting(config.SenderSubID))
+
+	// Built settings are independent of the builder.
+	builder.SetGlobal(config.SocketConnectHost, "10.0.0.1")
+	val, err = settings.GlobalSettings().Setting(config.SocketConnectHost)
+	require.Nil(t, err)
+	assert.Equal(t, "127.0.0.1", val)
+}
+
+func TestSettingsBuilder_Errors(t *testing.T) {
+	sessionID := SessionID{BeginString: BeginStringFIX44, SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	_, err := NewSettingsBuilder().AddSession(sessionID).Done().AddSession(sessionID).Done().Build()
+	assert.NotNil(t, err)
+
+	_, err = NewSettingsBuilder().AddSession(SessionID{BeginString: "FIX.9.9", SenderCompID: "SENDER", TargetCompID: "TARGET"}).Done().Build()
+	assert.NotNil(t, err)
+}
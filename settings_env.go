@@ -0,0 +1,256 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+const (
+	envGlobalPrefix  = "QUICKFIX_GLOBAL_"
+	envSessionPrefix = "QUICKFIX_SESSION_"
+)
+
+// envSettingNames maps the upper-case names of the settings in the config package to their names.
+var envSettingNames = func() map[string]string {
+	names := make(map[string]string)
+	for _, name := range []string{
+		config.BeginString,
+		config.SenderCompID,
+		config.SenderSubID,
+		config.SenderLocationID,
+		config.TargetCompID,
+		config.TargetSubID,
+		config.TargetLocationID,
+		config.SessionQualifier,
+		config.DefaultApplVerID,
+		config.StartTime,
+		config.EndTime,
+		config.StartDay,
+		config.EndDay,
+		config.Weekdays,
+		config.TimeZone,
+		config.TimeStampPrecision,
+		config.ResetOnLogon,
+		config.RefreshOnLogon,
+		config.PersistSessionState,
+		config.StoreHealthCheckIntervalSecs,
+		config.StoreHealthCheckMaxFailures,
+		config.LogonFields,
+		config.EncryptedTags,
+		config.EncryptMethod,
+		config.ResetOnLogout,
+		config.ResetOnLogoutText,
+		config.ResetOnDisconnect,
+		config.ResetSeqTime,
+		config.DataDictionary,
+		config.TransportDataDictionary,
+		config.AppDataDictionary,
+		config.RejectInvalidMessage,
+		config.DefaultUnsupportedMsgRejectReason,
+		config.AllowUnknownMessageFields,
+		config.CheckUserDefinedFields,
+		config.ValidateFieldsOutOfOrder,
+		config.CheckLatency,
+		config.MaxLatency,
+		config.ApplicationCallbackTimeout,
+		config.ReconnectInterval,
+		config.LogoutTimeout,
+		config.LogonTimeout,
+		config.HeartBtInt,
+		config.SocketConnectHost,
+		config.SocketConnectPort,
+		config.SocketTimeout,
+		config.ConnectTimeout,
+		config.ProxyType,
+		config.ProxyHost,
+		config.ProxyPort,
+		config.ProxyUser,
+		config.ProxyPassword,
+		config.TransportType,
+		config.WebSocketPath,
+		config.WebSocketOrigin,
+		config.SocketAcceptHost,
+		config.SocketAcceptPort,
+		config.HeartBtIntOverride,
+		config.UseTCPProxy,
+		config.DynamicSessions,
+		config.DynamicQualifier,
+		config.AcceptedBeginStrings,
+		config.SocketPrivateKeyFile,
+		config.SocketCertificateFile,
+		config.SocketCAFile,
+		config.SocketPrivateKeyBytes,
+		config.SocketCertificateBytes,
+		config.SocketCABytes,
+		config.SocketInsecureSkipVerify,
+		config.SocketServerName,
+		config.SocketMinimumTLSVersion,
+		config.SocketUseSSL,
+		config.SSLClientAuthType,
+		config.SSLClientCertificate,
+		config.SSLClientPrivateKey,
+		config.FileLogPath,
+		config.SQLLogDriver,
+		config.SQLLogDataSourceName,
+		config.SQLLogConnMaxLifetime,
+		config.MongoLogConnection,
+		config.MongoLogDatabase,
+		config.MongoLogReplicaSet,
+		config.PersistMessages,
+		config.FileStorePath,
+		config.FileStoreSync,
+		config.FileStoreSyncIntervalMs,
+		config.FileStorePermissions,
+		config.FileStoreCompress,
+		config.FileStoreCompressLevel,
+		config.FileStoreEncryptionKey,
+		config.FileStoreRotateInterval,
+		config.FileStoreMaxBodyBytes,
+		config.FileStoreMmap,
+		config.SQLStoreDriver,
+		config.SQLStoreDataSourceName,
+		config.SQLStoreConnMaxLifetime,
+		config.MongoStoreConnection,
+		config.MongoStoreDatabase,
+		config.MongoStoreReplicaSet,
+		config.RedisURL,
+		config.RedisPassword,
+		config.PostgresDSN,
+		config.PostgresSchemaName,
+		config.SQLiteFile,
+		config.AWSRegion,
+		config.DynamoDBTable,
+		config.DynamoDBEndpoint,
+		config.ResendRequestChunkSize,
+		config.MaxPendingOutboundMessages,
+		config.MaxOutboundMsgRatePerSecond,
+		config.MaxOutboundBurstMessages,
+		config.CPUAffinity,
+		config.TCPSendBufferSize,
+		config.TCPReceiveBufferSize,
+		config.TCPKeepAlive,
+		config.TCPKeepAliveIdleSecs,
+		config.TCPKeepAliveIntervalSecs,
+		config.TCPKeepAliveCount,
+		config.EnableLastMsgSeqNumProcessed,
+		config.EnableNextExpectedMsgSeqNum,
+	} {
+		names[strings.ToUpper(name)] = name
+	}
+	return names
+}()
+
+// NewAcceptorFromEnv creates and initializes a new Acceptor with Settings read from the environment variables of the
+// process, see ParseSettingsFromEnv.
+func NewAcceptorFromEnv(app Application, storeFactory MessageStoreFactory, logFactory LogFactory) (*Acceptor, error) {
+	settings, err := ParseSettingsFromEnv(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	return NewAcceptor(app, storeFactory, settings, logFactory)
+}
+
+// NewInitiatorFromEnv creates and initializes a new Initiator with Settings read from the environment variables of
+// the process, see ParseSettingsFromEnv.
+func NewInitiatorFromEnv(app Application, storeFactory MessageStoreFactory, logFactory LogFactory) (*Initiator, error) {
+	settings, err := ParseSettingsFromEnv(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	return NewInitiator(app, storeFactory, settings, logFactory)
+}
+
+// ParseSettingsFromEnv creates and initializes a Settings instance from environment variables given as "NAME=value"
+// strings, as returned by os.Environ. Use it instead of NewAcceptorFromEnv or NewInitiatorFromEnv when the store or log
+// factory is created from the Settings as well.
+//
+// Global settings are read from variables named QUICKFIX_GLOBAL_<SETTING>, e.g. QUICKFIX_GLOBAL_SOCKETACCEPTPORT=9000.
+// Session settings are read from variables named QUICKFIX_SESSION_<SESSION>_<SETTING>, where all variables with the
+// same <SESSION> make up one session, e.g. QUICKFIX_SESSION_FIX42_SENDER_TARGET_BEGINSTRING=FIX.4.2. By convention
+// <SESSION> is made up of the BeginString, SenderCompID and TargetCompID of the session, but the SessionID is taken
+// from the settings of the session only, as with ParseSettings.
+//
+// The names of the settings are matched case-insensitively against the settings in the config package. Other
+// settings, e.g. of the application, are kept as named in the variable. Variables not starting with
+// QUICKFIX_GLOBAL_ or QUICKFIX_SESSION_ are ignored.
+func ParseSettingsFromEnv(environ []string) (*Settings, error) {
+	s := NewSettings()
+	sessions := make(map[string]*SessionSettings)
+
+	for _, variable := range environ {
+		name, value, _ := strings.Cut(variable, "=")
+
+		switch {
+		case strings.HasPrefix(name, envGlobalPrefix):
+			setting := strings.TrimPrefix(name, envGlobalPrefix)
+			if setting == "" {
+				return nil, fmt.Errorf("environment variable %s does not name a setting", name)
+			}
+			s.GlobalSettings().Set(envSettingName(setting), value)
+
+		case strings.HasPrefix(name, envSessionPrefix):
+			session, setting, ok := cutLast(strings.TrimPrefix(name, envSessionPrefix), "_")
+			if !ok || session == "" || setting == "" {
+				return nil, fmt.Errorf("environment variable %s does not name a session and setting", name)
+			}
+			if sessions[session] == nil {
+				sessions[session] = NewSessionSettings()
+			}
+			sessions[session].Set(envSettingName(setting), value)
+		}
+	}
+
+	if len(sessions) == 0 {
+		return s, fmt.Errorf("no sessions declared")
+	}
+
+	names := make([]string, 0, len(sessions))
+	for name := range sessions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := s.AddSession(sessions[name]); err != nil {
+			return s, fmt.Errorf("session %s: %w", name, err)
+		}
+	}
+
+	return s, nil
+}
+
+// envSettingName returns the name of the setting named in an environment variable. A numeric suffix is kept, for
+// the alternate hosts and ports of SocketConnectHost<n> and SocketConnectPort<n>.
+func envSettingName(setting string) string {
+	base := strings.TrimRight(setting, "0123456789")
+	if name, ok := envSettingNames[strings.ToUpper(base)]; ok {
+		return name + setting[len(base):]
+	}
+	return setting
+}
+
+// cutLast slices s around the last instance of sep.
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
@@ -0,0 +1,149 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"go/ast"
+	goparser "go/parser"
+	"go/token"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+func TestParseSettingsFromEnv(t *testing.T) {
+	s, err := ParseSettingsFromEnv([]string{
+		"PATH=/usr/bin",
+		"QUICKFIX_GLOBAL_SOCKETACCEPTPORT=9000",
+		"QUICKFIX_GLOBAL_HeartBtInt=30",
+		"QUICKFIX_GLOBAL_MYAPPSETTING=a=b",
+		"QUICKFIX_GLOBAL_SOCKETCONNECTPORT1=5002",
+		"QUICKFIX_SESSION_FIX42_SENDER_TARGET_BEGINSTRING=FIX.4.2",
+		"QUICKFIX_SESSION_FIX42_SENDER_TARGET_SENDERCOMPID=SENDER",
+		"QUICKFIX_SESSION_FIX42_SENDER_TARGET_TARGETCOMPID=TARGET",
+		"QUICKFIX_SESSION_FIX42_SENDER_TARGET_HEARTBTINT=60",
+		"QUICKFIX_SESSION_FIX44_SEND_ER_TARGET_BEGINSTRING=FIX.4.4",
+		"QUICKFIX_SESSION_FIX44_SEND_ER_TARGET_SENDERCOMPID=SEND_ER",
+		"QUICKFIX_SESSION_FIX44_SEND_ER_TARGET_TARGETCOMPID=TARGET",
+		"QUICKFIX_OTHER=ignored",
+	})
+	require.Nil(t, err)
+
+	global := s.GlobalSettings()
+	port, err := global.IntSetting(config.SocketAcceptPort)
+	require.Nil(t, err)
+	assert.Equal(t, 9000, port)
+	heartBtInt, err := global.IntSetting(config.HeartBtInt)
+	require.Nil(t, err)
+	assert.Equal(t, 30, heartBtInt)
+	appSetting, err := global.Setting("MYAPPSETTING")
+	require.Nil(t, err)
+	assert.Equal(t, "a=b", appSetting)
+	assert.True(t, global.HasSetting(config.SocketConnectPort+"1"))
+
+	sessions := s.SessionSettings()
+	require.Len(t, sessions, 2)
+
+	fix42 := sessions[SessionID{BeginString: BeginStringFIX42, SenderCompID: "SENDER", TargetCompID: "TARGET"}]
+	require.NotNil(t, fix42)
+	heartBtInt, err = fix42.IntSetting(config.HeartBtInt)
+	require.Nil(t, err)
+	assert.Equal(t, 60, heartBtInt)
+
+	fix44 := sessions[SessionID{BeginString: BeginStringFIX44, SenderCompID: "SEND_ER", TargetCompID: "TARGET"}]
+	require.NotNil(t, fix44)
+	heartBtInt, err = fix44.IntSetting(config.HeartBtInt)
+	require.Nil(t, err)
+	assert.Equal(t, 30, heartBtInt)
+}
+
+func TestParseSettingsFromEnv_Errors(t *testing.T) {
+	var tests = []struct {
+		name    string
+		environ []string
+	}{
+		{"no sessions", []string{"QUICKFIX_GLOBAL_SOCKETACCEPTPORT=9000"}},
+		{"no global setting", []string{"QUICKFIX_GLOBAL_=9000"}},
+		{"no session setting", []string{"QUICKFIX_SESSION_FIX42_SENDER_TARGET_=FIX.4.2"}},
+		{"no session", []string{"QUICKFIX_SESSION_BEGINSTRING=FIX.4.2"}},
+		{"unsupported BeginString", []string{"QUICKFIX_SESSION_A_BEGINSTRING=FIX.1.0"}},
+		{"duplicate session", []string{
+			"QUICKFIX_SESSION_A_BEGINSTRING=FIX.4.2",
+			"QUICKFIX_SESSION_A_SENDERCOMPID=SENDER",
+			"QUICKFIX_SESSION_A_TARGETCOMPID=TARGET",
+			"QUICKFIX_SESSION_B_BEGINSTRING=FIX.4.2",
+			"QUICKFIX_SESSION_B_SENDERCOMPID=SENDER",
+			"QUICKFIX_SESSION_B_TARGETCOMPID=TARGET",
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseSettingsFromEnv(test.environ)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestParseSettingsFromEnv_SettingNames(t *testing.T) {
+	file, err := goparser.ParseFile(token.NewFileSet(), "config/configuration.go", nil, 0)
+	require.Nil(t, err)
+
+	var count int
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			for _, value := range spec.(*ast.ValueSpec).Values {
+				lit, ok := value.(*ast.BasicLit)
+				if !ok || lit.Kind != token.STRING {
+					continue
+				}
+				name, err := strconv.Unquote(lit.Value)
+				require.Nil(t, err)
+				assert.Equal(t, name, envSettingName(name), "setting %s is missing from envSettingNames", name)
+				count++
+			}
+		}
+	}
+	assert.Equal(t, count, len(envSettingNames))
+}
+
+func TestNewInitiatorFromEnv(t *testing.T) {
+	t.Setenv("QUICKFIX_GLOBAL_SOCKETCONNECTHOST", "127.0.0.1")
+	t.Setenv("QUICKFIX_GLOBAL_SOCKETCONNECTPORT", "5001")
+	t.Setenv("QUICKFIX_GLOBAL_HEARTBTINT", "30")
+	t.Setenv("QUICKFIX_SESSION_FIX42_SENDER_TARGET_BEGINSTRING", "FIX.4.2")
+	t.Setenv("QUICKFIX_SESSION_FIX42_SENDER_TARGET_SENDERCOMPID", "SENDER")
+	t.Setenv("QUICKFIX_SESSION_FIX42_SENDER_TARGET_TARGETCOMPID", "TARGET")
+
+	initiator, err := NewInitiatorFromEnv(&MockApp{}, NewMemoryStoreFactory(), NewNullLogFactory())
+	require.Nil(t, err)
+	assert.Len(t, initiator.sessionSettings, 1)
+}
+
+func TestNewAcceptorFromEnvWithoutSessions(t *testing.T) {
+	t.Setenv("QUICKFIX_GLOBAL_SOCKETACCEPTPORT", "9000")
+
+	_, err := NewAcceptorFromEnv(nil, NewMemoryStoreFactory(), NewNullLogFactory())
+	assert.NotNil(t, err)
+}
@@ -16,6 +16,7 @@
 package quickfix
 
 import (
+	"errors"
 	"strings"
 	"testing"
 
@@ -387,3 +388,266 @@ func TestSettings_SessionIDFromSessionSettings(t *testing.T) {
 		}
 	}
 }
+
+func TestSettings_MergeFrom(t *testing.T) {
+	template, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+ConnectionType=initiator
+SenderCompID=FIRM
+HeartBtInt=30
+ReconnectInterval=60
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=VENUE
+SocketConnectHost=127.0.0.1
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=OTHER`))
+	require.Nil(t, err)
+
+	venue, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+HeartBtInt=15
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=FIRM
+TargetCompID=VENUE
+SocketConnectHost=venue.example.com
+SocketConnectPort=5001`))
+	require.Nil(t, err)
+
+	override, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+HeartBtInt=20
+
+[SESSION]
+BeginString=FIXT.1.1
+SenderCompID=FIRM
+TargetCompID=NEW
+DefaultApplVerID=FIX.5.0SP2`))
+	require.Nil(t, err)
+
+	require.Nil(t, template.MergeFrom(venue))
+	require.Nil(t, template.MergeFrom(override))
+
+	venueID := SessionID{BeginString: "FIX.4.4", SenderCompID: "FIRM", TargetCompID: "VENUE"}
+	otherID := SessionID{BeginString: "FIX.4.2", SenderCompID: "FIRM", TargetCompID: "OTHER"}
+	newID := SessionID{BeginString: "FIXT.1.1", SenderCompID: "FIRM", TargetCompID: "NEW"}
+	sessionSettings := template.SessionSettings()
+	require.Len(t, sessionSettings, 3)
+
+	var tests = []struct {
+		sessionID SessionID
+		setting   string
+		expected  string
+	}{
+		{venueID, config.HeartBtInt, "20"},
+		{venueID, config.ReconnectInterval, "60"},
+		{venueID, config.SocketConnectHost, "venue.example.com"},
+		{venueID, config.SocketConnectPort, "5001"},
+		{otherID, config.HeartBtInt, "20"},
+		{newID, config.DefaultApplVerID, "FIX.5.0SP2"},
+		{newID, "ConnectionType", "initiator"},
+	}
+	for _, test := range tests {
+		actual, err := sessionSettings[test.sessionID].Setting(test.setting)
+		require.Nil(t, err, "%v %v", test.sessionID, test.setting)
+		assert.Equal(t, test.expected, actual, "%v %v", test.sessionID, test.setting)
+	}
+}
+
+func TestSettings_MergeFromResolvesSessionIDs(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+BeginString=FIX.4.4
+SenderCompID=FIRM
+
+[SESSION]
+TargetCompID=A
+
+[SESSION]
+TargetCompID=B
+SenderCompID=OTHER
+
+[SESSION]
+TargetCompID=A
+SenderCompID=OTHER`))
+	require.Nil(t, err)
+
+	// The overlay changes the SenderCompID inherited by the first session.
+	overlay := NewSettings()
+	overlay.GlobalSettings().Set(config.SenderCompID, "RENAMED")
+	require.Nil(t, s.MergeFrom(overlay))
+
+	sessionSettings := s.SessionSettings()
+	assert.Len(t, sessionSettings, 3)
+	assert.Contains(t, sessionSettings, SessionID{BeginString: "FIX.4.4", SenderCompID: "RENAMED", TargetCompID: "A"})
+	assert.Contains(t, sessionSettings, SessionID{BeginString: "FIX.4.4", SenderCompID: "OTHER", TargetCompID: "B"})
+	assert.Contains(t, sessionSettings, SessionID{BeginString: "FIX.4.4", SenderCompID: "OTHER", TargetCompID: "A"})
+
+	// Renaming the inherited SenderCompID to that of the last session makes the first a duplicate.
+	conflict := NewSettings()
+	conflict.GlobalSettings().Set(config.SenderCompID, "OTHER")
+	assert.NotNil(t, s.MergeFrom(conflict))
+
+	invalid := NewSettings()
+	invalid.GlobalSettings().Set(config.BeginString, "FIX.9.9")
+	assert.NotNil(t, s.MergeFrom(invalid))
+
+	// Failed merges leave the settings unchanged.
+	assert.Equal(t, sessionSettings, s.SessionSettings())
+}
+
+func TestSettings_WriteToWriter(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+ConnectionType=initiator
+SQLDataSourceName=root:root@/quickfix?parseTime=true
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET_B
+
+[SESSION]
+BeginString=FIX.4.2
+SenderCompID=SENDER
+TargetCompID=TARGET_A
+HeartBtInt=30`))
+	require.Nil(t, err)
+
+	var b strings.Builder
+	require.Nil(t, s.WriteToWriter(&b))
+	assert.Equal(t, `[DEFAULT]
+ConnectionType=initiator
+SQLDataSourceName=root:root@/quickfix?parseTime=true
+
+[SESSION]
+BeginString=FIX.4.2
+HeartBtInt=30
+SenderCompID=SENDER
+TargetCompID=TARGET_A
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET_B
+`, b.String())
+
+	reparsed, err := ParseSettings(strings.NewReader(b.String()))
+	require.Nil(t, err)
+	assert.Equal(t, s.SessionSettings(), reparsed.SessionSettings())
+
+	s.GlobalSettings().Set("Banner", "line one\nline two")
+	assert.NotNil(t, s.WriteToWriter(&b))
+}
+
+func TestSettings_SetEnvExpansion(t *testing.T) {
+	t.Setenv("FIX_SENDER_COMP_ID", "SENDER")
+	t.Setenv("FIX_TARGET_COMP_ID", "TARGET")
+
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+SenderCompID=${FIX_SENDER_COMP_ID}
+SocketConnectHost=${FIX_HOST_UNSET}
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=${FIX_TARGET_COMP_ID}`))
+	require.Nil(t, err)
+
+	unexpandedID := SessionID{BeginString: "FIX.4.4", SenderCompID: "${FIX_SENDER_COMP_ID}", TargetCompID: "${FIX_TARGET_COMP_ID}"}
+	assert.Contains(t, s.SessionSettings(), unexpandedID)
+
+	s.SetEnvExpansion(true)
+	sessionID := SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	sessionSettings, ok := s.SessionSettings()[sessionID]
+	require.True(t, ok)
+
+	sender, err := sessionSettings.Setting(config.SenderCompID)
+	require.Nil(t, err)
+	assert.Equal(t, "SENDER", sender)
+
+	_, err = sessionSettings.Setting(config.SocketConnectHost)
+	assert.Equal(t, UnsetEnvironmentVariable{Setting: config.SocketConnectHost, Variable: "FIX_HOST_UNSET"}, err)
+
+	// Sessions added later are expanded too.
+	added := NewSessionSettings()
+	added.Set(config.BeginString, "FIX.4.2")
+	added.Set(config.TargetCompID, "${FIX_TARGET_COMP_ID}")
+	addedID, err := s.AddSession(added)
+	require.Nil(t, err)
+	assert.Equal(t, SessionID{BeginString: "FIX.4.2", SenderCompID: "SENDER", TargetCompID: "TARGET"}, addedID)
+
+	// The settings are written unexpanded.
+	var b strings.Builder
+	require.Nil(t, s.WriteToWriter(&b))
+	assert.Contains(t, b.String(), "SenderCompID=${FIX_SENDER_COMP_ID}\n")
+
+	s.SetEnvExpansion(false)
+	assert.Contains(t, s.SessionSettings(), unexpandedID)
+}
+
+func TestSettings_Validate(t *testing.T) {
+	s, err := ParseSettings(strings.NewReader(`
+[DEFAULT]
+SenderCompID=FIRM
+
+[SESSION]
+BeginString=FIX.4.2
+TargetCompID=VENUE
+StartTime=08:00:00
+
+[SESSION]
+BeginString=FIXT.1.1
+TargetCompID=VENUE
+
+[SESSION]
+BeginString=FIX.4.4
+TargetCompID=VENUE
+StartTime=08:00:00
+EndTime=17:00:00`))
+	require.Nil(t, err)
+
+	err = s.Validate()
+	require.NotNil(t, err)
+	assert.Equal(t, "FIX.4.2:FIRM->VENUE: Conditionally Required Setting: EndTime\n"+
+		"FIXT.1.1:FIRM->VENUE: Conditionally Required Setting: DefaultApplVerID", err.Error())
+
+	var missing ConditionallyRequiredSetting
+	require.True(t, errors.As(err, &missing))
+	assert.Equal(t, config.EndTime, missing.Setting)
+
+	// Settings inherited from the global settings count.
+	s.GlobalSettings().Set(config.StartTime, "08:00:00")
+	s.GlobalSettings().Set(config.EndTime, "17:00:00")
+	s.GlobalSettings().Set(config.DefaultApplVerID, "FIX.5.0SP2")
+	assert.Nil(t, s.Validate())
+
+	noTarget := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIX44)
+	sessionSettings.Set(config.SenderCompID, "FIRM")
+	_, err = noTarget.AddSession(sessionSettings)
+	require.Nil(t, err)
+	assert.Equal(t, "FIX.4.4:FIRM->: Conditionally Required Setting: TargetCompID", noTarget.Validate().Error())
+}
+
+func TestSettings_ValidatedByAcceptorAndInitiator(t *testing.T) {
+	s := NewSettings()
+	sessionSettings := NewSessionSettings()
+	sessionSettings.Set(config.BeginString, BeginStringFIXT11)
+	sessionSettings.Set(config.SenderCompID, "FIRM")
+	sessionSettings.Set(config.TargetCompID, "VENUE")
+	_, err := s.AddSession(sessionSettings)
+	require.Nil(t, err)
+
+	_, err = NewAcceptor(&MockApp{}, NewMemoryStoreFactory(), s, NewNullLogFactory())
+	assert.ErrorAs(t, err, &ConditionallyRequiredSetting{})
+
+	_, err = NewInitiator(&MockApp{}, NewMemoryStoreFactory(), s, NewNullLogFactory())
+	assert.ErrorAs(t, err, &ConditionallyRequiredSetting{})
+}
@@ -0,0 +1,68 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import "net"
+
+// setSocketBuffers sizes the kernel buffers of the session's connection as configured by TCPSendBufferSize and
+// TCPReceiveBufferSize. Failures are logged, as the session can still run with the default buffers.
+func (s *session) setSocketBuffers(conn net.Conn) {
+	if s.TCPSendBufferSize == 0 && s.TCPReceiveBufferSize == 0 {
+		return
+	}
+	tcpConn, ok := tcpConnOf(conn)
+	if !ok {
+		s.log.OnEventf("Unable to set socket buffer sizes: %v is not a TCP connection", conn.RemoteAddr())
+		return
+	}
+
+	if s.TCPSendBufferSize > 0 {
+		if err := tcpConn.SetWriteBuffer(s.TCPSendBufferSize); err != nil {
+			s.log.OnEventf("Unable to set TCPSendBufferSize to %d: %v", s.TCPSendBufferSize, err)
+		}
+	}
+	if s.TCPReceiveBufferSize > 0 {
+		if err := tcpConn.SetReadBuffer(s.TCPReceiveBufferSize); err != nil {
+			s.log.OnEventf("Unable to set TCPReceiveBufferSize to %d: %v", s.TCPReceiveBufferSize, err)
+		}
+	}
+
+	// The kernel silently limits the sizes it grants.
+	send, receive, ok := socketBufferSizes(tcpConn)
+	if !ok {
+		return
+	}
+	if send < s.TCPSendBufferSize {
+		s.log.OnEventf("TCPSendBufferSize %d was limited to %d by the operating system", s.TCPSendBufferSize, send)
+	}
+	if receive < s.TCPReceiveBufferSize {
+		s.log.OnEventf("TCPReceiveBufferSize %d was limited to %d by the operating system", s.TCPReceiveBufferSize, receive)
+	}
+}
+
+// tcpConnOf returns the TCP connection underlying conn, e.g. of a TLS connection.
+func tcpConnOf(conn net.Conn) (*net.TCPConn, bool) {
+	for {
+		switch c := conn.(type) {
+		case *net.TCPConn:
+			return c, true
+		case interface{ NetConn() net.Conn }:
+			conn = c.NetConn()
+		default:
+			return nil, false
+		}
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+//go:build linux
+
+package quickfix
+
+import (
+	"net"
+
+	"golang.org/x/sys/unix"
+)
+
+// socketBufferSizes returns the sizes of the kernel send and receive buffers of conn, as they were requested.
+// Linux reports twice the requested size, the rest being reserved for bookkeeping.
+func socketBufferSizes(conn *net.TCPConn) (send, receive int, ok bool) {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, 0, false
+	}
+	var sendErr, receiveErr error
+	err = raw.Control(func(fd uintptr) {
+		send, sendErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_SNDBUF)
+		receive, receiveErr = unix.GetsockoptInt(int(fd), unix.SOL_SOCKET, unix.SO_RCVBUF)
+	})
+	if err != nil || sendErr != nil || receiveErr != nil {
+		return 0, 0, false
+	}
+	return send / 2, receive / 2, true
+}
@@ -0,0 +1,60 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+//go:build linux
+
+package quickfix
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetSocketBuffers(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	logger := &eventLog{}
+	s := &session{log: logger}
+	s.TCPSendBufferSize = 65536
+	s.TCPReceiveBufferSize = 131072
+	s.setSocketBuffers(conn)
+	assert.Empty(t, logger.events)
+
+	send, receive, ok := socketBufferSizes(conn.(*net.TCPConn))
+	require.True(t, ok)
+	assert.Equal(t, 65536, send)
+	assert.Equal(t, 131072, receive)
+
+	// Sizes above the kernel maximum are limited, which is logged.
+	rmemMax, err := os.ReadFile("/proc/sys/net/core/rmem_max")
+	require.Nil(t, err)
+	limit, err := strconv.Atoi(strings.TrimSpace(string(rmemMax)))
+	require.Nil(t, err)
+	s.TCPReceiveBufferSize = 2 * limit
+	s.setSocketBuffers(conn)
+	require.Len(t, logger.events, 1)
+	assert.Equal(t, "TCPReceiveBufferSize "+strconv.Itoa(2*limit)+" was limited to "+strconv.Itoa(limit)+" by the operating system", logger.events[0])
+}
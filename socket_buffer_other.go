@@ -0,0 +1,25 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+//go:build !linux
+
+package quickfix
+
+import "net"
+
+// socketBufferSizes is not supported on this platform, so the sizes granted are not checked.
+func socketBufferSizes(_ *net.TCPConn) (send, receive int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,64 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"crypto/tls"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTCPConnOf(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	tcpConn, ok := tcpConnOf(conn)
+	assert.True(t, ok)
+	assert.Same(t, conn, tcpConn)
+
+	tcpConn, ok = tcpConnOf(tls.Client(conn, &tls.Config{}))
+	assert.True(t, ok)
+	assert.Same(t, conn, tcpConn)
+
+	serverConn, clientConn := NewPipeTransport()
+	defer serverConn.Close()
+	defer clientConn.Close()
+	_, ok = tcpConnOf(clientConn)
+	assert.False(t, ok)
+}
+
+func TestSetSocketBuffersWithoutTCPConnection(t *testing.T) {
+	serverConn, clientConn := NewPipeTransport()
+	defer serverConn.Close()
+	defer clientConn.Close()
+
+	logger := &eventLog{}
+	s := &session{log: logger}
+	s.setSocketBuffers(clientConn)
+	assert.Empty(t, logger.events)
+
+	s.TCPSendBufferSize = 65536
+	s.setSocketBuffers(clientConn)
+	require.Len(t, logger.events, 1)
+	assert.Contains(t, logger.events[0], "is not a TCP connection")
+}
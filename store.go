@@ -16,9 +16,15 @@
 package quickfix
 
 import (
+	"errors"
+	"sort"
 	"time"
 )
 
+// ErrStopIteration can be returned by the callback passed to MessageStore.IterateMessages to stop the
+// iteration early. IterateMessages then returns nil.
+var ErrStopIteration = errors.New("stop iteration")
+
 // The MessageStore interface provides methods to record and retrieve messages for resend purposes.
 type MessageStore interface {
 	NextSenderMsgSeqNum() int
@@ -41,9 +47,131 @@ type MessageStore interface {
 	Refresh() error
 	Reset() error
 
+	// HealthCheck returns an error if the store is not operational. It neither reads nor writes messages.
+	HealthCheck() error
+
 	Close() error
 }
 
+// ReadOnlyMessageStore is the subset of MessageStore used to inspect a store without modifying it. Every
+// MessageStore implements it.
+type ReadOnlyMessageStore interface {
+	NextSenderMsgSeqNum() int
+	NextTargetMsgSeqNum() int
+	CreationTime() time.Time
+	GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error)
+	IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error
+}
+
+// BatchSaver is implemented by message stores that can persist several messages at once more cheaply than
+// saving them one at a time, e.g. by syncing to disk once for the whole batch.
+type BatchSaver interface {
+	SaveMessages(msgs map[int][]byte) error
+}
+
+// SaveMessages saves msgs, keyed by seqnum, to the store. Stores implementing BatchSaver save the batch in
+// one go, other stores save the messages individually in seqnum order.
+func SaveMessages(store MessageStore, msgs map[int][]byte) error {
+	if saver, ok := store.(BatchSaver); ok {
+		return saver.SaveMessages(msgs)
+	}
+
+	seqNums := make([]int, 0, len(msgs))
+	for seqNum := range msgs {
+		seqNums = append(seqNums, seqNum)
+	}
+	sort.Ints(seqNums)
+	for _, seqNum := range seqNums {
+		if err := store.SaveMessage(seqNum, msgs[seqNum]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SingleMessageGetter is implemented by message stores that can look up a single message more cheaply
+// than through GetMessages, e.g. with an index.
+type SingleMessageGetter interface {
+	// GetMessage returns the message saved under seqNum, and whether there is one.
+	GetMessage(seqNum int) ([]byte, bool, error)
+}
+
+// GetMessage returns the message saved under seqNum in the store, and whether there is one. Stores
+// implementing SingleMessageGetter look the message up directly, other stores go through IterateMessages.
+func GetMessage(store MessageStore, seqNum int) (msg []byte, found bool, err error) {
+	if getter, ok := store.(SingleMessageGetter); ok {
+		return getter.GetMessage(seqNum)
+	}
+
+	err = store.IterateMessages(seqNum, seqNum, func(m []byte) error {
+		msg, found = m, true
+		return ErrStopIteration
+	})
+	return msg, found, err
+}
+
+// MessageStoreStats is implemented by message stores that keep monitoring counters.
+type MessageStoreStats interface {
+	// SavedMessageCount returns the number of messages saved to the store.
+	SavedMessageCount() int64
+	// RetrievedMessageCount returns the number of messages read back from the store.
+	RetrievedMessageCount() int64
+	// BytesWritten returns the number of message bytes saved to the store.
+	BytesWritten() int64
+	// BytesRead returns the number of message bytes read back from the store.
+	BytesRead() int64
+}
+
+// MessageStoreStatsRecorder is implemented by the memory store, so that stores using it as a cache of their
+// sequence numbers can keep their monitoring counters in it too.
+type MessageStoreStatsRecorder interface {
+	MessageStoreStats
+	// SavedCount returns the number of messages saved to the store, as SavedMessageCount does.
+	SavedCount() int64
+	// RetrievedCount returns the number of messages read back from the store, as RetrievedMessageCount does.
+	RetrievedCount() int64
+	// RecordSaved counts a message of size bytes saved to the store.
+	RecordSaved(size int)
+	// RecordRetrieved counts a message of size bytes read back from the store.
+	RecordRetrieved(size int)
+}
+
+// MessageStoreFileStats is implemented by message stores that keep messages in files, to monitor their disk usage.
+type MessageStoreFileStats interface {
+	// BodyFileSize returns the size in bytes of the file holding the messages.
+	BodyFileSize() (int64, error)
+	// HeaderFileSize returns the size in bytes of the file indexing the messages.
+	HeaderFileSize() (int64, error)
+}
+
+// StoreStats returns the monitoring counters of the store, if it keeps any. Middleware stores are
+// unwrapped until a store keeping counters is found.
+func StoreStats(store MessageStore) (MessageStoreStats, bool) {
+	return unwrapStore[MessageStoreStats](store)
+}
+
+// StoreFileStats returns the file sizes of the store, if it keeps messages in files. Middleware stores are
+// unwrapped as by StoreStats.
+func StoreFileStats(store MessageStore) (MessageStoreFileStats, bool) {
+	return unwrapStore[MessageStoreFileStats](store)
+}
+
+// unwrapStore unwraps middleware stores until one implementing T is found.
+func unwrapStore[T any](store MessageStore) (T, bool) {
+	for store != nil {
+		if t, ok := store.(T); ok {
+			return t, true
+		}
+		unwrapper, ok := store.(StoreUnwrapper)
+		if !ok {
+			break
+		}
+		store = unwrapper.Unwrap()
+	}
+	var zero T
+	return zero, false
+}
+
 // The MessageStoreFactory interface is used by session to create a session specific message store.
 type MessageStoreFactory interface {
 	Create(sessionID SessionID) (MessageStore, error)
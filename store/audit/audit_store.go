@@ -0,0 +1,119 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package audit
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// RecordWriter may be implemented by the io.Writer given to NewAuditStoreFactory to receive each saved
+// message along with its seqnum and session, in place of a formatted record.
+type RecordWriter interface {
+	WriteRecord(seqNum int, sessionID quickfix.SessionID, msg []byte) error
+}
+
+// AuditStore is a MessageStore that writes every saved message to an audit sink, in addition to
+// saving it to the store it wraps. A message is only written to the sink once the inner store has
+// saved it, and a failure to write it is returned to the caller.
+type AuditStore struct {
+	quickfix.MessageStore
+	sessionID quickfix.SessionID
+	sink      *sink
+}
+
+// sink serializes the writes of all the stores created by a factory, which share the writer.
+type sink struct {
+	mu  sync.Mutex
+	w   io.Writer
+	buf bytes.Buffer
+}
+
+// write passes the record to the writer. Unless the writer implements RecordWriter, the record is
+// formatted as a single line "<session id> <seqnum> <msg>\n" and passed in a single Write call.
+func (s *sink) write(seqNum int, sessionID quickfix.SessionID, msg []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if rw, ok := s.w.(RecordWriter); ok {
+		return rw.WriteRecord(seqNum, sessionID, msg)
+	}
+
+	s.buf.Reset()
+	s.buf.WriteString(sessionID.String())
+	s.buf.WriteByte(' ')
+	s.buf.WriteString(strconv.Itoa(seqNum))
+	s.buf.WriteByte(' ')
+	s.buf.Write(msg)
+	s.buf.WriteByte('\n')
+	_, err := s.w.Write(s.buf.Bytes())
+	return err
+}
+
+type auditStoreFactory struct {
+	inner quickfix.MessageStoreFactory
+	sink  *sink
+}
+
+// NewAuditStoreFactory returns a MessageStoreFactory creating stores with inner, each wrapped in an
+// AuditStore writing to w. The stores of all sessions share w; their writes never interleave.
+func NewAuditStoreFactory(inner quickfix.MessageStoreFactory, w io.Writer) quickfix.MessageStoreFactory {
+	return auditStoreFactory{inner: inner, sink: &sink{w: w}}
+}
+
+// Create creates the inner store and wraps it in an AuditStore.
+func (f auditStoreFactory) Create(sessionID quickfix.SessionID) (quickfix.MessageStore, error) {
+	store, err := f.inner.Create(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditStore{MessageStore: store, sessionID: sessionID, sink: f.sink}, nil
+}
+
+// Unwrap returns the store the AuditStore wraps.
+func (store *AuditStore) Unwrap() quickfix.MessageStore {
+	return store.MessageStore
+}
+
+// SaveMessage saves the message to the inner store, then writes it to the audit sink.
+func (store *AuditStore) SaveMessage(seqNum int, msg []byte) error {
+	if err := store.MessageStore.SaveMessage(seqNum, msg); err != nil {
+		return err
+	}
+	return store.audit(seqNum, msg)
+}
+
+// SaveMessageAndIncrNextSenderMsgSeqNum saves the message to the inner store and increments the next
+// sender seqnum, then writes the message to the audit sink.
+func (store *AuditStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	if err := store.MessageStore.SaveMessageAndIncrNextSenderMsgSeqNum(seqNum, msg); err != nil {
+		return err
+	}
+	return store.audit(seqNum, msg)
+}
+
+func (store *AuditStore) audit(seqNum int, msg []byte) error {
+	if err := store.sink.write(seqNum, store.sessionID, msg); err != nil {
+		return errors.Wrapf(err, "audit message %d", seqNum)
+	}
+	return nil
+}
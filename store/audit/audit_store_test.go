@@ -0,0 +1,91 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package audit
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/internal/testsuite"
+)
+
+var sessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+// AuditStoreTestSuite runs all tests in the MessageStoreTestSuite against the AuditStore implementation.
+type AuditStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *AuditStoreTestSuite) SetupTest() {
+	var err error
+	suite.MsgStore, err = NewAuditStoreFactory(quickfix.NewMemoryStoreFactory(), new(bytes.Buffer)).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func TestAuditStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(AuditStoreTestSuite))
+}
+
+func TestAuditStoreWritesSavedMessages(t *testing.T) {
+	var buf bytes.Buffer
+	store, err := NewAuditStoreFactory(quickfix.NewMemoryStoreFactory(), &buf).Create(sessionID)
+	require.Nil(t, err)
+
+	require.Nil(t, store.SaveMessage(1, []byte("8=FIX.4.4\x0135=A\x01")))
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(2, []byte("8=FIX.4.4\x0135=0\x01")))
+	require.Nil(t, quickfix.SaveMessages(store, map[int][]byte{3: []byte("8=FIX.4.4\x0135=1\x01")}))
+
+	assert.Equal(t, "FIX.4.4:SENDER->TARGET 1 8=FIX.4.4\x0135=A\x01\n"+
+		"FIX.4.4:SENDER->TARGET 2 8=FIX.4.4\x0135=0\x01\n"+
+		"FIX.4.4:SENDER->TARGET 3 8=FIX.4.4\x0135=1\x01\n", buf.String())
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+}
+
+type recordWriter struct {
+	bytes.Buffer
+	records []int
+	err     error
+}
+
+func (w *recordWriter) WriteRecord(seqNum int, _ quickfix.SessionID, _ []byte) error {
+	w.records = append(w.records, seqNum)
+	return w.err
+}
+
+func TestAuditStoreRecordWriter(t *testing.T) {
+	w := new(recordWriter)
+	store, err := NewAuditStoreFactory(quickfix.NewMemoryStoreFactory(), w).Create(sessionID)
+	require.Nil(t, err)
+
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	assert.Equal(t, []int{1}, w.records)
+	assert.Zero(t, w.Len())
+
+	w.err = errors.New("sink unavailable")
+	err = store.SaveMessage(2, []byte("world"))
+	assert.ErrorIs(t, err, w.err)
+
+	// The message is still saved by the inner store.
+	msgs, err := store.GetMessages(2, 2)
+	require.Nil(t, err)
+	assert.Equal(t, [][]byte{[]byte("world")}, msgs)
+}
@@ -0,0 +1,497 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/pkg/errors"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// Every item of the table is keyed by the session ID string and a sequence number. Messages are stored
+// under their seqnum, while the session record, holding the creation time and the next seqnums, is
+// stored under sessionSeqNum, which no message can have.
+const (
+	attrSessionID    = "session_id"
+	attrSeqNum       = "seq_num"
+	attrBody         = "body"
+	attrSentAt       = "sent_at"
+	attrCreationTime = "creation_time"
+	attrNextSender   = "next_sender"
+	attrNextTarget   = "next_target"
+
+	sessionSeqNum = 0
+
+	// batchWriteSize is the maximum number of requests accepted by BatchWriteItem.
+	batchWriteSize = 25
+)
+
+type dynamoDBStoreFactory struct {
+	settings *quickfix.Settings
+}
+
+type dynamoDBStore struct {
+	sessionID quickfix.SessionID
+	cache     quickfix.MessageStore
+	client    *ddb.Client
+	table     string
+	key       string
+}
+
+// NewStoreFactory returns a DynamoDB-based implementation of MessageStoreFactory.
+func NewStoreFactory(settings *quickfix.Settings) quickfix.MessageStoreFactory {
+	return dynamoDBStoreFactory{settings: settings}
+}
+
+// Create creates a new DynamoDBStore implementation of the MessageStore interface.
+func (f dynamoDBStoreFactory) Create(sessionID quickfix.SessionID) (msgStore quickfix.MessageStore, err error) {
+	globalSettings := f.settings.GlobalSettings()
+	dynamicSessions, _ := globalSettings.BoolSetting(config.DynamicSessions)
+
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		if dynamicSessions {
+			sessionSettings = globalSettings
+		} else {
+			return nil, fmt.Errorf("unknown session: %v", sessionID)
+		}
+	}
+
+	region, err := sessionSettings.Setting(config.AWSRegion)
+	if err != nil {
+		return nil, err
+	}
+	table, err := sessionSettings.Setting(config.DynamoDBTable)
+	if err != nil {
+		return nil, err
+	}
+
+	// Optional.
+	var endpoint string
+	if sessionSettings.HasSetting(config.DynamoDBEndpoint) {
+		if endpoint, err = sessionSettings.Setting(config.DynamoDBEndpoint); err != nil {
+			return nil, err
+		}
+	}
+
+	awsCfg, err := awsconfig.LoadDefaultConfig(context.Background(), awsconfig.WithRegion(region))
+	if err != nil {
+		return nil, errors.Wrap(err, "load aws config")
+	}
+	client := ddb.NewFromConfig(awsCfg, func(o *ddb.Options) {
+		if endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+		}
+	})
+
+	return newDynamoDBStore(sessionID, client, table)
+}
+
+func newDynamoDBStore(sessionID quickfix.SessionID, client *ddb.Client, table string) (store *dynamoDBStore, err error) {
+	memStore, memErr := quickfix.NewMemoryStoreFactory().Create(sessionID)
+	if memErr != nil {
+		err = errors.Wrap(memErr, "cache creation")
+		return
+	}
+
+	store = &dynamoDBStore{
+		sessionID: sessionID,
+		cache:     memStore,
+		client:    client,
+		table:     table,
+		key:       sessionID.String(),
+	}
+
+	if err = store.Refresh(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+func numberValue(n int) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.Itoa(n)}
+}
+
+func (store *dynamoDBStore) itemKey(seqNum int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		attrSessionID: &types.AttributeValueMemberS{Value: store.key},
+		attrSeqNum:    numberValue(seqNum),
+	}
+}
+
+func (store *dynamoDBStore) sessionItem() (map[string]types.AttributeValue, error) {
+	creationTime, err := store.cache.CreationTime().MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	item := store.itemKey(sessionSeqNum)
+	item[attrCreationTime] = &types.AttributeValueMemberS{Value: string(creationTime)}
+	item[attrNextSender] = numberValue(store.cache.NextSenderMsgSeqNum())
+	item[attrNextTarget] = numberValue(store.cache.NextTargetMsgSeqNum())
+	return item, nil
+}
+
+func (store *dynamoDBStore) messageItem(seqNum int, msg []byte) map[string]types.AttributeValue {
+	item := store.itemKey(seqNum)
+	item[attrBody] = &types.AttributeValueMemberB{Value: msg}
+	item[attrSentAt] = &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)}
+	return item
+}
+
+// Reset deletes the store records and sets the seqnums back to 1.
+func (store *dynamoDBStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	ctx := context.Background()
+	paginator := ddb.NewQueryPaginator(store.client, &ddb.QueryInput{
+		TableName:              aws.String(store.table),
+		KeyConditionExpression: aws.String("session_id = :sid AND seq_num > :session"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sid":     &types.AttributeValueMemberS{Value: store.key},
+			":session": numberValue(sessionSeqNum),
+		},
+		ProjectionExpression: aws.String("session_id, seq_num"),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return errors.Wrap(err, "query")
+		}
+		for start := 0; start < len(page.Items); start += batchWriteSize {
+			end := min(start+batchWriteSize, len(page.Items))
+			requests := make([]types.WriteRequest, 0, end-start)
+			for _, item := range page.Items[start:end] {
+				requests = append(requests, types.WriteRequest{DeleteRequest: &types.DeleteRequest{Key: item}})
+			}
+			if err := store.batchWrite(ctx, requests); err != nil {
+				return errors.Wrap(err, "delete")
+			}
+		}
+	}
+
+	item, err := store.sessionItem()
+	if err != nil {
+		return err
+	}
+	_, err = store.client.PutItem(ctx, &ddb.PutItemInput{TableName: aws.String(store.table), Item: item})
+	return err
+}
+
+// batchWrite performs the requests, retrying those DynamoDB leaves unprocessed.
+func (store *dynamoDBStore) batchWrite(ctx context.Context, requests []types.WriteRequest) error {
+	for backoff := 50 * time.Millisecond; len(requests) > 0; backoff *= 2 {
+		out, err := store.client.BatchWriteItem(ctx, &ddb.BatchWriteItemInput{
+			RequestItems: map[string][]types.WriteRequest{store.table: requests},
+		})
+		if err != nil {
+			return err
+		}
+		requests = out.UnprocessedItems[store.table]
+		if len(requests) > 0 {
+			time.Sleep(backoff)
+		}
+	}
+	return nil
+}
+
+// Refresh reloads the store from DynamoDB.
+func (store *dynamoDBStore) Refresh() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+	return store.populateCache()
+}
+
+func (store *dynamoDBStore) populateCache() error {
+	ctx := context.Background()
+
+	// Create the session record unless another store has already done so.
+	item, err := store.sessionItem()
+	if err != nil {
+		return err
+	}
+	_, err = store.client.PutItem(ctx, &ddb.PutItemInput{
+		TableName:           aws.String(store.table),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(seq_num)"),
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return errors.Wrap(err, "insert")
+	}
+
+	out, err := store.client.GetItem(ctx, &ddb.GetItemInput{
+		TableName:      aws.String(store.table),
+		Key:            store.itemKey(sessionSeqNum),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return errors.Wrap(err, "query")
+	}
+
+	creationTime, ok := out.Item[attrCreationTime].(*types.AttributeValueMemberS)
+	if !ok {
+		return fmt.Errorf("malformed session record: %v", store.key)
+	}
+	var ctime time.Time
+	if err := ctime.UnmarshalText([]byte(creationTime.Value)); err != nil {
+		return errors.Wrap(err, "decode creation time")
+	}
+	store.cache.SetCreationTime(ctime)
+
+	nextSender, err := numberAttr(out.Item, attrNextSender)
+	if err != nil {
+		return err
+	}
+	if err := store.cache.SetNextSenderMsgSeqNum(nextSender); err != nil {
+		return errors.Wrap(err, "cache set next sender")
+	}
+
+	nextTarget, err := numberAttr(out.Item, attrNextTarget)
+	if err != nil {
+		return err
+	}
+	if err := store.cache.SetNextTargetMsgSeqNum(nextTarget); err != nil {
+		return errors.Wrap(err, "cache set next target")
+	}
+	return nil
+}
+
+func numberAttr(item map[string]types.AttributeValue, name string) (int, error) {
+	attr, ok := item[name].(*types.AttributeValueMemberN)
+	if !ok {
+		return 0, fmt.Errorf("missing number attribute: %v", name)
+	}
+	n, err := strconv.Atoi(attr.Value)
+	if err != nil {
+		return 0, errors.Wrapf(err, "decode %v", name)
+	}
+	return n, nil
+}
+
+func isConditionalCheckFailed(err error) bool {
+	var ccf *types.ConditionalCheckFailedException
+	return errors.As(err, &ccf)
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent.
+func (store *dynamoDBStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received.
+func (store *dynamoDBStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+func (store *dynamoDBStore) setSeqNum(attr string, next int) error {
+	_, err := store.client.UpdateItem(context.Background(), &ddb.UpdateItemInput{
+		TableName:                 aws.String(store.table),
+		Key:                       store.itemKey(sessionSeqNum),
+		UpdateExpression:          aws.String("SET #seq = :next"),
+		ExpressionAttributeNames:  map[string]string{"#seq": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":next": numberValue(next)},
+	})
+	return err
+}
+
+// incrSeqNum atomically increments the seqnum attribute and returns its new value.
+func (store *dynamoDBStore) incrSeqNum(attr string) (int, error) {
+	out, err := store.client.UpdateItem(context.Background(), &ddb.UpdateItemInput{
+		TableName:                 aws.String(store.table),
+		Key:                       store.itemKey(sessionSeqNum),
+		UpdateExpression:          aws.String("ADD #seq :one"),
+		ExpressionAttributeNames:  map[string]string{"#seq": attr},
+		ExpressionAttributeValues: map[string]types.AttributeValue{":one": numberValue(1)},
+		ReturnValues:              types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return numberAttr(out.Attributes, attr)
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent.
+func (store *dynamoDBStore) SetNextSenderMsgSeqNum(next int) error {
+	if err := store.setSeqNum(attrNextSender, next); err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received.
+func (store *dynamoDBStore) SetNextTargetMsgSeqNum(next int) error {
+	if err := store.setSeqNum(attrNextTarget, next); err != nil {
+		return err
+	}
+	return store.cache.SetNextTargetMsgSeqNum(next)
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
+func (store *dynamoDBStore) IncrNextSenderMsgSeqNum() error {
+	next, err := store.incrSeqNum(attrNextSender)
+	if err != nil {
+		return errors.Wrap(err, "save sequence number")
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
+func (store *dynamoDBStore) IncrNextTargetMsgSeqNum() error {
+	next, err := store.incrSeqNum(attrNextTarget)
+	if err != nil {
+		return errors.Wrap(err, "save sequence number")
+	}
+	return store.cache.SetNextTargetMsgSeqNum(next)
+}
+
+// CreationTime returns the creation time of the store.
+func (store *dynamoDBStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime is a no-op for DynamoDBStore.
+func (store *dynamoDBStore) SetCreationTime(_ time.Time) {
+}
+
+// SaveMessage stores the message. A message already stored under the same seqnum is left untouched.
+func (store *dynamoDBStore) SaveMessage(seqNum int, msg []byte) error {
+	_, err := store.client.PutItem(context.Background(), &ddb.PutItemInput{
+		TableName:           aws.String(store.table),
+		Item:                store.messageItem(seqNum, msg),
+		ConditionExpression: aws.String("attribute_not_exists(seq_num)"),
+	})
+	if err != nil && !isConditionalCheckFailed(err) {
+		return err
+	}
+	return nil
+}
+
+func (store *dynamoDBStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	next := store.cache.NextSenderMsgSeqNum() + 1
+	_, err := store.client.TransactWriteItems(context.Background(), &ddb.TransactWriteItemsInput{
+		TransactItems: []types.TransactWriteItem{
+			{Put: &types.Put{
+				TableName:           aws.String(store.table),
+				Item:                store.messageItem(seqNum, msg),
+				ConditionExpression: aws.String("attribute_not_exists(seq_num)"),
+			}},
+			{Update: &types.Update{
+				TableName:                 aws.String(store.table),
+				Key:                       store.itemKey(sessionSeqNum),
+				UpdateExpression:          aws.String("SET #seq = :next"),
+				ExpressionAttributeNames:  map[string]string{"#seq": attrNextSender},
+				ExpressionAttributeValues: map[string]types.AttributeValue{":next": numberValue(next)},
+			}},
+		},
+	})
+	if err != nil {
+		var canceled *types.TransactionCanceledException
+		if !errors.As(err, &canceled) || len(canceled.CancellationReasons) == 0 ||
+			aws.ToString(canceled.CancellationReasons[0].Code) != "ConditionalCheckFailed" {
+			return err
+		}
+		// The message is already stored; only the seqnum needs updating.
+		return store.SetNextSenderMsgSeqNum(next)
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// IterateMessages queries the messages page by page, so that only a single page of messages is
+// held in memory at any time.
+func (store *dynamoDBStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	if beginSeqNum <= sessionSeqNum {
+		beginSeqNum = sessionSeqNum + 1
+	}
+	if beginSeqNum > endSeqNum {
+		return nil
+	}
+
+	ctx := context.Background()
+	paginator := ddb.NewQueryPaginator(store.client, &ddb.QueryInput{
+		TableName:              aws.String(store.table),
+		KeyConditionExpression: aws.String("session_id = :sid AND seq_num BETWEEN :begin AND :end"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":sid":   &types.AttributeValueMemberS{Value: store.key},
+			":begin": numberValue(beginSeqNum),
+			":end":   numberValue(endSeqNum),
+		},
+		ConsistentRead: aws.Bool(true),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		for _, item := range page.Items {
+			body, ok := item[attrBody].(*types.AttributeValueMemberB)
+			if !ok {
+				return fmt.Errorf("malformed message record: %v", store.key)
+			}
+			if err := cb(body.Value); err != nil {
+				if errors.Is(err, quickfix.ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (store *dynamoDBStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// HealthCheck describes the table, verifying that it is reachable and can be read and written.
+func (store *dynamoDBStore) HealthCheck() error {
+	out, err := store.client.DescribeTable(context.Background(), &ddb.DescribeTableInput{TableName: aws.String(store.table)})
+	if err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	if out.Table != nil {
+		switch status := out.Table.TableStatus; status {
+		case types.TableStatusActive, types.TableStatusUpdating:
+		default:
+			return fmt.Errorf("table %v is %v", store.table, status)
+		}
+	}
+	return nil
+}
+
+// Close is a no-op for DynamoDBStore, as the client holds no persistent connection.
+func (store *dynamoDBStore) Close() error {
+	return nil
+}
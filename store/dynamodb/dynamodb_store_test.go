@@ -0,0 +1,108 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package dynamodb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ddb "github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/internal/testsuite"
+)
+
+const testTable = "automated_testing"
+
+// DynamoDBStoreTestSuite runs all tests in the message.StoreTestSuite against the DynamoDBStore implementation.
+type DynamoDBStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *DynamoDBStoreTestSuite) SetupTest() {
+	endpoint := os.Getenv("DYNAMODB_TEST_ENDPOINT")
+	if len(endpoint) <= 0 {
+		log.Println("DYNAMODB_TEST_ENDPOINT environment arg is not provided, skipping...")
+		suite.T().SkipNow()
+	}
+
+	// create settings
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+AWSRegion=us-east-1
+DynamoDBTable=%s
+DynamoDBEndpoint=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, testTable, endpoint, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.createTable(endpoint)
+
+	// create store
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+	err = suite.MsgStore.Reset()
+	require.Nil(suite.T(), err)
+}
+
+// createTable creates the test table unless it already exists.
+func (suite *DynamoDBStoreTestSuite) createTable(endpoint string) {
+	client := ddb.New(ddb.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	_, err := client.CreateTable(context.Background(), &ddb.CreateTableInput{
+		TableName: aws.String(testTable),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(attrSessionID), AttributeType: types.ScalarAttributeTypeS},
+			{AttributeName: aws.String(attrSeqNum), AttributeType: types.ScalarAttributeTypeN},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(attrSessionID), KeyType: types.KeyTypeHash},
+			{AttributeName: aws.String(attrSeqNum), KeyType: types.KeyTypeRange},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	var inUse *types.ResourceInUseException
+	if err != nil && !errors.As(err, &inUse) {
+		require.Nil(suite.T(), err)
+	}
+}
+
+func (suite *DynamoDBStoreTestSuite) TearDownTest() {
+	if suite.MsgStore != nil {
+		err := suite.MsgStore.Close()
+		require.Nil(suite.T(), err)
+	}
+}
+
+func TestDynamoDBStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(DynamoDBStoreTestSuite))
+}
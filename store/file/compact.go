@@ -0,0 +1,300 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// copyMsgDefs copies the messages described by defs from the body file into dstBody, appending their new
+// header records to dstHeader. dstOffset is the current end of dstBody and the updated end is returned.
+func copyMsgDefs(defs []msgDef, body *os.File, dstBody, dstHeader *os.File, dstOffset int64) (int64, error) {
+	for _, def := range defs {
+		msg := make([]byte, def.size)
+		if _, err := body.ReadAt(msg, def.offset); err != nil {
+			return dstOffset, fmt.Errorf("unable to read from file: %s: %s", body.Name(), err.Error())
+		}
+		def.offset = dstOffset
+		if err := writeMsgDef(dstHeader, def); err != nil {
+			return dstOffset, fmt.Errorf("unable to write to file: %s: %s", dstHeader.Name(), err.Error())
+		}
+		if _, err := dstBody.Write(msg); err != nil {
+			return dstOffset, fmt.Errorf("unable to write to file: %s: %s", dstBody.Name(), err.Error())
+		}
+		dstOffset += int64(def.size)
+	}
+	return dstOffset, nil
+}
+
+// Compaction writes the compacted body and header files alongside the live files with a .tmp suffix and
+// flushes them. Two renames are needed to swap them in, so a marker file is created first: once it exists,
+// both .tmp files are complete and Refresh completes an interrupted swap by renaming whichever .tmp file is
+// left. Without the marker, leftover .tmp files belong to a compaction that never finished and are discarded.
+
+// errFilesReplaced is returned by a compaction pass when the live files were replaced while it ran, e.g. by a
+// rotation triggered by SaveMessage.
+var errFilesReplaced = errors.New("files replaced during compaction")
+
+// compactAttempts bounds the passes Compact makes before giving up on files that keep being replaced.
+const compactAttempts = 3
+
+// Compact rewrites the body and header files so that they only retain the most recently saved copy of each
+// message, ordered by sequence number from the lowest to the highest stored seqnum. Bytes left behind by
+// overwritten messages are reclaimed.
+func (store *fileStore) Compact() error {
+	return store.CompactFrom(1)
+}
+
+// CompactFrom behaves like Compact, but also drops the messages with seqnums below beginSeqNum, so that only
+// the messages in [beginSeqNum, highest stored seqnum] are retained.
+//
+// The compacted files are built without holding the store lock, from a snapshot of the header file. The lock
+// is taken again to copy the messages saved while the snapshot was being compacted, and then held while the
+// files are swapped in together with any messages saved since. Should the live files be rotated in the
+// meantime, the compaction starts over on the new files. Iterations that are already in progress keep
+// reading from the files they opened.
+func (store *fileStore) CompactFrom(beginSeqNum int) (err error) {
+	for i := 0; i < compactAttempts; i++ {
+		if err = store.compact(beginSeqNum); !errors.Is(err, errFilesReplaced) {
+			return err
+		}
+	}
+	return err
+}
+
+// compactSource is a snapshot of the live body and header files taken by compact.
+type compactSource struct {
+	generation uint64
+	bodyMagic  []byte
+	bodyFile   *os.File
+	headerFile *os.File
+	headerLen  int64
+}
+
+func (src *compactSource) close() {
+	if src.bodyFile != nil {
+		_ = src.bodyFile.Close()
+	}
+	if src.headerFile != nil {
+		_ = src.headerFile.Close()
+	}
+}
+
+// snapshotLocked opens the live files for compaction and records how much of the header file is complete.
+func (store *fileStore) snapshotLocked() (src *compactSource, err error) {
+	if err := store.syncBodyAndHeaderFilesLocked(); err != nil {
+		return nil, err
+	}
+	src = &compactSource{generation: store.generation, bodyMagic: store.bodyMagic}
+	if src.bodyFile, err = os.Open(store.bodyFname); err != nil {
+		return nil, fmt.Errorf("unable to open file: %s: %s", store.bodyFname, err.Error())
+	}
+	if src.headerFile, err = os.Open(store.headerFname); err != nil {
+		src.close()
+		return nil, fmt.Errorf("unable to open file: %s: %s", store.headerFname, err.Error())
+	}
+	if src.headerLen, err = completeHeaderLen(src.headerFile); err != nil {
+		src.close()
+		return nil, err
+	}
+	return src, nil
+}
+
+// catchUpLocked returns the end of the complete records of the live header file, for copying the messages
+// saved since the snapshot. It fails with errFilesReplaced if the live files are no longer the snapshot's.
+func (store *fileStore) catchUpLocked(src *compactSource) (int64, error) {
+	if store.generation != src.generation {
+		return 0, errFilesReplaced
+	}
+	if err := store.syncBodyAndHeaderFilesLocked(); err != nil {
+		return 0, err
+	}
+	return completeHeaderLen(src.headerFile)
+}
+
+func (store *fileStore) compact(beginSeqNum int) error {
+	store.fileMu.Lock()
+	src, err := store.snapshotLocked()
+	store.fileMu.Unlock()
+	if err != nil {
+		return err
+	}
+	defer src.close()
+	if store.compactSnapshotTaken != nil {
+		store.compactSnapshotTaken()
+	}
+
+	defs, err := readMsgDefs(src.headerFile, 0, src.headerLen)
+	if err != nil {
+		return err
+	}
+
+	// Only the last record written for each seqnum is retained.
+	latest := make(map[int]msgDef, len(defs))
+	for _, def := range defs {
+		if def.seqNum >= beginSeqNum {
+			latest[def.seqNum] = def
+		}
+	}
+	defs = defs[:0]
+	for _, def := range latest {
+		defs = append(defs, def)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].seqNum < defs[j].seqNum })
+
+	tmpBodyFname := store.bodyFname + ".tmp"
+	tmpHeaderFname := store.headerFname + ".tmp"
+	tmpBody, err := os.OpenFile(tmpBodyFname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", tmpBodyFname, err.Error())
+	}
+	defer func() { _ = tmpBody.Close() }()
+	tmpHeader, err := os.OpenFile(tmpHeaderFname, os.O_RDWR|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", tmpHeaderFname, err.Error())
+	}
+	defer func() { _ = tmpHeader.Close() }()
+
+	var tmpOffset int64
+	if src.bodyMagic != nil {
+		if _, err := tmpBody.Write(src.bodyMagic); err != nil {
+			return fmt.Errorf("unable to write to file: %s: %s", tmpBodyFname, err.Error())
+		}
+		tmpOffset = int64(len(src.bodyMagic))
+	}
+	if tmpOffset, err = copyMsgDefs(defs, src.bodyFile, tmpBody, tmpHeader, tmpOffset); err != nil {
+		return err
+	}
+
+	// Copy the messages saved while the snapshot was compacted without holding the lock, so that it is
+	// only held for whatever is saved during this shorter catch-up.
+	store.fileMu.Lock()
+	headerLen, err := store.catchUpLocked(src)
+	store.fileMu.Unlock()
+	if err != nil {
+		return err
+	}
+	if tmpOffset, err = copyTail(src, headerLen, beginSeqNum, tmpBody, tmpHeader, tmpOffset); err != nil {
+		return err
+	}
+	src.headerLen = headerLen
+
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	if headerLen, err = store.catchUpLocked(src); err != nil {
+		return err
+	}
+	if _, err = copyTail(src, headerLen, beginSeqNum, tmpBody, tmpHeader, tmpOffset); err != nil {
+		return err
+	}
+
+	if err := tmpBody.Sync(); err != nil {
+		return fmt.Errorf("unable to flush file: %s: %s", tmpBodyFname, err.Error())
+	}
+	if err := tmpHeader.Sync(); err != nil {
+		return fmt.Errorf("unable to flush file: %s: %s", tmpHeaderFname, err.Error())
+	}
+	return store.swapCompactedLocked()
+}
+
+// copyTail copies the messages recorded in the header file between the end of the previous copy and
+// headerLen to the compacted files.
+func copyTail(src *compactSource, headerLen int64, beginSeqNum int, dstBody, dstHeader *os.File, dstOffset int64) (int64, error) {
+	if headerLen <= src.headerLen {
+		return dstOffset, nil
+	}
+	tail, err := readMsgDefs(src.headerFile, src.headerLen, headerLen)
+	if err != nil {
+		return dstOffset, err
+	}
+	kept := tail[:0]
+	for _, def := range tail {
+		if def.seqNum >= beginSeqNum {
+			kept = append(kept, def)
+		}
+	}
+	return copyMsgDefs(kept, src.bodyFile, dstBody, dstHeader, dstOffset)
+}
+
+// swapCompactedLocked renames the flushed .tmp files over the live files and reopens them.
+func (store *fileStore) swapCompactedLocked() error {
+	marker, err := os.OpenFile(store.compactMarkerFname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", store.compactMarkerFname, err.Error())
+	}
+	if err := marker.Close(); err != nil {
+		return fmt.Errorf("unable to close file: %s: %s", store.compactMarkerFname, err.Error())
+	}
+	dirname := filepath.Dir(store.bodyFname)
+	syncDir(dirname)
+
+	if err := store.completeCompaction(); err != nil {
+		return err
+	}
+
+	// The store's handles still point at the replaced files.
+	store.generation++
+	if err := closeSyncFile(store.bodyFile); err != nil {
+		return err
+	}
+	if err := closeSyncFile(store.headerFile); err != nil {
+		return err
+	}
+	if store.bodyFile, err = openOrCreateFile(store.bodyFname, store.filePerm); err != nil {
+		return err
+	}
+	if store.headerFile, err = openOrCreateFile(store.headerFname, store.filePerm); err != nil {
+		return err
+	}
+	return nil
+}
+
+// completeCompaction renames the .tmp files left by a compaction over the live files and removes the marker.
+func (store *fileStore) completeCompaction() error {
+	for _, fname := range []string{store.bodyFname, store.headerFname} {
+		if err := os.Rename(fname+".tmp", fname); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "rename %v", fname+".tmp")
+		}
+	}
+	syncDir(filepath.Dir(store.bodyFname))
+	return removeFile(store.compactMarkerFname)
+}
+
+// recoverCompaction completes a compaction interrupted while swapping the files in, and discards the
+// .tmp files of a compaction interrupted before.
+func (store *fileStore) recoverCompaction() error {
+	if _, err := os.Stat(store.compactMarkerFname); err == nil {
+		return store.completeCompaction()
+	} else if !os.IsNotExist(err) {
+		return errors.Wrapf(err, "stat %v", store.compactMarkerFname)
+	}
+	if err := removeFile(store.bodyFname + ".tmp"); err != nil {
+		return err
+	}
+	return removeFile(store.headerFname + ".tmp")
+}
+
+// completeHeaderLen returns the length of the complete records of the header file.
+func completeHeaderLen(headerFile *os.File) (int64, error) {
+	n, err := headerRecordCount(headerFile)
+	return int64(n) * headerRecordSize, err
+}
@@ -0,0 +1,127 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// A body file created with FileStoreCompress enabled starts with compressedBodyMagic, which cannot
+// begin a FIX message. Each message is then DEFLATE compressed on its own, and its header record
+// holds both the compressed and the original size. Body files without the magic prefix hold
+// uncompressed messages.
+var compressedBodyMagic = []byte("QFZ\x01")
+
+// detectBodyFormatLocked determines whether the body file holds compressed or encrypted messages,
+// writing the magic prefix to a new body file if compression or encryption is enabled.
+func (store *fileStore) detectBodyFormatLocked() error {
+	info, err := store.bodyFile.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat file: %s: %s", store.bodyFname, err.Error())
+	}
+
+	if info.Size() == 0 {
+		store.bodyCompressed = store.compress
+		store.bodyEncrypted = store.aead != nil
+		switch {
+		case store.bodyEncrypted:
+			store.bodyMagic = encryptedBodyMagic
+		case store.bodyCompressed:
+			store.bodyMagic = compressedBodyMagic
+		default:
+			store.bodyMagic = nil
+			return nil
+		}
+		if _, err := store.bodyFile.WriteAt(store.bodyMagic, 0); err != nil {
+			return fmt.Errorf("unable to write to file: %s: %s", store.bodyFname, err.Error())
+		}
+		if store.fileSync {
+			if err := store.bodyFile.Sync(); err != nil {
+				return fmt.Errorf("unable to flush file: %s: %s", store.bodyFname, err.Error())
+			}
+		}
+		return nil
+	}
+
+	if store.bodyMagic, err = readBodyMagic(store.bodyFile); err != nil {
+		return err
+	}
+	store.bodyCompressed = bytes.Equal(store.bodyMagic, compressedBodyMagic)
+	store.bodyEncrypted = bytes.Equal(store.bodyMagic, encryptedBodyMagic)
+	if store.bodyEncrypted {
+		if store.aead == nil {
+			return fmt.Errorf("body file is encrypted but no FileStoreEncryptionKey is set: %s", store.bodyFname)
+		}
+		// Each message of an encrypted body file records whether it is compressed.
+		store.bodyCompressed = store.compress
+	} else if store.aead != nil {
+		return fmt.Errorf("body file is not encrypted but FileStoreEncryptionKey is set, reset the store to encrypt it: %s", store.bodyFname)
+	}
+	return nil
+}
+
+// readBodyMagic returns the magic prefix the body file starts with, nil if it has none.
+func readBodyMagic(bodyFile *os.File) ([]byte, error) {
+	prefix := make([]byte, len(compressedBodyMagic))
+	n, err := bodyFile.ReadAt(prefix, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("unable to read from file: %s: %s", bodyFile.Name(), err.Error())
+	}
+	for _, magic := range [][]byte{compressedBodyMagic, encryptedBodyMagic} {
+		if bytes.Equal(prefix[:n], magic) {
+			return magic, nil
+		}
+	}
+	return nil, nil
+}
+
+// compressMsgLocked returns the compressed form of msg. The returned slice is only valid until the next call.
+func (store *fileStore) compressMsgLocked(msg []byte) ([]byte, error) {
+	store.compressBuf.Reset()
+	if store.flateWriter == nil {
+		w, err := flate.NewWriter(&store.compressBuf, store.compressLevel)
+		if err != nil {
+			return nil, err
+		}
+		store.flateWriter = w
+	} else {
+		store.flateWriter.Reset(&store.compressBuf)
+	}
+	if _, err := store.flateWriter.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := store.flateWriter.Close(); err != nil {
+		return nil, err
+	}
+	return store.compressBuf.Bytes(), nil
+}
+
+// decompressMsg inflates a message stored compressed, given its original size.
+func decompressMsg(stored []byte, rawSize int) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(stored))
+	defer func() { _ = r.Close() }()
+	msg := make([]byte, rawSize)
+	if _, err := io.ReadFull(r, msg); err != nil {
+		return nil, errors.Wrap(err, "decompress")
+	}
+	return msg, nil
+}
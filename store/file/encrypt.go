@@ -0,0 +1,113 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/pkg/errors"
+)
+
+// A body file created with FileStoreEncryptionKey set starts with encryptedBodyMagic. Each message is
+// then sealed on its own with AES-256-GCM and stored as
+// [1 byte key version][12 bytes nonce][ciphertext and tag], with the seqnum as additional data so that
+// a message cannot be passed off as another. A message is compressed before it is encrypted when
+// FileStoreCompress is also enabled, as recorded by the original size in its header record.
+var encryptedBodyMagic = []byte("QFE\x01")
+
+// encryptionKeyVersion identifies the key messages are encrypted with. Only a single key is supported
+// for now, the version leaves room for key rotation.
+const encryptionKeyVersion byte = 1
+
+// parseEncryptionKey decodes a hex-encoded AES-256 key.
+func parseEncryptionKey(raw string) ([]byte, error) {
+	key, err := hex.DecodeString(raw)
+	if err != nil {
+		return nil, err
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("expected a 32 byte key, got %d bytes", len(key))
+	}
+	return key, nil
+}
+
+// newAEAD returns the AES-GCM cipher for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func additionalData(seqNum int) []byte {
+	var ad [8]byte
+	binary.BigEndian.PutUint64(ad[:], uint64(seqNum))
+	return ad[:]
+}
+
+// sealMsg encrypts msg with a random nonce.
+func (store *fileStore) sealMsg(seqNum int, msg []byte) ([]byte, error) {
+	nonceSize := store.aead.NonceSize()
+	sealed := make([]byte, 1+nonceSize, 1+nonceSize+len(msg)+store.aead.Overhead())
+	sealed[0] = encryptionKeyVersion
+	nonce := sealed[1 : 1+nonceSize]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "generate nonce")
+	}
+	return store.aead.Seal(sealed, nonce, msg, additionalData(seqNum)), nil
+}
+
+// openMsg decrypts a message sealed by sealMsg.
+func (store *fileStore) openMsg(seqNum int, sealed []byte) ([]byte, error) {
+	if store.aead == nil {
+		return nil, errors.New("message is encrypted but no FileStoreEncryptionKey is set")
+	}
+	nonceSize := store.aead.NonceSize()
+	if len(sealed) < 1+nonceSize {
+		return nil, errors.New("encrypted message is truncated")
+	}
+	if sealed[0] != encryptionKeyVersion {
+		return nil, fmt.Errorf("unsupported encryption key version: %d", sealed[0])
+	}
+	msg, err := store.aead.Open(nil, sealed[1:1+nonceSize], sealed[1+nonceSize:], additionalData(seqNum))
+	if err != nil {
+		return nil, errors.Wrap(err, "decrypt")
+	}
+	return msg, nil
+}
+
+// decodeMsg returns the original form of a message as read from a body file, given whether the body
+// file is encrypted.
+func (store *fileStore) decodeMsg(stored []byte, def msgDef, encrypted bool) (msg []byte, err error) {
+	msg = stored
+	if encrypted {
+		if msg, err = store.openMsg(def.seqNum, msg); err != nil {
+			return nil, err
+		}
+	}
+	if def.rawSize > 0 {
+		if msg, err = decompressMsg(msg, def.rawSize); err != nil {
+			return nil, err
+		}
+	}
+	return msg, nil
+}
@@ -16,10 +16,14 @@
 package file
 
 import (
+	"bytes"
+	"compress/flate"
+	"crypto/cipher"
 	"fmt"
 	"io"
 	"os"
 	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -35,13 +39,16 @@ type fileStoreFactory struct {
 }
 
 type fileStore struct {
-	sessionID          quickfix.SessionID
-	cache              quickfix.MessageStore
-	bodyFname          string
-	headerFname        string
-	sessionFname       string
-	senderSeqNumsFname string
-	targetSeqNumsFname string
+	sessionID           quickfix.SessionID
+	cache               quickfix.MessageStore
+	bodyFname           string
+	headerFname         string
+	sessionFname        string
+	senderSeqNumsFname  string
+	targetSeqNumsFname  string
+	seqNumsJournalFname string
+	stateFname          string
+	compactMarkerFname  string
 
 	fileMu            sync.Mutex
 	bodyFile          *os.File
@@ -50,8 +57,55 @@ type fileStore struct {
 	senderSeqNumsFile *os.File
 	targetSeqNumsFile *os.File
 	fileSync          bool
+	filePerm          os.FileMode
+
+	// generation changes whenever the body and header files are closed or replaced, see Compact.
+	generation uint64
+	// compactSnapshotTaken is called by Compact once it has taken its snapshot, for tests.
+	compactSnapshotTaken func()
+
+	compress       bool
+	compressLevel  int
+	bodyCompressed bool
+	bodyEncrypted  bool
+	bodyMagic      []byte
+	flateWriter    *flate.Writer
+	compressBuf    bytes.Buffer
+
+	rotateInterval string
+	rotateAt       time.Time
+	maxBodyBytes   int64
+
+	mmap bool
+
+	aead cipher.AEAD
+
+	syncInterval time.Duration
+	syncStop     chan struct{}
+	syncDone     chan struct{}
+	// syncErr is the last error of the periodic sync, returned by the next SaveMessage or Close.
+	syncErr error
+
+	// cacheStats is the cache, keeping the monitoring counters of the store.
+	cacheStats quickfix.MessageStoreStatsRecorder
+}
+
+// fileStoreOptions holds the settings a fileStore is created with.
+type fileStoreOptions struct {
+	fileSync       bool
+	filePerm       os.FileMode
+	syncInterval   time.Duration
+	compress       bool
+	compressLevel  int
+	rotateInterval string
+	maxBodyBytes   int64
+	mmap           bool
+	encryptionKey  []byte
 }
 
+// defaultFilePerm is the permission mask used for store files unless FileStorePermissions is set.
+const defaultFilePerm os.FileMode = 0660
+
 // NewStoreFactory returns a file-based implementation of MessageStoreFactory.
 func NewStoreFactory(settings *quickfix.Settings) quickfix.MessageStoreFactory {
 	return fileStoreFactory{settings: settings}
@@ -75,39 +129,142 @@ func (f fileStoreFactory) Create(sessionID quickfix.SessionID) (msgStore quickfi
 	if err != nil {
 		return nil, err
 	}
-	var fsync bool
+	dirname = expandPathTemplate(dirname, sessionID)
+	opts := fileStoreOptions{
+		fileSync:      true, //existing behavior is to fsync writes
+		filePerm:      defaultFilePerm,
+		compressLevel: flate.DefaultCompression,
+	}
 	if sessionSettings.HasSetting(config.FileStoreSync) {
-		fsync, err = sessionSettings.BoolSetting(config.FileStoreSync)
+		opts.fileSync, err = sessionSettings.BoolSetting(config.FileStoreSync)
 		if err != nil {
 			return nil, err
 		}
-	} else {
-		fsync = true //existing behavior is to fsync writes
 	}
-	return newFileStore(sessionID, dirname, fsync)
+	if sessionSettings.HasSetting(config.FileStorePermissions) {
+		if opts.filePerm, err = parseFilePerm(sessionSettings); err != nil {
+			return nil, err
+		}
+	}
+	if sessionSettings.HasSetting(config.FileStoreSyncIntervalMs) {
+		intervalMs, err := sessionSettings.IntSetting(config.FileStoreSyncIntervalMs)
+		if err != nil {
+			return nil, err
+		}
+		if intervalMs < 0 {
+			return nil, quickfix.IncorrectFormatForSetting{Setting: config.FileStoreSyncIntervalMs, Value: []byte(strconv.Itoa(intervalMs))}
+		}
+		opts.syncInterval = time.Duration(intervalMs) * time.Millisecond
+	}
+	if sessionSettings.HasSetting(config.FileStoreCompress) {
+		if opts.compress, err = sessionSettings.BoolSetting(config.FileStoreCompress); err != nil {
+			return nil, err
+		}
+	}
+	if sessionSettings.HasSetting(config.FileStoreCompressLevel) {
+		if opts.compressLevel, err = sessionSettings.IntSetting(config.FileStoreCompressLevel); err != nil {
+			return nil, err
+		}
+		if opts.compressLevel < flate.HuffmanOnly || opts.compressLevel > flate.BestCompression {
+			return nil, quickfix.IncorrectFormatForSetting{Setting: config.FileStoreCompressLevel, Value: []byte(strconv.Itoa(opts.compressLevel))}
+		}
+	}
+	if sessionSettings.HasSetting(config.FileStoreRotateInterval) {
+		raw, err := sessionSettings.Setting(config.FileStoreRotateInterval)
+		if err != nil {
+			return nil, err
+		}
+		if opts.rotateInterval, err = parseRotateInterval(raw); err != nil {
+			return nil, quickfix.IncorrectFormatForSetting{Setting: config.FileStoreRotateInterval, Value: []byte(raw), Err: err}
+		}
+	}
+	if sessionSettings.HasSetting(config.FileStoreMaxBodyBytes) {
+		maxBodyBytes, err := sessionSettings.IntSetting(config.FileStoreMaxBodyBytes)
+		if err != nil {
+			return nil, err
+		}
+		if maxBodyBytes < 0 {
+			return nil, quickfix.IncorrectFormatForSetting{Setting: config.FileStoreMaxBodyBytes, Value: []byte(strconv.Itoa(maxBodyBytes))}
+		}
+		opts.maxBodyBytes = int64(maxBodyBytes)
+	}
+	if sessionSettings.HasSetting(config.FileStoreEncryptionKey) {
+		raw, err := sessionSettings.Setting(config.FileStoreEncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+		if opts.encryptionKey, err = parseEncryptionKey(raw); err != nil {
+			return nil, quickfix.IncorrectFormatForSetting{Setting: config.FileStoreEncryptionKey, Value: []byte(raw), Err: err}
+		}
+	}
+	if sessionSettings.HasSetting(config.FileStoreMmap) {
+		if opts.mmap, err = sessionSettings.BoolSetting(config.FileStoreMmap); err != nil {
+			return nil, err
+		}
+	}
+	return newFileStore(sessionID, dirname, opts)
 }
 
-func newFileStore(sessionID quickfix.SessionID, dirname string, fileSync bool) (*fileStore, error) {
-	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
-		return nil, err
+// parseFilePerm reads FileStorePermissions as an octal permission mask.
+func parseFilePerm(sessionSettings *quickfix.SessionSettings) (os.FileMode, error) {
+	raw, err := sessionSettings.Setting(config.FileStorePermissions)
+	if err != nil {
+		return 0, err
+	}
+	perm, err := strconv.ParseUint(raw, 8, 32)
+	if err != nil {
+		return 0, quickfix.IncorrectFormatForSetting{Setting: config.FileStorePermissions, Value: []byte(raw), Err: err}
+	}
+	if perm > 0777 {
+		return 0, quickfix.IncorrectFormatForSetting{
+			Setting: config.FileStorePermissions,
+			Value:   []byte(raw),
+			Err:     fmt.Errorf("%#o is not a valid permission mask", perm),
+		}
 	}
+	return os.FileMode(perm), nil
+}
 
-	sessionPrefix := createFilenamePrefix(sessionID)
+// createCache creates the memory store caching the seqnums and creation time of the store, which also keeps
+// its monitoring counters.
+func (store *fileStore) createCache() error {
+	memStore, err := quickfix.NewMemoryStoreFactory().Create(store.sessionID)
+	if err != nil {
+		return errors.Wrap(err, "cache creation")
+	}
+	cacheStats, ok := memStore.(quickfix.MessageStoreStatsRecorder)
+	if !ok {
+		return errors.New("cache creation: memory store does not keep monitoring counters")
+	}
+	store.cache, store.cacheStats = memStore, cacheStats
+	return nil
+}
 
-	memStore, memErr := quickfix.NewMemoryStoreFactory().Create(sessionID)
-	if memErr != nil {
-		return nil, errors.Wrap(memErr, "cache creation")
+func newFileStore(sessionID quickfix.SessionID, dirname string, opts fileStoreOptions) (*fileStore, error) {
+	if err := os.MkdirAll(dirname, os.ModePerm); err != nil {
+		return nil, err
 	}
 
 	store := &fileStore{
-		sessionID:          sessionID,
-		cache:              memStore,
-		bodyFname:          path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "body")),
-		headerFname:        path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "header")),
-		sessionFname:       path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "session")),
-		senderSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "senderseqnums")),
-		targetSeqNumsFname: path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "targetseqnums")),
-		fileSync:           fileSync,
+		sessionID:      sessionID,
+		fileSync:       opts.fileSync,
+		filePerm:       opts.filePerm,
+		syncInterval:   opts.syncInterval,
+		compress:       opts.compress,
+		compressLevel:  opts.compressLevel,
+		rotateInterval: opts.rotateInterval,
+		maxBodyBytes:   opts.maxBodyBytes,
+		mmap:           opts.mmap,
+	}
+	if err := store.createCache(); err != nil {
+		return nil, err
+	}
+	store.setFilenames(dirname)
+	if opts.encryptionKey != nil {
+		var err error
+		if store.aead, err = newAEAD(opts.encryptionKey); err != nil {
+			return nil, errors.Wrap(err, "cipher creation")
+		}
 	}
 
 	if err := store.Refresh(); err != nil {
@@ -117,15 +274,38 @@ func newFileStore(sessionID quickfix.SessionID, dirname string, fileSync bool) (
 	return store, nil
 }
 
+// setFilenames sets the names of the session's files in dirname.
+func (store *fileStore) setFilenames(dirname string) {
+	sessionPrefix := createFilenamePrefix(store.sessionID)
+	store.bodyFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "body"))
+	store.headerFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "header"))
+	store.sessionFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "session"))
+	store.senderSeqNumsFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "senderseqnums"))
+	store.targetSeqNumsFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "targetseqnums"))
+	store.seqNumsJournalFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "seqjrn"))
+	store.stateFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "state"))
+	store.compactMarkerFname = path.Join(dirname, fmt.Sprintf("%s.%s", sessionPrefix, "compact"))
+}
+
 // Reset deletes the store files and sets the seqnums back to 1.
 func (store *fileStore) Reset() error {
 	if err := store.cache.Reset(); err != nil {
 		return errors.Wrap(err, "cache reset")
 	}
 
-	if err := store.Close(); err != nil {
+	if err := store.closeFiles(); err != nil {
 		return errors.Wrap(err, "close")
 	}
+	// An interrupted compaction must not be completed with the files being reset.
+	if err := removeFile(store.compactMarkerFname); err != nil {
+		return err
+	}
+	if err := removeFile(store.bodyFname + ".tmp"); err != nil {
+		return err
+	}
+	if err := removeFile(store.headerFname + ".tmp"); err != nil {
+		return err
+	}
 	if err := removeFile(store.bodyFname); err != nil {
 		return err
 	}
@@ -141,6 +321,15 @@ func (store *fileStore) Reset() error {
 	if err := removeFile(store.targetSeqNumsFname); err != nil {
 		return err
 	}
+	if err := removeFile(store.seqNumsJournalFname); err != nil {
+		return err
+	}
+	if err := removeFile(store.stateFname); err != nil {
+		return err
+	}
+	if err := store.removeArchives(); err != nil {
+		return err
+	}
 	return store.Refresh()
 }
 
@@ -151,48 +340,114 @@ func (store *fileStore) Refresh() (err error) {
 		return
 	}
 
-	if err = store.Close(); err != nil {
+	if err = store.closeFiles(); err != nil {
 		return err
 	}
 
+	if err = store.recoverCompaction(); err != nil {
+		return errors.Wrap(err, "recover compaction")
+	}
+
+	if err = store.recoverRotation(); err != nil {
+		return errors.Wrap(err, "recover rotation")
+	}
+
+	if err = store.migrateLegacyHeader(); err != nil {
+		return errors.Wrap(err, "migrate header")
+	}
+
 	creationTimePopulated, err := store.populateCache()
 	if err != nil {
 		return err
 	}
 
-	if store.bodyFile, err = openOrCreateFile(store.bodyFname, 0660); err != nil {
+	store.fileMu.Lock()
+	err = store.openFilesLocked()
+	if err == nil {
+		err = store.detectBodyFormatLocked()
+	}
+	if err == nil && store.rotateInterval != "" {
+		err = store.scheduleRotationLocked()
+	}
+	store.fileMu.Unlock()
+	if err != nil {
 		return err
 	}
-	if store.headerFile, err = openOrCreateFile(store.headerFname, 0660); err != nil {
+
+	if !creationTimePopulated {
+		if err := store.setSession(); err != nil {
+			return err
+		}
+	}
+
+	if err := store.setSeqNums(store.NextSenderMsgSeqNum(), store.NextTargetMsgSeqNum()); err != nil {
+		return errors.Wrap(err, "set seqnums")
+	}
+
+	if store.syncInterval > 0 && store.syncStop == nil {
+		store.syncStop = make(chan struct{})
+		store.syncDone = make(chan struct{})
+		go store.syncLoop(store.syncInterval, store.syncStop, store.syncDone)
+	}
+	return nil
+}
+
+func (store *fileStore) openFilesLocked() (err error) {
+	if store.bodyFile, err = openOrCreateFile(store.bodyFname, store.filePerm); err != nil {
 		return err
 	}
-	if store.sessionFile, err = openOrCreateFile(store.sessionFname, 0660); err != nil {
+	if store.headerFile, err = openOrCreateFile(store.headerFname, store.filePerm); err != nil {
 		return err
 	}
-	if store.senderSeqNumsFile, err = openOrCreateFile(store.senderSeqNumsFname, 0660); err != nil {
+	if store.sessionFile, err = openOrCreateFile(store.sessionFname, store.filePerm); err != nil {
 		return err
 	}
-	if store.targetSeqNumsFile, err = openOrCreateFile(store.targetSeqNumsFname, 0660); err != nil {
+	if store.senderSeqNumsFile, err = openOrCreateFile(store.senderSeqNumsFname, store.filePerm); err != nil {
 		return err
 	}
-
-	if !creationTimePopulated {
-		if err := store.setSession(); err != nil {
-			return err
-		}
+	if store.targetSeqNumsFile, err = openOrCreateFile(store.targetSeqNumsFname, store.filePerm); err != nil {
+		return err
 	}
+	return nil
+}
 
-	if err := store.SetNextSenderMsgSeqNum(store.NextSenderMsgSeqNum()); err != nil {
-		return errors.Wrap(err, "set next sender")
+// syncLoop periodically flushes the body and header files when FileStoreSyncIntervalMs is set,
+// in place of flushing them after every write. A failed flush is kept in syncErr.
+func (store *fileStore) syncLoop(interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			store.fileMu.Lock()
+			if store.bodyFile != nil && store.headerFile != nil {
+				if err := store.syncBodyAndHeaderFilesLocked(); err != nil {
+					store.syncErr = err
+				}
+			}
+			store.fileMu.Unlock()
+		}
 	}
+}
 
-	if err := store.SetNextTargetMsgSeqNum(store.NextTargetMsgSeqNum()); err != nil {
-		return errors.Wrap(err, "set next target")
+// takeSyncErrLocked returns and clears the error of the last periodic sync, if it failed.
+func (store *fileStore) takeSyncErrLocked() error {
+	err := store.syncErr
+	store.syncErr = nil
+	if err != nil {
+		return errors.Wrap(err, "periodic sync")
 	}
 	return nil
 }
 
 func (store *fileStore) populateCache() (creationTimePopulated bool, err error) {
+	if err := store.recoverSeqNumsJournal(); err != nil {
+		return false, errors.Wrap(err, "recover journal")
+	}
+
 	if timeBytes, err := os.ReadFile(store.sessionFname); err == nil {
 		var ctime time.Time
 		if err := ctime.UnmarshalText(timeBytes); err == nil {
@@ -243,23 +498,6 @@ func (store *fileStore) setSession() error {
 	return nil
 }
 
-func (store *fileStore) setSeqNum(f *os.File, seqNum int) error {
-	store.fileMu.Lock()
-	defer store.fileMu.Unlock()
-	if _, err := f.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("unable to rewind file: %s: %s", f.Name(), err.Error())
-	}
-	if _, err := fmt.Fprintf(f, "%019d", seqNum); err != nil {
-		return fmt.Errorf("unable to write to file: %s: %s", f.Name(), err.Error())
-	}
-	if store.fileSync {
-		if err := f.Sync(); err != nil {
-			return fmt.Errorf("unable to flush file: %s: %s", f.Name(), err.Error())
-		}
-	}
-	return nil
-}
-
 // NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent.
 func (store *fileStore) NextSenderMsgSeqNum() int {
 	return store.cache.NextSenderMsgSeqNum()
@@ -272,34 +510,26 @@ func (store *fileStore) NextTargetMsgSeqNum() int {
 
 // SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent.
 func (store *fileStore) SetNextSenderMsgSeqNum(next int) error {
-	if err := store.setSeqNum(store.senderSeqNumsFile, next); err != nil {
-		return errors.Wrap(err, "file")
-	}
-	return store.cache.SetNextSenderMsgSeqNum(next)
+	err := store.updateSeqNums(func(_, nextTarget int) (int, int) { return next, nextTarget })
+	return errors.Wrap(err, "file")
 }
 
 // SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received.
 func (store *fileStore) SetNextTargetMsgSeqNum(next int) error {
-	if err := store.setSeqNum(store.targetSeqNumsFile, next); err != nil {
-		return errors.Wrap(err, "file")
-	}
-	return store.cache.SetNextTargetMsgSeqNum(next)
+	err := store.updateSeqNums(func(nextSender, _ int) (int, int) { return nextSender, next })
+	return errors.Wrap(err, "file")
 }
 
 // IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
 func (store *fileStore) IncrNextSenderMsgSeqNum() error {
-	if err := store.SetNextSenderMsgSeqNum(store.cache.NextSenderMsgSeqNum() + 1); err != nil {
-		return errors.Wrap(err, "file")
-	}
-	return nil
+	err := store.updateSeqNums(func(nextSender, nextTarget int) (int, int) { return nextSender + 1, nextTarget })
+	return errors.Wrap(err, "file")
 }
 
 // IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
 func (store *fileStore) IncrNextTargetMsgSeqNum() error {
-	if err := store.SetNextTargetMsgSeqNum(store.cache.NextTargetMsgSeqNum() + 1); err != nil {
-		return errors.Wrap(err, "file")
-	}
-	return nil
+	err := store.updateSeqNums(func(nextSender, nextTarget int) (int, int) { return nextSender, nextTarget + 1 })
+	return errors.Wrap(err, "file")
 }
 
 // CreationTime returns the creation time of the store.
@@ -314,23 +544,87 @@ func (store *fileStore) SetCreationTime(_ time.Time) {
 func (store *fileStore) SaveMessage(seqNum int, msg []byte) error {
 	store.fileMu.Lock()
 	defer store.fileMu.Unlock()
-	offset, err := store.bodyFile.Seek(0, io.SeekEnd)
-	if err != nil {
+	if err := store.takeSyncErrLocked(); err != nil {
+		return err
+	}
+	if err := store.writeMessageLocked(seqNum, msg); err != nil {
+		return err
+	}
+	if store.fileSync && store.syncInterval == 0 {
+		return store.syncBodyAndHeaderFilesLocked()
+	}
+	return nil
+}
+
+// SaveMessages saves msgs in seqnum order, syncing the files once for the whole batch.
+func (store *fileStore) SaveMessages(msgs map[int][]byte) error {
+	seqNums := make([]int, 0, len(msgs))
+	for seqNum := range msgs {
+		seqNums = append(seqNums, seqNum)
+	}
+	sort.Ints(seqNums)
+
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+	if err := store.takeSyncErrLocked(); err != nil {
+		return err
+	}
+	for _, seqNum := range seqNums {
+		if err := store.writeMessageLocked(seqNum, msgs[seqNum]); err != nil {
+			return err
+		}
+	}
+	if store.fileSync && store.syncInterval == 0 {
+		return store.syncBodyAndHeaderFilesLocked()
+	}
+	return nil
+}
+
+func (store *fileStore) writeMessageLocked(seqNum int, msg []byte) (err error) {
+	def := msgDef{seqNum: seqNum, size: len(msg)}
+	stored := msg
+	if store.bodyCompressed && len(msg) > 0 {
+		if stored, err = store.compressMsgLocked(msg); err != nil {
+			return fmt.Errorf("unable to compress message: %d: %s", seqNum, err.Error())
+		}
+		def.size, def.rawSize = len(stored), len(msg)
+	}
+	if store.bodyEncrypted {
+		if stored, err = store.sealMsg(seqNum, stored); err != nil {
+			return fmt.Errorf("unable to encrypt message: %d: %s", seqNum, err.Error())
+		}
+		def.size = len(stored)
+	}
+
+	if def.offset, err = store.bodyFile.Seek(0, io.SeekEnd); err != nil {
 		return fmt.Errorf("unable to seek to end of file: %s: %s", store.bodyFname, err.Error())
 	}
-	if _, err := store.headerFile.Seek(0, io.SeekEnd); err != nil {
+	if store.shouldRotateLocked(def.offset, len(stored)) {
+		if err := store.rotateLocked(); err != nil {
+			return errors.Wrap(err, "rotate")
+		}
+		if def.offset, err = store.bodyFile.Seek(0, io.SeekEnd); err != nil {
+			return fmt.Errorf("unable to seek to end of file: %s: %s", store.bodyFname, err.Error())
+		}
+	}
+	headerLen, err := store.headerFile.Seek(0, io.SeekEnd)
+	if err != nil {
 		return fmt.Errorf("unable to seek to end of file: %s: %s", store.headerFname, err.Error())
 	}
-	if _, err := fmt.Fprintf(store.headerFile, "%d,%d,%d\n", seqNum, offset, len(msg)); err != nil {
+	if partial := headerLen % headerRecordSize; partial != 0 {
+		// Overwrite the partial record left behind by an interrupted write.
+		if _, err := store.headerFile.Seek(headerLen-partial, io.SeekStart); err != nil {
+			return fmt.Errorf("unable to seek in file: %s: %s", store.headerFname, err.Error())
+		}
+	}
+	if err := writeMsgDef(store.headerFile, def); err != nil {
 		return fmt.Errorf("unable to write to file: %s: %s", store.headerFname, err.Error())
 	}
 
-	if _, err := store.bodyFile.Write(msg); err != nil {
+	if _, err := store.bodyFile.Write(stored); err != nil {
 		return fmt.Errorf("unable to write to file: %s: %s", store.bodyFname, err.Error())
 	}
-	if store.fileSync {
-		return store.syncBodyAndHeaderFilesLocked()
-	}
+	store.cacheStats.RecordSaved(len(msg))
 	return nil
 }
 
@@ -351,68 +645,309 @@ func (store *fileStore) syncBodyAndHeaderFilesLocked() error {
 	return nil
 }
 
+// IterateMessages passes the messages with seqnums in [beginSeqNum, endSeqNum] to cb. Messages saved
+// before the current files were archived by FileStoreRotateInterval or FileStoreMaxBodyBytes are read
+// back from the archived files.
 func (store *fileStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	return store.iterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error { return cb(msg) })
+}
+
+// iterateMessages behaves like IterateMessages, also passing cb the seqnum of each message.
+func (store *fileStore) iterateMessages(beginSeqNum, endSeqNum int, cb func(seqNum int, msg []byte) error) error {
 	// Sync files
 	store.fileMu.Lock()
 	err := store.syncBodyAndHeaderFilesLocked()
+	var suffixes []string
+	if err == nil {
+		suffixes, err = store.archivesBeforeLocked(beginSeqNum)
+	}
 	store.fileMu.Unlock()
 	if err != nil {
 		return err
 	}
 
-	// Open a read only view to body and header file
-	bodyFile, err := openOrCreateFile(store.bodyFname, 0440)
+	for _, suffix := range append(suffixes, "") {
+		err := store.iterateFiles(store.bodyFname+suffix, store.headerFname+suffix, beginSeqNum, endSeqNum, cb)
+		if err != nil {
+			if errors.Is(err, quickfix.ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// archivesBeforeLocked returns the suffixes of the archived files to read ahead of the current files
+// for messages from beginSeqNum on. None are needed if the current files start at or before beginSeqNum.
+func (store *fileStore) archivesBeforeLocked(beginSeqNum int) ([]string, error) {
+	n, err := headerRecordCount(store.headerFile)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if n > 0 {
+		def, err := readMsgDefAt(store.headerFile, 0)
+		if err != nil || def.seqNum <= beginSeqNum {
+			return nil, err
+		}
+	}
+	return store.archivesFrom(beginSeqNum)
+}
+
+// iterateFiles passes the messages in the given body and header files with seqnums in [beginSeqNum, endSeqNum]
+// to cb, with their seqnums. Missing files hold no messages. Errors returned by cb, including ErrStopIteration, are
+// returned as-is.
+func (store *fileStore) iterateFiles(bodyFname, headerFname string, beginSeqNum, endSeqNum int, cb func(seqNum int, msg []byte) error) error {
+	// Open a read only view to body and header file
+	bodyFile, err := os.Open(bodyFname)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to open file: %s: %s", bodyFname, err.Error())
 	}
 	defer func() { _ = bodyFile.Close() }()
-	headerFile, err := openOrCreateFile(store.headerFname, 0440)
+	headerFile, err := os.Open(headerFname)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to open file: %s: %s", headerFname, err.Error())
+	}
+	defer func() { _ = headerFile.Close() }()
+
+	// Archived body files may have been written with different settings than the current one.
+	magic, err := readBodyMagic(bodyFile)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = headerFile.Close() }()
-	if _, err = headerFile.Seek(0, io.SeekStart); err != nil {
-		return fmt.Errorf("unable to seek to start of file: %s: %s", store.headerFname, err.Error())
+	encrypted := bytes.Equal(magic, encryptedBodyMagic)
+
+	// The mapping covers the body file as of now. Messages saved after it was made are read with ReadAt.
+	var bodyData []byte
+	if store.mmap {
+		if bodyData, err = mmapFile(bodyFile); err != nil {
+			return err
+		}
+		defer func() { _ = munmapFile(bodyData) }()
 	}
 
-	// Iterate over the header file
+	// Seek to the first record at or after beginSeqNum, then read forward until endSeqNum is passed.
+	n, err := headerRecordCount(headerFile)
+	if err != nil {
+		return err
+	}
+	first, err := searchMsgDefs(headerFile, n, beginSeqNum)
+	if err != nil {
+		return err
+	}
+	hr := newHeaderReader(headerFile, int64(first)*headerRecordSize, int64(n)*headerRecordSize)
 	for {
-		var seqNum, size int
-		var offset int64
-		if cnt, err := fmt.Fscanf(headerFile, "%d,%d,%d\n", &seqNum, &offset, &size); err != nil {
+		def, err := hr.next()
+		if err != nil {
 			if errors.Is(err, io.EOF) {
 				break
 			}
-			return fmt.Errorf("unable to read from file: %s: %s", store.headerFname, err.Error())
-		} else if cnt < 3 || seqNum > endSeqNum {
+			return err
+		} else if def.seqNum > endSeqNum {
 			// If we have reached the end of possible iteration then break
 			break
-		} else if seqNum < beginSeqNum {
-			// If we have not yet reached the starting sequence number then continue
-			continue
-		}
-		// Otherwise process the file
-		msg := make([]byte, size)
-		if _, err := bodyFile.ReadAt(msg, offset); err != nil {
-			return fmt.Errorf("unable to read from file: %s: %s", store.bodyFname, err.Error())
-		} else if err = cb(msg); err != nil {
+		}
+		msg := make([]byte, def.size)
+		if end := def.offset + int64(def.size); end <= int64(len(bodyData)) {
+			copy(msg, bodyData[def.offset:end])
+		} else if _, err := bodyFile.ReadAt(msg, def.offset); err != nil {
+			return fmt.Errorf("unable to read from file: %s: %s", bodyFname, err.Error())
+		}
+		if msg, err = store.decodeMsg(msg, def, encrypted); err != nil {
+			return fmt.Errorf("unable to read from file: %s: %s", bodyFname, err.Error())
+		}
+		store.cacheStats.RecordRetrieved(len(msg))
+		if err = cb(def.seqNum, msg); err != nil {
 			return err
 		}
 	}
 	return nil
 }
 
+// GetMessages returns the messages with seqnums in [beginSeqNum, endSeqNum] in seqnum order. If a message was
+// saved more than once, the latest copy is returned, as with GetMessage.
 func (store *fileStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
-	var msgs [][]byte
-	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
-		msgs = append(msgs, msg)
+	return latestMessages(func(cb func(int, []byte) error) error {
+		return store.iterateMessages(beginSeqNum, endSeqNum, cb)
+	})
+}
+
+// latestMessages returns the messages passed by iterate in seqnum order, keeping the last one passed for each seqnum.
+func latestMessages(iterate func(cb func(seqNum int, msg []byte) error) error) ([][]byte, error) {
+	latest := make(map[int][]byte)
+	err := iterate(func(seqNum int, msg []byte) error {
+		latest[seqNum] = msg
 		return nil
 	})
-	return msgs, err
+	if err != nil || len(latest) == 0 {
+		return nil, err
+	}
+
+	seqNums := make([]int, 0, len(latest))
+	for seqNum := range latest {
+		seqNums = append(seqNums, seqNum)
+	}
+	sort.Ints(seqNums)
+	msgs := make([][]byte, len(seqNums))
+	for i, seqNum := range seqNums {
+		msgs[i] = latest[seqNum]
+	}
+	return msgs, nil
 }
 
-// Close closes the store's files.
+// GetMessage looks up the message saved under seqNum with a binary search of the header file. If the
+// message was saved more than once, the latest copy is returned.
+func (store *fileStore) GetMessage(seqNum int) ([]byte, bool, error) {
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	n, err := headerRecordCount(store.headerFile)
+	if err != nil {
+		return nil, false, err
+	}
+	i, err := searchMsgDefs(store.headerFile, n, seqNum+1)
+	if err != nil {
+		return nil, false, err
+	} else if i == 0 {
+		return store.getArchivedMessageLocked(seqNum)
+	}
+	def, err := readMsgDefAt(store.headerFile, i-1)
+	if err != nil || def.seqNum != seqNum {
+		return nil, false, err
+	}
+
+	msg := make([]byte, def.size)
+	if _, err := store.bodyFile.ReadAt(msg, def.offset); err != nil {
+		return nil, false, fmt.Errorf("unable to read from file: %s: %s", store.bodyFname, err.Error())
+	}
+	if msg, err = store.decodeMsg(msg, def, store.bodyEncrypted); err != nil {
+		return nil, false, fmt.Errorf("unable to read from file: %s: %s", store.bodyFname, err.Error())
+	}
+	store.cacheStats.RecordRetrieved(len(msg))
+	return msg, true, nil
+}
+
+// getArchivedMessageLocked looks up a message saved under seqNum before the current files were archived.
+// The latest copy held by the archives is returned.
+func (store *fileStore) getArchivedMessageLocked(seqNum int) ([]byte, bool, error) {
+	suffixes, err := store.archivesFrom(seqNum)
+	if err != nil {
+		return nil, false, err
+	}
+	var msg []byte
+	var found bool
+	for _, suffix := range suffixes {
+		err := store.iterateFiles(store.bodyFname+suffix, store.headerFname+suffix, seqNum, seqNum, func(_ int, m []byte) error {
+			msg, found = m, true
+			return nil
+		})
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return msg, found, nil
+}
+
+// HealthCheck verifies that the body, header, session and seqnum files are open for writing and still
+// present under their names.
+func (store *fileStore) HealthCheck() error {
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	for _, f := range []struct {
+		fname string
+		file  *os.File
+	}{
+		{store.bodyFname, store.bodyFile},
+		{store.headerFname, store.headerFile},
+		{store.sessionFname, store.sessionFile},
+		{store.senderSeqNumsFname, store.senderSeqNumsFile},
+		{store.targetSeqNumsFname, store.targetSeqNumsFile},
+	} {
+		if f.file == nil {
+			return fmt.Errorf("file not open: %s", f.fname)
+		}
+		info, err := f.file.Stat()
+		if err != nil {
+			return fmt.Errorf("unable to stat file: %s: %s", f.fname, err.Error())
+		}
+		if current, err := os.Stat(f.fname); err != nil || !os.SameFile(info, current) {
+			return fmt.Errorf("file removed or replaced: %s", f.fname)
+		}
+		// An empty write fails on a file that is not open for writing.
+		if _, err := f.file.Write(nil); err != nil {
+			return fmt.Errorf("unable to write to file: %s: %s", f.fname, err.Error())
+		}
+	}
+	return nil
+}
+
+// SavedMessageCount returns the number of messages saved to the store.
+func (store *fileStore) SavedMessageCount() int64 {
+	return store.cacheStats.SavedMessageCount()
+}
+
+// RetrievedMessageCount returns the number of messages read back from the store.
+func (store *fileStore) RetrievedMessageCount() int64 {
+	return store.cacheStats.RetrievedMessageCount()
+}
+
+// BytesWritten returns the number of message bytes saved to the store.
+func (store *fileStore) BytesWritten() int64 {
+	return store.cacheStats.BytesWritten()
+}
+
+// BytesRead returns the number of message bytes read back from the store.
+func (store *fileStore) BytesRead() int64 {
+	return store.cacheStats.BytesRead()
+}
+
+// BodyFileSize returns the size in bytes of the body file.
+func (store *fileStore) BodyFileSize() (int64, error) {
+	return fileSize(store.bodyFname)
+}
+
+// HeaderFileSize returns the size in bytes of the header file.
+func (store *fileStore) HeaderFileSize() (int64, error) {
+	return fileSize(store.headerFname)
+}
+
+func fileSize(fname string) (int64, error) {
+	info, err := os.Stat(fname)
+	if err != nil {
+		return 0, fmt.Errorf("unable to stat file: %s: %s", fname, err.Error())
+	}
+	return info.Size(), nil
+}
+
+// Close stops the background sync, if any, and closes the store's files.
 func (store *fileStore) Close() error {
+	if store.syncStop != nil {
+		close(store.syncStop)
+		<-store.syncDone
+		store.syncStop = nil
+		store.syncDone = nil
+	}
+	err := store.closeFiles()
+
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+	if syncErr := store.takeSyncErrLocked(); err == nil {
+		err = syncErr
+	}
+	return err
+}
+
+// closeFiles closes the store's files.
+func (store *fileStore) closeFiles() error {
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	store.generation++
 	if err := closeSyncFile(store.bodyFile); err != nil {
 		return err
 	}
@@ -16,11 +16,14 @@
 package file
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -77,3 +80,919 @@ func TestStringParse(t *testing.T) {
 	assert.Nil(err)
 	assert.Equal(6, i)
 }
+
+func (suite *FileStoreTestSuite) TestCompact() {
+	store := suite.MsgStore.(*fileStore)
+	suite.Require().Nil(store.SaveMessage(1, []byte("msg1")))
+	suite.Require().Nil(store.SaveMessage(2, []byte("stale2")))
+	suite.Require().Nil(store.SaveMessage(3, []byte("msg3")))
+	suite.Require().Nil(store.SaveMessage(2, []byte("msg2")))
+
+	before, err := os.Stat(store.bodyFname)
+	suite.Require().Nil(err)
+
+	// Compacting mid-iteration must not disturb the iteration in progress.
+	var iterated [][]byte
+	suite.Require().Nil(store.IterateMessages(1, 3, func(msg []byte) error {
+		if len(iterated) == 0 {
+			suite.Require().Nil(store.Compact())
+		}
+		iterated = append(iterated, msg)
+		return nil
+	}))
+	suite.Equal([][]byte{[]byte("msg1"), []byte("stale2"), []byte("msg3"), []byte("msg2")}, iterated)
+
+	after, err := os.Stat(store.bodyFname)
+	suite.Require().Nil(err)
+	suite.Less(after.Size(), before.Size())
+
+	msgs, err := store.GetMessages(1, 3)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3")}, msgs)
+
+	// The store keeps appending to the compacted files.
+	suite.Require().Nil(store.SaveMessage(4, []byte("msg4")))
+	msgs, err = store.GetMessages(1, 4)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3"), []byte("msg4")}, msgs)
+
+	_, err = os.Stat(store.bodyFname + ".tmp")
+	suite.True(os.IsNotExist(err))
+}
+
+func (suite *FileStoreTestSuite) TestCompactFrom() {
+	store := suite.MsgStore.(*fileStore)
+	for seqNum := 1; seqNum <= 4; seqNum++ {
+		suite.Require().Nil(store.SaveMessage(seqNum, []byte(fmt.Sprintf("msg%d", seqNum))))
+	}
+	suite.Require().Nil(store.SaveMessage(1, []byte("again1")))
+
+	suite.Require().Nil(store.CompactFrom(3))
+
+	msgs, err := store.GetMessages(1, 4)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("msg3"), []byte("msg4")}, msgs)
+	_, found, err := store.GetMessage(1)
+	suite.Require().Nil(err)
+	suite.False(found)
+}
+
+func TestFileStoreCompactRecovery(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	dirname := t.TempDir()
+	opts := fileStoreOptions{filePerm: defaultFilePerm}
+
+	store, err := newFileStore(sessionID, dirname, opts)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, []byte("msg1")))
+	require.Nil(t, store.SaveMessage(2, []byte("stale2")))
+	require.Nil(t, store.SaveMessage(3, []byte("msg3")))
+	require.Nil(t, store.SaveMessage(2, []byte("msg2")))
+	require.Nil(t, store.Close())
+	oldBody, err := os.ReadFile(store.bodyFname)
+	require.Nil(t, err)
+	oldHeader, err := os.ReadFile(store.headerFname)
+	require.Nil(t, err)
+
+	store, err = newFileStore(sessionID, dirname, opts)
+	require.Nil(t, err)
+	require.Nil(t, store.Compact())
+	require.Nil(t, store.Close())
+	newBody, err := os.ReadFile(store.bodyFname)
+	require.Nil(t, err)
+	newHeader, err := os.ReadFile(store.headerFname)
+	require.Nil(t, err)
+
+	expected := [][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3")}
+	write := func(fname string, data []byte) { require.Nil(t, os.WriteFile(fname, data, 0660)) }
+
+	// A crash between the two renames leaves the new body with the old header. The marker completes the swap.
+	write(store.bodyFname, newBody)
+	write(store.headerFname, oldHeader)
+	write(store.headerFname+".tmp", newHeader)
+	write(store.compactMarkerFname, nil)
+	store, err = newFileStore(sessionID, dirname, opts)
+	require.Nil(t, err)
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert2.Equal(t, expected, msgs)
+	require.Nil(t, store.Close())
+	for _, fname := range []string{store.compactMarkerFname, store.bodyFname + ".tmp", store.headerFname + ".tmp"} {
+		_, err = os.Stat(fname)
+		assert2.True(t, os.IsNotExist(err), fname)
+	}
+
+	// Without the marker the .tmp files may be incomplete, and the live files are kept.
+	write(store.bodyFname, oldBody)
+	write(store.headerFname, oldHeader)
+	write(store.bodyFname+".tmp", newBody[:len(newBody)/2])
+	write(store.headerFname+".tmp", newHeader)
+	store, err = newFileStore(sessionID, dirname, opts)
+	require.Nil(t, err)
+	defer store.Close()
+	msg, found, err := store.GetMessage(1)
+	require.Nil(t, err)
+	assert2.True(t, found)
+	assert2.Equal(t, []byte("msg1"), msg)
+	_, err = os.Stat(store.bodyFname + ".tmp")
+	assert2.True(t, os.IsNotExist(err))
+}
+
+func TestFileStoreCompactDuringRotation(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	store, err := newFileStore(sessionID, t.TempDir(), fileStoreOptions{filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	defer store.Close()
+	require.Nil(t, store.SaveMessage(1, []byte("msg1")))
+	require.Nil(t, store.SaveMessage(2, []byte("msg2")))
+
+	// Rotate the live files, and save to the new ones, while the first attempt compacts its snapshot.
+	var snapshots int
+	store.compactSnapshotTaken = func() {
+		snapshots++
+		if snapshots > 1 {
+			return
+		}
+		store.fileMu.Lock()
+		require.Nil(t, store.rotateLocked())
+		store.fileMu.Unlock()
+		require.Nil(t, store.SaveMessage(3, []byte("stale3")))
+		require.Nil(t, store.SaveMessage(3, []byte("msg3")))
+	}
+	require.Nil(t, store.Compact())
+	assert2.Equal(t, 2, snapshots, "compaction starts over on the rotated files")
+
+	n, err := headerRecordCount(store.headerFile)
+	require.Nil(t, err)
+	assert2.Equal(t, 1, n)
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{[]byte("msg1"), []byte("msg2"), []byte("msg3")}, msgs)
+}
+
+func TestFileStorePermissions(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	newSettings := func(perm string) *quickfix.Settings {
+		settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStorePermissions=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, t.TempDir(), perm, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+		require.Nil(t, err)
+		return settings
+	}
+
+	store, err := NewStoreFactory(newSettings("0600")).Create(sessionID)
+	require.Nil(t, err)
+	defer store.Close()
+
+	fs := store.(*fileStore)
+	for _, fname := range []string{fs.bodyFname, fs.headerFname, fs.sessionFname, fs.senderSeqNumsFname, fs.targetSeqNumsFname} {
+		info, err := os.Stat(fname)
+		require.Nil(t, err)
+		assert2.Equal(t, os.FileMode(0600), info.Mode().Perm(), fname)
+	}
+
+	for _, perm := range []string{"1777", "0800", "rw-r-----"} {
+		_, err = NewStoreFactory(newSettings(perm)).Create(sessionID)
+		assert2.NotNil(t, err, perm)
+	}
+}
+
+func (suite *FileStoreTestSuite) TestSeqNumsJournalRecovery() {
+	store := suite.MsgStore.(*fileStore)
+	suite.Require().Nil(store.SetNextSenderMsgSeqNum(10))
+	suite.Require().Nil(store.SetNextTargetMsgSeqNum(20))
+	_, err := os.Stat(store.seqNumsJournalFname)
+	suite.True(os.IsNotExist(err), "journal is removed once the seqnum files are updated")
+
+	// Simulate a crash after the journal was published but before the seqnum files were updated,
+	// and another one while a later journal was still being written.
+	suite.Require().Nil(os.WriteFile(store.seqNumsJournalFname, []byte(fmt.Sprintf(journalFormat, 11, 21)), 0660))
+	suite.Require().Nil(os.WriteFile(store.seqNumsJournalFname+".tmp", []byte("0000000000000000012,"), 0660))
+
+	suite.Require().Nil(store.Refresh())
+	suite.Equal(11, store.NextSenderMsgSeqNum())
+	suite.Equal(21, store.NextTargetMsgSeqNum())
+
+	for _, fname := range []string{store.seqNumsJournalFname, store.seqNumsJournalFname + ".tmp"} {
+		_, err = os.Stat(fname)
+		suite.True(os.IsNotExist(err), fname)
+	}
+	senderSeqNums, err := os.ReadFile(store.senderSeqNumsFname)
+	suite.Require().Nil(err)
+	suite.Equal("0000000000000000011", string(senderSeqNums))
+}
+
+func (suite *FileStoreTestSuite) TestIncrSeqNumsConcurrently() {
+	const n = 50
+	var wg sync.WaitGroup
+	for _, incr := range []func() error{suite.MsgStore.IncrNextSenderMsgSeqNum, suite.MsgStore.IncrNextTargetMsgSeqNum} {
+		wg.Add(1)
+		go func(incr func() error) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				suite.Nil(incr())
+			}
+		}(incr)
+	}
+	wg.Wait()
+
+	suite.Equal(n+1, suite.MsgStore.NextSenderMsgSeqNum())
+	suite.Equal(n+1, suite.MsgStore.NextTargetMsgSeqNum())
+	suite.Require().Nil(suite.MsgStore.Refresh())
+	suite.Equal(n+1, suite.MsgStore.NextSenderMsgSeqNum())
+	suite.Equal(n+1, suite.MsgStore.NextTargetMsgSeqNum())
+}
+
+func (suite *FileStoreTestSuite) TestSessionState() {
+	store := suite.MsgStore.(*fileStore)
+	_, found, err := store.LoadSessionState()
+	suite.Require().Nil(err)
+	suite.False(found)
+
+	state := quickfix.PersistedSessionState{
+		State:               "Resend",
+		NextTargetMsgSeqNum: 5,
+		ResendRangeEnd:      9,
+		StashedMessages:     map[int][]byte{10: buildFIXMessage(10)},
+	}
+	suite.Require().Nil(store.SaveSessionState(state))
+
+	// The state survives a restart, and is discarded with the seqnums.
+	suite.Require().Nil(store.Close())
+	suite.Require().Nil(store.Refresh())
+	loaded, found, err := store.LoadSessionState()
+	suite.Require().Nil(err)
+	suite.True(found)
+	suite.Equal(state, loaded)
+
+	suite.Require().Nil(store.Reset())
+	_, found, err = store.LoadSessionState()
+	suite.Require().Nil(err)
+	suite.False(found)
+}
+
+func TestExpandPathTemplate(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.2", SenderCompID: "SENDER", TargetCompID: "TARGET", Qualifier: "Q1"}
+	var cases = []struct {
+		dirname  string
+		expected string
+	}{
+		{"/data/fix", "/data/fix"},
+		{"/data/fix/${BeginString}/${SenderCompID}-${TargetCompID}", "/data/fix/FIX.4.2/SENDER-TARGET"},
+		{"/data/fix/${Qualifier}/${TargetSubID}", "/data/fix/Q1/"},
+		{"/data/${Unknown}/${SenderCompID}", "/data/${Unknown}/SENDER"},
+		{"/data/$SenderCompID", "/data/$SenderCompID"},
+	}
+
+	for _, tc := range cases {
+		assert2.Equal(t, tc.expected, expandPathTemplate(tc.dirname, sessionID))
+	}
+}
+
+func TestFileStoreSyncInterval(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStoreSyncIntervalMs=5
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, t.TempDir(), sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(t, err)
+
+	store, err := NewStoreFactory(settings).Create(sessionID)
+	require.Nil(t, err)
+	fs := store.(*fileStore)
+	require.NotNil(t, fs.syncStop)
+	syncStop := fs.syncStop
+
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(1, []byte("hello")))
+	time.Sleep(20 * time.Millisecond)
+	require.Nil(t, store.Refresh())
+	assert2.Equal(t, syncStop, fs.syncStop, "refresh keeps the running sync goroutine")
+
+	msgs, err := store.GetMessages(1, 1)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{[]byte("hello")}, msgs)
+
+	require.Nil(t, store.Close())
+	assert2.Nil(t, fs.syncStop)
+	require.Nil(t, store.Close())
+}
+
+func TestFileStoreSyncIntervalError(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	store, err := newFileStore(sessionID, t.TempDir(), fileStoreOptions{filePerm: defaultFilePerm, syncInterval: 5 * time.Millisecond})
+	require.Nil(t, err)
+	defer store.Close()
+
+	// Make the periodic sync fail by swapping in a closed body file.
+	closed, err := os.CreateTemp(t.TempDir(), "body")
+	require.Nil(t, err)
+	require.Nil(t, closed.Close())
+	store.fileMu.Lock()
+	bodyFile := store.bodyFile
+	store.bodyFile = closed
+	store.fileMu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	store.fileMu.Lock()
+	store.bodyFile = bodyFile
+	store.fileMu.Unlock()
+
+	err = store.SaveMessage(1, []byte("hello"))
+	require.NotNil(t, err)
+	assert2.Contains(t, err.Error(), "periodic sync")
+	require.Nil(t, store.SaveMessage(1, []byte("hello")), "the error is reported once")
+}
+
+func (suite *FileStoreTestSuite) TestStoreStats() {
+	stats, ok := quickfix.StoreStats(suite.MsgStore)
+	suite.Require().True(ok)
+
+	suite.Require().Nil(suite.MsgStore.SaveMessage(1, []byte("hello")))
+	suite.Require().Nil(quickfix.SaveMessages(suite.MsgStore, map[int][]byte{2: []byte("cruel"), 3: []byte("world!")}))
+	_, err := suite.MsgStore.GetMessages(2, 3)
+	suite.Require().Nil(err)
+
+	suite.Equal(int64(3), stats.SavedMessageCount())
+	suite.Equal(int64(16), stats.BytesWritten())
+	suite.Equal(int64(2), stats.RetrievedMessageCount())
+	suite.Equal(int64(11), stats.BytesRead())
+
+	// The counters are kept in the memory store caching the sequence numbers.
+	cacheStats, ok := suite.MsgStore.(*fileStore).cache.(quickfix.MessageStoreStatsRecorder)
+	suite.Require().True(ok)
+	suite.Equal(int64(3), cacheStats.SavedCount())
+	suite.Equal(int64(2), cacheStats.RetrievedCount())
+	suite.Equal(int64(16), cacheStats.BytesWritten())
+
+	_, ok = quickfix.StoreStats(nil)
+	suite.False(ok)
+}
+
+func (suite *FileStoreTestSuite) TestStoreFileStats() {
+	stats, ok := quickfix.StoreFileStats(suite.MsgStore)
+	suite.Require().True(ok)
+	bodySize, err := stats.BodyFileSize()
+	suite.Require().Nil(err)
+	headerSize, err := stats.HeaderFileSize()
+	suite.Require().Nil(err)
+
+	suite.Require().Nil(suite.MsgStore.SaveMessage(1, []byte("hello")))
+	newBodySize, err := stats.BodyFileSize()
+	suite.Require().Nil(err)
+	suite.Equal(bodySize+5, newBodySize)
+	newHeaderSize, err := stats.HeaderFileSize()
+	suite.Require().Nil(err)
+	suite.Greater(newHeaderSize, headerSize)
+
+	_, ok = quickfix.StoreFileStats(nil)
+	suite.False(ok)
+}
+
+func (suite *FileStoreTestSuite) TestHealthCheck() {
+	store := suite.MsgStore.(*fileStore)
+	suite.Require().Nil(store.HealthCheck())
+
+	suite.Require().Nil(os.Remove(store.senderSeqNumsFname))
+	suite.NotNil(store.HealthCheck())
+
+	suite.Require().Nil(store.Refresh())
+	suite.Require().Nil(store.HealthCheck())
+
+	suite.Require().Nil(store.Close())
+	suite.NotNil(store.HealthCheck())
+}
+
+func (suite *FileStoreTestSuite) TestGetMessageReturnsLatestCopy() {
+	suite.Require().Nil(suite.MsgStore.SaveMessage(1, []byte("hello")))
+	suite.Require().Nil(suite.MsgStore.SaveMessage(2, []byte("cruel")))
+	suite.Require().Nil(suite.MsgStore.SaveMessage(2, []byte("world")))
+
+	msg, found, err := suite.MsgStore.(quickfix.SingleMessageGetter).GetMessage(2)
+	suite.Require().Nil(err)
+	suite.True(found)
+	suite.Equal([]byte("world"), msg)
+}
+
+func (suite *FileStoreTestSuite) TestGetMessagesReturnsLatestCopies() {
+	store := suite.MsgStore.(*fileStore)
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		suite.Require().Nil(store.SaveMessage(seqNum, []byte(fmt.Sprintf("m%d", seqNum))))
+	}
+	suite.Require().Nil(store.SaveMessage(2, []byte("m2b")))
+	suite.Require().Nil(store.SaveMessage(3, []byte("m3b")))
+
+	want := [][]byte{[]byte("m1"), []byte("m2b"), []byte("m3b")}
+	msgs, err := store.GetMessages(1, 3)
+	suite.Require().Nil(err)
+	suite.Equal(want, msgs)
+	msg, found, err := store.GetMessage(3)
+	suite.Require().Nil(err)
+	suite.True(found)
+	suite.Equal(want[2], msg)
+
+	ro, err := OpenReadOnlyStore(path.Dir(store.bodyFname), store.sessionID)
+	suite.Require().Nil(err)
+	msgs, err = ro.GetMessages(1, 3)
+	suite.Require().Nil(err)
+	suite.Equal(want, msgs)
+}
+
+func (suite *FileStoreTestSuite) TestLegacyHeaderMigration() {
+	store := suite.MsgStore.(*fileStore)
+	suite.Require().Nil(store.closeFiles())
+
+	// Write a body and a text header as older versions of the store did, including a partial last line.
+	suite.Require().Nil(os.WriteFile(store.bodyFname, []byte("firstsecondthird"), 0660))
+	suite.Require().Nil(os.WriteFile(store.headerFname, []byte("1,0,5\n2,5,6\n3,11,5\n4,1"), 0660))
+
+	suite.Require().Nil(store.Refresh())
+	legacy, err := isLegacyHeader(store.headerFname)
+	suite.Require().Nil(err)
+	suite.False(legacy)
+
+	info, err := os.Stat(store.headerFname)
+	suite.Require().Nil(err)
+	suite.Equal(int64(3*headerRecordSize), info.Size())
+
+	msgs, err := store.GetMessages(2, 10)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("second"), []byte("third")}, msgs)
+
+	suite.Require().Nil(store.SaveMessage(4, []byte("fourth")))
+	msgs, err = store.GetMessages(1, 4)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("first"), []byte("second"), []byte("third"), []byte("fourth")}, msgs)
+}
+
+func (suite *FileStoreTestSuite) TestPartialHeaderRecordIsOverwritten() {
+	store := suite.MsgStore.(*fileStore)
+	suite.Require().Nil(store.SaveMessage(1, []byte("first")))
+
+	// Simulate a crash part way through writing a header record.
+	_, err := store.headerFile.Write([]byte{2, 0, 0})
+	suite.Require().Nil(err)
+
+	suite.Require().Nil(store.SaveMessage(2, []byte("second")))
+	msgs, err := store.GetMessages(1, 2)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("first"), []byte("second")}, msgs)
+}
+
+// CompressedFileStoreTestSuite runs all tests in the MessageStoreTestSuite against a FileStore compressing its message bodies.
+type CompressedFileStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *CompressedFileStoreTestSuite) SetupTest() {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStoreCompress=Y
+FileStoreCompressLevel=9
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.T().TempDir(), sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *CompressedFileStoreTestSuite) TearDownTest() {
+	suite.MsgStore.Close()
+}
+
+func TestCompressedFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(CompressedFileStoreTestSuite))
+}
+
+func TestFileStoreCompress(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	dirname := t.TempDir()
+	msg := []byte(strings.Repeat("8=FIX.4.4\x019=100\x0135=D\x0149=SENDER\x0156=TARGET\x01", 8))
+
+	store, err := newFileStore(sessionID, dirname, fileStoreOptions{compress: true, compressLevel: -1, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, msg))
+	require.Nil(t, store.SaveMessage(2, []byte{}))
+	require.Nil(t, store.Close())
+
+	body, err := os.ReadFile(store.bodyFname)
+	require.Nil(t, err)
+	assert2.True(t, strings.HasPrefix(string(body), string(compressedBodyMagic)))
+	assert2.Less(t, len(body), len(msg)/2)
+
+	// Compressed bodies remain readable once compression is disabled, and keep being written compressed.
+	store, err = newFileStore(sessionID, dirname, fileStoreOptions{filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(3, msg))
+	msgs, err := store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{msg, {}, msg}, msgs)
+	require.Nil(t, store.Compact())
+	msgs, err = store.GetMessages(1, 3)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{msg, {}, msg}, msgs)
+
+	// An uncompressed body is left uncompressed until the store is reset.
+	require.Nil(t, store.Close())
+	require.Nil(t, os.WriteFile(store.bodyFname, msg, 0660))
+	require.Nil(t, os.WriteFile(store.headerFname, msgDef{seqNum: 1, size: len(msg)}.encode(), 0660))
+	store, err = newFileStore(sessionID, dirname, fileStoreOptions{compress: true, compressLevel: -1, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	defer store.Close()
+	assert2.False(t, store.bodyCompressed)
+	require.Nil(t, store.SaveMessage(2, msg))
+	msgs, err = store.GetMessages(1, 2)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{msg, msg}, msgs)
+
+	require.Nil(t, store.Reset())
+	assert2.True(t, store.bodyCompressed)
+}
+
+const testEncryptionKey = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f"
+
+// EncryptedFileStoreTestSuite runs all tests in the MessageStoreTestSuite against a FileStore encrypting and compressing its message bodies.
+type EncryptedFileStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *EncryptedFileStoreTestSuite) SetupTest() {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStoreEncryptionKey=%s
+FileStoreCompress=Y
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.T().TempDir(), testEncryptionKey, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *EncryptedFileStoreTestSuite) TearDownTest() {
+	suite.MsgStore.Close()
+}
+
+func TestEncryptedFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(EncryptedFileStoreTestSuite))
+}
+
+func TestFileStoreEncryption(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	dirname := t.TempDir()
+	msg := []byte("8=FIX.4.4\x019=100\x0135=D\x0149=SENDER\x0156=TARGET\x01")
+	key, err := parseEncryptionKey(testEncryptionKey)
+	require.Nil(t, err)
+
+	store, err := newFileStore(sessionID, dirname, fileStoreOptions{encryptionKey: key, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, msg))
+	require.Nil(t, store.SaveMessage(2, msg))
+	require.Nil(t, store.Close())
+
+	body, err := os.ReadFile(store.bodyFname)
+	require.Nil(t, err)
+	assert2.True(t, strings.HasPrefix(string(body), string(encryptedBodyMagic)))
+	assert2.NotContains(t, string(body), "SENDER")
+	// Every message gets its own nonce.
+	first, second := body[len(encryptedBodyMagic):len(body)/2+2], body[len(body)/2+2:]
+	assert2.Equal(t, encryptionKeyVersion, first[0])
+	assert2.NotEqual(t, first, second)
+
+	// Encrypted bodies cannot be opened without the key, or read with another one.
+	_, err = newFileStore(sessionID, dirname, fileStoreOptions{filePerm: defaultFilePerm})
+	assert2.NotNil(t, err)
+	otherKey := append([]byte{}, key...)
+	otherKey[0] ^= 0xff
+	store, err = newFileStore(sessionID, dirname, fileStoreOptions{encryptionKey: otherKey, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	_, err = store.GetMessages(1, 2)
+	assert2.NotNil(t, err)
+	require.Nil(t, store.Close())
+
+	// An existing unencrypted body is not written to once a key is set.
+	plainDirname := t.TempDir()
+	store, err = newFileStore(sessionID, plainDirname, fileStoreOptions{filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessage(1, msg))
+	require.Nil(t, store.Close())
+	_, err = newFileStore(sessionID, plainDirname, fileStoreOptions{encryptionKey: key, filePerm: defaultFilePerm})
+	require.NotNil(t, err)
+	assert2.Contains(t, err.Error(), "not encrypted")
+
+	store, err = newFileStore(sessionID, dirname, fileStoreOptions{encryptionKey: key, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	defer store.Close()
+	msgs, err := store.GetMessages(1, 2)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{msg, msg}, msgs)
+	require.Nil(t, store.Compact())
+	got, found, err := store.GetMessage(2)
+	require.Nil(t, err)
+	assert2.True(t, found)
+	assert2.Equal(t, msg, got)
+	assert2.NotNil(t, store.Repair())
+}
+
+func TestFileStoreEncryptedSessionState(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	key, err := parseEncryptionKey(testEncryptionKey)
+	require.Nil(t, err)
+	store, err := newFileStore(sessionID, t.TempDir(), fileStoreOptions{encryptionKey: key, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	defer store.Close()
+
+	state := quickfix.PersistedSessionState{
+		State:               "Resend",
+		NextTargetMsgSeqNum: 5,
+		ResendRangeEnd:      9,
+		StashedMessages:     map[int][]byte{10: []byte("8=FIX.4.4\x019=100\x0135=D\x0149=SENDER\x0156=TARGET\x01")},
+	}
+	require.Nil(t, store.SaveSessionState(state))
+
+	data, err := os.ReadFile(store.stateFname)
+	require.Nil(t, err)
+	var record stateRecord
+	require.Nil(t, json.Unmarshal(data, &record))
+	assert2.True(t, record.Encrypted)
+	assert2.NotContains(t, string(record.StashedMessages[10]), "SENDER")
+	loaded, found, err := store.LoadSessionState()
+	require.Nil(t, err)
+	assert2.True(t, found)
+	assert2.Equal(t, state, loaded)
+}
+
+func TestFileStoreEncryptionKeySetting(t *testing.T) {
+	for _, key := range []string{"zz", testEncryptionKey[:62]} {
+		settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStoreEncryptionKey=%s
+
+[SESSION]
+BeginString=FIX.4.4
+SenderCompID=SENDER
+TargetCompID=TARGET`, t.TempDir(), key)))
+		require.Nil(t, err)
+
+		_, err = NewStoreFactory(settings).Create(quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"})
+		var formatErr quickfix.IncorrectFormatForSetting
+		assert2.ErrorAs(t, err, &formatErr, key)
+	}
+}
+
+// buildFIXMessage returns a heartbeat with a valid BodyLength and CheckSum.
+func buildFIXMessage(seqNum int) []byte {
+	body := fmt.Sprintf("35=0\x0134=%d\x0149=SENDER\x0156=TARGET\x01", seqNum)
+	msg := fmt.Sprintf("8=FIX.4.4\x019=%d\x01%s", len(body), body)
+	var sum int
+	for i := 0; i < len(msg); i++ {
+		sum += int(msg[i])
+	}
+	return []byte(fmt.Sprintf("%s10=%03d\x01", msg, sum%256))
+}
+
+func (suite *FileStoreTestSuite) TestRepair() {
+	store := suite.MsgStore.(*fileStore)
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		suite.Require().Nil(store.SaveMessage(seqNum, buildFIXMessage(seqNum)))
+	}
+	// Leave a corrupt message and a partially written one in the body file.
+	corrupt := buildFIXMessage(4)
+	corrupt[len(corrupt)-2] = '0'
+	_, err := store.bodyFile.Write(corrupt)
+	suite.Require().Nil(err)
+	suite.Require().Nil(store.SaveMessage(5, buildFIXMessage(5)))
+	_, err = store.bodyFile.Write(buildFIXMessage(6)[:20])
+	suite.Require().Nil(err)
+
+	// Lose the index.
+	suite.Require().Nil(store.headerFile.Truncate(headerRecordSize + 5))
+
+	suite.Require().Nil(store.Repair())
+	msgs, err := store.GetMessages(1, 10)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{buildFIXMessage(1), buildFIXMessage(2), buildFIXMessage(3), buildFIXMessage(5)}, msgs)
+
+	suite.Require().Nil(store.SaveMessage(7, buildFIXMessage(7)))
+	msgs, err = store.GetMessages(7, 7)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{buildFIXMessage(7)}, msgs)
+}
+
+func TestFileStoreRotation(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	dirname := t.TempDir()
+	store, err := newFileStore(sessionID, dirname, fileStoreOptions{filePerm: defaultFilePerm, maxBodyBytes: 20, rotateInterval: rotateDaily})
+	require.Nil(t, err)
+	defer store.Close()
+	assert2.True(t, store.rotateAt.After(time.Now()))
+
+	for seqNum := 1; seqNum <= 5; seqNum++ {
+		require.Nil(t, store.SaveMessage(seqNum, []byte(fmt.Sprintf("message-%02d", seqNum))))
+	}
+	suffixes, err := archiveSuffixes(store.bodyFname)
+	require.Nil(t, err)
+	assert2.Len(t, suffixes, 2)
+
+	// A passed boundary archives the current files on the next write.
+	store.rotateAt = time.Now().Add(-time.Second)
+	require.Nil(t, store.SaveMessage(6, []byte("message-06")))
+	assert2.True(t, store.rotateAt.After(time.Now()))
+
+	// Messages saved before a rotation are read back from the archived files.
+	msgs, err := store.GetMessages(1, 6)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{
+		[]byte("message-01"), []byte("message-02"), []byte("message-03"),
+		[]byte("message-04"), []byte("message-05"), []byte("message-06"),
+	}, msgs)
+	msgs, err = store.GetMessages(6, 6)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{[]byte("message-06")}, msgs)
+	msg, ok, err := store.GetMessage(3)
+	require.Nil(t, err)
+	assert2.True(t, ok)
+	assert2.Equal(t, []byte("message-03"), msg)
+	_, ok, err = store.GetMessage(7)
+	require.Nil(t, err)
+	assert2.False(t, ok)
+
+	var all []string
+	require.Nil(t, store.IterateAllMessages(2, 5, func(msg []byte) error {
+		all = append(all, string(msg))
+		return nil
+	}))
+	assert2.Equal(t, []string{"message-02", "message-03", "message-04", "message-05"}, all)
+
+	// Simulate a crash between archiving the header and body files.
+	require.Nil(t, store.Close())
+	require.Nil(t, os.Rename(store.headerFname, store.headerFname+".29991231-000000.000000000"))
+	require.Nil(t, store.Refresh())
+	_, err = os.Stat(store.bodyFname + ".29991231-000000.000000000")
+	assert2.Nil(t, err)
+
+	require.Nil(t, store.Reset())
+	suffixes, err = archiveSuffixes(store.bodyFname)
+	require.Nil(t, err)
+	assert2.Empty(t, suffixes)
+}
+
+// MmapFileStoreTestSuite runs all tests in the MessageStoreTestSuite against a FileStore reading its body file through a memory mapping.
+type MmapFileStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *MmapFileStoreTestSuite) SetupTest() {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+FileStorePath=%s
+FileStoreMmap=Y
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.T().TempDir(), sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *MmapFileStoreTestSuite) TearDownTest() {
+	suite.MsgStore.Close()
+}
+
+func TestMmapFileStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(MmapFileStoreTestSuite))
+}
+
+func BenchmarkIterateMessages(b *testing.B) {
+	const count, size = 10000, 300
+	msg := bytes.Repeat([]byte("x"), size)
+	msgs := make(map[int][]byte, count)
+	for seqNum := 1; seqNum <= count; seqNum++ {
+		msgs[seqNum] = msg
+	}
+
+	for _, mmap := range []bool{false, true} {
+		b.Run(fmt.Sprintf("mmap=%v", mmap), func(b *testing.B) {
+			sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+			store, err := newFileStore(sessionID, b.TempDir(), fileStoreOptions{filePerm: defaultFilePerm, mmap: mmap})
+			require.Nil(b, err)
+			defer store.Close()
+			require.Nil(b, store.SaveMessages(msgs))
+
+			b.SetBytes(count * size)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				var n int
+				err := store.IterateMessages(1, count, func([]byte) error {
+					n++
+					return nil
+				})
+				require.Nil(b, err)
+				require.Equal(b, count, n)
+			}
+		})
+	}
+}
+
+func BenchmarkIncrNextSenderMsgSeqNum(b *testing.B) {
+	for _, fileSync := range []bool{false, true} {
+		b.Run(fmt.Sprintf("sync=%v", fileSync), func(b *testing.B) {
+			sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+			store, err := newFileStore(sessionID, b.TempDir(), fileStoreOptions{filePerm: defaultFilePerm, fileSync: fileSync})
+			require.Nil(b, err)
+			defer store.Close()
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				require.Nil(b, store.IncrNextSenderMsgSeqNum())
+			}
+		})
+	}
+}
+
+func TestOpenReadOnlyStore(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	dirname := t.TempDir()
+
+	_, err := OpenReadOnlyStore(dirname, sessionID)
+	assert2.NotNil(t, err, "no store in dir")
+
+	store, err := newFileStore(sessionID, dirname, fileStoreOptions{compress: true, compressLevel: -1, filePerm: defaultFilePerm})
+	require.Nil(t, err)
+	defer store.Close()
+	for seqNum := 1; seqNum <= 3; seqNum++ {
+		require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(seqNum, buildFIXMessage(seqNum)))
+	}
+	require.Nil(t, store.SetNextTargetMsgSeqNum(7))
+
+	entries, err := os.ReadDir(dirname)
+	require.Nil(t, err)
+	modTimes := make(map[string]time.Time)
+	for _, entry := range entries {
+		info, err := entry.Info()
+		require.Nil(t, err)
+		modTimes[entry.Name()] = info.ModTime()
+	}
+
+	ro, err := OpenReadOnlyStore(dirname, sessionID)
+	require.Nil(t, err)
+	assert2.Equal(t, 4, ro.NextSenderMsgSeqNum())
+	assert2.Equal(t, 7, ro.NextTargetMsgSeqNum())
+	assert2.True(t, store.CreationTime().Equal(ro.CreationTime()))
+	msgs, err := ro.GetMessages(2, 3)
+	require.Nil(t, err)
+	assert2.Equal(t, [][]byte{buildFIXMessage(2), buildFIXMessage(3)}, msgs)
+
+	// Messages saved after the store was opened are read from the files.
+	require.Nil(t, store.SaveMessage(4, buildFIXMessage(4)))
+	msgs = nil
+	require.Nil(t, ro.IterateMessages(3, 10, func(msg []byte) error {
+		msgs = append(msgs, msg)
+		return nil
+	}))
+	assert2.Equal(t, [][]byte{buildFIXMessage(3), buildFIXMessage(4)}, msgs)
+
+	// A journal left behind by a crash is honoured but not rolled forward.
+	require.Nil(t, os.WriteFile(store.seqNumsJournalFname, []byte(fmt.Sprintf(journalFormat, 11, 21)), 0660))
+	ro, err = OpenReadOnlyStore(dirname, sessionID)
+	require.Nil(t, err)
+	assert2.Equal(t, 11, ro.NextSenderMsgSeqNum())
+	assert2.Equal(t, 21, ro.NextTargetMsgSeqNum())
+	senderSeqNums, err := os.ReadFile(store.senderSeqNumsFname)
+	require.Nil(t, err)
+	assert2.Equal(t, "0000000000000000004", string(senderSeqNums))
+
+	for name, modTime := range modTimes {
+		if name == path.Base(store.bodyFname) || name == path.Base(store.headerFname) {
+			continue
+		}
+		info, err := os.Stat(path.Join(dirname, name))
+		require.Nil(t, err)
+		assert2.Equal(t, modTime, info.ModTime(), name)
+	}
+}
@@ -0,0 +1,230 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// The header file indexes the body file with one fixed-width record per saved message:
+// [8 bytes seqNum][8 bytes offset][8 bytes size], each a little-endian int64. Records are appended
+// in seqnum order, so the record for a given seqnum can be found with a binary search. For a message
+// stored compressed, the low 32 bits of size hold the compressed size and the high 32 bits the
+// original size.
+//
+// Older stores wrote the header as "seqNum,offset,size\n" text lines. Such a header is rewritten
+// in the binary format the next time the store is refreshed.
+
+// headerRecordSize is the size in bytes of a single header record.
+const headerRecordSize = 24
+
+// msgDef locates a single message within the body file.
+type msgDef struct {
+	seqNum int
+	offset int64
+	// size is the number of bytes the message occupies in the body file.
+	size int
+	// rawSize is the original size of a compressed message, 0 if the message is stored uncompressed.
+	rawSize int
+}
+
+func (def msgDef) encode() []byte {
+	var rec [headerRecordSize]byte
+	binary.LittleEndian.PutUint64(rec[0:8], uint64(def.seqNum))
+	binary.LittleEndian.PutUint64(rec[8:16], uint64(def.offset))
+	binary.LittleEndian.PutUint64(rec[16:24], uint64(def.rawSize)<<32|uint64(def.size))
+	return rec[:]
+}
+
+func decodeMsgDef(rec []byte) msgDef {
+	size := binary.LittleEndian.Uint64(rec[16:24])
+	return msgDef{
+		seqNum:  int(binary.LittleEndian.Uint64(rec[0:8])),
+		offset:  int64(binary.LittleEndian.Uint64(rec[8:16])),
+		size:    int(size & 0xffffffff),
+		rawSize: int(size >> 32),
+	}
+}
+
+// writeMsgDef appends a header record to w.
+func writeMsgDef(w io.Writer, def msgDef) error {
+	_, err := w.Write(def.encode())
+	return err
+}
+
+// headerRecordCount returns the number of complete records in the header file. A trailing partial
+// record, left behind by an interrupted write, is not counted.
+func headerRecordCount(headerFile *os.File) (int, error) {
+	info, err := headerFile.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("unable to stat file: %s: %s", headerFile.Name(), err.Error())
+	}
+	return int(info.Size() / headerRecordSize), nil
+}
+
+// readMsgDefAt reads the i-th record of the header file.
+func readMsgDefAt(headerFile *os.File, i int) (msgDef, error) {
+	var rec [headerRecordSize]byte
+	if _, err := headerFile.ReadAt(rec[:], int64(i)*headerRecordSize); err != nil {
+		return msgDef{}, fmt.Errorf("unable to read from file: %s: %s", headerFile.Name(), err.Error())
+	}
+	return decodeMsgDef(rec[:]), nil
+}
+
+// searchMsgDefs returns the index of the first of the header file's n records whose seqnum is at least seqNum.
+func searchMsgDefs(headerFile *os.File, n, seqNum int) (int, error) {
+	var searchErr error
+	i := sort.Search(n, func(i int) bool {
+		if searchErr != nil {
+			return true
+		}
+		def, err := readMsgDefAt(headerFile, i)
+		if err != nil {
+			searchErr = err
+			return true
+		}
+		return def.seqNum >= seqNum
+	})
+	return i, searchErr
+}
+
+// headerReader reads consecutive records from the header file.
+type headerReader struct {
+	r    *bufio.Reader
+	name string
+	rec  [headerRecordSize]byte
+}
+
+// newHeaderReader returns a reader over the header records stored in the byte range [from, to) of the header file.
+func newHeaderReader(headerFile *os.File, from, to int64) *headerReader {
+	return &headerReader{
+		r:    bufio.NewReader(io.NewSectionReader(headerFile, from, to-from)),
+		name: headerFile.Name(),
+	}
+}
+
+// next returns the next record, or io.EOF once no complete record remains.
+func (hr *headerReader) next() (msgDef, error) {
+	if _, err := io.ReadFull(hr.r, hr.rec[:]); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return msgDef{}, io.EOF
+		}
+		return msgDef{}, fmt.Errorf("unable to read from file: %s: %s", hr.name, err.Error())
+	}
+	return decodeMsgDef(hr.rec[:]), nil
+}
+
+// readMsgDefs reads the header records stored in the byte range [from, to) of the header file.
+func readMsgDefs(headerFile *os.File, from, to int64) ([]msgDef, error) {
+	var defs []msgDef
+	hr := newHeaderReader(headerFile, from, to)
+	for {
+		def, err := hr.next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return defs, nil
+			}
+			return nil, err
+		}
+		defs = append(defs, def)
+	}
+}
+
+var reLegacyHeaderRecord = regexp.MustCompile(`^\d+,\d+,\d+\n`)
+
+// isLegacyHeader reports whether the header file holds text records.
+func isLegacyHeader(fname string) (bool, error) {
+	f, err := os.Open(fname)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("unable to open file: %s: %s", fname, err.Error())
+	}
+	defer func() { _ = f.Close() }()
+
+	// The longest possible text record is three 19 digit numbers, two commas and a newline.
+	buf := make([]byte, 3*19+3)
+	n, err := io.ReadFull(f, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return false, fmt.Errorf("unable to read from file: %s: %s", fname, err.Error())
+	}
+	return reLegacyHeaderRecord.Match(buf[:n]), nil
+}
+
+// migrateLegacyHeader rewrites a header file holding text records in the binary format. The new
+// header is written alongside the old one and renamed over it, so a crash leaves either intact.
+func (store *fileStore) migrateLegacyHeader() error {
+	legacy, err := isLegacyHeader(store.headerFname)
+	if err != nil || !legacy {
+		return err
+	}
+
+	src, err := os.Open(store.headerFname)
+	if err != nil {
+		return fmt.Errorf("unable to open file: %s: %s", store.headerFname, err.Error())
+	}
+	defer func() { _ = src.Close() }()
+
+	tmpFname := store.headerFname + ".migrate.tmp"
+	dst, err := os.OpenFile(tmpFname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", tmpFname, err.Error())
+	}
+	defer func() { _ = dst.Close() }()
+
+	r := bufio.NewReader(src)
+	w := bufio.NewWriter(dst)
+	var count int
+	for {
+		var def msgDef
+		if _, err := fmt.Fscanf(r, "%d,%d,%d\n", &def.seqNum, &def.offset, &def.size); err != nil {
+			// A partial last line is left behind by an interrupted write.
+			if !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+				log.Printf("%s: dropping header records after record %d: %s; run fixrepair to rebuild the header", store.headerFname, count, err)
+			}
+			break
+		}
+		count++
+		if err := writeMsgDef(w, def); err != nil {
+			return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+	}
+	if err := dst.Sync(); err != nil {
+		return fmt.Errorf("unable to flush file: %s: %s", tmpFname, err.Error())
+	}
+	if err := dst.Close(); err != nil {
+		return fmt.Errorf("unable to close file: %s: %s", tmpFname, err.Error())
+	}
+
+	if err := os.Rename(tmpFname, store.headerFname); err != nil {
+		return errors.Wrapf(err, "rename %v", tmpFname)
+	}
+	syncDir(filepath.Dir(store.headerFname))
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// The sender and target seqnums are kept in two files, which cannot be replaced in a single step.
+// Every update is therefore first published as a journal holding both values: the journal is written
+// to a temporary file, flushed, and renamed into place, so it is either absent or complete. Only then
+// are the seqnum files rewritten and the journal removed. A journal found on startup belongs to an
+// update that may not have reached the seqnum files and is rolled forward; a leftover temporary
+// journal belongs to an update that never happened and is discarded.
+
+const journalFormat = "%019d,%019d\n"
+
+// setSeqNums durably records the next sender and target seqnums.
+func (store *fileStore) setSeqNums(nextSender, nextTarget int) error {
+	return store.updateSeqNums(func(int, int) (int, int) { return nextSender, nextTarget })
+}
+
+// updateSeqNums durably records the seqnums returned by update, which is passed the current ones.
+// Both values are read and written under fileMu so concurrent updates of the sender and target
+// seqnums cannot overwrite each other.
+func (store *fileStore) updateSeqNums(update func(nextSender, nextTarget int) (int, int)) error {
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	nextSender, nextTarget := update(store.cache.NextSenderMsgSeqNum(), store.cache.NextTargetMsgSeqNum())
+	if err := store.writeSeqNumsJournalLocked(nextSender, nextTarget); err != nil {
+		return err
+	}
+	if err := store.writeSeqNumLocked(store.senderSeqNumsFile, nextSender); err != nil {
+		return err
+	}
+	if err := store.writeSeqNumLocked(store.targetSeqNumsFile, nextTarget); err != nil {
+		return err
+	}
+	if err := removeFile(store.seqNumsJournalFname); err != nil {
+		return err
+	}
+	if err := store.cache.SetNextSenderMsgSeqNum(nextSender); err != nil {
+		return err
+	}
+	return store.cache.SetNextTargetMsgSeqNum(nextTarget)
+}
+
+func (store *fileStore) writeSeqNumsJournalLocked(nextSender, nextTarget int) error {
+	tmpFname := store.seqNumsJournalFname + ".tmp"
+	f, err := os.OpenFile(tmpFname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", tmpFname, err.Error())
+	}
+	if _, err := fmt.Fprintf(f, journalFormat, nextSender, nextTarget); err != nil {
+		_ = f.Close()
+		return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+	}
+	if store.fileSync {
+		if err := f.Sync(); err != nil {
+			_ = f.Close()
+			return fmt.Errorf("unable to flush file: %s: %s", tmpFname, err.Error())
+		}
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("unable to close file: %s: %s", tmpFname, err.Error())
+	}
+	if err := os.Rename(tmpFname, store.seqNumsJournalFname); err != nil {
+		return errors.Wrapf(err, "rename %v", tmpFname)
+	}
+	if store.fileSync {
+		syncDir(filepath.Dir(store.seqNumsJournalFname))
+	}
+	return nil
+}
+
+func (store *fileStore) writeSeqNumLocked(f *os.File, seqNum int) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("unable to rewind file: %s: %s", f.Name(), err.Error())
+	}
+	if _, err := fmt.Fprintf(f, "%019d", seqNum); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", f.Name(), err.Error())
+	}
+	if store.fileSync {
+		if err := f.Sync(); err != nil {
+			return fmt.Errorf("unable to flush file: %s: %s", f.Name(), err.Error())
+		}
+	}
+	return nil
+}
+
+// recoverSeqNumsJournal rolls forward a complete journal left behind by a crash and discards a partial one.
+// It must be called before the seqnum files are read.
+func (store *fileStore) recoverSeqNumsJournal() error {
+	if err := removeFile(store.seqNumsJournalFname + ".tmp"); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(store.seqNumsJournalFname)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("unable to read from file: %s: %s", store.seqNumsJournalFname, err.Error())
+	}
+
+	var nextSender, nextTarget int
+	if _, err := fmt.Sscanf(string(data), journalFormat, &nextSender, &nextTarget); err == nil {
+		for fname, seqNum := range map[string]int{store.senderSeqNumsFname: nextSender, store.targetSeqNumsFname: nextTarget} {
+			f, err := openOrCreateFile(fname, store.filePerm)
+			if err != nil {
+				return err
+			}
+			err = store.writeSeqNumLocked(f, seqNum)
+			if closeErr := closeSyncFile(f); err == nil {
+				err = closeErr
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return removeFile(store.seqNumsJournalFname)
+}
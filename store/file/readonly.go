@@ -0,0 +1,124 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/quickfixgo/quickfix"
+)
+
+// ReadOnlyFileStore implements quickfix.ReadOnlyMessageStore over the files of a FileStore, for offline
+// tools such as archivers or seqnum gap auditors. Files are only ever opened for reading, so the store
+// may be opened while a session is using it. The seqnums and creation time are those found when it was
+// opened, while messages are read as they are found in the files.
+//
+// Encrypted body files cannot be read.
+type ReadOnlyFileStore struct {
+	store        *fileStore
+	creationTime time.Time
+	nextSender   int
+	nextTarget   int
+}
+
+var _ quickfix.ReadOnlyMessageStore = (*ReadOnlyFileStore)(nil)
+
+// OpenReadOnlyStore opens the FileStore of sessionID found in dir for reading.
+func OpenReadOnlyStore(dir string, sessionID quickfix.SessionID) (*ReadOnlyFileStore, error) {
+	store := &fileStore{sessionID: sessionID}
+	if err := store.createCache(); err != nil {
+		return nil, err
+	}
+	store.setFilenames(expandPathTemplate(dir, sessionID))
+
+	timeBytes, err := os.ReadFile(store.sessionFname)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read from file: %s: %s", store.sessionFname, err.Error())
+	}
+	ro := &ReadOnlyFileStore{store: store, nextSender: 1, nextTarget: 1}
+	if err := ro.creationTime.UnmarshalText(timeBytes); err != nil {
+		return nil, fmt.Errorf("unable to parse file: %s: %s", store.sessionFname, err.Error())
+	}
+
+	// A journal left behind by a crash holds seqnums the seqnum files may not have received yet.
+	if data, err := os.ReadFile(store.seqNumsJournalFname); err == nil {
+		var nextSender, nextTarget int
+		if _, err := fmt.Sscanf(string(data), journalFormat, &nextSender, &nextTarget); err == nil {
+			ro.nextSender, ro.nextTarget = nextSender, nextTarget
+			return ro, nil
+		}
+	}
+	if seqNum, ok := readSeqNumFile(store.senderSeqNumsFname); ok {
+		ro.nextSender = seqNum
+	}
+	if seqNum, ok := readSeqNumFile(store.targetSeqNumsFname); ok {
+		ro.nextTarget = seqNum
+	}
+	return ro, nil
+}
+
+// readSeqNumFile returns the seqnum held by a sender or target seqnums file, if any.
+func readSeqNumFile(fname string) (int, bool) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return 0, false
+	}
+	seqNum, err := strconv.Atoi(strings.Trim(string(data), "\r\n"))
+	return seqNum, err == nil
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum the session would send.
+func (ro *ReadOnlyFileStore) NextSenderMsgSeqNum() int {
+	return ro.nextSender
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum the session expects to receive.
+func (ro *ReadOnlyFileStore) NextTargetMsgSeqNum() int {
+	return ro.nextTarget
+}
+
+// CreationTime returns the time the store was created or last reset.
+func (ro *ReadOnlyFileStore) CreationTime() time.Time {
+	return ro.creationTime
+}
+
+// IterateMessages passes the saved messages with seqnums in [beginSeqNum, endSeqNum] to cb, in the order
+// they were saved. Iteration stops at the first error returned by cb, which is returned unless it is
+// quickfix.ErrStopIteration.
+func (ro *ReadOnlyFileStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	return ro.iterateMessages(beginSeqNum, endSeqNum, func(_ int, msg []byte) error { return cb(msg) })
+}
+
+func (ro *ReadOnlyFileStore) iterateMessages(beginSeqNum, endSeqNum int, cb func(seqNum int, msg []byte) error) error {
+	err := ro.store.iterateFiles(ro.store.bodyFname, ro.store.headerFname, beginSeqNum, endSeqNum, cb)
+	if errors.Is(err, quickfix.ErrStopIteration) {
+		return nil
+	}
+	return err
+}
+
+// GetMessages returns the saved messages with seqnums in [beginSeqNum, endSeqNum] in seqnum order, the latest
+// copy of each.
+func (ro *ReadOnlyFileStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	return latestMessages(func(cb func(int, []byte) error) error {
+		return ro.iterateMessages(beginSeqNum, endSeqNum, cb)
+	})
+}
@@ -0,0 +1,199 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const soh = '\x01'
+
+// checksumFieldLen is the length of the trailing "10=nnn\x01" field of a FIX message.
+const checksumFieldLen = 7
+
+// maxFrameHeaderLen bounds the length of the "8=...\x019=...\x01" prefix of a FIX message.
+const maxFrameHeaderLen = 64
+
+// Repair rebuilds the header file from the body file, for use when the header file has been truncated
+// or corrupted. The body file is scanned from the start for complete FIX messages with a valid
+// checksum, and a header record is written for each of them in the order they were saved. Bytes that
+// do not belong to such a message, e.g. a message only partially written before a crash, are skipped.
+//
+// Compressed and encrypted body files cannot be repaired, as their messages cannot be delimited without the header.
+func (store *fileStore) Repair() error {
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	if store.bodyMagic != nil {
+		return fmt.Errorf("unable to repair compressed or encrypted body file: %s", store.bodyFname)
+	}
+	if err := store.syncBodyAndHeaderFilesLocked(); err != nil {
+		return err
+	}
+
+	defs, err := scanBody(store.bodyFile)
+	if err != nil {
+		return err
+	}
+
+	tmpFname := store.headerFname + ".repair.tmp"
+	tmpHeader, err := os.OpenFile(tmpFname, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, store.filePerm)
+	if err != nil {
+		return fmt.Errorf("error opening or creating file: %s: %s", tmpFname, err.Error())
+	}
+	defer func() { _ = tmpHeader.Close() }()
+
+	w := bufio.NewWriter(tmpHeader)
+	for _, def := range defs {
+		if err := writeMsgDef(w, def); err != nil {
+			return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+		}
+	}
+	if err := w.Flush(); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+	}
+	if err := tmpHeader.Sync(); err != nil {
+		return fmt.Errorf("unable to flush file: %s: %s", tmpFname, err.Error())
+	}
+
+	if err := os.Rename(tmpFname, store.headerFname); err != nil {
+		return errors.Wrapf(err, "rename %v", tmpFname)
+	}
+	syncDir(filepath.Dir(store.headerFname))
+
+	// The store's handle still points at the replaced file.
+	if err := closeSyncFile(store.headerFile); err != nil {
+		return err
+	}
+	store.headerFile, err = openOrCreateFile(store.headerFname, store.filePerm)
+	return err
+}
+
+// scanBody locates the complete FIX messages stored in the body file.
+func scanBody(body *os.File) ([]msgDef, error) {
+	info, err := body.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("unable to stat file: %s: %s", body.Name(), err.Error())
+	}
+
+	var defs []msgDef
+	r := bufio.NewReader(io.NewSectionReader(body, 0, info.Size()))
+	for offset := int64(0); offset < info.Size(); {
+		prefix, err := r.Peek(maxFrameHeaderLen)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("unable to read from file: %s: %s", body.Name(), err.Error())
+		}
+
+		def, ok, err := readMsgDefAtOffset(body, offset, prefix)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			// Skip ahead to the next byte that could start a message.
+			skip := 1
+			if i := bytes.Index(prefix[1:], []byte("8=")); i >= 0 {
+				skip += i
+			} else if len(prefix) > 2 {
+				skip = len(prefix) - 1
+			}
+			if _, err := r.Discard(skip); err != nil {
+				return nil, fmt.Errorf("unable to read from file: %s: %s", body.Name(), err.Error())
+			}
+			offset += int64(skip)
+			continue
+		}
+
+		defs = append(defs, def)
+		if _, err := r.Discard(def.size); err != nil {
+			return nil, fmt.Errorf("unable to read from file: %s: %s", body.Name(), err.Error())
+		}
+		offset += int64(def.size)
+	}
+	return defs, nil
+}
+
+// readMsgDefAtOffset checks whether a complete FIX message starts at offset in the body file, given the
+// first bytes found there, and returns its location and seqnum if so.
+func readMsgDefAtOffset(body *os.File, offset int64, prefix []byte) (msgDef, bool, error) {
+	// 8=BeginString<SOH>9=BodyLength<SOH>
+	if !bytes.HasPrefix(prefix, []byte("8=")) {
+		return msgDef{}, false, nil
+	}
+	beginStringEnd := bytes.IndexByte(prefix, soh)
+	if beginStringEnd < 0 || !bytes.HasPrefix(prefix[beginStringEnd+1:], []byte("9=")) {
+		return msgDef{}, false, nil
+	}
+	bodyLengthStart := beginStringEnd + 3
+	bodyLengthEnd := bytes.IndexByte(prefix[bodyLengthStart:], soh)
+	if bodyLengthEnd < 0 {
+		return msgDef{}, false, nil
+	}
+	bodyLength, err := strconv.Atoi(string(prefix[bodyLengthStart : bodyLengthStart+bodyLengthEnd]))
+	if err != nil || bodyLength < 0 {
+		return msgDef{}, false, nil
+	}
+	frameHeaderLen := bodyLengthStart + bodyLengthEnd + 1
+
+	msg := make([]byte, frameHeaderLen+bodyLength+checksumFieldLen)
+	if _, err := body.ReadAt(msg, offset); err != nil {
+		if errors.Is(err, io.EOF) {
+			return msgDef{}, false, nil
+		}
+		return msgDef{}, false, fmt.Errorf("unable to read from file: %s: %s", body.Name(), err.Error())
+	}
+
+	// 10=CheckSum<SOH>
+	trailer := msg[len(msg)-checksumFieldLen:]
+	if !bytes.HasPrefix(trailer, []byte("10=")) || trailer[checksumFieldLen-1] != soh {
+		return msgDef{}, false, nil
+	}
+	checksum, err := strconv.Atoi(string(trailer[3 : checksumFieldLen-1]))
+	if err != nil {
+		return msgDef{}, false, nil
+	}
+	var sum int
+	for _, b := range msg[:len(msg)-checksumFieldLen] {
+		sum += int(b)
+	}
+	if sum%256 != checksum {
+		return msgDef{}, false, nil
+	}
+
+	// 34=MsgSeqNum<SOH>
+	seqNumStart := bytes.Index(msg[frameHeaderLen-1:], []byte("\x0134="))
+	if seqNumStart < 0 {
+		return msgDef{}, false, nil
+	}
+	seqNumStart += frameHeaderLen - 1 + 4
+	seqNumEnd := bytes.IndexByte(msg[seqNumStart:], soh)
+	if seqNumEnd < 0 {
+		return msgDef{}, false, nil
+	}
+	seqNum, err := strconv.Atoi(string(msg[seqNumStart : seqNumStart+seqNumEnd]))
+	if err != nil {
+		return msgDef{}, false, nil
+	}
+
+	return msgDef{seqNum: seqNum, offset: offset, size: len(msg)}, true, nil
+}
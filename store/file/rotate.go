@@ -0,0 +1,258 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/quickfixgo/quickfix"
+)
+
+// Rotation archives the body and header files by renaming them with a timestamp suffix. The header
+// file is renamed first: a crash before the body file follows is detected on the next Refresh by an
+// archived header without a matching body, and the rotation is completed then.
+
+// archiveTimeFormat is fixed width, so that archives sort by name in the order they were created.
+const archiveTimeFormat = "20060102-150405.000000000"
+
+const (
+	rotateDaily  = "daily"
+	rotateHourly = "hourly"
+)
+
+// nextRotation returns the first rotation boundary after now.
+func nextRotation(interval string, now time.Time) time.Time {
+	now = now.UTC()
+	switch interval {
+	case rotateDaily:
+		return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+	case rotateHourly:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	}
+	return time.Time{}
+}
+
+// shouldRotateLocked reports whether the body and header files must be archived before msgSize more
+// bytes are written to the body file.
+func (store *fileStore) shouldRotateLocked(bodyLen int64, msgSize int) bool {
+	empty := bodyLen <= store.bodyDataStart()
+	if !store.rotateAt.IsZero() {
+		if now := time.Now(); !now.Before(store.rotateAt) {
+			if !empty {
+				return true
+			}
+			// There is nothing to archive from the period that has ended.
+			store.rotateAt = nextRotation(store.rotateInterval, now)
+		}
+	}
+	return !empty && store.maxBodyBytes > 0 && bodyLen+int64(msgSize) > store.maxBodyBytes
+}
+
+// bodyDataStart returns the offset of the first message in the body file.
+func (store *fileStore) bodyDataStart() int64 {
+	return int64(len(store.bodyMagic))
+}
+
+// scheduleRotationLocked sets the time of the next scheduled rotation. It is based on the time the body
+// file was last written to, so that messages saved before a restart are archived once the boundary
+// that followed them has passed.
+func (store *fileStore) scheduleRotationLocked() error {
+	info, err := store.bodyFile.Stat()
+	if err != nil {
+		return fmt.Errorf("unable to stat file: %s: %s", store.bodyFname, err.Error())
+	}
+	store.rotateAt = nextRotation(store.rotateInterval, info.ModTime())
+	return nil
+}
+
+// rotateLocked archives the body and header files and continues with new empty files.
+func (store *fileStore) rotateLocked() error {
+	if err := store.syncBodyAndHeaderFilesLocked(); err != nil {
+		return err
+	}
+	suffix := "." + time.Now().UTC().Format(archiveTimeFormat)
+
+	store.generation++
+	if err := closeSyncFile(store.headerFile); err != nil {
+		return err
+	}
+	store.headerFile = nil
+	if err := os.Rename(store.headerFname, store.headerFname+suffix); err != nil {
+		return errors.Wrapf(err, "rename %v", store.headerFname)
+	}
+	if err := closeSyncFile(store.bodyFile); err != nil {
+		return err
+	}
+	store.bodyFile = nil
+	if err := os.Rename(store.bodyFname, store.bodyFname+suffix); err != nil {
+		return errors.Wrapf(err, "rename %v", store.bodyFname)
+	}
+	syncDir(filepath.Dir(store.bodyFname))
+
+	var err error
+	if store.bodyFile, err = openOrCreateFile(store.bodyFname, store.filePerm); err != nil {
+		return err
+	}
+	if store.headerFile, err = openOrCreateFile(store.headerFname, store.filePerm); err != nil {
+		return err
+	}
+	if store.rotateInterval != "" {
+		store.rotateAt = nextRotation(store.rotateInterval, time.Now())
+	}
+	return store.detectBodyFormatLocked()
+}
+
+// archiveSuffixes returns the timestamp suffixes of the archived files with the given base name, oldest first.
+func archiveSuffixes(fname string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Dir(fname))
+	if err != nil {
+		return nil, errors.Wrapf(err, "read dir %v", filepath.Dir(fname))
+	}
+	prefix := filepath.Base(fname) + "."
+	var suffixes []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		if _, err := time.Parse(archiveTimeFormat, name[len(prefix):]); err == nil {
+			suffixes = append(suffixes, name[len(prefix)-1:])
+		}
+	}
+	sort.Strings(suffixes)
+	return suffixes, nil
+}
+
+// recoverRotation completes a rotation interrupted after the header file was archived.
+func (store *fileStore) recoverRotation() error {
+	suffixes, err := archiveSuffixes(store.headerFname)
+	if err != nil || len(suffixes) == 0 {
+		return err
+	}
+	// The current header file is only missing between the two renames.
+	if _, err := os.Stat(store.headerFname); !os.IsNotExist(err) {
+		return nil
+	}
+	last := suffixes[len(suffixes)-1]
+	if _, err := os.Stat(store.bodyFname + last); !os.IsNotExist(err) {
+		return nil
+	}
+	if err := os.Rename(store.bodyFname, store.bodyFname+last); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "rename %v", store.bodyFname)
+	}
+	return nil
+}
+
+// removeArchives deletes the archived body and header files.
+func (store *fileStore) removeArchives() error {
+	for _, fname := range []string{store.bodyFname, store.headerFname} {
+		suffixes, err := archiveSuffixes(fname)
+		if err != nil {
+			return err
+		}
+		for _, suffix := range suffixes {
+			if err := removeFile(fname + suffix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// archivesFrom returns the suffixes of the archived files that may hold messages with seqnums from
+// beginSeqNum on, oldest first. Archives are walked from the newest back to the first one starting at
+// or before beginSeqNum.
+func (store *fileStore) archivesFrom(beginSeqNum int) ([]string, error) {
+	suffixes, err := archiveSuffixes(store.headerFname)
+	if err != nil {
+		return nil, err
+	}
+	i := len(suffixes)
+	for i > 0 {
+		i--
+		first, ok, err := firstSeqNum(store.headerFname + suffixes[i])
+		if err != nil {
+			return nil, err
+		} else if ok && first <= beginSeqNum {
+			break
+		}
+	}
+	return suffixes[i:], nil
+}
+
+// firstSeqNum returns the seqnum of the first record of the given header file, false if it holds none.
+func firstSeqNum(headerFname string) (int, bool, error) {
+	headerFile, err := os.Open(headerFname)
+	if os.IsNotExist(err) {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, fmt.Errorf("unable to open file: %s: %s", headerFname, err.Error())
+	}
+	defer func() { _ = headerFile.Close() }()
+	n, err := headerRecordCount(headerFile)
+	if err != nil || n == 0 {
+		return 0, false, err
+	}
+	def, err := readMsgDefAt(headerFile, 0)
+	if err != nil {
+		return 0, false, err
+	}
+	return def.seqNum, true, nil
+}
+
+// IterateAllMessages behaves like IterateMessages, but also reads the messages held by the body and
+// header files archived by FileStoreRotateInterval or FileStoreMaxBodyBytes. Archived files are
+// read from the oldest to the newest, followed by the current files.
+func (store *fileStore) IterateAllMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	store.fileMu.Lock()
+	err := store.syncBodyAndHeaderFilesLocked()
+	var suffixes []string
+	if err == nil {
+		suffixes, err = archiveSuffixes(store.headerFname)
+	}
+	store.fileMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, suffix := range append(suffixes, "") {
+		err := store.iterateFiles(store.bodyFname+suffix, store.headerFname+suffix, beginSeqNum, endSeqNum, func(_ int, msg []byte) error {
+			return cb(msg)
+		})
+		if err != nil {
+			if errors.Is(err, quickfix.ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRotateInterval validates a FileStoreRotateInterval value.
+func parseRotateInterval(raw string) (string, error) {
+	switch interval := strings.ToLower(raw); interval {
+	case rotateDaily, rotateHourly:
+		return interval, nil
+	}
+	return "", fmt.Errorf("expected %s or %s", rotateDaily, rotateHourly)
+}
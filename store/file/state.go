@@ -0,0 +1,125 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/quickfixgo/quickfix"
+)
+
+// stateRecord is the content of the state file. Stashed messages are encrypted like the body file when
+// FileStoreEncryptionKey is set.
+type stateRecord struct {
+	State               string
+	NextTargetMsgSeqNum int
+	ResendRangeEnd      int
+	StashedMessages     map[int][]byte `json:",omitempty"`
+	Encrypted           bool           `json:",omitempty"`
+}
+
+// SaveSessionState replaces the state file. The new state is written to a temporary file first, so a
+// crash leaves either the previous or the new state behind.
+func (store *fileStore) SaveSessionState(state quickfix.PersistedSessionState) error {
+	record := stateRecord{
+		State:               state.State,
+		NextTargetMsgSeqNum: state.NextTargetMsgSeqNum,
+		ResendRangeEnd:      state.ResendRangeEnd,
+		StashedMessages:     state.StashedMessages,
+	}
+	if store.aead != nil && len(state.StashedMessages) > 0 {
+		record.Encrypted = true
+		record.StashedMessages = make(map[int][]byte, len(state.StashedMessages))
+		for seqNum, msg := range state.StashedMessages {
+			sealed, err := store.sealMsg(seqNum, msg)
+			if err != nil {
+				return err
+			}
+			record.StashedMessages[seqNum] = sealed
+		}
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("unable to marshal session state to file: %s: %s", store.stateFname, err.Error())
+	}
+
+	store.fileMu.Lock()
+	defer store.fileMu.Unlock()
+
+	tmpFname := store.stateFname + ".tmp"
+	if err := os.WriteFile(tmpFname, data, store.filePerm); err != nil {
+		return fmt.Errorf("unable to write to file: %s: %s", tmpFname, err.Error())
+	}
+	if store.fileSync {
+		if err := syncFile(tmpFname); err != nil {
+			return fmt.Errorf("unable to flush file: %s: %s", tmpFname, err.Error())
+		}
+	}
+	if err := os.Rename(tmpFname, store.stateFname); err != nil {
+		return errors.Wrapf(err, "rename %v", tmpFname)
+	}
+	if store.fileSync {
+		syncDir(filepath.Dir(store.stateFname))
+	}
+	return nil
+}
+
+// LoadSessionState reads the state file, if there is one.
+func (store *fileStore) LoadSessionState() (quickfix.PersistedSessionState, bool, error) {
+	store.fileMu.Lock()
+	data, err := os.ReadFile(store.stateFname)
+	store.fileMu.Unlock()
+	if os.IsNotExist(err) {
+		return quickfix.PersistedSessionState{}, false, nil
+	}
+	if err != nil {
+		return quickfix.PersistedSessionState{}, false, fmt.Errorf("unable to read from file: %s: %s", store.stateFname, err.Error())
+	}
+
+	var record stateRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return quickfix.PersistedSessionState{}, false, fmt.Errorf("unable to unmarshal session state from file: %s: %s", store.stateFname, err.Error())
+	}
+	if record.Encrypted {
+		for seqNum, sealed := range record.StashedMessages {
+			msg, err := store.openMsg(seqNum, sealed)
+			if err != nil {
+				return quickfix.PersistedSessionState{}, false, err
+			}
+			record.StashedMessages[seqNum] = msg
+		}
+	}
+
+	return quickfix.PersistedSessionState{
+		State:               record.State,
+		NextTargetMsgSeqNum: record.NextTargetMsgSeqNum,
+		ResendRangeEnd:      record.ResendRangeEnd,
+		StashedMessages:     record.StashedMessages,
+	}, true, nil
+}
+
+// syncFile flushes the file with the given name to disk.
+func syncFile(fname string) error {
+	f, err := os.OpenFile(fname, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	return closeSyncFile(f)
+}
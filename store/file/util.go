@@ -17,7 +17,9 @@ package file
 
 import (
 	"fmt"
+	"log"
 	"os"
+	"regexp"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -48,6 +50,36 @@ func createFilenamePrefix(s quickfix.SessionID) string {
 	return strings.Join(fname, "-")
 }
 
+var rePathTemplateVar = regexp.MustCompile(`\$\{(\w+)\}`)
+
+// expandPathTemplate substitutes ${BeginString}, ${SenderCompID}, ${TargetCompID}, ${Qualifier} and the other
+// SessionID fields in dirname. Unrecognized variables are left as-is.
+func expandPathTemplate(dirname string, s quickfix.SessionID) string {
+	return rePathTemplateVar.ReplaceAllStringFunc(dirname, func(token string) string {
+		switch name := token[2 : len(token)-1]; name {
+		case "BeginString":
+			return s.BeginString
+		case "SenderCompID":
+			return s.SenderCompID
+		case "SenderSubID":
+			return s.SenderSubID
+		case "SenderLocationID":
+			return s.SenderLocationID
+		case "TargetCompID":
+			return s.TargetCompID
+		case "TargetSubID":
+			return s.TargetSubID
+		case "TargetLocationID":
+			return s.TargetLocationID
+		case "Qualifier":
+			return s.Qualifier
+		default:
+			log.Printf("FileStorePath %q: leaving unrecognized variable %s unexpanded", dirname, token)
+			return token
+		}
+	})
+}
+
 // closeSyncFile behaves like Sync and Close, except that no error is returned if the file does not exist.
 func closeSyncFile(f *os.File) error {
 	if f != nil {
@@ -82,3 +114,12 @@ func openOrCreateFile(fname string, perm os.FileMode) (f *os.File, err error) {
 	}
 	return f, nil
 }
+
+// syncDir flushes a directory so that renames within it are durable. Errors are ignored as not every
+// platform supports syncing directories.
+func syncDir(dirname string) {
+	if d, err := os.Open(dirname); err == nil {
+		_ = d.Sync()
+		_ = d.Close()
+	}
+}
@@ -35,6 +35,26 @@ func (suite *MemoryStoreTestSuite) SetupTest() {
 	require.Nil(suite.T(), err)
 }
 
+func (suite *MemoryStoreTestSuite) TestStoreStats() {
+	stats, ok := quickfix.StoreStats(suite.MsgStore)
+	suite.Require().True(ok)
+
+	suite.Require().Nil(suite.MsgStore.SaveMessage(1, []byte("hello")))
+	suite.Require().Nil(quickfix.SaveMessages(suite.MsgStore, map[int][]byte{2: []byte("cruel"), 3: []byte("world!")}))
+	_, err := suite.MsgStore.GetMessages(2, 3)
+	suite.Require().Nil(err)
+
+	suite.Equal(int64(3), stats.SavedMessageCount())
+	suite.Equal(int64(16), stats.BytesWritten())
+	suite.Equal(int64(2), stats.RetrievedMessageCount())
+	suite.Equal(int64(11), stats.BytesRead())
+
+	recorder, ok := suite.MsgStore.(quickfix.MessageStoreStatsRecorder)
+	suite.Require().True(ok)
+	suite.Equal(int64(3), recorder.SavedCount())
+	suite.Equal(int64(2), recorder.RetrievedCount())
+}
+
 func TestMemoryStoreTestSuite(t *testing.T) {
 	suite.Run(t, new(MemoryStoreTestSuite))
 }
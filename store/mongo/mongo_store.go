@@ -260,19 +260,35 @@ func (store *mongoStore) SetNextTargetMsgSeqNum(next int) error {
 }
 
 // IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
+// The increment is performed by mongo so that concurrent stores sharing the session never lose an update.
 func (store *mongoStore) IncrNextSenderMsgSeqNum() error {
-	if err := store.SetNextSenderMsgSeqNum(store.cache.NextSenderMsgSeqNum() + 1); err != nil {
+	sessionData, err := store.incrSeqNum(context.Background(), "outgoing_seq_num")
+	if err != nil {
 		return errors.Wrap(err, "save sequence number")
 	}
-	return nil
+	return store.cache.SetNextSenderMsgSeqNum(sessionData.OutgoingSeqNum)
 }
 
 // IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
+// The increment is performed by mongo so that concurrent stores sharing the session never lose an update.
 func (store *mongoStore) IncrNextTargetMsgSeqNum() error {
-	if err := store.SetNextTargetMsgSeqNum(store.cache.NextTargetMsgSeqNum() + 1); err != nil {
+	sessionData, err := store.incrSeqNum(context.Background(), "incoming_seq_num")
+	if err != nil {
 		return errors.Wrap(err, "save sequence number")
 	}
-	return nil
+	return store.cache.SetNextTargetMsgSeqNum(sessionData.IncomingSeqNum)
+}
+
+// incrSeqNum atomically increments the given seqnum field of the session record and returns the updated record.
+func (store *mongoStore) incrSeqNum(ctx context.Context, field string) (*mongoQuickFixEntryData, error) {
+	msgFilter := generateMessageFilter(&store.sessionID)
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	res := store.db.Database(store.mongoDatabase).Collection(store.sessionsCollection).FindOneAndUpdate(ctx, msgFilter, bson.M{"$inc": bson.M{field: 1}}, opts)
+	sessionData := &mongoQuickFixEntryData{}
+	if err := res.Decode(sessionData); err != nil {
+		return nil, err
+	}
+	return sessionData, nil
 }
 
 // CreationTime returns the creation time of the store.
@@ -317,17 +333,11 @@ func (store *mongoStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg [
 			return err
 		}
 
-		next = store.cache.NextSenderMsgSeqNum() + 1
-
-		msgFilter = generateMessageFilter(&store.sessionID)
-		sessionUpdate := generateMessageFilter(&store.sessionID)
-		sessionUpdate.IncomingSeqNum = store.cache.NextTargetMsgSeqNum()
-		sessionUpdate.OutgoingSeqNum = next
-		sessionUpdate.CreationTime = store.cache.CreationTime()
-		_, err = store.db.Database(store.mongoDatabase).Collection(store.sessionsCollection).UpdateOne(sessionCtx, msgFilter, bson.M{"$set": sessionUpdate})
+		sessionData, err := store.incrSeqNum(sessionCtx, "outgoing_seq_num")
 		if err != nil {
 			return err
 		}
+		next = sessionData.OutgoingSeqNum
 
 		return sessionCtx.CommitTransaction(context.Background())
 	})
@@ -365,6 +375,9 @@ func (store *mongoStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]b
 		if err = cursor.Decode(&msgFilter); err != nil {
 			return err
 		} else if err = cb(msgFilter.Message); err != nil {
+			if errors.Is(err, quickfix.ErrStopIteration) {
+				return nil
+			}
 			return err
 		}
 	}
@@ -375,11 +388,25 @@ func (store *mongoStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, erro
 	var msgs [][]byte
 	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
 		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
 		return nil
 	})
 	return msgs, err
 }
 
+// HealthCheck pings the database.
+func (store *mongoStore) HealthCheck() error {
+	if store.db == nil {
+		return errors.New("store closed")
+	}
+	if err := store.db.Ping(context.Background(), nil); err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	return nil
+}
+
 // Close closes the store's database connection.
 func (store *mongoStore) Close() error {
 	if store.db != nil {
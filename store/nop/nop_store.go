@@ -0,0 +1,149 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package nop provides message stores that persist nothing, for use in tests.
+package nop
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+type nopStoreFactory struct {
+	strict bool
+}
+
+type nopStore struct {
+	creationTime time.Time
+	strict       bool
+}
+
+// NewStoreFactory returns a MessageStoreFactory creating stores as returned by NewStore.
+func NewStoreFactory() quickfix.MessageStoreFactory {
+	return nopStoreFactory{}
+}
+
+// NewStrictStoreFactory returns a MessageStoreFactory creating stores as returned by NewStrictStore.
+func NewStrictStoreFactory() quickfix.MessageStoreFactory {
+	return nopStoreFactory{strict: true}
+}
+
+// Create creates a new nop store.
+func (f nopStoreFactory) Create(_ quickfix.SessionID) (quickfix.MessageStore, error) {
+	return &nopStore{creationTime: time.Now(), strict: f.strict}, nil
+}
+
+// NewStore returns a MessageStore that discards every message. Its seqnums stay at 1 and it never
+// returns any messages.
+func NewStore() quickfix.MessageStore {
+	return &nopStore{creationTime: time.Now()}
+}
+
+// NewStrictStore returns a store like NewStore, except that it panics if a message is saved or
+// retrieved. Use it to verify that a code path never touches the stored messages.
+func NewStrictStore() quickfix.MessageStore {
+	return &nopStore{creationTime: time.Now(), strict: true}
+}
+
+func (store *nopStore) unexpected(call string) {
+	if store.strict {
+		panic(fmt.Sprintf("nop store: unexpected call to %s", call))
+	}
+}
+
+// NextSenderMsgSeqNum always returns 1.
+func (store *nopStore) NextSenderMsgSeqNum() int {
+	return 1
+}
+
+// NextTargetMsgSeqNum always returns 1.
+func (store *nopStore) NextTargetMsgSeqNum() int {
+	return 1
+}
+
+// IncrNextSenderMsgSeqNum is a no-op.
+func (store *nopStore) IncrNextSenderMsgSeqNum() error {
+	return nil
+}
+
+// IncrNextTargetMsgSeqNum is a no-op.
+func (store *nopStore) IncrNextTargetMsgSeqNum() error {
+	return nil
+}
+
+// SetNextSenderMsgSeqNum is a no-op.
+func (store *nopStore) SetNextSenderMsgSeqNum(_ int) error {
+	return nil
+}
+
+// SetNextTargetMsgSeqNum is a no-op.
+func (store *nopStore) SetNextTargetMsgSeqNum(_ int) error {
+	return nil
+}
+
+// CreationTime returns the time the store was created.
+func (store *nopStore) CreationTime() time.Time {
+	return store.creationTime
+}
+
+// SetCreationTime is a no-op.
+func (store *nopStore) SetCreationTime(_ time.Time) {
+}
+
+// SaveMessage discards the message. The strict store panics.
+func (store *nopStore) SaveMessage(_ int, _ []byte) error {
+	store.unexpected("SaveMessage")
+	return nil
+}
+
+// SaveMessageAndIncrNextSenderMsgSeqNum discards the message. The strict store panics.
+func (store *nopStore) SaveMessageAndIncrNextSenderMsgSeqNum(_ int, _ []byte) error {
+	store.unexpected("SaveMessageAndIncrNextSenderMsgSeqNum")
+	return nil
+}
+
+// GetMessages returns no messages. The strict store panics.
+func (store *nopStore) GetMessages(_, _ int) ([][]byte, error) {
+	store.unexpected("GetMessages")
+	return nil, nil
+}
+
+// IterateMessages never calls cb. The strict store panics.
+func (store *nopStore) IterateMessages(_, _ int, _ func([]byte) error) error {
+	store.unexpected("IterateMessages")
+	return nil
+}
+
+// HealthCheck always returns nil.
+func (store *nopStore) HealthCheck() error {
+	return nil
+}
+
+// Refresh is a no-op.
+func (store *nopStore) Refresh() error {
+	return nil
+}
+
+// Reset is a no-op.
+func (store *nopStore) Reset() error {
+	return nil
+}
+
+// Close is a no-op.
+func (store *nopStore) Close() error {
+	return nil
+}
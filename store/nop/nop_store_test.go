@@ -0,0 +1,64 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package nop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+func TestNopStore(t *testing.T) {
+	store := NewStore()
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(1, []byte("hello")))
+	require.Nil(t, store.IncrNextTargetMsgSeqNum())
+	require.Nil(t, store.SetNextSenderMsgSeqNum(10))
+	assert.Equal(t, 1, store.NextSenderMsgSeqNum())
+	assert.Equal(t, 1, store.NextTargetMsgSeqNum())
+	assert.False(t, store.CreationTime().IsZero())
+
+	msgs, err := store.GetMessages(1, 10)
+	require.Nil(t, err)
+	assert.Empty(t, msgs)
+	require.Nil(t, store.IterateMessages(1, 10, func([]byte) error {
+		t.Fatal("no message is stored")
+		return nil
+	}))
+}
+
+func TestStrictStore(t *testing.T) {
+	store := NewStrictStore()
+	require.Nil(t, store.IncrNextSenderMsgSeqNum())
+	require.Nil(t, store.Reset())
+
+	assert.Panics(t, func() { _ = store.SaveMessage(1, []byte("hello")) })
+	assert.Panics(t, func() { _ = store.SaveMessageAndIncrNextSenderMsgSeqNum(1, []byte("hello")) })
+	assert.Panics(t, func() { _, _ = store.GetMessages(1, 1) })
+	assert.Panics(t, func() { _ = store.IterateMessages(1, 1, func([]byte) error { return nil }) })
+}
+
+func TestStoreFactory(t *testing.T) {
+	store, err := NewStrictStoreFactory().Create(quickfix.SessionID{})
+	require.Nil(t, err)
+	assert.Panics(t, func() { _ = store.SaveMessage(1, nil) })
+
+	store, err = NewStoreFactory().Create(quickfix.SessionID{})
+	require.Nil(t, err)
+	assert.NotPanics(t, func() { _ = store.SaveMessage(1, nil) })
+}
@@ -0,0 +1,374 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	_ "github.com/jackc/pgx/v5/stdlib" // registers the pgx database/sql driver.
+	"github.com/pkg/errors"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+const (
+	defaultSchemaName = "public"
+
+	// fetchSize is the number of rows fetched per round trip when iterating messages.
+	fetchSize = 100
+)
+
+// schemaDDL creates the store's schema and tables. The {schema} placeholder is replaced with the quoted schema name.
+var schemaDDL = []string{
+	`CREATE SCHEMA IF NOT EXISTS {schema}`,
+	`CREATE TABLE IF NOT EXISTS {schema}.fix_sessions (
+		session_id    TEXT        NOT NULL PRIMARY KEY,
+		creation_time TIMESTAMPTZ NOT NULL,
+		next_sender   INTEGER     NOT NULL,
+		next_target   INTEGER     NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS {schema}.fix_messages (
+		session_id TEXT        NOT NULL,
+		seq_num    INTEGER     NOT NULL,
+		sent_at    TIMESTAMPTZ NOT NULL DEFAULT now(),
+		body       BYTEA       NOT NULL,
+		PRIMARY KEY (session_id, seq_num)
+	)`,
+}
+
+type postgresStoreFactory struct {
+	settings *quickfix.Settings
+}
+
+type postgresStore struct {
+	sessionID quickfix.SessionID
+	cache     quickfix.MessageStore
+	db        *sql.DB
+	key       string
+	schema    string
+}
+
+// NewStoreFactory returns a postgres-based implementation of MessageStoreFactory.
+func NewStoreFactory(settings *quickfix.Settings) quickfix.MessageStoreFactory {
+	return postgresStoreFactory{settings: settings}
+}
+
+// Create creates a new PostgresStore implementation of the MessageStore interface.
+func (f postgresStoreFactory) Create(sessionID quickfix.SessionID) (msgStore quickfix.MessageStore, err error) {
+	globalSettings := f.settings.GlobalSettings()
+	dynamicSessions, _ := globalSettings.BoolSetting(config.DynamicSessions)
+
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		if dynamicSessions {
+			sessionSettings = globalSettings
+		} else {
+			return nil, fmt.Errorf("unknown session: %v", sessionID)
+		}
+	}
+
+	dsn, err := sessionSettings.Setting(config.PostgresDSN)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaName := defaultSchemaName
+	if sessionSettings.HasSetting(config.PostgresSchemaName) {
+		if schemaName, err = sessionSettings.Setting(config.PostgresSchemaName); err != nil {
+			return nil, err
+		}
+	}
+
+	return newPostgresStore(sessionID, dsn, schemaName)
+}
+
+func newPostgresStore(sessionID quickfix.SessionID, dsn, schemaName string) (store *postgresStore, err error) {
+	memStore, memErr := quickfix.NewMemoryStoreFactory().Create(sessionID)
+	if memErr != nil {
+		err = errors.Wrap(memErr, "cache creation")
+		return
+	}
+
+	store = &postgresStore{
+		sessionID: sessionID,
+		cache:     memStore,
+		key:       sessionID.String(),
+		schema:    pgx.Identifier{schemaName}.Sanitize(),
+	}
+
+	if store.db, err = sql.Open("pgx", dsn); err != nil {
+		return nil, err
+	}
+	if err = store.db.Ping(); err != nil { // ensure immediate connection
+		_ = store.db.Close()
+		return nil, err
+	}
+
+	for _, ddl := range schemaDDL {
+		if _, err = store.db.Exec(store.sqlString(ddl)); err != nil {
+			_ = store.db.Close()
+			return nil, errors.Wrap(err, "create schema")
+		}
+	}
+
+	if err = store.Refresh(); err != nil {
+		_ = store.db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// sqlString qualifies the table names in the query with the store's schema.
+func (store *postgresStore) sqlString(raw string) string {
+	return strings.ReplaceAll(raw, "{schema}", store.schema)
+}
+
+// Reset deletes the store records and sets the seqnums back to 1.
+func (store *postgresStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(store.sqlString(`DELETE FROM {schema}.fix_messages WHERE session_id=$1`), store.key); err != nil {
+		return err
+	}
+	_, err = tx.Exec(store.sqlString(`UPDATE {schema}.fix_sessions
+		SET creation_time=$1, next_sender=$2, next_target=$3
+		WHERE session_id=$4`),
+		store.cache.CreationTime(), store.cache.NextSenderMsgSeqNum(), store.cache.NextTargetMsgSeqNum(), store.key)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Refresh reloads the store from the database.
+func (store *postgresStore) Refresh() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+	return store.populateCache()
+}
+
+func (store *postgresStore) populateCache() error {
+	// Create the session record unless another store has already done so.
+	_, err := store.db.Exec(store.sqlString(`INSERT INTO {schema}.fix_sessions
+		(session_id, creation_time, next_sender, next_target)
+		VALUES($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING`),
+		store.key, store.cache.CreationTime(), store.cache.NextSenderMsgSeqNum(), store.cache.NextTargetMsgSeqNum())
+	if err != nil {
+		return errors.Wrap(err, "insert")
+	}
+
+	var creationTime time.Time
+	var nextSender, nextTarget int
+	row := store.db.QueryRow(store.sqlString(`SELECT creation_time, next_sender, next_target
+		FROM {schema}.fix_sessions WHERE session_id=$1`), store.key)
+	if err := row.Scan(&creationTime, &nextSender, &nextTarget); err != nil {
+		return errors.Wrap(err, "query")
+	}
+
+	store.cache.SetCreationTime(creationTime)
+	if err := store.cache.SetNextSenderMsgSeqNum(nextSender); err != nil {
+		return errors.Wrap(err, "cache set next sender")
+	}
+	if err := store.cache.SetNextTargetMsgSeqNum(nextTarget); err != nil {
+		return errors.Wrap(err, "cache set next target")
+	}
+	return nil
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent.
+func (store *postgresStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received.
+func (store *postgresStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent.
+func (store *postgresStore) SetNextSenderMsgSeqNum(next int) error {
+	_, err := store.db.Exec(store.sqlString(`UPDATE {schema}.fix_sessions SET next_sender=$1 WHERE session_id=$2`), next, store.key)
+	if err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received.
+func (store *postgresStore) SetNextTargetMsgSeqNum(next int) error {
+	_, err := store.db.Exec(store.sqlString(`UPDATE {schema}.fix_sessions SET next_target=$1 WHERE session_id=$2`), next, store.key)
+	if err != nil {
+		return err
+	}
+	return store.cache.SetNextTargetMsgSeqNum(next)
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
+func (store *postgresStore) IncrNextSenderMsgSeqNum() error {
+	if err := store.SetNextSenderMsgSeqNum(store.cache.NextSenderMsgSeqNum() + 1); err != nil {
+		return errors.Wrap(err, "store next")
+	}
+	return nil
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
+func (store *postgresStore) IncrNextTargetMsgSeqNum() error {
+	if err := store.SetNextTargetMsgSeqNum(store.cache.NextTargetMsgSeqNum() + 1); err != nil {
+		return errors.Wrap(err, "store next")
+	}
+	return nil
+}
+
+// CreationTime returns the creation time of the store.
+func (store *postgresStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime is a no-op for PostgresStore.
+func (store *postgresStore) SetCreationTime(_ time.Time) {
+}
+
+const insertMessage = `INSERT INTO {schema}.fix_messages (session_id, seq_num, body)
+	VALUES($1, $2, $3)
+	ON CONFLICT DO NOTHING`
+
+// SaveMessage stores the message. A message already stored under the same seqnum is left untouched.
+func (store *postgresStore) SaveMessage(seqNum int, msg []byte) error {
+	_, err := store.db.Exec(store.sqlString(insertMessage), store.key, seqNum, msg)
+	return err
+}
+
+func (store *postgresStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(store.sqlString(insertMessage), store.key, seqNum, msg); err != nil {
+		return err
+	}
+
+	next := store.cache.NextSenderMsgSeqNum() + 1
+	if _, err = tx.Exec(store.sqlString(`UPDATE {schema}.fix_sessions SET next_sender=$1 WHERE session_id=$2`), next, store.key); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// IterateMessages streams the messages through a server-side cursor, so that only fetchSize
+// messages are held in memory at any time.
+func (store *postgresStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	_, err = tx.Exec(store.sqlString(`DECLARE fix_messages_cursor NO SCROLL CURSOR FOR
+		SELECT body FROM {schema}.fix_messages
+		WHERE session_id=$1 AND seq_num>=$2 AND seq_num<=$3
+		ORDER BY seq_num`), store.key, beginSeqNum, endSeqNum)
+	if err != nil {
+		return err
+	}
+
+	fetch := fmt.Sprintf("FETCH %d FROM fix_messages_cursor", fetchSize)
+	for {
+		rows, err := tx.Query(fetch)
+		if err != nil {
+			return err
+		}
+
+		fetched := 0
+		for rows.Next() {
+			fetched++
+			var msg []byte
+			if err = rows.Scan(&msg); err != nil {
+				_ = rows.Close()
+				return err
+			} else if err = cb(msg); err != nil {
+				_ = rows.Close()
+				if errors.Is(err, quickfix.ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+		}
+		if err = rows.Err(); err != nil {
+			return err
+		}
+		if fetched < fetchSize {
+			break
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (store *postgresStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// HealthCheck runs a trivial query against the database.
+func (store *postgresStore) HealthCheck() error {
+	if store.db == nil {
+		return errors.New("store closed")
+	}
+	var one int
+	if err := store.db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	return nil
+}
+
+// Close closes the store's database connection.
+func (store *postgresStore) Close() error {
+	if store.db != nil {
+		if err := store.db.Close(); err != nil {
+			return errors.Wrap(err, "error disconnecting from database")
+		}
+		store.db = nil
+	}
+	return nil
+}
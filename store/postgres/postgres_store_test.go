@@ -0,0 +1,80 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package postgres
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/internal/testsuite"
+)
+
+// PostgresStoreTestSuite runs all tests in the message.StoreTestSuite against the PostgresStore implementation.
+type PostgresStoreTestSuite struct {
+	testsuite.StoreTestSuite
+}
+
+func (suite *PostgresStoreTestSuite) SetupTest() {
+	postgresDSN := os.Getenv("POSTGRES_TEST_CXN")
+	if len(postgresDSN) <= 0 {
+		log.Println("POSTGRES_TEST_CXN environment arg is not provided, skipping...")
+		suite.T().SkipNow()
+	}
+
+	// create settings
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+PostgresDSN=%s
+PostgresSchemaName=automated_testing
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, postgresDSN, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	// create store
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+	err = suite.MsgStore.Reset()
+	require.Nil(suite.T(), err)
+}
+
+func (suite *PostgresStoreTestSuite) TearDownTest() {
+	if suite.MsgStore != nil {
+		err := suite.MsgStore.Close()
+		require.Nil(suite.T(), err)
+	}
+}
+
+func TestPostgresStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(PostgresStoreTestSuite))
+}
+
+func TestSQLStringQualifiesSchema(t *testing.T) {
+	store := &postgresStore{schema: pgx.Identifier{`my"schema`}.Sanitize()}
+	assert.Equal(t, `SELECT 1 FROM "my""schema".fix_sessions`, store.sqlString(`SELECT 1 FROM {schema}.fix_sessions`))
+}
@@ -0,0 +1,401 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package redis
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+const (
+	fieldNextSenderSeq = "next_sender_seq_num"
+	fieldNextTargetSeq = "next_target_seq_num"
+
+	// iterateBatchSize bounds the number of messages fetched per round trip by IterateMessages.
+	iterateBatchSize = 100
+)
+
+// initSession creates the session record if another store has not already done so.
+var initSession = goredis.NewScript(`
+redis.call('HSETNX', KEYS[1], 'creation_time', ARGV[1])
+redis.call('HSETNX', KEYS[1], 'next_sender_seq_num', 1)
+redis.call('HSETNX', KEYS[1], 'next_target_seq_num', 1)
+return redis.call('HMGET', KEYS[1], 'creation_time', 'next_sender_seq_num', 'next_target_seq_num')
+`)
+
+// saveMessage replaces any message already stored under the seqnum.
+var saveMessage = goredis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], ARGV[1], ARGV[1])
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`)
+
+// saveMessageAndIncr saves a message and increments the next sender seqnum in a single step.
+var saveMessageAndIncr = goredis.NewScript(`
+redis.call('ZREMRANGEBYSCORE', KEYS[1], ARGV[1], ARGV[1])
+redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+return redis.call('HINCRBY', KEYS[2], 'next_sender_seq_num', 1)
+`)
+
+// setSeqNum sets a seqnum field to ARGV[3] only if it still holds ARGV[2], the value the store last saw. It
+// returns 1 and the new value if it was set, 0 and the value the field holds otherwise. A missing session
+// record is created.
+var setSeqNum = goredis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if current == false or current == ARGV[2] then
+	redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+	return {1, tonumber(ARGV[3])}
+end
+return {0, tonumber(current)}
+`)
+
+// resetSession drops all messages and resets the session record.
+var resetSession = goredis.NewScript(`
+redis.call('DEL', KEYS[1])
+redis.call('HSET', KEYS[2], 'creation_time', ARGV[1], 'next_sender_seq_num', 1, 'next_target_seq_num', 1)
+return 1
+`)
+
+// ErrConcurrentUpdate is returned when a seqnum is set while another store sharing the session has changed it
+// since this store last saw it. The store then holds the current seqnum, so the update can be retried.
+var ErrConcurrentUpdate = errors.New("sequence number updated concurrently")
+
+type redisStoreFactory struct {
+	settings *quickfix.Settings
+}
+
+type redisStore struct {
+	sessionID   quickfix.SessionID
+	cache       quickfix.MessageStore
+	client      *goredis.Client
+	sessionKey  string
+	messagesKey string
+}
+
+// NewStoreFactory returns a redis-based implementation of MessageStoreFactory.
+func NewStoreFactory(settings *quickfix.Settings) quickfix.MessageStoreFactory {
+	return redisStoreFactory{settings: settings}
+}
+
+// Create creates a new RedisStore implementation of the MessageStore interface.
+func (f redisStoreFactory) Create(sessionID quickfix.SessionID) (msgStore quickfix.MessageStore, err error) {
+	globalSettings := f.settings.GlobalSettings()
+	dynamicSessions, _ := globalSettings.BoolSetting(config.DynamicSessions)
+
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		if dynamicSessions {
+			sessionSettings = globalSettings
+		} else {
+			return nil, fmt.Errorf("unknown session: %v", sessionID)
+		}
+	}
+
+	redisURL, err := sessionSettings.Setting(config.RedisURL)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := goredis.ParseURL(redisURL)
+	if err != nil {
+		return nil, quickfix.IncorrectFormatForSetting{Setting: config.RedisURL, Value: []byte(redisURL), Err: err}
+	}
+
+	// Optional.
+	if sessionSettings.HasSetting(config.RedisPassword) {
+		if opts.Password, err = sessionSettings.Setting(config.RedisPassword); err != nil {
+			return nil, err
+		}
+	}
+
+	return newRedisStore(sessionID, goredis.NewClient(opts))
+}
+
+func newRedisStore(sessionID quickfix.SessionID, client *goredis.Client) (store *redisStore, err error) {
+	memStore, memErr := quickfix.NewMemoryStoreFactory().Create(sessionID)
+	if memErr != nil {
+		err = errors.Wrap(memErr, "cache creation")
+		return
+	}
+
+	namespace := "quickfix:" + sessionID.String()
+	store = &redisStore{
+		sessionID:   sessionID,
+		cache:       memStore,
+		client:      client,
+		sessionKey:  namespace + ":session",
+		messagesKey: namespace + ":messages",
+	}
+
+	if err = store.Refresh(); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reset deletes the store records and sets the seqnums back to 1.
+func (store *redisStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	creationTime, err := store.cache.CreationTime().MarshalText()
+	if err != nil {
+		return err
+	}
+	keys := []string{store.messagesKey, store.sessionKey}
+	if err := resetSession.Run(context.Background(), store.client, keys, creationTime).Err(); err != nil {
+		return errors.Wrap(err, "reset")
+	}
+	return nil
+}
+
+// Refresh reloads the store from redis.
+func (store *redisStore) Refresh() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+	return store.populateCache()
+}
+
+func (store *redisStore) populateCache() error {
+	creationTime, err := store.cache.CreationTime().MarshalText()
+	if err != nil {
+		return err
+	}
+	vals, err := initSession.Run(context.Background(), store.client, []string{store.sessionKey}, creationTime).StringSlice()
+	if err != nil {
+		return errors.Wrap(err, "query")
+	}
+	if len(vals) != 3 {
+		return fmt.Errorf("unexpected session record: %v", vals)
+	}
+
+	var ctime time.Time
+	if err := ctime.UnmarshalText([]byte(vals[0])); err != nil {
+		return errors.Wrap(err, "decode creation time")
+	}
+	store.cache.SetCreationTime(ctime)
+
+	nextSender, err := strconv.Atoi(vals[1])
+	if err != nil {
+		return errors.Wrap(err, "decode next sender")
+	}
+	if err := store.cache.SetNextSenderMsgSeqNum(nextSender); err != nil {
+		return errors.Wrap(err, "cache set next sender")
+	}
+
+	nextTarget, err := strconv.Atoi(vals[2])
+	if err != nil {
+		return errors.Wrap(err, "decode next target")
+	}
+	if err := store.cache.SetNextTargetMsgSeqNum(nextTarget); err != nil {
+		return errors.Wrap(err, "cache set next target")
+	}
+	return nil
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent.
+func (store *redisStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received.
+func (store *redisStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent. It fails with ErrConcurrentUpdate if another
+// store sharing the session changed it since this store last saw it.
+func (store *redisStore) SetNextSenderMsgSeqNum(next int) error {
+	current, ok, err := store.setSeqNum(fieldNextSenderSeq, store.cache.NextSenderMsgSeqNum(), next)
+	if err != nil {
+		return err
+	}
+	if err := store.cache.SetNextSenderMsgSeqNum(current); err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Wrapf(ErrConcurrentUpdate, "next sender seqnum is %d", current)
+	}
+	return nil
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received. It fails with ErrConcurrentUpdate if
+// another store sharing the session changed it since this store last saw it.
+func (store *redisStore) SetNextTargetMsgSeqNum(next int) error {
+	current, ok, err := store.setSeqNum(fieldNextTargetSeq, store.cache.NextTargetMsgSeqNum(), next)
+	if err != nil {
+		return err
+	}
+	if err := store.cache.SetNextTargetMsgSeqNum(current); err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Wrapf(ErrConcurrentUpdate, "next target seqnum is %d", current)
+	}
+	return nil
+}
+
+// setSeqNum sets the seqnum field to next if it still holds expected. It returns the value the field holds
+// afterwards, and whether it was set.
+func (store *redisStore) setSeqNum(field string, expected, next int) (int, bool, error) {
+	res, err := setSeqNum.Run(context.Background(), store.client, []string{store.sessionKey}, field, expected, next).Int64Slice()
+	if err != nil {
+		return 0, false, errors.Wrap(err, "save sequence number")
+	}
+	if len(res) != 2 {
+		return 0, false, fmt.Errorf("unexpected reply setting %s: %v", field, res)
+	}
+	return int(res[1]), res[0] == 1, nil
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
+// The increment is performed by redis so that concurrent stores sharing the session never lose an update.
+func (store *redisStore) IncrNextSenderMsgSeqNum() error {
+	next, err := store.client.HIncrBy(context.Background(), store.sessionKey, fieldNextSenderSeq, 1).Result()
+	if err != nil {
+		return errors.Wrap(err, "save sequence number")
+	}
+	return store.cache.SetNextSenderMsgSeqNum(int(next))
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
+// The increment is performed by redis so that concurrent stores sharing the session never lose an update.
+func (store *redisStore) IncrNextTargetMsgSeqNum() error {
+	next, err := store.client.HIncrBy(context.Background(), store.sessionKey, fieldNextTargetSeq, 1).Result()
+	if err != nil {
+		return errors.Wrap(err, "save sequence number")
+	}
+	return store.cache.SetNextTargetMsgSeqNum(int(next))
+}
+
+// CreationTime returns the creation time of the store.
+func (store *redisStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime is a no-op for RedisStore.
+func (store *redisStore) SetCreationTime(_ time.Time) {
+}
+
+// encodeMember prefixes the message with its seqnum so that identical messages remain distinct
+// members of the sorted set.
+func encodeMember(seqNum int, msg []byte) []byte {
+	member := make([]byte, 0, len(msg)+20)
+	member = strconv.AppendInt(member, int64(seqNum), 10)
+	member = append(member, ':')
+	return append(member, msg...)
+}
+
+func decodeMember(member string) ([]byte, error) {
+	i := strings.IndexByte(member, ':')
+	if i < 0 {
+		return nil, fmt.Errorf("malformed message record: %q", member)
+	}
+	return []byte(member[i+1:]), nil
+}
+
+func (store *redisStore) SaveMessage(seqNum int, msg []byte) error {
+	return saveMessage.Run(context.Background(), store.client, []string{store.messagesKey}, seqNum, encodeMember(seqNum, msg)).Err()
+}
+
+func (store *redisStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	keys := []string{store.messagesKey, store.sessionKey}
+	next, err := saveMessageAndIncr.Run(context.Background(), store.client, keys, seqNum, encodeMember(seqNum, msg)).Int()
+	if err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+func (store *redisStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	maxScore := strconv.Itoa(endSeqNum)
+	for next := beginSeqNum; next <= endSeqNum; {
+		members, err := store.client.ZRangeByScoreWithScores(context.Background(), store.messagesKey, &goredis.ZRangeBy{
+			Min:   strconv.Itoa(next),
+			Max:   maxScore,
+			Count: iterateBatchSize,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		for _, z := range members {
+			member, ok := z.Member.(string)
+			if !ok {
+				return fmt.Errorf("unexpected message record type: %T", z.Member)
+			}
+			msg, err := decodeMember(member)
+			if err != nil {
+				return err
+			}
+			if err = cb(msg); err != nil {
+				if errors.Is(err, quickfix.ErrStopIteration) {
+					return nil
+				}
+				return err
+			}
+			next = int(z.Score) + 1
+		}
+		if len(members) < iterateBatchSize {
+			break
+		}
+	}
+	return nil
+}
+
+func (store *redisStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// HealthCheck pings the redis server.
+func (store *redisStore) HealthCheck() error {
+	if store.client == nil {
+		return errors.New("store closed")
+	}
+	if err := store.client.Ping(context.Background()).Err(); err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	return nil
+}
+
+// Close closes the store's redis connection.
+func (store *redisStore) Close() error {
+	if store.client != nil {
+		if err := store.client.Close(); err != nil {
+			return errors.Wrap(err, "error disconnecting from redis")
+		}
+		store.client = nil
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package redis
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/internal/testsuite"
+)
+
+// RedisStoreTestSuite runs all tests in the message.StoreTestSuite against the RedisStore implementation.
+type RedisStoreTestSuite struct {
+	testsuite.StoreTestSuite
+	server    *miniredis.Miniredis
+	sessionID quickfix.SessionID
+	settings  *quickfix.Settings
+}
+
+func (suite *RedisStoreTestSuite) SetupTest() {
+	suite.server = miniredis.RunT(suite.T())
+	suite.sessionID = quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+
+	// create settings
+	var err error
+	suite.settings, err = quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+RedisURL=redis://%s/0
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, suite.server.Addr(), suite.sessionID.BeginString, suite.sessionID.SenderCompID, suite.sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	// create store
+	suite.MsgStore, err = NewStoreFactory(suite.settings).Create(suite.sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *RedisStoreTestSuite) TearDownTest() {
+	if suite.MsgStore != nil {
+		suite.Require().Nil(suite.MsgStore.Close())
+	}
+}
+
+func (suite *RedisStoreTestSuite) TestSharedSequenceNumbers() {
+	other, err := NewStoreFactory(suite.settings).Create(suite.sessionID)
+	suite.Require().Nil(err)
+	defer other.Close()
+
+	suite.Require().Nil(suite.MsgStore.IncrNextSenderMsgSeqNum())
+	suite.Require().Nil(other.IncrNextSenderMsgSeqNum())
+	suite.Require().Nil(suite.MsgStore.SaveMessageAndIncrNextSenderMsgSeqNum(3, []byte("msg3")))
+
+	suite.Equal(4, suite.MsgStore.NextSenderMsgSeqNum())
+	suite.Require().Nil(other.Refresh())
+	suite.Equal(4, other.NextSenderMsgSeqNum())
+	suite.Equal(suite.MsgStore.CreationTime(), other.CreationTime())
+
+	msgs, err := other.GetMessages(1, 3)
+	suite.Require().Nil(err)
+	suite.Equal([][]byte{[]byte("msg3")}, msgs)
+}
+
+func (suite *RedisStoreTestSuite) TestSetSeqNumConflict() {
+	other, err := NewStoreFactory(suite.settings).Create(suite.sessionID)
+	suite.Require().Nil(err)
+	defer other.Close()
+
+	suite.Require().Nil(other.IncrNextSenderMsgSeqNum())
+
+	// The store has not seen the increment, so setting the seqnum would lose it.
+	err = suite.MsgStore.SetNextSenderMsgSeqNum(10)
+	suite.True(errors.Is(err, ErrConcurrentUpdate))
+	suite.Equal(2, suite.MsgStore.NextSenderMsgSeqNum())
+	suite.Require().Nil(other.Refresh())
+	suite.Equal(2, other.NextSenderMsgSeqNum())
+
+	// Once up to date the store can set it.
+	suite.Require().Nil(suite.MsgStore.SetNextSenderMsgSeqNum(10))
+	suite.Require().Nil(other.Refresh())
+	suite.Equal(10, other.NextSenderMsgSeqNum())
+}
+
+func (suite *RedisStoreTestSuite) TestSetSeqNumConcurrently() {
+	const stores, updates = 4, 25
+
+	var wg sync.WaitGroup
+	errs := make(chan error, stores)
+	for i := 0; i < stores; i++ {
+		store, err := NewStoreFactory(suite.settings).Create(suite.sessionID)
+		suite.Require().Nil(err)
+		defer store.Close()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < updates; {
+				err := store.SetNextTargetMsgSeqNum(store.NextTargetMsgSeqNum() + 1)
+				switch {
+				case err == nil:
+					n++
+				case !errors.Is(err, ErrConcurrentUpdate):
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		suite.Require().Nil(err)
+	}
+
+	// No update was lost.
+	suite.Require().Nil(suite.MsgStore.Refresh())
+	suite.Equal(1+stores*updates, suite.MsgStore.NextTargetMsgSeqNum())
+}
+
+func (suite *RedisStoreTestSuite) TestIterateMessagesAcrossBatches() {
+	for seqNum := 1; seqNum <= 2*iterateBatchSize+1; seqNum++ {
+		suite.Require().Nil(suite.MsgStore.SaveMessage(seqNum, []byte("same")))
+	}
+
+	msgs, err := suite.MsgStore.GetMessages(2, 2*iterateBatchSize+5)
+	suite.Require().Nil(err)
+	suite.Len(msgs, 2*iterateBatchSize)
+}
+
+func TestRedisStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(RedisStoreTestSuite))
+}
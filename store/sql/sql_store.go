@@ -374,6 +374,9 @@ func (store *sqlStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byt
 		if err = rows.Scan(&message); err != nil {
 			return err
 		} else if err = cb([]byte(message)); err != nil {
+			if errors.Is(err, quickfix.ErrStopIteration) {
+				return nil
+			}
 			return err
 		}
 	}
@@ -385,11 +388,26 @@ func (store *sqlStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error)
 	var msgs [][]byte
 	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
 		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
 		return nil
 	})
 	return msgs, err
 }
 
+// HealthCheck runs a trivial query against the database.
+func (store *sqlStore) HealthCheck() error {
+	if store.db == nil {
+		return errors.New("store closed")
+	}
+	var one int
+	if err := store.db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	return nil
+}
+
 // Close closes the store's database connection.
 func (store *sqlStore) Close() error {
 	if store.db != nil {
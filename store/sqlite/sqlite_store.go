@@ -0,0 +1,341 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	_ "modernc.org/sqlite" // registers the pure-Go sqlite database/sql driver.
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/config"
+)
+
+// pragmas are applied to every connection opened by the store. WAL mode lets IterateMessages read
+// while messages are being saved, and the busy timeout makes concurrent writers wait for the lock
+// rather than fail.
+var pragmas = []string{
+	"journal_mode(WAL)",
+	"synchronous(FULL)",
+	"busy_timeout(5000)",
+}
+
+// schemaDDL creates the store's tables, mirroring the postgres store's schema.
+var schemaDDL = []string{
+	`CREATE TABLE IF NOT EXISTS fix_sessions (
+		session_id    TEXT     NOT NULL PRIMARY KEY,
+		creation_time DATETIME NOT NULL,
+		next_sender   INTEGER  NOT NULL,
+		next_target   INTEGER  NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS fix_messages (
+		session_id TEXT     NOT NULL,
+		seq_num    INTEGER  NOT NULL,
+		sent_at    DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		body       BLOB     NOT NULL,
+		PRIMARY KEY (session_id, seq_num)
+	)`,
+}
+
+type sqliteStoreFactory struct {
+	settings *quickfix.Settings
+}
+
+type sqliteStore struct {
+	sessionID quickfix.SessionID
+	cache     quickfix.MessageStore
+	db        *sql.DB
+	key       string
+}
+
+// NewStoreFactory returns a sqlite-based implementation of MessageStoreFactory.
+func NewStoreFactory(settings *quickfix.Settings) quickfix.MessageStoreFactory {
+	return sqliteStoreFactory{settings: settings}
+}
+
+// Create creates a new SQLiteStore implementation of the MessageStore interface.
+func (f sqliteStoreFactory) Create(sessionID quickfix.SessionID) (msgStore quickfix.MessageStore, err error) {
+	globalSettings := f.settings.GlobalSettings()
+	dynamicSessions, _ := globalSettings.BoolSetting(config.DynamicSessions)
+
+	sessionSettings, ok := f.settings.SessionSettings()[sessionID]
+	if !ok {
+		if dynamicSessions {
+			sessionSettings = globalSettings
+		} else {
+			return nil, fmt.Errorf("unknown session: %v", sessionID)
+		}
+	}
+
+	filename, err := sessionSettings.Setting(config.SQLiteFile)
+	if err != nil {
+		return nil, err
+	}
+
+	return newSQLiteStore(sessionID, filename)
+}
+
+// dataSourceName builds the driver DSN for the database file, applying the store's pragmas.
+// Write transactions take the database lock up front so that they never fail part way through.
+func dataSourceName(filename string) string {
+	query := url.Values{}
+	for _, pragma := range pragmas {
+		query.Add("_pragma", pragma)
+	}
+	query.Set("_txlock", "immediate")
+	return "file:" + filename + "?" + query.Encode()
+}
+
+func newSQLiteStore(sessionID quickfix.SessionID, filename string) (store *sqliteStore, err error) {
+	memStore, memErr := quickfix.NewMemoryStoreFactory().Create(sessionID)
+	if memErr != nil {
+		err = errors.Wrap(memErr, "cache creation")
+		return
+	}
+
+	store = &sqliteStore{
+		sessionID: sessionID,
+		cache:     memStore,
+		key:       sessionID.String(),
+	}
+
+	if store.db, err = sql.Open("sqlite", dataSourceName(filename)); err != nil {
+		return nil, err
+	}
+	if err = store.db.Ping(); err != nil { // ensure the file can be opened
+		_ = store.db.Close()
+		return nil, err
+	}
+
+	for _, ddl := range schemaDDL {
+		if _, err = store.db.Exec(ddl); err != nil {
+			_ = store.db.Close()
+			return nil, errors.Wrap(err, "create schema")
+		}
+	}
+
+	if err = store.Refresh(); err != nil {
+		_ = store.db.Close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// Reset deletes the store records and sets the seqnums back to 1.
+func (store *sqliteStore) Reset() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(`DELETE FROM fix_messages WHERE session_id=?`, store.key); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE fix_sessions
+		SET creation_time=?, next_sender=?, next_target=?
+		WHERE session_id=?`,
+		store.cache.CreationTime(), store.cache.NextSenderMsgSeqNum(), store.cache.NextTargetMsgSeqNum(), store.key)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Refresh reloads the store from the database.
+func (store *sqliteStore) Refresh() error {
+	if err := store.cache.Reset(); err != nil {
+		return errors.Wrap(err, "cache reset")
+	}
+	return store.populateCache()
+}
+
+func (store *sqliteStore) populateCache() error {
+	// Create the session record unless another store has already done so.
+	_, err := store.db.Exec(`INSERT OR IGNORE INTO fix_sessions
+		(session_id, creation_time, next_sender, next_target)
+		VALUES(?, ?, ?, ?)`,
+		store.key, store.cache.CreationTime(), store.cache.NextSenderMsgSeqNum(), store.cache.NextTargetMsgSeqNum())
+	if err != nil {
+		return errors.Wrap(err, "insert")
+	}
+
+	var creationTime time.Time
+	var nextSender, nextTarget int
+	row := store.db.QueryRow(`SELECT creation_time, next_sender, next_target
+		FROM fix_sessions WHERE session_id=?`, store.key)
+	if err := row.Scan(&creationTime, &nextSender, &nextTarget); err != nil {
+		return errors.Wrap(err, "query")
+	}
+
+	store.cache.SetCreationTime(creationTime)
+	if err := store.cache.SetNextSenderMsgSeqNum(nextSender); err != nil {
+		return errors.Wrap(err, "cache set next sender")
+	}
+	if err := store.cache.SetNextTargetMsgSeqNum(nextTarget); err != nil {
+		return errors.Wrap(err, "cache set next target")
+	}
+	return nil
+}
+
+// NextSenderMsgSeqNum returns the next MsgSeqNum that will be sent.
+func (store *sqliteStore) NextSenderMsgSeqNum() int {
+	return store.cache.NextSenderMsgSeqNum()
+}
+
+// NextTargetMsgSeqNum returns the next MsgSeqNum that should be received.
+func (store *sqliteStore) NextTargetMsgSeqNum() int {
+	return store.cache.NextTargetMsgSeqNum()
+}
+
+// SetNextSenderMsgSeqNum sets the next MsgSeqNum that will be sent.
+func (store *sqliteStore) SetNextSenderMsgSeqNum(next int) error {
+	_, err := store.db.Exec(`UPDATE fix_sessions SET next_sender=? WHERE session_id=?`, next, store.key)
+	if err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+// SetNextTargetMsgSeqNum sets the next MsgSeqNum that should be received.
+func (store *sqliteStore) SetNextTargetMsgSeqNum(next int) error {
+	_, err := store.db.Exec(`UPDATE fix_sessions SET next_target=? WHERE session_id=?`, next, store.key)
+	if err != nil {
+		return err
+	}
+	return store.cache.SetNextTargetMsgSeqNum(next)
+}
+
+// IncrNextSenderMsgSeqNum increments the next MsgSeqNum that will be sent.
+func (store *sqliteStore) IncrNextSenderMsgSeqNum() error {
+	if err := store.SetNextSenderMsgSeqNum(store.cache.NextSenderMsgSeqNum() + 1); err != nil {
+		return errors.Wrap(err, "store next")
+	}
+	return nil
+}
+
+// IncrNextTargetMsgSeqNum increments the next MsgSeqNum that should be received.
+func (store *sqliteStore) IncrNextTargetMsgSeqNum() error {
+	if err := store.SetNextTargetMsgSeqNum(store.cache.NextTargetMsgSeqNum() + 1); err != nil {
+		return errors.Wrap(err, "store next")
+	}
+	return nil
+}
+
+// CreationTime returns the creation time of the store.
+func (store *sqliteStore) CreationTime() time.Time {
+	return store.cache.CreationTime()
+}
+
+// SetCreationTime is a no-op for SQLiteStore.
+func (store *sqliteStore) SetCreationTime(_ time.Time) {
+}
+
+const insertMessage = `INSERT OR IGNORE INTO fix_messages (session_id, seq_num, body) VALUES(?, ?, ?)`
+
+// SaveMessage stores the message. A message already stored under the same seqnum is left untouched.
+func (store *sqliteStore) SaveMessage(seqNum int, msg []byte) error {
+	_, err := store.db.Exec(insertMessage, store.key, seqNum, msg)
+	return err
+}
+
+func (store *sqliteStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	tx, err := store.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err = tx.Exec(insertMessage, store.key, seqNum, msg); err != nil {
+		return err
+	}
+
+	next := store.cache.NextSenderMsgSeqNum() + 1
+	if _, err = tx.Exec(`UPDATE fix_sessions SET next_sender=? WHERE session_id=?`, next, store.key); err != nil {
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		return err
+	}
+	return store.cache.SetNextSenderMsgSeqNum(next)
+}
+
+func (store *sqliteStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	rows, err := store.db.Query(`SELECT body FROM fix_messages
+		WHERE session_id=? AND seq_num>=? AND seq_num<=?
+		ORDER BY seq_num`, store.key, beginSeqNum, endSeqNum)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var msg []byte
+		if err = rows.Scan(&msg); err != nil {
+			return err
+		} else if err = cb(msg); err != nil {
+			if errors.Is(err, quickfix.ErrStopIteration) {
+				return nil
+			}
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (store *sqliteStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	var msgs [][]byte
+	err := store.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		msgs = append(msgs, msg)
+		if len(msgs) > endSeqNum-beginSeqNum {
+			return quickfix.ErrStopIteration
+		}
+		return nil
+	})
+	return msgs, err
+}
+
+// HealthCheck runs a trivial query against the database.
+func (store *sqliteStore) HealthCheck() error {
+	if store.db == nil {
+		return errors.New("store closed")
+	}
+	var one int
+	if err := store.db.QueryRow("SELECT 1").Scan(&one); err != nil {
+		return errors.Wrap(err, "health check")
+	}
+	return nil
+}
+
+// Close closes the store's database connection.
+func (store *sqliteStore) Close() error {
+	if store.db != nil {
+		if err := store.db.Close(); err != nil {
+			return errors.Wrap(err, "error disconnecting from database")
+		}
+		store.db = nil
+	}
+	return nil
+}
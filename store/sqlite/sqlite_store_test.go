@@ -0,0 +1,97 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package sqlite
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/quickfixgo/quickfix"
+	"github.com/quickfixgo/quickfix/internal/testsuite"
+)
+
+// SQLiteStoreTestSuite runs all tests in the message.StoreTestSuite against the SQLiteStore implementation.
+type SQLiteStoreTestSuite struct {
+	testsuite.StoreTestSuite
+	sqlStoreRootPath string
+}
+
+func (suite *SQLiteStoreTestSuite) SetupTest() {
+	suite.sqlStoreRootPath = suite.T().TempDir()
+	sqlFile := filepath.Join(suite.sqlStoreRootPath, "quickfix.db")
+
+	// create settings
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	settings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SQLiteFile=%s
+
+[SESSION]
+BeginString=%s
+SenderCompID=%s
+TargetCompID=%s`, sqlFile, sessionID.BeginString, sessionID.SenderCompID, sessionID.TargetCompID)))
+	require.Nil(suite.T(), err)
+
+	// create store
+	suite.MsgStore, err = NewStoreFactory(settings).Create(sessionID)
+	require.Nil(suite.T(), err)
+}
+
+func (suite *SQLiteStoreTestSuite) TearDownTest() {
+	if suite.MsgStore != nil {
+		err := suite.MsgStore.Close()
+		require.Nil(suite.T(), err)
+	}
+}
+
+func TestSQLiteStoreTestSuite(t *testing.T) {
+	suite.Run(t, new(SQLiteStoreTestSuite))
+}
+
+func TestSQLiteStoreReopen(t *testing.T) {
+	sessionID := quickfix.SessionID{BeginString: "FIX.4.4", SenderCompID: "SENDER", TargetCompID: "TARGET"}
+	sqlFile := filepath.Join(t.TempDir(), "quickfix.db")
+
+	store, err := newSQLiteStore(sessionID, sqlFile)
+	require.Nil(t, err)
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(1, []byte("hello")))
+	require.Nil(t, store.IncrNextTargetMsgSeqNum())
+	creationTime := store.CreationTime()
+	require.Nil(t, store.Close())
+
+	store, err = newSQLiteStore(sessionID, sqlFile)
+	require.Nil(t, err)
+	defer func() { _ = store.Close() }()
+
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+	assert.Equal(t, 2, store.NextTargetMsgSeqNum())
+	assert.True(t, creationTime.Equal(store.CreationTime()))
+
+	msgs, err := store.GetMessages(1, 1)
+	require.Nil(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, []byte("hello"), msgs[0])
+
+	var journalMode string
+	require.Nil(t, store.db.QueryRow(`PRAGMA journal_mode`).Scan(&journalMode))
+	assert.Equal(t, "wal", journalMode)
+}
@@ -0,0 +1,116 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+// StoreMiddleware decorates a MessageStore with additional behavior. Middlewares typically embed the
+// MessageStore they are given and override the methods they are interested in.
+type StoreMiddleware func(MessageStore) MessageStore
+
+// WrapStore layers the middlewares on top of inner. The first middleware is the outermost, so it sees
+// every call before the ones that follow it.
+func WrapStore(inner MessageStore, middlewares ...StoreMiddleware) MessageStore {
+	store := inner
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		store = middlewares[i](store)
+	}
+	return store
+}
+
+// StoreUnwrapper is implemented by middleware stores to expose the store they decorate.
+type StoreUnwrapper interface {
+	Unwrap() MessageStore
+}
+
+type wrappedStoreFactory struct {
+	factory     MessageStoreFactory
+	middlewares []StoreMiddleware
+}
+
+// NewWrappedStoreFactory returns a MessageStoreFactory that wraps every store created by factory with
+// the middlewares, see WrapStore.
+func NewWrappedStoreFactory(factory MessageStoreFactory, middlewares ...StoreMiddleware) MessageStoreFactory {
+	return wrappedStoreFactory{factory: factory, middlewares: middlewares}
+}
+
+// Create creates the inner store and wraps it with the middlewares.
+func (f wrappedStoreFactory) Create(sessionID SessionID) (MessageStore, error) {
+	store, err := f.factory.Create(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return WrapStore(store, f.middlewares...), nil
+}
+
+// LoggingMiddleware returns a StoreMiddleware that records every save and retrieval as an event on logger.
+func LoggingMiddleware(logger Log) StoreMiddleware {
+	return func(store MessageStore) MessageStore {
+		return &loggingStore{MessageStore: store, log: logger}
+	}
+}
+
+type loggingStore struct {
+	MessageStore
+	log Log
+}
+
+// Unwrap returns the decorated store.
+func (store *loggingStore) Unwrap() MessageStore {
+	return store.MessageStore
+}
+
+func (store *loggingStore) SaveMessage(seqNum int, msg []byte) error {
+	err := store.MessageStore.SaveMessage(seqNum, msg)
+	store.logSave(seqNum, msg, err)
+	return err
+}
+
+func (store *loggingStore) SaveMessageAndIncrNextSenderMsgSeqNum(seqNum int, msg []byte) error {
+	err := store.MessageStore.SaveMessageAndIncrNextSenderMsgSeqNum(seqNum, msg)
+	store.logSave(seqNum, msg, err)
+	return err
+}
+
+func (store *loggingStore) logSave(seqNum int, msg []byte, err error) {
+	if err != nil {
+		store.log.OnEventf("Store: failed to save message %d: %v", seqNum, err)
+		return
+	}
+	store.log.OnEventf("Store: saved message %d (%d bytes)", seqNum, len(msg))
+}
+
+func (store *loggingStore) GetMessages(beginSeqNum, endSeqNum int) ([][]byte, error) {
+	msgs, err := store.MessageStore.GetMessages(beginSeqNum, endSeqNum)
+	store.logRetrieve(beginSeqNum, endSeqNum, len(msgs), err)
+	return msgs, err
+}
+
+func (store *loggingStore) IterateMessages(beginSeqNum, endSeqNum int, cb func([]byte) error) error {
+	var count int
+	err := store.MessageStore.IterateMessages(beginSeqNum, endSeqNum, func(msg []byte) error {
+		count++
+		return cb(msg)
+	})
+	store.logRetrieve(beginSeqNum, endSeqNum, count, err)
+	return err
+}
+
+func (store *loggingStore) logRetrieve(beginSeqNum, endSeqNum, count int, err error) {
+	if err != nil {
+		store.log.OnEventf("Store: failed to retrieve messages %d-%d: %v", beginSeqNum, endSeqNum, err)
+		return
+	}
+	store.log.OnEventf("Store: retrieved %d messages %d-%d", count, beginSeqNum, endSeqNum)
+}
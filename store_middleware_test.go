@@ -0,0 +1,79 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type eventLog struct {
+	nullLog
+	events []string
+}
+
+func (l *eventLog) OnEvent(s string) {
+	l.events = append(l.events, s)
+}
+
+func (l *eventLog) OnEventf(format string, a ...interface{}) {
+	l.OnEvent(fmt.Sprintf(format, a...))
+}
+
+func TestWrapStoreOrder(t *testing.T) {
+	var calls []string
+	tag := func(name string) StoreMiddleware {
+		return func(store MessageStore) MessageStore {
+			calls = append(calls, name)
+			return store
+		}
+	}
+
+	inner, err := NewMemoryStoreFactory().Create(SessionID{})
+	require.Nil(t, err)
+	assert.Equal(t, inner, WrapStore(inner))
+
+	WrapStore(inner, tag("outer"), tag("inner"))
+	assert.Equal(t, []string{"inner", "outer"}, calls, "middlewares are applied innermost first")
+}
+
+func TestLoggingMiddleware(t *testing.T) {
+	logger := &eventLog{}
+	factory := NewWrappedStoreFactory(NewMemoryStoreFactory(), LoggingMiddleware(logger))
+	store, err := factory.Create(SessionID{})
+	require.Nil(t, err)
+
+	require.Nil(t, store.SaveMessage(1, []byte("hello")))
+	require.Nil(t, store.SaveMessageAndIncrNextSenderMsgSeqNum(2, []byte("world")))
+	assert.Equal(t, 2, store.NextSenderMsgSeqNum())
+	_, err = store.GetMessages(1, 2)
+	require.Nil(t, err)
+	require.Nil(t, store.IterateMessages(2, 5, func([]byte) error { return nil }))
+
+	assert.Equal(t, []string{
+		"Store: saved message 1 (5 bytes)",
+		"Store: saved message 2 (5 bytes)",
+		"Store: retrieved 2 messages 1-2",
+		"Store: retrieved 1 messages 2-5",
+	}, logger.events)
+
+	unwrapper, ok := store.(StoreUnwrapper)
+	require.True(t, ok)
+	assert.IsType(t, &memoryStore{}, unwrapper.Unwrap())
+}
@@ -0,0 +1,53 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// StreamParser reads FIX messages one at a time from an io.Reader, such as a TCP connection or a log file.
+// Input is read incrementally into a buffer of bufSize bytes, grown when a message does not fit, and message
+// boundaries are located via BodyLength (9) and CheckSum (10). Each message is parsed in place, in the buffer it
+// was read into.
+type StreamParser struct {
+	parser *parser
+}
+
+// NewStreamParser returns a StreamParser reading from r. A bufSize of zero or less selects the default buffer size.
+func NewStreamParser(r io.Reader, bufSize int) *StreamParser {
+	return &StreamParser{parser: &parser{reader: r, bufSize: bufSize, retain: true}}
+}
+
+// Next returns the next message in the stream. Bytes preceding a message that cannot start it are skipped. Next
+// returns io.EOF once the stream ends, or io.ErrUnexpectedEOF if it ends within a message.
+func (p *StreamParser) Next() (*Message, error) {
+	raw, err := p.parser.nextMessage()
+	if err != nil {
+		if errors.Is(err, io.EOF) && bytes.Contains(p.parser.buffer, []byte("8=")) {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	msg := NewMessage()
+	if err := ParseMessage(msg, bytes.NewBuffer(raw)); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func streamOfHeartbeats(n int) ([]string, string) {
+	var msgs []string
+	for i := 1; i <= n; i++ {
+		msg := NewMessage()
+		msg.Header.SetString(tagBeginString, "FIX.4.2").SetString(tagMsgType, "0").
+			SetString(tagSenderCompID, "TW").SetString(tagTargetCompID, "ISLD").SetInt(tagMsgSeqNum, i)
+		msgs = append(msgs, msg.String())
+	}
+	return msgs, strings.Join(msgs, "")
+}
+
+func TestStreamParserNext(t *testing.T) {
+	msgs, stream := streamOfHeartbeats(50)
+
+	var tests = []struct {
+		name    string
+		reader  io.Reader
+		bufSize int
+	}{
+		{"default buffer", strings.NewReader(stream), 0},
+		{"small buffer", strings.NewReader(stream), 16},
+		{"one byte reads", iotest.OneByteReader(strings.NewReader(stream)), 32},
+		{"leading garbage", strings.NewReader("\r\ngarbage" + stream), 64},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			p := NewStreamParser(test.reader, test.bufSize)
+
+			var parsed []*Message
+			for {
+				msg, err := p.Next()
+				if err == io.EOF {
+					break
+				}
+				require.Nil(t, err)
+				parsed = append(parsed, msg)
+			}
+
+			// Messages stay valid while the parser reads on.
+			require.Len(t, parsed, len(msgs))
+			for i, msg := range parsed {
+				assert.Equal(t, msgs[i], msg.String())
+				seqNum, err := msg.Header.GetInt(tagMsgSeqNum)
+				require.Nil(t, err)
+				assert.Equal(t, i+1, seqNum)
+			}
+		})
+	}
+}
+
+func TestStreamParserUnexpectedEOF(t *testing.T) {
+	msgs, _ := streamOfHeartbeats(2)
+	p := NewStreamParser(strings.NewReader(msgs[0]+msgs[1][:20]), 0)
+
+	_, err := p.Next()
+	require.Nil(t, err)
+	_, err = p.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func BenchmarkStreamParser_Next(b *testing.B) {
+	_, stream := streamOfHeartbeats(100)
+	for i := 0; i < b.N; i++ {
+		p := NewStreamParser(strings.NewReader(stream), 0)
+		for {
+			if _, err := p.Next(); err != nil {
+				break
+			}
+		}
+	}
+}
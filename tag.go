@@ -15,6 +15,8 @@
 
 package quickfix
 
+import "github.com/quickfixgo/quickfix/datadictionary"
+
 // Tag is a typed int representing a FIX tag.
 type Tag int
 
@@ -77,6 +79,17 @@ const (
 	tagCheckSum        Tag = 10
 )
 
+// Name returns the name of the field the tag identifies in dd, e.g. Symbol for tag 55, or Unknown if dd is nil or
+// does not define the tag.
+func (t Tag) Name(dd *datadictionary.DataDictionary) string {
+	if dd != nil {
+		if fieldType, ok := dd.FieldTypeByTag[int(t)]; ok {
+			return fieldType.Name()
+		}
+	}
+	return "Unknown"
+}
+
 // IsTrailer returns true if tag belongs in the message trailer.
 func (t Tag) IsTrailer() bool {
 	switch t {
@@ -0,0 +1,35 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+func TestTagName(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX42.xml")
+	require.Nil(t, err)
+
+	assert.Equal(t, "Symbol", Tag(55).Name(dict))
+	assert.Equal(t, "MsgType", tagMsgType.Name(dict))
+	assert.Equal(t, "Unknown", Tag(9999).Name(dict))
+	assert.Equal(t, "Unknown", Tag(55).Name(nil))
+}
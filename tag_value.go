@@ -29,15 +29,28 @@ type TagValue struct {
 }
 
 func (tv *TagValue) init(tag Tag, value []byte) {
-	tv.bytes = strconv.AppendInt(nil, int64(tag), 10)
-	tv.bytes = append(tv.bytes, []byte("=")...)
+	// Room for the tag, '=', the value and SOH, so that the field is built with a single allocation.
+	tv.bytes = make([]byte, 0, 12+len(value))
+	tv.bytes = strconv.AppendInt(tv.bytes, int64(tag), 10)
+	tv.bytes = append(tv.bytes, '=')
 	tv.bytes = append(tv.bytes, value...)
-	tv.bytes = append(tv.bytes, []byte("")...)
+	tv.bytes = append(tv.bytes, '')
 
 	tv.tag = tag
 	tv.value = value
 }
 
+// clone returns a copy of the TagValue that shares no memory with it. The value of the copy is held by its bytes,
+// which end with the value and SOH, so that the copy takes a single allocation.
+func (tv TagValue) clone() TagValue {
+	if len(tv.bytes) < len(tv.value)+1 {
+		return TagValue{tag: tv.tag, value: bytes.Clone(tv.value), bytes: bytes.Clone(tv.bytes)}
+	}
+	b := bytes.Clone(tv.bytes)
+	end := len(b) - 1
+	return TagValue{tag: tv.tag, value: b[end-len(tv.value) : end : end], bytes: b}
+}
+
 func (tv *TagValue) parse(rawFieldBytes []byte) error {
 	var sepIndex int
 
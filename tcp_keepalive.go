@@ -0,0 +1,34 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import "net"
+
+// setKeepAlive configures the TCP keepalive probes of the session's connection as set by TCPKeepAlive. Failures
+// are logged, as the session can still run with the default probes.
+func (s *session) setKeepAlive(conn net.Conn) {
+	if !s.ConfigureTCPKeepAlive {
+		return
+	}
+	tcpConn, ok := tcpConnOf(conn)
+	if !ok {
+		s.log.OnEventf("Unable to set TCPKeepAlive: %v is not a TCP connection", conn.RemoteAddr())
+		return
+	}
+	if err := tcpConn.SetKeepAliveConfig(s.TCPKeepAlive); err != nil {
+		s.log.OnEventf("Unable to set TCPKeepAlive: %v", err)
+	}
+}
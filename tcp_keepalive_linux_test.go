@@ -0,0 +1,63 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+//go:build linux
+
+package quickfix
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+func TestSetKeepAlive(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.Nil(t, err)
+	defer listener.Close()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.Nil(t, err)
+	defer conn.Close()
+
+	logger := &eventLog{}
+	s := &session{log: logger}
+	s.ConfigureTCPKeepAlive = true
+	s.TCPKeepAlive = net.KeepAliveConfig{Enable: true, Idle: 60 * time.Second, Interval: 10 * time.Second, Count: 5}
+	s.setKeepAlive(conn)
+	assert.Empty(t, logger.events)
+
+	sockopt := func(level, opt int) int {
+		raw, err := conn.(*net.TCPConn).SyscallConn()
+		require.Nil(t, err)
+		var value int
+		require.Nil(t, raw.Control(func(fd uintptr) {
+			value, err = unix.GetsockoptInt(int(fd), level, opt)
+		}))
+		require.Nil(t, err)
+		return value
+	}
+	assert.Equal(t, 1, sockopt(unix.SOL_SOCKET, unix.SO_KEEPALIVE))
+	assert.Equal(t, 60, sockopt(unix.IPPROTO_TCP, unix.TCP_KEEPIDLE))
+	assert.Equal(t, 10, sockopt(unix.IPPROTO_TCP, unix.TCP_KEEPINTVL))
+	assert.Equal(t, 5, sockopt(unix.IPPROTO_TCP, unix.TCP_KEEPCNT))
+
+	s.TCPKeepAlive = net.KeepAliveConfig{Enable: false}
+	s.setKeepAlive(conn)
+	assert.Equal(t, 0, sockopt(unix.SOL_SOCKET, unix.SO_KEEPALIVE))
+}
@@ -20,6 +20,7 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 
 	"github.com/quickfixgo/quickfix/config"
@@ -54,7 +55,9 @@ func loadTLSConfig(settings *SessionSettings) (*tls.Config, error) {
 	if !settings.HasSetting(config.SocketPrivateKeyFile) &&
 		!settings.HasSetting(config.SocketCertificateFile) &&
 		!settings.HasSetting(config.SocketPrivateKeyBytes) &&
-		!settings.HasSetting(config.SocketCertificateBytes) {
+		!settings.HasSetting(config.SocketCertificateBytes) &&
+		!settings.HasSetting(config.SSLClientPrivateKey) &&
+		!settings.HasSetting(config.SSLClientCertificate) {
 		if !allowSkipClientCerts {
 			return nil, nil
 		}
@@ -106,10 +109,22 @@ func loadTLSConfig(settings *SessionSettings) (*tls.Config, error) {
 		tlsConfig.Certificates[0] = certificate
 	}
 
+	if settings.HasSetting(config.SSLClientPrivateKey) || settings.HasSetting(config.SSLClientCertificate) {
+		if err = loadClientCertificate(settings, tlsConfig); err != nil {
+			return nil, err
+		}
+	}
+
 	if !allowSkipClientCerts {
 		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
 	}
 
+	if settings.HasSetting(config.SSLClientAuthType) {
+		if tlsConfig.ClientAuth, err = clientAuthType(settings); err != nil {
+			return nil, err
+		}
+	}
+
 	if !settings.HasSetting(config.SocketCAFile) && !settings.HasSetting(config.SocketCABytes) {
 		return tlsConfig, nil
 	}
@@ -148,6 +163,49 @@ func loadTLSConfig(settings *SessionSettings) (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
+// loadClientCertificate configures the certificate an initiator presents when the acceptor requests one.
+// GetClientCertificate is only consulted by clients, so an acceptor sharing the settings keeps serving
+// its own certificate.
+func loadClientCertificate(settings *SessionSettings, tlsConfig *tls.Config) error {
+	privateKeyFile, err := settings.Setting(config.SSLClientPrivateKey)
+	if err != nil {
+		return err
+	}
+
+	certificateFile, err := settings.Setting(config.SSLClientCertificate)
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(certificateFile, privateKeyFile)
+	if err != nil {
+		return fmt.Errorf("failed to load client key pair: %w", err)
+	}
+
+	tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return &certificate, nil
+	}
+	return nil
+}
+
+// clientAuthType parses the SSLClientAuthType setting.
+func clientAuthType(settings *SessionSettings) (tls.ClientAuthType, error) {
+	authType, err := settings.Setting(config.SSLClientAuthType)
+	if err != nil {
+		return tls.NoClientCert, err
+	}
+
+	switch authType {
+	case "RequireAndVerifyClientCert":
+		return tls.RequireAndVerifyClientCert, nil
+	case "RequireAnyClientCert":
+		return tls.RequireAnyClientCert, nil
+	case "NoClientCert":
+		return tls.NoClientCert, nil
+	}
+	return tls.NoClientCert, IncorrectFormatForSetting{Setting: config.SSLClientAuthType, Value: []byte(authType)}
+}
+
 // defaultTLSConfig brought to you by https://github.com/gtank/cryptopasta/
 func defaultTLSConfig() *tls.Config {
 	return &tls.Config{
@@ -163,6 +221,24 @@ func defaultTLSConfig() *tls.Config {
 	}
 }
 
+// clientTLSConfig returns the TLS config of an initiator connection to address. Unless InsecureSkipVerify is true,
+// a server name is required to verify the received certificate: without SocketServerName, the host of address is
+// used, which is also sent for SNI unless it is an IP address. tlsConfig is shared by every connection attempt of
+// the session and is left unchanged, so that each address of SocketConnectHost<n> gets its own server name.
+func clientTLSConfig(tlsConfig *tls.Config, address string) *tls.Config {
+	if tlsConfig.InsecureSkipVerify || len(tlsConfig.ServerName) != 0 {
+		return tlsConfig
+	}
+
+	serverName := address
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		serverName = host
+	}
+	connTLSConfig := tlsConfig.Clone()
+	connTLSConfig.ServerName = serverName
+	return connTLSConfig
+}
+
 func setMinVersionExplicit(settings *SessionSettings, tlsConfig *tls.Config) {
 	if settings.HasSetting(config.SocketMinimumTLSVersion) {
 		minVersion, err := settings.Setting(config.SocketMinimumTLSVersion)
@@ -16,9 +16,19 @@
 package quickfix
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/suite"
 
@@ -172,6 +182,21 @@ func (s *TLSTestSuite) TestServerNameWithCerts() {
 	s.Equal("DummyServerNameWithCerts", tlsConfig.ServerName)
 }
 
+func (s *TLSTestSuite) TestClientTLSConfig() {
+	tlsConfig := defaultTLSConfig()
+	s.Equal("fix1.example.com", clientTLSConfig(tlsConfig, "fix1.example.com:5001").ServerName)
+	s.Equal("::1", clientTLSConfig(tlsConfig, "[::1]:5001").ServerName)
+	s.Equal("fix2.example.com", clientTLSConfig(tlsConfig, "fix2.example.com:5001").ServerName)
+	s.Empty(tlsConfig.ServerName, "the shared config should be left unchanged")
+
+	tlsConfig.ServerName = "fix.example.com"
+	s.Same(tlsConfig, clientTLSConfig(tlsConfig, "10.0.0.1:5001"))
+
+	tlsConfig.ServerName = ""
+	tlsConfig.InsecureSkipVerify = true
+	s.Empty(clientTLSConfig(tlsConfig, "fix1.example.com:5001").ServerName)
+}
+
 func (s *TLSTestSuite) TestInsecureSkipVerify() {
 	s.settings.GlobalSettings().Set(config.SocketInsecureSkipVerify, "Y")
 
@@ -331,3 +356,232 @@ func (s *TLSTestSuite) TestServerNameWithCertsFromBytes() {
 	s.NotNil(tlsConfig)
 	s.Equal("DummyServerNameWithCerts", tlsConfig.ServerName)
 }
+
+func (s *TLSTestSuite) TestClientAuthType() {
+	s.settings.GlobalSettings().Set(config.SocketPrivateKeyFile, s.PrivateKeyFile)
+	s.settings.GlobalSettings().Set(config.SocketCertificateFile, s.CertificateFile)
+
+	for value, expected := range map[string]tls.ClientAuthType{
+		"RequireAndVerifyClientCert": tls.RequireAndVerifyClientCert,
+		"RequireAnyClientCert":       tls.RequireAnyClientCert,
+		"NoClientCert":               tls.NoClientCert,
+	} {
+		s.settings.GlobalSettings().Set(config.SSLClientAuthType, value)
+		tlsConfig, err := loadTLSConfig(s.settings.GlobalSettings())
+		s.Require().Nil(err)
+		s.Equal(expected, tlsConfig.ClientAuth, value)
+	}
+
+	s.settings.GlobalSettings().Set(config.SSLClientAuthType, "Sometimes")
+	_, err := loadTLSConfig(s.settings.GlobalSettings())
+	s.EqualError(err, `"Sometimes" is invalid for SSLClientAuthType`)
+}
+
+func (s *TLSTestSuite) TestLoadTLSClientCertificateMissingKeyOrCert() {
+	s.settings.GlobalSettings().Set(config.SSLClientPrivateKey, s.PrivateKeyFile)
+	_, err := loadTLSConfig(s.settings.GlobalSettings())
+	s.EqualError(err, "Conditionally Required Setting: SSLClientCertificate")
+
+	s.SetupTest()
+	s.settings.GlobalSettings().Set(config.SSLClientCertificate, s.CertificateFile)
+	_, err = loadTLSConfig(s.settings.GlobalSettings())
+	s.EqualError(err, "Conditionally Required Setting: SSLClientPrivateKey")
+}
+
+func (s *TLSTestSuite) TestLoadTLSInvalidClientCertificate() {
+	s.settings.GlobalSettings().Set(config.SSLClientPrivateKey, "blah")
+	s.settings.GlobalSettings().Set(config.SSLClientCertificate, "foo")
+	_, err := loadTLSConfig(s.settings.GlobalSettings())
+	s.EqualError(err, "failed to load client key pair: open foo: no such file or directory")
+}
+
+func (s *TLSTestSuite) TestLoadTLSClientCertificate() {
+	s.settings.GlobalSettings().Set(config.SSLClientPrivateKey, s.PrivateKeyFile)
+	s.settings.GlobalSettings().Set(config.SSLClientCertificate, s.CertificateFile)
+
+	tlsConfig, err := loadTLSConfig(s.settings.GlobalSettings())
+	s.Nil(err)
+	s.NotNil(tlsConfig)
+
+	s.Len(tlsConfig.Certificates, 0)
+	s.NotNil(tlsConfig.GetClientCertificate)
+}
+
+// mutualTLSFiles holds the PEM files of a CA and of a server and a client certificate it issued.
+type mutualTLSFiles struct {
+	CAFile, ServerCertFile, ServerKeyFile, ClientCertFile, ClientKeyFile string
+	// UntrustedCertFile and UntrustedKeyFile hold a self signed client certificate.
+	UntrustedCertFile, UntrustedKeyFile string
+}
+
+func (s *TLSTestSuite) writeMutualTLSFiles() mutualTLSFiles {
+	dir := s.T().TempDir()
+	files := mutualTLSFiles{
+		CAFile:            filepath.Join(dir, "ca.crt"),
+		ServerCertFile:    filepath.Join(dir, "server.crt"),
+		ServerKeyFile:     filepath.Join(dir, "server.key"),
+		ClientCertFile:    filepath.Join(dir, "client.crt"),
+		ClientKeyFile:     filepath.Join(dir, "client.key"),
+		UntrustedCertFile: filepath.Join(dir, "untrusted.crt"),
+		UntrustedKeyFile:  filepath.Join(dir, "untrusted.key"),
+	}
+
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "quickfix test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caKey, caCert := s.writeCertificate(caTemplate, nil, nil, files.CAFile, "")
+
+	s.writeCertificate(&x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}, caCert, caKey, files.ServerCertFile, files.ServerKeyFile)
+
+	clientTemplate := &x509.Certificate{
+		SerialNumber: big.NewInt(3),
+		Subject:      pkix.Name{CommonName: "initiator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	s.writeCertificate(clientTemplate, caCert, caKey, files.ClientCertFile, files.ClientKeyFile)
+	s.writeCertificate(clientTemplate, nil, nil, files.UntrustedCertFile, files.UntrustedKeyFile)
+
+	return files
+}
+
+// writeCertificate creates a certificate from template, signed by parent or self signed if parent is nil.
+func (s *TLSTestSuite) writeCertificate(template, parent *x509.Certificate, parentKey *ecdsa.PrivateKey, certFile, keyFile string) (*ecdsa.PrivateKey, *x509.Certificate) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	s.Require().NoError(err)
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	s.Require().NoError(err)
+	cert, err := x509.ParseCertificate(der)
+	s.Require().NoError(err)
+	s.Require().NoError(os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+
+	if keyFile != "" {
+		keyDER, err := x509.MarshalECPrivateKey(key)
+		s.Require().NoError(err)
+		s.Require().NoError(os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	}
+	return key, cert
+}
+
+// handshake connects a client configured by initiatorSettings to a server configured by acceptorSettings,
+// and returns the certificates the server received from the client.
+func (s *TLSTestSuite) handshake(acceptorSettings, initiatorSettings *SessionSettings) ([]*x509.Certificate, error) {
+	serverConfig, err := loadTLSConfig(acceptorSettings)
+	s.Require().NoError(err)
+	clientConfig, err := loadTLSConfig(initiatorSettings)
+	s.Require().NoError(err)
+
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", serverConfig)
+	s.Require().NoError(err)
+	defer func() { _ = listener.Close() }()
+
+	type result struct {
+		peerCerts []*x509.Certificate
+		err       error
+	}
+	accepted := make(chan result, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			accepted <- result{err: err}
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		tlsConn := conn.(*tls.Conn)
+		err = tlsConn.Handshake()
+		accepted <- result{peerCerts: tlsConn.ConnectionState().PeerCertificates, err: err}
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	if err == nil {
+		// With TLS 1.3 the client learns about a rejected certificate on its first read.
+		_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+		_, _ = conn.Read(make([]byte, 1))
+		_ = conn.Close()
+	}
+
+	r := <-accepted
+	return r.peerCerts, r.err
+}
+
+func (s *TLSTestSuite) newMutualTLSSettings(files mutualTLSFiles) (acceptor, initiator *SessionSettings) {
+	acceptor = NewSessionSettings()
+	acceptor.Set(config.SocketCertificateFile, files.ServerCertFile)
+	acceptor.Set(config.SocketPrivateKeyFile, files.ServerKeyFile)
+	acceptor.Set(config.SocketCAFile, files.CAFile)
+
+	initiator = NewSessionSettings()
+	initiator.Set(config.SocketUseSSL, "Y")
+	initiator.Set(config.SocketCAFile, files.CAFile)
+	return acceptor, initiator
+}
+
+func (s *TLSTestSuite) TestMutualTLSHandshake() {
+	files := s.writeMutualTLSFiles()
+	acceptor, initiator := s.newMutualTLSSettings(files)
+	acceptor.Set(config.SSLClientAuthType, "RequireAndVerifyClientCert")
+	initiator.Set(config.SSLClientCertificate, files.ClientCertFile)
+	initiator.Set(config.SSLClientPrivateKey, files.ClientKeyFile)
+
+	peerCerts, err := s.handshake(acceptor, initiator)
+	s.Require().NoError(err)
+	s.Require().Len(peerCerts, 1)
+	s.Equal("initiator", peerCerts[0].Subject.CommonName)
+}
+
+func (s *TLSTestSuite) TestMutualTLSHandshakeWithoutClientCertificate() {
+	files := s.writeMutualTLSFiles()
+	acceptor, initiator := s.newMutualTLSSettings(files)
+	acceptor.Set(config.SSLClientAuthType, "RequireAndVerifyClientCert")
+
+	_, err := s.handshake(acceptor, initiator)
+	s.Error(err)
+}
+
+func (s *TLSTestSuite) TestMutualTLSHandshakeWithUntrustedClientCertificate() {
+	files := s.writeMutualTLSFiles()
+	acceptor, initiator := s.newMutualTLSSettings(files)
+	initiator.Set(config.SSLClientCertificate, files.UntrustedCertFile)
+	initiator.Set(config.SSLClientPrivateKey, files.UntrustedKeyFile)
+
+	acceptor.Set(config.SSLClientAuthType, "RequireAndVerifyClientCert")
+	_, err := s.handshake(acceptor, initiator)
+	s.Error(err)
+
+	acceptor.Set(config.SSLClientAuthType, "RequireAnyClientCert")
+	peerCerts, err := s.handshake(acceptor, initiator)
+	s.Require().NoError(err)
+	s.Len(peerCerts, 1)
+}
+
+func (s *TLSTestSuite) TestMutualTLSHandshakeNoClientCert() {
+	files := s.writeMutualTLSFiles()
+	acceptor, initiator := s.newMutualTLSSettings(files)
+	acceptor.Set(config.SSLClientAuthType, "NoClientCert")
+	initiator.Set(config.SSLClientCertificate, files.ClientCertFile)
+	initiator.Set(config.SSLClientPrivateKey, files.ClientKeyFile)
+
+	peerCerts, err := s.handshake(acceptor, initiator)
+	s.Require().NoError(err)
+	s.Empty(peerCerts)
+}
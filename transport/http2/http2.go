@@ -0,0 +1,116 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+// Package http2 carries FIX sessions as HTTP/2 streams, so that the sessions an initiator maintains with the
+// same host share a single TCP connection.
+//
+// Each session is a POST request to /fix/<SessionID>. The request body carries the messages sent by the
+// initiator and the response body those sent by the acceptor, framed as on a TCP connection.
+//
+// Initiators open streams with a Transport:
+//
+//	initiator.SetSessionDialer(http2.NewTransport(tlsConfig))
+//
+// and acceptors accept them from a Listener:
+//
+//	listener, err := http2.Listen(":5001", tlsConfig)
+//	acceptor.AddListener(listener)
+package http2
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+const sessionPathPrefix = "/fix/"
+
+var errDeadlineNotSupported = errors.New("http2: deadlines are not supported on FIX streams")
+
+// sessionPath returns the request path of the stream of a session.
+func sessionPath(sessionID quickfix.SessionID) string {
+	return sessionPathPrefix + url.PathEscape(sessionID.String())
+}
+
+// Addr is the address of one end of a FIX stream.
+type Addr struct {
+	// Conn is the address of the HTTP/2 connection carrying the stream.
+	Conn net.Addr
+
+	// Path is the request path naming the session of the stream.
+	Path string
+}
+
+// Network returns "http2".
+func (a Addr) Network() string { return "http2" }
+
+func (a Addr) String() string {
+	if a.Conn == nil {
+		return a.Path
+	}
+	return a.Conn.String() + a.Path
+}
+
+// streamConn exposes a FIX stream as a net.Conn.
+type streamConn struct {
+	io.Reader
+	localAddr, remoteAddr net.Addr
+
+	// writeMu guards w, which must not be used once the stream is closed.
+	writeMu sync.Mutex
+	w       io.Writer
+	flush   func() error
+	closed  bool
+
+	closeOnce sync.Once
+	closeErr  error
+	onClose   func() error
+}
+
+func (c *streamConn) Write(p []byte) (int, error) {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if c.closed {
+		return 0, net.ErrClosed
+	}
+
+	n, err := c.w.Write(p)
+	if err == nil && c.flush != nil {
+		err = c.flush()
+	}
+	return n, err
+}
+
+func (c *streamConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.writeMu.Lock()
+		c.closed = true
+		c.writeMu.Unlock()
+		c.closeErr = c.onClose()
+	})
+	return c.closeErr
+}
+
+func (c *streamConn) LocalAddr() net.Addr  { return c.localAddr }
+func (c *streamConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+func (c *streamConn) SetDeadline(time.Time) error      { return errDeadlineNotSupported }
+func (c *streamConn) SetReadDeadline(time.Time) error  { return errDeadlineNotSupported }
+func (c *streamConn) SetWriteDeadline(time.Time) error { return errDeadlineNotSupported }
@@ -0,0 +1,235 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package http2
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+var (
+	sessionA = quickfix.SessionID{BeginString: quickfix.BeginStringFIX44, SenderCompID: "INIT", TargetCompID: "ACCA"}
+	sessionB = quickfix.SessionID{BeginString: quickfix.BeginStringFIX44, SenderCompID: "INIT", TargetCompID: "ACCB"}
+)
+
+// dialStream opens the stream of a session, and returns both of its ends.
+func dialStream(t *testing.T, transport *Transport, listener *Listener, sessionID quickfix.SessionID) (client, server net.Conn) {
+	accepted := make(chan net.Conn, 1)
+	go func() {
+		conn, err := listener.Accept()
+		assert.Nil(t, err)
+		accepted <- conn
+	}()
+
+	client, err := transport.DialSession(context.Background(), sessionID, listener.Addr().String())
+	require.Nil(t, err)
+
+	select {
+	case server = <-accepted:
+		require.NotNil(t, server)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timed out accepting a stream")
+	}
+	return client, server
+}
+
+func exchange(t *testing.T, from, to net.Conn, payload string) {
+	_, err := from.Write([]byte(payload))
+	require.Nil(t, err)
+
+	received := make([]byte, len(payload))
+	_, err = io.ReadFull(to, received)
+	require.Nil(t, err)
+	assert.Equal(t, payload, string(received))
+}
+
+func testStreams(t *testing.T, serverTLS, clientTLS *tls.Config) {
+	listener, err := Listen("127.0.0.1:0", serverTLS)
+	require.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+
+	transport := NewTransport(clientTLS)
+	defer transport.CloseIdleConnections()
+
+	clientA, serverA := dialStream(t, transport, listener, sessionA)
+	clientB, serverB := dialStream(t, transport, listener, sessionB)
+
+	exchange(t, clientA, serverA, "8=FIX.4.4\x01A")
+	exchange(t, serverA, clientA, "8=FIX.4.4\x01a")
+	exchange(t, clientB, serverB, "8=FIX.4.4\x01B")
+	exchange(t, serverB, clientB, "8=FIX.4.4\x01b")
+
+	// Both streams share a single TCP connection.
+	localA, ok := clientA.LocalAddr().(Addr)
+	require.True(t, ok)
+	localB, ok := clientB.LocalAddr().(Addr)
+	require.True(t, ok)
+	assert.Equal(t, localA.Conn.String(), localB.Conn.String())
+	assert.Equal(t, sessionPath(sessionA), localA.Path)
+	assert.Equal(t, sessionPath(sessionB), serverB.LocalAddr().(Addr).Path)
+
+	// Closing a stream leaves the other one open.
+	require.Nil(t, clientA.Close())
+	_, err = serverA.Read(make([]byte, 1))
+	assert.NotNil(t, err)
+	exchange(t, clientB, serverB, "8=FIX.4.4\x01B")
+
+	require.Nil(t, serverB.Close())
+	_, err = clientB.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+	_, err = serverB.Write([]byte("8=FIX.4.4\x01"))
+	assert.ErrorIs(t, err, net.ErrClosed)
+	require.Nil(t, clientB.Close())
+}
+
+func TestStreamsCleartext(t *testing.T) {
+	testStreams(t, nil, nil)
+}
+
+func TestStreamsTLS(t *testing.T) {
+	certificate, err := tls.LoadX509KeyPair("../../_test_data/localhost.crt", "../../_test_data/localhost.key")
+	require.Nil(t, err)
+
+	testStreams(t,
+		&tls.Config{Certificates: []tls.Certificate{certificate}, MinVersion: tls.VersionTLS12},
+		&tls.Config{InsecureSkipVerify: true, MinVersion: tls.VersionTLS12}, //nolint:gosec // self signed test certificate
+	)
+}
+
+func TestDialSessionCanceled(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0", nil)
+	require.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+
+	// Nobody accepts the stream, so it never opens.
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = NewTransport(nil).DialSession(ctx, sessionA, listener.Addr().String())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestListenerRejectsOtherRequests(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0", nil)
+	require.Nil(t, err)
+	defer func() { _ = listener.Close() }()
+
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	require.Nil(t, err)
+	defer func() { _ = conn.Close() }()
+
+	_, err = fmt.Fprintf(conn, "POST /fix/x HTTP/1.1\r\nHost: %s\r\nContent-Length: 0\r\n\r\n", listener.Addr())
+	require.Nil(t, err)
+	status := make([]byte, len("HTTP/1.1 400"))
+	_, err = io.ReadFull(conn, status)
+	require.Nil(t, err)
+	assert.Equal(t, "HTTP/1.1 400", string(status))
+}
+
+type logonApp struct {
+	logons chan quickfix.SessionID
+}
+
+func (a logonApp) OnSessionCreate(quickfix.SessionID)                {}
+func (a logonApp) OnCreate(quickfix.SessionID)                       {}
+func (a logonApp) OnLogon(sessionID quickfix.SessionID)              { a.logons <- sessionID }
+func (a logonApp) OnLogout(quickfix.SessionID)                       {}
+func (a logonApp) ToAdmin(*quickfix.Message, quickfix.SessionID)     {}
+func (a logonApp) ToApp(*quickfix.Message, quickfix.SessionID) error { return nil }
+func (a logonApp) FromAdmin(*quickfix.Message, quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+func (a logonApp) FromApp(*quickfix.Message, quickfix.SessionID) quickfix.MessageRejectError {
+	return nil
+}
+
+func TestAcceptorAndInitiator(t *testing.T) {
+	listener, err := Listen("127.0.0.1:0", nil)
+	require.Nil(t, err)
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	require.Nil(t, err)
+
+	acceptorSettings, err := quickfix.ParseSettings(strings.NewReader(`
+[DEFAULT]
+SenderCompID=ACCA
+TargetCompID=INIT
+BeginString=FIX.4.4
+
+[SESSION]
+
+[SESSION]
+SenderCompID=ACCB
+`))
+	require.Nil(t, err)
+	acceptorApp := logonApp{logons: make(chan quickfix.SessionID, 2)}
+	acceptor, err := quickfix.NewAcceptor(acceptorApp, quickfix.NewMemoryStoreFactory(), acceptorSettings, quickfix.NewNullLogFactory())
+	require.Nil(t, err)
+	acceptor.AddListener(listener)
+	require.Nil(t, acceptor.Start())
+	defer acceptor.Stop()
+
+	initiatorSettings, err := quickfix.ParseSettings(strings.NewReader(fmt.Sprintf(`
+[DEFAULT]
+SenderCompID=INIT
+BeginString=FIX.4.4
+HeartBtInt=30
+ReconnectInterval=1
+SocketConnectHost=%s
+SocketConnectPort=%s
+
+[SESSION]
+TargetCompID=ACCA
+
+[SESSION]
+TargetCompID=ACCB
+`, host, port)))
+	require.Nil(t, err)
+	initiatorApp := logonApp{logons: make(chan quickfix.SessionID, 2)}
+	initiator, err := quickfix.NewInitiator(initiatorApp, quickfix.NewMemoryStoreFactory(), initiatorSettings, quickfix.NewNullLogFactory())
+	require.Nil(t, err)
+	transport := NewTransport(nil)
+	initiator.SetSessionDialer(transport)
+	require.Nil(t, initiator.Start())
+	defer initiator.Stop()
+
+	loggedOn := make(map[quickfix.SessionID]bool)
+	for len(loggedOn) < 2 {
+		select {
+		case sessionID := <-initiatorApp.logons:
+			loggedOn[sessionID] = true
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for logons")
+		}
+	}
+	assert.True(t, loggedOn[sessionA])
+	assert.True(t, loggedOn[sessionB])
+
+	remoteA, ok := acceptor.RemoteAddr(quickfix.SessionID{BeginString: quickfix.BeginStringFIX44, SenderCompID: "ACCA", TargetCompID: "INIT"})
+	require.True(t, ok)
+	remoteB, ok := acceptor.RemoteAddr(quickfix.SessionID{BeginString: quickfix.BeginStringFIX44, SenderCompID: "ACCB", TargetCompID: "INIT"})
+	require.True(t, ok)
+	assert.Equal(t, remoteA.(Addr).Conn.String(), remoteB.(Addr).Conn.String())
+}
@@ -0,0 +1,157 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package http2
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+
+	xhttp2 "golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Listener accepts the FIX streams opened by a Transport on an HTTP/2 server endpoint. Pass it to
+// quickfix.Acceptor.AddListener.
+type Listener struct {
+	listener net.Listener
+	server   *http.Server
+	streams  chan *streamConn
+
+	done      chan struct{}
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Listen announces on the TCP address and serves HTTP/2 with the TLS configuration tlsConfig, or
+// cleartext HTTP/2 (h2c) if tlsConfig is nil.
+func Listen(address string, tlsConfig *tls.Config) (*Listener, error) {
+	netListener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &Listener{
+		listener: netListener,
+		streams:  make(chan *streamConn),
+		done:     make(chan struct{}),
+	}
+	h2Server := &xhttp2.Server{}
+	l.server = &http.Server{Handler: http.HandlerFunc(l.serveStream)}
+	if tlsConfig == nil {
+		l.server.Handler = h2c.NewHandler(l.server.Handler, h2Server)
+	} else {
+		l.server.TLSConfig = tlsConfig.Clone()
+		if err := xhttp2.ConfigureServer(l.server, h2Server); err != nil {
+			_ = netListener.Close()
+			return nil, err
+		}
+		netListener = tls.NewListener(netListener, l.server.TLSConfig)
+	}
+
+	go func() { _ = l.server.Serve(netListener) }()
+	return l, nil
+}
+
+// serveStream hands the stream of a request to Accept, and keeps the request open until the stream is closed.
+func (l *Listener) serveStream(w http.ResponseWriter, r *http.Request) {
+	if r.ProtoMajor != 2 || r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, sessionPathPrefix) {
+		http.Error(w, "expected an HTTP/2 POST to "+sessionPathPrefix+"<SessionID>", http.StatusBadRequest)
+		return
+	}
+	select {
+	case <-l.done:
+		http.Error(w, "listener closed", http.StatusServiceUnavailable)
+		return
+	default:
+	}
+
+	localAddr, _ := r.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	remoteAddr, _ := net.ResolveTCPAddr("tcp", r.RemoteAddr)
+	rc := http.NewResponseController(w)
+	closed := make(chan struct{})
+	conn := &streamConn{
+		Reader:     r.Body,
+		localAddr:  Addr{Conn: localAddr, Path: r.URL.EscapedPath()},
+		remoteAddr: Addr{Conn: remoteAddr, Path: r.URL.EscapedPath()},
+		w:          w,
+		flush:      rc.Flush,
+		onClose: func() error {
+			close(closed)
+			return nil
+		},
+	}
+	if remoteAddr == nil {
+		conn.remoteAddr = Addr{Path: r.URL.EscapedPath()}
+	}
+
+	// The response headers complete the opening of the stream, so they are only sent once it is accepted,
+	// and before anything is written to it.
+	conn.writeMu.Lock()
+	select {
+	case l.streams <- conn:
+	case <-l.done:
+		conn.writeMu.Unlock()
+		http.Error(w, "listener closed", http.StatusServiceUnavailable)
+		return
+	case <-r.Context().Done():
+		conn.writeMu.Unlock()
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	err := rc.Flush()
+	conn.writeMu.Unlock()
+	if err != nil {
+		_ = conn.Close()
+		return
+	}
+
+	select {
+	case <-closed:
+	case <-r.Context().Done():
+		// The response writer must not be used once the handler returns.
+		_ = conn.Close()
+	}
+}
+
+// Accept waits for and returns the next FIX stream.
+func (l *Listener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.streams:
+		return conn, nil
+	case <-l.done:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close stops accepting streams. Like the connections accepted from a TCP listener, the streams already
+// accepted remain open until they are closed.
+func (l *Listener) Close() error {
+	l.closeOnce.Do(func() {
+		close(l.done)
+		l.closeErr = l.listener.Close()
+		go func() { _ = l.server.Shutdown(context.Background()) }()
+	})
+	return l.closeErr
+}
+
+// Addr returns the TCP address the Listener serves on.
+func (l *Listener) Addr() net.Addr {
+	return l.listener.Addr()
+}
@@ -0,0 +1,111 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package http2
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+
+	xhttp2 "golang.org/x/net/http2"
+
+	"github.com/quickfixgo/quickfix"
+)
+
+// Transport opens FIX streams, multiplexing the streams to the same host over a single HTTP/2 connection.
+// It implements quickfix.SessionDialer.
+type Transport struct {
+	transport *xhttp2.Transport
+	scheme    string
+}
+
+// NewTransport returns a Transport connecting with the TLS configuration tlsConfig, or over cleartext
+// HTTP/2 (h2c) if tlsConfig is nil.
+func NewTransport(tlsConfig *tls.Config) *Transport {
+	t := &Transport{
+		transport: &xhttp2.Transport{TLSClientConfig: tlsConfig},
+		scheme:    "https",
+	}
+	if tlsConfig == nil {
+		t.scheme = "http"
+		t.transport.AllowHTTP = true
+		t.transport.DialTLSContext = func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var dialer net.Dialer
+			return dialer.DialContext(ctx, network, addr)
+		}
+	}
+	return t
+}
+
+// DialSession opens the stream of the session with the given ID to the Listener at address. ctx only
+// bounds the opening of the stream.
+func (t *Transport) DialSession(ctx context.Context, sessionID quickfix.SessionID, address string) (net.Conn, error) {
+	streamCtx, cancel := context.WithCancel(context.Background())
+
+	path := sessionPath(sessionID)
+	conn := &streamConn{}
+	streamCtx = httptrace.WithClientTrace(streamCtx, &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn.localAddr = Addr{Conn: info.Conn.LocalAddr(), Path: path}
+			conn.remoteAddr = Addr{Conn: info.Conn.RemoteAddr(), Path: path}
+		},
+	})
+
+	body, bodyWriter := io.Pipe()
+	req, err := http.NewRequestWithContext(streamCtx, http.MethodPost, t.scheme+"://"+address+path, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	stop := context.AfterFunc(ctx, cancel)
+	resp, err := t.transport.RoundTrip(req)
+	if !stop() {
+		if err == nil {
+			_ = resp.Body.Close()
+		}
+		cancel()
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		_ = resp.Body.Close()
+		cancel()
+		return nil, fmt.Errorf("http2: unexpected response status %s", resp.Status)
+	}
+
+	conn.Reader = resp.Body
+	conn.w = bodyWriter
+	conn.onClose = func() error {
+		_ = bodyWriter.Close()
+		err := resp.Body.Close()
+		cancel()
+		return err
+	}
+	return conn, nil
+}
+
+// CloseIdleConnections closes the connections no longer carrying any stream.
+func (t *Transport) CloseIdleConnections() {
+	t.transport.CloseIdleConnections()
+}
@@ -0,0 +1,176 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+// ValidationError describes a problem found by Validate.
+type ValidationError struct {
+	// Tag is the field the problem was found on, 0 if it concerns the whole message.
+	Tag Tag
+	// MsgType is the MsgType of the validated message.
+	MsgType string
+	// ErrorCode is the SessionRejectReason(373) a counterparty would reject the message with.
+	ErrorCode int
+	// Description is a human readable description of the problem.
+	Description string
+}
+
+func (e ValidationError) Error() string {
+	if e.Tag == 0 {
+		return fmt.Sprintf("%s: %s", e.MsgType, e.Description)
+	}
+	return fmt.Sprintf("%s: tag %d: %s", e.MsgType, e.Tag, e.Description)
+}
+
+// Validate checks msg against dd outside of a session, e.g. before sending it, and returns every problem
+// found, nil if there are none. Unlike a Validator, which stops at the first problem, it reports missing
+// required fields, unknown tags, values of the wrong type, values outside of a field's enums, and
+// repeating groups whose members do not match their NumInGroup field or delimiter.
+//
+// The header and trailer are checked against dd as well, so header fields the session sets on send,
+// e.g. MsgSeqNum or SendingTime, are reported as missing if msg does not carry them yet.
+func Validate(msg *Message, dd *datadictionary.DataDictionary) []ValidationError {
+	msgType, err := msg.Header.GetString(tagMsgType)
+	if err != nil {
+		return []ValidationError{newValidationError(msgType, RequiredTagMissing(tagMsgType))}
+	}
+	messageDef, ok := dd.Messages[msgType]
+	if !ok {
+		return []ValidationError{newValidationError(msgType, InvalidMessageType())}
+	}
+
+	v := messageValidation{dd: dd, messageDef: messageDef, msgType: msgType}
+	v.checkRequired(dd.Header, msg.Header.FieldMap)
+	v.checkRequired(messageDef, msg.Body.FieldMap)
+	v.checkRequired(dd.Trailer, msg.Trailer.FieldMap)
+
+	// A parsed message keeps its fields in the order they were received, otherwise they are validated in
+	// the order they would be sent.
+	fields := msg.fields
+	if len(fields) == 0 {
+		fields = msg.Header.appendFields(fields)
+		fields = msg.Body.appendFields(fields)
+		fields = msg.Trailer.appendFields(fields)
+	}
+	for _, field := range fields {
+		v.add(validateField(dd, defaultValidatorSettings, nil, field))
+	}
+	v.walk(fields)
+
+	return v.errs
+}
+
+// messageValidation collects the problems found by Validate.
+type messageValidation struct {
+	dd         *datadictionary.DataDictionary
+	messageDef *datadictionary.MessageDef
+	msgType    string
+	errs       []ValidationError
+}
+
+func newValidationError(msgType string, err MessageRejectError) ValidationError {
+	validationErr := ValidationError{MsgType: msgType, ErrorCode: err.RejectReason(), Description: err.Error()}
+	if tag := err.RefTagID(); tag != nil {
+		validationErr.Tag = *tag
+	}
+	return validationErr
+}
+
+func (v *messageValidation) add(err MessageRejectError) {
+	if err != nil {
+		v.errs = append(v.errs, newValidationError(v.msgType, err))
+	}
+}
+
+// checkRequired reports the required fields of def missing from fieldMap, in ascending tag order.
+func (v *messageValidation) checkRequired(def *datadictionary.MessageDef, fieldMap FieldMap) {
+	required := make([]int, 0, len(def.RequiredTags))
+	for tag := range def.RequiredTags {
+		required = append(required, tag)
+	}
+	sort.Ints(required)
+	for _, tag := range required {
+		if !fieldMap.Has(Tag(tag)) {
+			v.add(RequiredTagMissing(Tag(tag)))
+		}
+	}
+}
+
+// walk checks that the fields belong to the message, and that its repeating groups are well formed.
+func (v *messageValidation) walk(fields []TagValue) {
+	seen := make(datadictionary.TagSet)
+	for len(fields) > 0 {
+		tag := fields[0].tag
+
+		messageDef := v.messageDef
+		switch {
+		case tag.IsHeader():
+			messageDef = v.dd.Header
+		case tag.IsTrailer():
+			messageDef = v.dd.Trailer
+		}
+
+		if _, duplicate := seen[int(tag)]; duplicate {
+			v.add(tagAppearsMoreThanOnce(tag))
+		}
+		seen.Add(int(tag))
+
+		fieldDef, ok := messageDef.Fields[int(tag)]
+		if !ok {
+			// Tags unknown to the dictionary have already been reported as such.
+			if _, known := v.dd.FieldTypeByTag[int(tag)]; known {
+				v.add(TagNotDefinedForThisMessageType(tag))
+			}
+			fields = fields[1:]
+			continue
+		}
+
+		remaining, err := validateVisitField(fieldDef, fields)
+		if err != nil {
+			v.add(err)
+			remaining = skipGroup(fieldDef, fields)
+		}
+		fields = remaining
+	}
+}
+
+// skipGroup returns the fields following the repeating group that starts fields.
+func skipGroup(fieldDef *datadictionary.FieldDef, fields []TagValue) []TagValue {
+	members := make(datadictionary.TagSet)
+	addGroupTags(fieldDef, members)
+
+	fields = fields[1:]
+	for len(fields) > 0 {
+		if _, ok := members[int(fields[0].tag)]; !ok {
+			break
+		}
+		fields = fields[1:]
+	}
+	return fields
+}
+
+func addGroupTags(fieldDef *datadictionary.FieldDef, tags datadictionary.TagSet) {
+	for _, child := range fieldDef.Fields {
+		tags.Add(child.Tag())
+		addGroupTags(child, tags)
+	}
+}
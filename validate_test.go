@@ -0,0 +1,89 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/quickfixgo/quickfix/datadictionary"
+)
+
+func TestValidateMessage(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX43.xml")
+	require.Nil(t, err)
+
+	msg := createFIX43NewOrderSingle()
+	parties := NewRepeatingGroup(Tag(453), GroupTemplate{GroupElement(448), GroupElement(447), GroupElement(452)})
+	parties.Add().SetString(Tag(448), "PARTYID").SetString(Tag(447), "D").SetInt(Tag(452), 3)
+	msg.Body.SetGroup(parties)
+	assert.Empty(t, Validate(msg, dict))
+
+	msg.Body.Remove(Tag(11))
+	msg.Body.SetString(Tag(9999), "hello")
+	msg.Body.SetString(Tag(38), "abc")
+	msg.Body.SetString(Tag(54), "Z")
+	msg.Body.tagLookup[Tag(453)][0].init(Tag(453), []byte("2"))
+
+	assert.Equal(t, []ValidationError{
+		{Tag: 11, MsgType: "D", ErrorCode: rejectReasonRequiredTagMissing, Description: "Required tag missing"},
+		{Tag: 38, MsgType: "D", ErrorCode: rejectReasonIncorrectDataFormatForValue, Description: "Incorrect data format for value"},
+		{Tag: 54, MsgType: "D", ErrorCode: rejectReasonValueIsIncorrect, Description: "Value is incorrect (out of range) for this tag"},
+		{Tag: 9999, MsgType: "D", ErrorCode: rejectReasonInvalidTagNumber, Description: "Invalid tag number"},
+		{Tag: 453, MsgType: "D", ErrorCode: rejectReasonIncorrectNumInGroupCountForRepeatingGroup, Description: "Incorrect NumInGroup count for repeating group"},
+	}, Validate(msg, dict))
+}
+
+func TestValidateMessageMsgType(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX43.xml")
+	require.Nil(t, err)
+
+	msg := createFIX43NewOrderSingle()
+	msg.Header.Remove(tagMsgType)
+	errs := Validate(msg, dict)
+	require.Len(t, errs, 1)
+	assert.Equal(t, tagMsgType, errs[0].Tag)
+	assert.Equal(t, rejectReasonRequiredTagMissing, errs[0].ErrorCode)
+
+	msg.Header.SetString(tagMsgType, "ZZ")
+	errs = Validate(msg, dict)
+	require.Len(t, errs, 1)
+	assert.Equal(t, Tag(0), errs[0].Tag)
+	assert.Equal(t, rejectReasonInvalidMsgType, errs[0].ErrorCode)
+	assert.Equal(t, "ZZ: Invalid MsgType", errs[0].Error())
+}
+
+func TestValidateParsedMessage(t *testing.T) {
+	dict, err := datadictionary.Parse("spec/FIX43.xml")
+	require.Nil(t, err)
+
+	msg := NewMessage()
+	rawMsg := bytes.NewBufferString("8=FIX.4.39=17635=D34=249=TW52=20140329-22:38:4556=ISLD11=ID453=2448=PARTYID452=3523=SUBID448=PARTYID2452=378=179=ACCOUNT80=121=140=154=138=20055=INTC60=20140329-22:38:4510=178")
+	require.Nil(t, ParseMessageWithDataDictionary(msg, rawMsg, dict, dict))
+	assert.Empty(t, Validate(msg, dict))
+
+	// The group delimiter is missing from the second member.
+	msg = NewMessage()
+	rawMsg = bytes.NewBufferString("8=FIX.4.39=15335=D34=249=TW52=20140329-22:38:4556=ISLD11=ID453=2448=PARTYID452=3452=378=179=ACCOUNT80=121=140=154=138=20055=INTC60=20140329-22:38:4510=178")
+	require.Nil(t, ParseMessageWithDataDictionary(msg, rawMsg, dict, dict))
+	errs := Validate(msg, dict)
+	require.Len(t, errs, 1)
+	assert.Equal(t, Tag(453), errs[0].Tag)
+	assert.Equal(t, rejectReasonIncorrectNumInGroupCountForRepeatingGroup, errs[0].ErrorCode)
+}
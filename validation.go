@@ -436,6 +436,9 @@ func validateField(d *datadictionary.DataDictionary,
 	case "UTCTIMESTAMP", "TIME":
 		prototype = new(FIXUTCTimestamp)
 
+	case "UTCTIMESTAMPWITHNANOS":
+		prototype = new(FIXUTCTimestampNanos)
+
 	case "QTY", "QUANTITY":
 		fallthrough
 	case "AMT":
@@ -1204,3 +1204,22 @@ func TestValidateVisitField(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateFieldUTCTimestampWithNanos(t *testing.T) {
+	dict := &datadictionary.DataDictionary{FieldTypeByTag: map[int]*datadictionary.FieldType{
+		60: datadictionary.NewFieldType("TransactTime", 60, "UTCTIMESTAMPWITHNANOS"),
+	}}
+
+	for _, value := range []string{"20160208-22:07:16", "20160208-22:07:16.954123123"} {
+		var field TagValue
+		field.init(Tag(60), []byte(value))
+		assert.Nil(t, validateField(dict, defaultValidatorSettings, nil, field), value)
+	}
+
+	var field TagValue
+	field.init(Tag(60), []byte("20160208"))
+	reject := validateField(dict, defaultValidatorSettings, nil, field)
+	if assert.NotNil(t, reject) {
+		assert.Equal(t, rejectReasonIncorrectDataFormatForValue, reject.RejectReason())
+	}
+}
@@ -0,0 +1,114 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+const (
+	transportTCP       = "tcp"
+	transportWebSocket = "websocket"
+)
+
+// webSocketConfig describes the WebSocket handshake an initiator performs once connected.
+type webSocketConfig struct {
+	path   string
+	origin string
+}
+
+// loadWebSocketConfig returns nil unless the session settings select the websocket transport.
+func loadWebSocketConfig(settings *SessionSettings) (*webSocketConfig, error) {
+	if !settings.HasSetting(config.TransportType) {
+		return nil, nil
+	}
+
+	transportType, err := settings.Setting(config.TransportType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch strings.ToLower(transportType) {
+	case transportTCP:
+		return nil, nil
+	case transportWebSocket:
+	default:
+		return nil, IncorrectFormatForSetting{Setting: config.TransportType, Value: []byte(transportType)}
+	}
+
+	wsConfig := &webSocketConfig{path: "/"}
+	if settings.HasSetting(config.WebSocketPath) {
+		if wsConfig.path, err = settings.Setting(config.WebSocketPath); err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(wsConfig.path, "/") {
+			wsConfig.path = "/" + wsConfig.path
+		}
+	}
+
+	if settings.HasSetting(config.WebSocketOrigin) {
+		if wsConfig.origin, err = settings.Setting(config.WebSocketOrigin); err != nil {
+			return nil, err
+		}
+	}
+
+	return wsConfig, nil
+}
+
+// upgrade performs the WebSocket handshake over conn, connected to address, and returns a connection
+// carrying the FIX byte stream in binary frames.
+func (c *webSocketConfig) upgrade(ctx context.Context, conn net.Conn, address string, secure bool) (net.Conn, error) {
+	scheme, originScheme := "ws", "http"
+	if secure {
+		scheme, originScheme = "wss", "https"
+	}
+
+	origin := c.origin
+	if origin == "" {
+		origin = fmt.Sprintf("%s://%s/", originScheme, address)
+	}
+
+	wsConfig, err := websocket.NewConfig(fmt.Sprintf("%s://%s%s", scheme, address, c.path), origin)
+	if err != nil {
+		return nil, err
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	ws, err := websocket.NewClient(wsConfig, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = conn.SetDeadline(time.Time{}); err != nil {
+		return nil, err
+	}
+
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
@@ -0,0 +1,99 @@
+// Copyright (c) quickfixengine.org  All rights reserved.
+//
+// This file may be distributed under the terms of the quickfixengine.org
+// license as defined by quickfixengine.org and appearing in the file
+// LICENSE included in the packaging of this file.
+//
+// This file is provided AS IS with NO WARRANTY OF ANY KIND, INCLUDING
+// THE WARRANTY OF DESIGN, MERCHANTABILITY AND FITNESS FOR A
+// PARTICULAR PURPOSE.
+//
+// See http://www.quickfixengine.org/LICENSE for licensing information.
+//
+// Contact ask@quickfixengine.org if any conditions of this licensing
+// are not clear to you.
+
+package quickfix
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	"github.com/quickfixgo/quickfix/config"
+)
+
+func TestLoadWebSocketConfig(t *testing.T) {
+	settings := NewSessionSettings()
+	wsConfig, err := loadWebSocketConfig(settings)
+	require.Nil(t, err)
+	assert.Nil(t, wsConfig)
+
+	settings.Set(config.TransportType, "tcp")
+	wsConfig, err = loadWebSocketConfig(settings)
+	require.Nil(t, err)
+	assert.Nil(t, wsConfig)
+
+	settings.Set(config.TransportType, "WebSocket")
+	wsConfig, err = loadWebSocketConfig(settings)
+	require.Nil(t, err)
+	require.NotNil(t, wsConfig)
+	assert.Equal(t, "/", wsConfig.path)
+	assert.Empty(t, wsConfig.origin)
+
+	settings.Set(config.WebSocketPath, "fix?venue=1")
+	settings.Set(config.WebSocketOrigin, "https://trader.example.com")
+	wsConfig, err = loadWebSocketConfig(settings)
+	require.Nil(t, err)
+	assert.Equal(t, "/fix?venue=1", wsConfig.path)
+	assert.Equal(t, "https://trader.example.com", wsConfig.origin)
+
+	settings.Set(config.TransportType, "carrier-pigeon")
+	_, err = loadWebSocketConfig(settings)
+	assert.EqualError(t, err, `"carrier-pigeon" is invalid for TransportType`)
+}
+
+func TestWebSocketUpgrade(t *testing.T) {
+	requests := make(chan *http.Request, 1)
+	server := httptest.NewServer(websocket.Handler(func(ws *websocket.Conn) {
+		requests <- ws.Request()
+		ws.PayloadType = websocket.BinaryFrame
+		_, _ = io.Copy(ws, ws)
+	}))
+	defer server.Close()
+
+	address := strings.TrimPrefix(server.URL, "http://")
+	netConn, err := net.Dial("tcp", address)
+	require.Nil(t, err)
+
+	wsConfig := &webSocketConfig{path: "/fix"}
+	conn, err := wsConfig.upgrade(context.Background(), netConn, address, false)
+	require.Nil(t, err)
+	defer func() { _ = conn.Close() }()
+	require.Nil(t, conn.SetDeadline(time.Now().Add(5*time.Second)))
+
+	request := <-requests
+	assert.Equal(t, "/fix", request.URL.RequestURI())
+	assert.Equal(t, "http://"+address+"/", request.Header.Get("Origin"))
+
+	// A message split over several frames is reassembled by the parser.
+	heartbeat := "8=FIX.4.2\x019=45\x0135=0\x0134=2\x0149=TW\x0152=20231231-20:19:41\x0156=ISLD\x0110=220\x01"
+	_, err = conn.Write([]byte(heartbeat[:20]))
+	require.Nil(t, err)
+	_, err = conn.Write([]byte(heartbeat[20:]))
+	require.Nil(t, err)
+
+	msg, err := newParser(bufio.NewReader(conn)).ReadMessage()
+	require.Nil(t, err)
+	assert.Equal(t, heartbeat, msg.String())
+}